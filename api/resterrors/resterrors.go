@@ -11,29 +11,59 @@
 package resterrors
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/alesr/platform-go-challenge/internal/app/rest/handlers"
 	"github.com/alesr/platform-go-challenge/internal/assets"
+	"github.com/alesr/platform-go-challenge/internal/assets/audience"
 	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
 	"github.com/alesr/platform-go-challenge/internal/users"
 	"github.com/alesr/resterr"
 )
 
 // ErrorMap maps all possible errors returned by the Platform Go Challenge (PGC) REST API.
+// Errors added here are also listed in errorOrder, which sets the precedence among them.
 var ErrorMap = map[error]resterr.RESTErr{
 	// From users service
 	users.ErrUserNotFound: e(http.StatusNotFound, "User resource was not found"),
 
 	// From assets service
-	assets.ErrAssetNotFound: e(http.StatusNotFound, "Asset resource was not found"),
+	assets.ErrAssetNotFound:           e(http.StatusNotFound, "Asset resource was not found"),
+	assets.ErrAssetTypeMismatch:       e(http.StatusConflict, "Asset type cannot be changed"),
+	assets.ErrNotAudience:             e(http.StatusBadRequest, "Only audience assets have a size"),
+	assets.ErrEmptyChartTitle:         e(http.StatusBadRequest, "Chart title is required"),
+	assets.ErrEmptyAxisLabel:          e(http.StatusBadRequest, "Chart axis labels are required"),
+	assets.ErrInvalidChartData:        e(http.StatusBadRequest, "Invalid chart data"),
+	assets.ErrEmptyInsight:            e(http.StatusBadRequest, "Insight is required"),
+	assets.ErrInsightTooLong:          e(http.StatusBadRequest, "Insight is too long"),
+	assets.ErrEmptyGender:             e(http.StatusBadRequest, "Audience genders are required and cannot be empty"),
+	assets.ErrEmptyBirthCountry:       e(http.StatusBadRequest, "Audience birth countries are required and cannot be empty"),
+	assets.ErrInvalidAgeGroup:         e(http.StatusBadRequest, "Invalid audience age groups"),
+	assets.ErrInvalidSocialMediaHours: e(http.StatusBadRequest, "Invalid audience social media hours"),
+	assets.ErrInvalidPurchases:        e(http.StatusBadRequest, "Invalid audience last month purchases"),
+	assets.ErrFieldTooLong:            e(http.StatusBadRequest, "Asset field is too long"),
+	assets.ErrTooManyValues:           e(http.StatusBadRequest, "Asset field has too many values"),
+	assets.ErrDuplicateValue:          e(http.StatusBadRequest, "Asset field has duplicate values"),
+	assets.ErrInvalidRange:            e(http.StatusBadRequest, "Invalid range, min cannot be above max"),
+	audience.ErrInvalidExpression:     e(http.StatusBadRequest, "Invalid audience expression"),
 
 	// From favorites service
 
-	favorites.ErrInvalidAssetID:        e(http.StatusBadRequest, "Invalid asset ID"),
-	favorites.ErrFavoriteAssetNotFound: e(http.StatusNotFound, "Favorite asset not found"),
+	favorites.ErrInvalidAssetID:          e(http.StatusBadRequest, "Invalid asset ID"),
+	favorites.ErrFavoriteAssetNotFound:   e(http.StatusNotFound, "Favorite asset not found"),
+	favorites.ErrFavoriteJobNotFound:     e(http.StatusNotFound, "Favorite job not found"),
+	favorites.ErrFavoriteAlreadyExists:   e(http.StatusConflict, "The asset is already among the user's favorites"),
+	favorites.ErrDeadLetterNotFound:      e(http.StatusNotFound, "Dead letter not found"),
+	favorites.ErrQueueFull:               e(http.StatusServiceUnavailable, "Too many favorites waiting to be processed, try again later"),
+	favorites.ErrPendingWrites:           e(http.StatusConflict, "Favorites are still being processed, try again later"),
+	favorites.ErrBatchAborted:            e(http.StatusFailedDependency, "Operation not applied, another operation in the batch failed"),
+	favorites.ErrCollectionNotFound:      e(http.StatusNotFound, "Collection not found"),
+	favorites.ErrCollectionNameTaken:     e(http.StatusConflict, "A collection with this name already exists"),
+	favorites.ErrSmartCollectionNotFound: e(http.StatusNotFound, "Smart collection not found"),
+	favorites.ErrInvalidRule:             e(http.StatusBadRequest, "Invalid smart collection rule"),
 
 	// From transport handlers
 
@@ -44,12 +74,169 @@ var ErrorMap = map[error]resterr.RESTErr{
 	handlers.ErrUserIDRequired:              e(http.StatusBadRequest, "User ID is required"),
 	handlers.ErrFavoriteIDRequired:          e(http.StatusBadRequest, "Favorite ID is required"),
 	handlers.ErrInvalidUserID:               e(http.StatusBadRequest, "Invalid user ID"),
+	handlers.ErrInvalidJobID:                e(http.StatusBadRequest, "Invalid job ID"),
+	handlers.ErrInvalidDeadLetterID:         e(http.StatusBadRequest, "Invalid dead letter ID"),
+	handlers.ErrInvalidWaitForWrites:        e(http.StatusBadRequest, "Invalid wait for writes value, expected true or false"),
+	handlers.ErrInvalidAssetID:              e(http.StatusBadRequest, "Invalid asset ID"),
+	handlers.ErrInvalidAssetPayload:         e(http.StatusBadRequest, "Invalid request payload for asset"),
+	handlers.ErrInvalidBatchPayload:         e(http.StatusBadRequest, "Invalid request payload for batch"),
+	handlers.ErrInvalidBatchOperation:       e(http.StatusBadRequest, "Invalid batch operation, expected add, remove or update"),
+	handlers.ErrInvalidAtomicFlag:           e(http.StatusBadRequest, "Invalid atomic flag, expected true or false"),
+	handlers.ErrInvalidBatchSize: e(
+		http.StatusBadRequest,
+		fmt.Sprintf("Batch must have between 1 and %d operations", handlers.MaxBatchOperations),
+	),
+	handlers.ErrInvalidMovePayload: e(http.StatusBadRequest, "Invalid request payload to move favorite"),
+	handlers.ErrInvalidFavoritesOrder: e(
+		http.StatusBadRequest,
+		fmt.Sprintf("Order must list between 1 and %d favorites, each of them once", handlers.MaxOrderedFavorites),
+	),
+	handlers.ErrInvalidCollectionID:           e(http.StatusBadRequest, "Invalid collection ID"),
+	handlers.ErrInvalidCollectionPayload:      e(http.StatusBadRequest, "Invalid request payload for collection"),
+	handlers.ErrInvalidSmartCollectionID:      e(http.StatusBadRequest, "Invalid smart collection ID"),
+	handlers.ErrInvalidSmartCollectionPayload: e(http.StatusBadRequest, "Invalid request payload for smart collection"),
+	handlers.ErrInvalidCollectionName: e(
+		http.StatusBadRequest,
+		fmt.Sprintf("Collection name must have between 1 and %d characters", handlers.MaxCollectionNameLength),
+	),
+	handlers.ErrInvalidTags: e(
+		http.StatusBadRequest,
+		fmt.Sprintf("Favorites can have up to %d tags of 1 to %d characters", handlers.MaxFavoriteTags, handlers.MaxTagLength),
+	),
+	handlers.ErrInvalidAssetTypeFilter: e(http.StatusBadRequest, "Invalid asset type filter"),
+	handlers.ErrInvalidDateRange: e(
+		http.StatusBadRequest,
+		"Invalid date range, expected RFC 3339 timestamps with the end after the start",
+	),
+	handlers.ErrInvalidSort: e(http.StatusBadRequest, "Invalid sort, expected position or frecency"),
+	handlers.ErrInvalidSearchQuery: e(
+		http.StatusBadRequest,
+		fmt.Sprintf("Search query is too long (max length '%d')", handlers.MaxSearchQueryLength),
+	),
+	handlers.ErrUnsupportedAssetType: e(http.StatusBadRequest, "Unsupported asset type"),
 	handlers.ErrDescriptionMaxLen: e(
 		http.StatusBadRequest,
 		fmt.Sprintf("Description for favorite asset is too long (max length '%d')", handlers.MaxDescriptionLength),
 	),
 }
 
+// errorOrder lists the keys of ErrorMap in the order they're looked up,
+// so an error wrapping several of them is always reported as the first one listed.
+// Service errors come first, since the handler errors wrapping them tell less about what went wrong.
+var errorOrder = []error{
+	// From users service
+	users.ErrUserNotFound,
+
+	// From assets service
+	assets.ErrAssetNotFound,
+	assets.ErrAssetTypeMismatch,
+	assets.ErrNotAudience,
+	assets.ErrEmptyChartTitle,
+	assets.ErrEmptyAxisLabel,
+	assets.ErrInvalidChartData,
+	assets.ErrEmptyInsight,
+	assets.ErrInsightTooLong,
+	assets.ErrEmptyGender,
+	assets.ErrEmptyBirthCountry,
+	assets.ErrInvalidAgeGroup,
+	assets.ErrInvalidSocialMediaHours,
+	assets.ErrInvalidPurchases,
+	assets.ErrFieldTooLong,
+	assets.ErrTooManyValues,
+	assets.ErrDuplicateValue,
+	assets.ErrInvalidRange,
+	audience.ErrInvalidExpression,
+
+	// From favorites service
+	favorites.ErrInvalidAssetID,
+	favorites.ErrFavoriteAssetNotFound,
+	favorites.ErrFavoriteJobNotFound,
+	favorites.ErrFavoriteAlreadyExists,
+	favorites.ErrDeadLetterNotFound,
+	favorites.ErrQueueFull,
+	favorites.ErrPendingWrites,
+	favorites.ErrBatchAborted,
+	favorites.ErrCollectionNotFound,
+	favorites.ErrCollectionNameTaken,
+	favorites.ErrSmartCollectionNotFound,
+	favorites.ErrInvalidRule,
+
+	// From transport handlers
+	handlers.ErrInvalidPageSize,
+	handlers.ErrInvalidPageMaxResults,
+	handlers.ErrInvalidPageToken,
+	handlers.ErrInvalidFavoriteAssetPayload,
+	handlers.ErrUserIDRequired,
+	handlers.ErrFavoriteIDRequired,
+	handlers.ErrInvalidUserID,
+	handlers.ErrInvalidJobID,
+	handlers.ErrInvalidDeadLetterID,
+	handlers.ErrInvalidWaitForWrites,
+	handlers.ErrInvalidAssetID,
+	handlers.ErrInvalidAssetPayload,
+	handlers.ErrInvalidBatchPayload,
+	handlers.ErrInvalidBatchOperation,
+	handlers.ErrInvalidAtomicFlag,
+	handlers.ErrInvalidBatchSize,
+	handlers.ErrInvalidMovePayload,
+	handlers.ErrInvalidFavoritesOrder,
+	handlers.ErrInvalidCollectionID,
+	handlers.ErrInvalidCollectionPayload,
+	handlers.ErrInvalidSmartCollectionID,
+	handlers.ErrInvalidSmartCollectionPayload,
+	handlers.ErrInvalidCollectionName,
+	handlers.ErrInvalidTags,
+	handlers.ErrInvalidAssetTypeFilter,
+	handlers.ErrInvalidDateRange,
+	handlers.ErrInvalidSort,
+	handlers.ErrInvalidSearchQuery,
+	handlers.ErrUnsupportedAssetType,
+	handlers.ErrDescriptionMaxLen,
+}
+
+// internalErr mirrors the error the resterr handler writes for unmapped errors.
+var internalErr = e(http.StatusInternalServerError, "something went wrong")
+
+// Lookup returns the REST error mapped to err, or an internal error if there is none.
+// It's used where errors are reported as part of a payload instead of as the response itself,
+// like the outcome of asynchronous jobs.
+func Lookup(err error) resterr.RESTErr {
+	if restErr, ok := lookup(err); ok {
+		return restErr
+	}
+	return internalErr
+}
+
+func lookup(err error) (resterr.RESTErr, bool) {
+	for _, target := range errorOrder {
+		if errors.Is(err, target) {
+			return ErrorMap[target], true
+		}
+	}
+	return resterr.RESTErr{}, false
+}
+
+// Handler wraps a resterr handler for ErrorMap so that mapped errors are resolved in the order of errorOrder,
+// as Lookup does, instead of the order resterr happens to range over the map in.
+type Handler struct {
+	handler *resterr.Handler
+}
+
+// NewHandler wraps the given resterr handler, which is expected to be created from ErrorMap.
+func NewHandler(handler *resterr.Handler) *Handler {
+	return &Handler{handler: handler}
+}
+
+// Handle writes the REST error err is mapped to, or an internal error if there is none.
+// REST errors wrapped by err, which carry a message of their own, take precedence over the mapped ones.
+func (h *Handler) Handle(ctx context.Context, w resterr.Writer, err error) {
+	// resterr checks for a wrapped REST error before ranging over its map
+	if restErr, ok := lookup(err); ok {
+		err = fmt.Errorf("%w, %w", err, restErr)
+	}
+	h.handler.Handle(ctx, w, err)
+}
+
 func e(code int, message string) resterr.RESTErr {
 	return resterr.RESTErr{StatusCode: code,
 		Message: message,
@@ -0,0 +1,94 @@
+package resterrors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alesr/platform-go-challenge/internal/app/rest/handlers"
+	"github.com/alesr/platform-go-challenge/internal/assets"
+	"github.com/alesr/platform-go-challenge/internal/pkg/logutil"
+	"github.com/alesr/resterr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup(t *testing.T) {
+	t.Parallel()
+
+	// wraps two mapped errors, the asset one is listed first
+	givenErr := fmt.Errorf("%w: %w", handlers.ErrInvalidAssetPayload, assets.ErrEmptyChartTitle)
+
+	for range 20 {
+		assert.Equal(t, e(http.StatusBadRequest, "Chart title is required"), Lookup(givenErr))
+	}
+
+	assert.Equal(t, internalErr, Lookup(assert.AnError))
+}
+
+func TestErrorOrder(t *testing.T) {
+	t.Parallel()
+
+	// every mapped error is listed once, so it's always looked up
+	listed := make(map[error]bool, len(errorOrder))
+	for _, target := range errorOrder {
+		assert.Contains(t, ErrorMap, target)
+		assert.False(t, listed[target], "%v is listed more than once", target)
+		listed[target] = true
+	}
+	assert.Len(t, listed, len(ErrorMap))
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	errHandler, err := resterr.NewHandler(logutil.NewNoop(), ErrorMap, resterr.WithValidationFn(ValidateRestErr))
+	require.NoError(t, err)
+
+	handler := NewHandler(errHandler)
+
+	testCases := []struct {
+		name          string
+		givenErr      error
+		expectCode    int
+		expectMessage string
+	}{
+		{
+			name:          "first listed of the mapped errors",
+			givenErr:      fmt.Errorf("%w: %w", handlers.ErrInvalidAssetPayload, assets.ErrEmptyChartTitle),
+			expectCode:    http.StatusBadRequest,
+			expectMessage: "Chart title is required",
+		},
+		{
+			name: "wrapped REST error over the mapped one",
+			givenErr: fmt.Errorf("%w, %w", assets.ErrEmptyChartTitle, resterr.RESTErr{
+				StatusCode: http.StatusBadRequest,
+				Message:    "Invalid asset field 'title'",
+			}),
+			expectCode:    http.StatusBadRequest,
+			expectMessage: "Invalid asset field 'title'",
+		},
+		{
+			name:          "unmapped error",
+			givenErr:      assert.AnError,
+			expectCode:    http.StatusInternalServerError,
+			expectMessage: internalErr.Message,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			for range 20 {
+				rec := httptest.NewRecorder()
+				handler.Handle(context.TODO(), rec, tc.givenErr)
+
+				assert.Equal(t, tc.expectCode, rec.Code)
+				assert.Contains(t, rec.Body.String(), tc.expectMessage)
+			}
+		})
+	}
+}
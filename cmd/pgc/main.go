@@ -9,7 +9,6 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/alesr/platform-go-challenge/internal/app/rest"
 	usrsampler "github.com/alesr/platform-go-challenge/internal/users/sampler"
 	_ "github.com/jackc/pgx/v5/stdlib"
 )
@@ -21,16 +20,50 @@ const (
 	ExitAssetPopulationError
 	ExitServerSetupError
 	ExitShutdownError
+	ExitUnknownModeError
+	ExitFavoritesSetupError
+	ExitAssetsSetupError
+	ExitRespondentsError
+)
+
+// Modes pgc can run in, picked by the first command line argument.
+const (
+	// modeServer runs the HTTP server, and the favorites consumers unless disabled. It's the default.
+	modeServer = "server"
+
+	// modeWorker only runs the favorites consumers, so they can be scaled apart from the HTTP server.
+	modeWorker = "worker"
+
+	// modeRespondents loads or generates the panel of respondents audiences are sized against, and exits.
+	modeRespondents = "respondents"
 )
 
 func main() {
 	logger := setupLogger()
 
+	mode := modeServer
+	if len(os.Args) > 1 {
+		mode = os.Args[1]
+	}
+
+	if mode != modeServer && mode != modeWorker && mode != modeRespondents {
+		logger.Error("Unknown mode", slog.String("mode", mode), slog.String("usage", "pgc [server|worker|respondents]"))
+		os.Exit(ExitUnknownModeError)
+	}
+
 	if err := setupUTC(); err != nil {
 		logger.Error("Failed to setup UTC timezone", slog.String("error", err.Error()))
 		os.Exit(ExitTimezoneSetupError)
 	}
 
+	if mode == modeRespondents {
+		if err := runRespondents(logger, os.Args[2:]); err != nil {
+			logger.Error("Failed to run respondents command", slog.String("error", err.Error()), slog.String("usage", respondentsUsage))
+			os.Exit(ExitRespondentsError)
+		}
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
 
@@ -53,9 +86,33 @@ func main() {
 
 	assetsRepo := setupAssetsRepository(dbPool)
 
-	assetsSvc := setupAssetsService(logger, assetsRepo)
+	assetsSvc, err := setupAssetsService(logger, assetsRepo)
+	if err != nil {
+		logger.Error("Failed to setup assets service", slog.String("error", err.Error()))
+		os.Exit(ExitAssetsSetupError)
+	}
+
+	favoritesSvc, err := setupFavoritesService(logger, assetsRepo, usersSvc)
+	if err != nil {
+		logger.Error("Failed to setup favorites service", slog.String("error", err.Error()))
+		os.Exit(ExitFavoritesSetupError)
+	}
+
+	if mode == modeWorker {
+		logger.Info("Starting favorites consumers...")
+		favoritesSvc.StartConsumers()
 
-	favoritesSvc := setupFavoritesService(logger, assetsRepo, usersSvc)
+		if err := waitForShutdown(favoritesSvc.Shutdown); err != nil {
+			logger.Error("Failed during shutdown", slog.String("error", err.Error()))
+			os.Exit(ExitShutdownError)
+		}
+		return
+	}
+
+	if runConsumers() {
+		logger.Info("Starting favorites consumers...")
+		favoritesSvc.StartConsumers()
+	}
 
 	logger.Info("Populating assets database...")
 	if err := populateDatabase(ctx, assetsSvc); err != nil {
@@ -70,19 +127,25 @@ func main() {
 		os.Exit(ExitServerSetupError)
 	}
 
-	if err := waitForShutdown(restApp); err != nil {
+	// The REST app shuts down the favorites service along with the server.
+	shutdownFn := func(context.Context) error { return restApp.Shutdown() }
+
+	if err := waitForShutdown(shutdownFn); err != nil {
 		logger.Error("Failed during shutdown", slog.String("error", err.Error()))
 		os.Exit(ExitShutdownError)
 	}
 }
 
-func waitForShutdown(restApp *rest.App) error {
+func waitForShutdown(shutdownFn func(ctx context.Context) error) error {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	if err := restApp.Shutdown(); err != nil {
-		return fmt.Errorf("could not shutdown server: %w", err)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := shutdownFn(ctx); err != nil {
+		return fmt.Errorf("could not shutdown: %w", err)
 	}
 	return nil
 }
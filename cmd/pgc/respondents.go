@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/alesr/platform-go-challenge/internal/assets"
+	"github.com/alesr/platform-go-challenge/internal/assets/sampler"
+)
+
+// Commands of the respondents mode, picked by the command line argument following it.
+const (
+	// respondentsLoad loads a panel of respondents from a CSV file, replacing the respondents with the same IDs.
+	respondentsLoad = "load"
+
+	// respondentsGenerate writes a synthetic panel of respondents to a CSV file, ready to be loaded.
+	respondentsGenerate = "generate"
+)
+
+const respondentsUsage = "pgc respondents load <file.csv> | pgc respondents generate <count> <file.csv>"
+
+var errRespondentsUsage = errors.New("invalid respondents command")
+
+// runRespondents runs the respondents command given by args, the command line arguments following the mode.
+func runRespondents(logger *slog.Logger, args []string) error {
+	switch {
+	case len(args) == 2 && args[0] == respondentsLoad:
+		return loadRespondents(logger, args[1])
+
+	case len(args) == 3 && args[0] == respondentsGenerate:
+		count, err := strconv.Atoi(args[1])
+		if err != nil || count < 0 {
+			return fmt.Errorf("%w: count '%s' is not a number of respondents", errRespondentsUsage, args[1])
+		}
+		return generateRespondents(logger, count, args[2])
+	}
+	return errRespondentsUsage
+}
+
+func loadRespondents(logger *slog.Logger, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("could not open respondents file: %w", err)
+	}
+	defer f.Close()
+
+	respondents, err := assets.ReadRespondentsCSV(f)
+	if err != nil {
+		return fmt.Errorf("could not read respondents: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), respondentsLoadTimeout)
+	defer cancel()
+
+	dbPool, err := setupDatabase(ctx)
+	if err != nil {
+		return fmt.Errorf("could not setup database: %w", err)
+	}
+	defer dbPool.Close()
+
+	assetsSvc, err := setupAssetsService(logger, setupAssetsRepository(dbPool))
+	if err != nil {
+		return fmt.Errorf("could not setup assets service: %w", err)
+	}
+
+	if err := assetsSvc.StoreRespondents(ctx, respondents); err != nil {
+		return fmt.Errorf("could not load respondents: %w", err)
+	}
+	logger.Info("Respondents loaded", slog.String("file", path), slog.Int("number_of_respondents", len(respondents)))
+	return nil
+}
+
+func generateRespondents(logger *slog.Logger, count int, path string) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create respondents file: %w", err)
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("could not close respondents file: %w", closeErr)
+		}
+	}()
+
+	if err := assets.WriteRespondentsCSV(f, sampler.SampleRespondents(count)); err != nil {
+		return fmt.Errorf("could not write respondents: %w", err)
+	}
+	logger.Info("Respondents generated", slog.String("file", path), slog.Int("number_of_respondents", count))
+	return nil
+}
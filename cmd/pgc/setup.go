@@ -40,6 +40,12 @@ const (
 	httpWriteTimeout = 10 * time.Second
 	httpIdleTimeout  = 15 * time.Second
 
+	// How long we wait for in-flight work to finish when shutting down
+	shutdownTimeout = 30 * time.Second
+
+	// How long loading a panel of respondents can take
+	respondentsLoadTimeout = 10 * time.Minute
+
 	// Number of assets and users we populate the DB with
 	preloadedAssets = 100
 	preloadedusers  = 50
@@ -103,18 +109,67 @@ func setupAssetsRepository(pool *pgxpool.Pool) *postgres.Repository {
 	return postgres.NewRepository(pool)
 }
 
-func setupAssetsService(logger *slog.Logger, repo *postgres.Repository) *assets.Service {
-	return assets.NewService(logger, repo)
+// setupAssetsService reads the smallest audience size reported from the environment.
+// Unset, it keeps the service default.
+func setupAssetsService(logger *slog.Logger, repo *postgres.Repository) (*assets.Service, error) {
+	sizeThreshold, err := envutil.GetEnvInt("AUDIENCE_SIZE_THRESHOLD", 0)
+	if err != nil {
+		return nil, fmt.Errorf("could not read audience size threshold: %w", err)
+	}
+	return assets.NewService(logger, repo, assets.WithSizeThreshold(sizeThreshold)), nil
+}
+
+// setupFavoritesService reads the queue limits, batching and trash settings from the environment.
+// Unset variables keep the service defaults.
+func setupFavoritesService(logger *slog.Logger, repo *postgres.Repository, usersSvc *users.Service) (*favorites.Service, error) {
+	bufferSize, err := envutil.GetEnvInt("FAVORITES_BUFFER_SIZE", 0)
+	if err != nil {
+		return nil, fmt.Errorf("could not read favorites buffer size: %w", err)
+	}
+
+	maxPendingJobs, err := envutil.GetEnvInt("FAVORITES_MAX_PENDING_JOBS", 0)
+	if err != nil {
+		return nil, fmt.Errorf("could not read favorites max pending jobs: %w", err)
+	}
+
+	batchSize, err := envutil.GetEnvInt("FAVORITES_BATCH_SIZE", 0)
+	if err != nil {
+		return nil, fmt.Errorf("could not read favorites batch size: %w", err)
+	}
+
+	batchLinger, err := envutil.GetEnvDuration("FAVORITES_BATCH_LINGER", 0)
+	if err != nil {
+		return nil, fmt.Errorf("could not read favorites batch linger: %w", err)
+	}
+
+	trashRetention, err := envutil.GetEnvDuration("FAVORITES_TRASH_RETENTION", 0)
+	if err != nil {
+		return nil, fmt.Errorf("could not read favorites trash retention: %w", err)
+	}
+
+	return favorites.NewService(
+		logger, repo, usersSvc,
+		favorites.WithBufferSize(bufferSize),
+		favorites.WithMaxPendingJobs(maxPendingJobs),
+		favorites.WithBatchSize(batchSize),
+		favorites.WithBatchLinger(batchLinger),
+		favorites.WithTrashRetention(trashRetention),
+	), nil
 }
 
-func setupFavoritesService(logger *slog.Logger, repo *postgres.Repository, usersSvc *users.Service) *favorites.Service {
-	return favorites.NewService(logger, repo, usersSvc)
+// runConsumers reports whether the server should also process queued favorites.
+// Set RUN_CONSUMERS=false when they run as separate "pgc worker" processes.
+func runConsumers() bool {
+	return envutil.GetEnv("RUN_CONSUMERS", "true") != "false"
 }
 
 func populateDatabase(ctx context.Context, assetsSvc *assets.Service) error {
-	if err := assetsSvc.StoreAssets(
-		ctx, sampler.SampleAssets(preloadedAssets),
-	); err != nil {
+	samples, err := sampler.SampleAssets(preloadedAssets)
+	if err != nil {
+		return fmt.Errorf("could not sample assets: %w", err)
+	}
+
+	if err := assetsSvc.StoreAssets(ctx, samples); err != nil {
 		return fmt.Errorf("could not populate assets tables: %w", err)
 	}
 	return nil
@@ -142,7 +197,7 @@ func setupHTTPServer(
 		return nil, fmt.Errorf("could not create error handler: %w", err)
 	}
 
-	restHandlers := handlers.New(logger, errHandler, usersSvc, assetsSvc, favSvc)
+	restHandlers := handlers.New(logger, resterrors.NewHandler(errHandler), resterrors.Lookup, usersSvc, assetsSvc, favSvc)
 	restApp := rest.NewApp(logger, &httpSrv, restHandlers)
 
 	if err := restApp.Start(); err != nil {
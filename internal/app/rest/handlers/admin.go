@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
+	"github.com/alesr/platform-go-challenge/internal/pkg/httputil"
+)
+
+const (
+	defaultDeadLettersPageSize = 20
+	maxDeadLettersPageSize     = 100
+)
+
+// QueueStatsResponse defines the data structure for the state of the favorites queue.
+// Pending jobs are counted across all consumers, the rest is specific to the instance answering.
+type QueueStatsResponse struct {
+	PendingJobs    int64 `json:"pending_jobs"`
+	MaxPendingJobs int   `json:"max_pending_jobs"`
+	BufferedTasks  int   `json:"buffered_tasks"`
+	BufferCapacity int   `json:"buffer_capacity"`
+	RejectedJobs   int64 `json:"rejected_jobs"`
+}
+
+// ListDeadLettersResponse defines the data structure for listing dead letters.
+type ListDeadLettersResponse struct {
+	Items         []DeadLetterResponse `json:"items"`
+	NextPageToken string               `json:"next_page_token,omitempty"`
+}
+
+// DeadLetterResponse defines the data structure for a favorite job that failed for good.
+type DeadLetterResponse struct {
+	ID          string    `json:"id"`
+	JobID       string    `json:"job_id"`
+	UserID      string    `json:"user_id"`
+	AssetID     string    `json:"asset_id"`
+	Description string    `json:"description"`
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"last_error"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// GetQueueStats returns the state of the favorites queue for monitoring.
+// NOTE: like the rest of the API, admin endpoints are not authenticated yet.
+func (h *Handler) GetQueueStats() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		stats, err := h.favoritesSvc.QueueStats(r.Context())
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not get queue stats: %w", err))
+			return
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, QueueStatsResponse{
+			PendingJobs:    stats.PendingJobs,
+			MaxPendingJobs: stats.MaxPendingJobs,
+			BufferedTasks:  stats.BufferedTasks,
+			BufferCapacity: stats.BufferCapacity,
+			RejectedJobs:   stats.RejectedJobs,
+		})
+	}
+}
+
+// ListDeadLetters returns a page of favorite jobs that failed for good, most recent first.
+func (h *Handler) ListDeadLetters() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params, err := parseListDeadLettersParams(r)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not parse list dead letters params: %w", err))
+			return
+		}
+
+		deadLetters, nextPageToken, err := h.favoritesSvc.ListDeadLetters(r.Context(), params)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not list dead letters: %w", err))
+			return
+		}
+
+		items := make([]DeadLetterResponse, 0, len(deadLetters))
+		for _, dl := range deadLetters {
+			items = append(items, DeadLetterResponse{
+				ID:          dl.ID,
+				JobID:       dl.JobID,
+				UserID:      dl.UserID,
+				AssetID:     dl.AssetID,
+				Description: dl.Description,
+				Attempts:    dl.Attempts,
+				LastError:   dl.LastError,
+				CreatedAt:   dl.CreatedAt,
+			})
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, ListDeadLettersResponse{
+			Items:         items,
+			NextPageToken: nextPageToken,
+		})
+	}
+}
+
+// ReplayDeadLetter queues a dead letter's favorite job again.
+// Like favoriting an asset, it responds with the job tracking the new attempt.
+func (h *Handler) ReplayDeadLetter() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("dead_letter_id")
+		if err := validateID(id); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate dead letter ID: %w, %v", ErrInvalidDeadLetterID, err))
+			return
+		}
+
+		job, err := h.favoritesSvc.ReplayDeadLetter(r.Context(), id)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not replay dead letter: %w", err))
+			return
+		}
+
+		w.Header().Set("Location", "/favorite-jobs/"+job.ID)
+		httputil.RespondWithJSON(w, http.StatusAccepted, h.toFavoriteJobResponse(job))
+	}
+}
+
+func parseListDeadLettersParams(r *http.Request) (*favorites.ListDeadLettersParams, error) {
+	params := favorites.ListDeadLettersParams{PageSize: defaultDeadLettersPageSize}
+
+	if v := r.URL.Query().Get("pageSize"); v != "" {
+		pageSize, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPageSize, err)
+		}
+		if pageSize > 0 {
+			params.PageSize = min(pageSize, maxDeadLettersPageSize)
+		}
+	}
+
+	if pageToken := r.URL.Query().Get("pageToken"); pageToken != "" {
+		if err := validateID(pageToken); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPageToken, err)
+		}
+		params.PageToken = pageToken
+	}
+	return &params, nil
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
+	"github.com/alesr/platform-go-challenge/internal/pkg/httputil"
+	"github.com/alesr/resterr"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetQueueStats(t *testing.T) {
+	t.Parallel()
+
+	givenStats := favorites.QueueStats{
+		PendingJobs:    3,
+		MaxPendingJobs: 100,
+		BufferedTasks:  2,
+		BufferCapacity: 20,
+		RejectedJobs:   1,
+	}
+
+	handler := Handler{
+		favoritesSvc: &favoritesSvcMock{
+			queueStatsFunc: func(ctx context.Context) (*favorites.QueueStats, error) {
+				return &givenStats, nil
+			},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/queue", nil)
+	rec := httptest.NewRecorder()
+
+	handler.GetQueueStats().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp httputil.Response[QueueStatsResponse]
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+
+	assert.Equal(t, QueueStatsResponse{
+		PendingJobs:    3,
+		MaxPendingJobs: 100,
+		BufferedTasks:  2,
+		BufferCapacity: 20,
+		RejectedJobs:   1,
+	}, resp.Data)
+}
+
+func TestListDeadLetters(t *testing.T) {
+	t.Parallel()
+
+	givenDeadLetter := favorites.DeadLetter{
+		ID:        ulid.Make().String(),
+		JobID:     ulid.Make().String(),
+		UserID:    ulid.Make().String(),
+		AssetID:   ulid.Make().String(),
+		Attempts:  1,
+		LastError: "asset not found",
+	}
+
+	pageToken := ulid.Make().String()
+
+	favoritesSvc := &favoritesSvcMock{
+		listDeadLettersFunc: func(ctx context.Context, params *favorites.ListDeadLettersParams) ([]favorites.DeadLetter, string, error) {
+			assert.Equal(t, &favorites.ListDeadLettersParams{PageSize: 5, PageToken: pageToken}, params)
+			return []favorites.DeadLetter{givenDeadLetter}, "next-token", nil
+		},
+	}
+
+	handler := Handler{favoritesSvc: favoritesSvc}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/dead-letters?pageSize=5&pageToken="+pageToken, nil)
+	rec := httptest.NewRecorder()
+
+	handler.ListDeadLetters().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp httputil.Response[ListDeadLettersResponse]
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+
+	require.Len(t, resp.Data.Items, 1)
+	assert.Equal(t, givenDeadLetter.ID, resp.Data.Items[0].ID)
+	assert.Equal(t, givenDeadLetter.JobID, resp.Data.Items[0].JobID)
+	assert.Equal(t, givenDeadLetter.LastError, resp.Data.Items[0].LastError)
+	assert.Equal(t, "next-token", resp.Data.NextPageToken)
+}
+
+func TestParseListDeadLettersParams(t *testing.T) {
+	t.Parallel()
+
+	pageToken := ulid.Make().String()
+
+	testCases := []struct {
+		name           string
+		givenQuery     string
+		expectedParams *favorites.ListDeadLettersParams
+		expectedError  error
+	}{
+		{
+			name:           "defaults",
+			expectedParams: &favorites.ListDeadLettersParams{PageSize: defaultDeadLettersPageSize},
+		},
+		{
+			name:           "page size is capped",
+			givenQuery:     "pageSize=1000",
+			expectedParams: &favorites.ListDeadLettersParams{PageSize: maxDeadLettersPageSize},
+		},
+		{
+			name:           "page token",
+			givenQuery:     "pageToken=" + pageToken,
+			expectedParams: &favorites.ListDeadLettersParams{PageSize: defaultDeadLettersPageSize, PageToken: pageToken},
+		},
+		{
+			name:          "invalid page size",
+			givenQuery:    "pageSize=foo",
+			expectedError: ErrInvalidPageSize,
+		},
+		{
+			name:          "invalid page token",
+			givenQuery:    "pageToken=foo",
+			expectedError: ErrInvalidPageToken,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/dead-letters?"+tc.givenQuery, nil)
+
+			params, err := parseListDeadLettersParams(req)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedParams, params)
+		})
+	}
+}
+
+func TestReplayDeadLetter(t *testing.T) {
+	t.Parallel()
+
+	deadLetterID := ulid.Make().String()
+
+	testCases := []struct {
+		name           string
+		givenID        string
+		givenSvcResult func() (*favorites.FavoriteJob, error)
+		expectedStatus int
+		expectedError  error
+	}{
+		{
+			name:    "success",
+			givenID: deadLetterID,
+			givenSvcResult: func() (*favorites.FavoriteJob, error) {
+				return &favorites.FavoriteJob{ID: "job-1", Status: favorites.JobStatusPending}, nil
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:          "invalid dead letter id",
+			givenID:       "foo",
+			expectedError: ErrInvalidDeadLetterID,
+		},
+		{
+			name:    "dead letter not found",
+			givenID: deadLetterID,
+			givenSvcResult: func() (*favorites.FavoriteJob, error) {
+				return nil, favorites.ErrDeadLetterNotFound
+			},
+			expectedError: favorites.ErrDeadLetterNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var handledErr error
+
+			handler := Handler{
+				errHandler: &errorHandlerMock{
+					handleFunc: func(ctx context.Context, w resterr.Writer, err error) {
+						handledErr = err
+					},
+				},
+				favoritesSvc: &favoritesSvcMock{
+					replayDeadLetterFunc: func(ctx context.Context, id string) (*favorites.FavoriteJob, error) {
+						assert.Equal(t, tc.givenID, id)
+						return tc.givenSvcResult()
+					},
+				},
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/dead-letters/"+tc.givenID+"/replay", nil)
+			req.SetPathValue("dead_letter_id", tc.givenID)
+			rec := httptest.NewRecorder()
+
+			handler.ReplayDeadLetter().ServeHTTP(rec, req)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, handledErr, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, handledErr)
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Equal(t, "/favorite-jobs/job-1", rec.Header().Get("Location"))
+		})
+	}
+}
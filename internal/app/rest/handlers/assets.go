@@ -1,60 +1,63 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/alesr/platform-go-challenge/internal/assets"
+	"github.com/alesr/platform-go-challenge/internal/assets/audience"
 	"github.com/alesr/platform-go-challenge/internal/pkg/httputil"
+	"github.com/alesr/resterr"
 	"github.com/oklog/ulid/v2"
 )
 
 type (
-	assetResponse interface {
-		chartAssetResponse | insightAssetResponse | audienceAssetResponse
-	}
-
-	// fields must be exported for json marshalling
-	asset[T assetResponse] struct {
+	// assetResponse defines the data structure of an asset, whatever its type.
+	// Data takes the shape the asset type registered, see assets.Register.
+	assetResponse struct {
 		ID        string    `json:"id"`
 		Type      string    `json:"type"`
 		CreatedAt time.Time `json:"created_at"`
 		UpdatedAt time.Time `json:"updated_at"`
-		Data      T         `json:"data"`
-	}
-
-	chartAssetResponse struct {
-		Title string    `json:"title"`
-		XAxis string    `json:"x_axis"`
-		YAxis string    `json:"y_axis"`
-		Data  []float64 `json:"data"`
-	}
+		Data      any       `json:"data"`
 
-	insightAssetResponse struct {
-		Insight string `json:"insight"`
+		// only set when listing assets, its fields are inlined in the asset
+		*favoriteStatus
 	}
 
-	audienceAssetResponse struct {
-		Gender             string `json:"gender"`
-		BirthCountry       string `json:"birth_country"`
-		AgeMin             int    `json:"age_min"`
-		AgeMax             int    `json:"age_max"`
-		SocialMediaHours   int    `json:"social_media_hours"`
-		LastMonthPurchases int    `json:"last_month_purchases"`
+	// favoriteStatus tells how a listed asset has been favorited.
+	// IsFavorited and FavoriteID are left out unless the assets are listed on behalf of a user.
+	favoriteStatus struct {
+		FavoriteCount int64  `json:"favorite_count"`
+		IsFavorited   *bool  `json:"is_favorited,omitempty"`
+		FavoriteID    string `json:"favorite_id,omitempty"`
 	}
 
-	// type aliases for improved readability
-	chartResponse    = asset[chartAssetResponse]
-	insightResponse  = asset[insightAssetResponse]
-	audienceResponse = asset[audienceAssetResponse]
-
 	// ListAssetsResponse defines the data structure for listing assets
 	ListAssetsResponse struct {
 		Items         []any  `json:"items"`
 		NextPageToken string `json:"next_page_token,omitempty"`
 	}
+
+	// AssetSizeResponse defines the data structure of the size of an audience.
+	// Size is null when it's below the threshold, and suppressed.
+	AssetSizeResponse struct {
+		AssetID    string `json:"asset_id"`
+		Size       *int   `json:"size"`
+		Suppressed bool   `json:"suppressed"`
+		Threshold  int    `json:"threshold"`
+	}
+
+	// AssetRequest defines the data structure for creating or updating an asset.
+	// Data must match the shape of the asset type, the same one we return when listing assets.
+	AssetRequest struct {
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
 )
 
 // ListAssets returns a list of assets
@@ -66,15 +69,22 @@ func (h *Handler) ListAssets() http.HandlerFunc {
 			return
 		}
 
-		assets, nextPageToken, err := h.assetsSvc.ListAssets(r.Context(), params)
+		listed, nextPageToken, err := h.assetsSvc.ListAssets(r.Context(), params)
 		if err != nil {
 			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not list assets: %w", err))
 			return
 		}
 
-		items := make([]any, 0, len(assets))
-		for _, asset := range assets {
-			if transportItem := toTransportAsset(asset); transportItem != nil {
+		items := make([]any, 0, len(listed))
+		for _, l := range listed {
+			status := favoriteStatus{FavoriteCount: l.FavoriteCount}
+			if params.UserID != "" {
+				isFavorited := l.FavoriteID != ""
+				status.IsFavorited = &isFavorited
+				status.FavoriteID = l.FavoriteID
+			}
+
+			if transportItem := newTransportAsset(l.Asset, &status); transportItem != nil {
 				items = append(items, transportItem)
 			}
 		}
@@ -86,6 +96,186 @@ func (h *Handler) ListAssets() http.HandlerFunc {
 	}
 }
 
+// CreateAsset creates a new chart, insight or audience asset.
+func (h *Handler) CreateAsset() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		var reqData AssetRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqData); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not decode request data: %w, %w", err, ErrInvalidAssetPayload))
+			return
+		}
+
+		kind, err := lookupAssetKind(assets.AssetType(reqData.Type))
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not create asset: %w", err))
+			return
+		}
+
+		asset, err := kind.New("", reqData.Data)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, assetDataError(err))
+			return
+		}
+
+		if err := h.assetsSvc.CreateAsset(r.Context(), asset); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not create asset: %w", err))
+			return
+		}
+		httputil.RespondWithJSON(w, http.StatusCreated, toTransportAsset(asset))
+	}
+}
+
+// GetAsset returns a single asset.
+func (h *Handler) GetAsset() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		assetID := r.PathValue("asset_id")
+		if err := validateID(assetID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate asset ID: %w, %v", ErrInvalidAssetID, err))
+			return
+		}
+
+		asset, err := h.assetsSvc.FetchAsset(r.Context(), assetID)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not fetch asset: %w", err))
+			return
+		}
+		httputil.RespondWithJSON(w, http.StatusOK, toTransportAsset(asset))
+	}
+}
+
+// GetAssetSize returns how many respondents of the panel an audience asset represents.
+func (h *Handler) GetAssetSize() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		assetID := r.PathValue("asset_id")
+		if err := validateID(assetID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate asset ID: %w, %v", ErrInvalidAssetID, err))
+			return
+		}
+
+		size, err := h.assetsSvc.AudienceSize(r.Context(), assetID)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not size audience: %w", err))
+			return
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, AssetSizeResponse{
+			AssetID:    assetID,
+			Size:       size.Size,
+			Suppressed: size.Suppressed,
+			Threshold:  size.Threshold,
+		})
+	}
+}
+
+// ReplaceAsset replaces all the data of an asset.
+// The asset type is required and must match the type of the stored asset.
+func (h *Handler) ReplaceAsset() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		assetID := r.PathValue("asset_id")
+		if err := validateID(assetID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate asset ID: %w, %v", ErrInvalidAssetID, err))
+			return
+		}
+
+		var reqData AssetRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqData); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not decode request data: %w, %w", err, ErrInvalidAssetPayload))
+			return
+		}
+
+		kind, err := lookupAssetKind(assets.AssetType(reqData.Type))
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not replace asset: %w", err))
+			return
+		}
+
+		asset, err := kind.New(assetID, reqData.Data)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, assetDataError(err))
+			return
+		}
+
+		updated, err := h.assetsSvc.UpdateAsset(r.Context(), assetID, asset)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not replace asset: %w", err))
+			return
+		}
+		httputil.RespondWithJSON(w, http.StatusOK, toTransportAsset(updated))
+	}
+}
+
+// PatchAsset updates only the asset data fields present in the request.
+// The asset type can be omitted, but if present it must match the type of the stored asset.
+func (h *Handler) PatchAsset() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		assetID := r.PathValue("asset_id")
+		if err := validateID(assetID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate asset ID: %w, %v", ErrInvalidAssetID, err))
+			return
+		}
+
+		var reqData AssetRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqData); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not decode request data: %w, %w", err, ErrInvalidAssetPayload))
+			return
+		}
+
+		existing, err := h.assetsSvc.FetchAsset(r.Context(), assetID)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not fetch asset: %w", err))
+			return
+		}
+
+		if reqData.Type != "" && assets.AssetType(reqData.Type) != existing.Type() {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not patch asset: %w", assets.ErrAssetTypeMismatch))
+			return
+		}
+
+		kind, err := lookupAssetKind(existing.Type())
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not patch asset: %w", err))
+			return
+		}
+
+		// Patching the stored data leaves the fields missing in the request untouched.
+		asset, err := kind.Patch(existing, reqData.Data)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, assetDataError(err))
+			return
+		}
+
+		updated, err := h.assetsSvc.UpdateAsset(r.Context(), assetID, asset)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not patch asset: %w", err))
+			return
+		}
+		httputil.RespondWithJSON(w, http.StatusOK, toTransportAsset(updated))
+	}
+}
+
+// DeleteAsset deletes an asset and removes it from the favorites of all users.
+func (h *Handler) DeleteAsset() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		assetID := r.PathValue("asset_id")
+		if err := validateID(assetID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate asset ID: %w, %v", ErrInvalidAssetID, err))
+			return
+		}
+
+		if err := h.assetsSvc.DeleteAsset(r.Context(), assetID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not delete asset: %w", err))
+			return
+		}
+		httputil.RespondWithJSON[any](w, http.StatusNoContent, nil)
+	}
+}
+
 const (
 	defaultPageSize   = 10
 	defaultMaxResults = 100
@@ -109,6 +299,13 @@ func (h *Handler) parseListAssetsParams(r *http.Request) (*assets.ListAssetsPara
 		}
 	}
 
+	userID := r.URL.Query().Get("userId")
+	if userID != "" {
+		if err := validateID(userID); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidUserID, err)
+		}
+	}
+
 	if pageSize <= 0 {
 		pageSize = defaultPageSize
 	}
@@ -121,49 +318,62 @@ func (h *Handler) parseListAssetsParams(r *http.Request) (*assets.ListAssetsPara
 		PageSize:   pageSize,
 		PageToken:  pageToken,
 		MaxResults: maxResults,
+		UserID:     userID,
 	}, nil
 }
 
 func toTransportAsset(a assets.Asseter) any {
-	switch v := a.(type) {
-	case assets.ChartAsset:
-		return chartResponse{
-			ID:        v.ID,
-			Type:      string(a.Type()),
-			CreatedAt: v.CreatedAt,
-			UpdatedAt: v.UpdatedAt,
-			Data: chartAssetResponse{
-				Title: v.Data.Title,
-				XAxis: v.Data.XAxis,
-				YAxis: v.Data.YAxis,
-				Data:  v.Data.Data,
-			},
-		}
-	case assets.InsightAsset:
-		return insightResponse{
-			ID:        v.ID,
-			Type:      string(a.Type()),
-			CreatedAt: v.CreatedAt,
-			UpdatedAt: v.UpdatedAt,
-			Data: insightAssetResponse{
-				Insight: v.Data.Insight,
-			},
-		}
-	case assets.AudienceAsset:
-		return audienceResponse{
-			ID:        v.ID,
-			Type:      string(a.Type()),
-			CreatedAt: v.CreatedAt,
-			UpdatedAt: v.UpdatedAt,
-			Data: audienceAssetResponse{
-				Gender:             v.Data.Gender,
-				BirthCountry:       v.Data.BirthCountry,
-				AgeMin:             v.Data.AgeMin,
-				AgeMax:             v.Data.AgeMax,
-				SocialMediaHours:   v.Data.SocialMediaHours,
-				LastMonthPurchases: v.Data.LastMonthPurchases,
-			},
-		}
-	}
-	return nil
+	return newTransportAsset(a, nil)
+}
+
+// newTransportAsset maps an asset to its response, with its favorite status if given.
+// Assets of types that aren't registered are left out, as there's no telling their shape.
+func newTransportAsset(a assets.Asseter, status *favoriteStatus) any {
+	kind, ok := assets.Lookup(a.Type())
+	if !ok {
+		return nil
+	}
+
+	metadata := a.Metadata()
+	return assetResponse{
+		ID:             metadata.ID,
+		Type:           string(a.Type()),
+		CreatedAt:      metadata.CreatedAt,
+		UpdatedAt:      metadata.UpdatedAt,
+		Data:           kind.Data(a),
+		favoriteStatus: status,
+	}
+}
+
+// lookupAssetKind returns the registered asset type t, or ErrUnsupportedAssetType.
+func lookupAssetKind(t assets.AssetType) (assets.Kind, error) {
+	kind, ok := assets.Lookup(t)
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrUnsupportedAssetType, t)
+	}
+	return kind, nil
+}
+
+// assetDataError wraps the error of building an asset from the data of a request.
+// Validation errors are reported with the field they are about and the rule it broke, bounds included,
+// and errors in audience expressions with their position, none of which a mapped error can tell.
+func assetDataError(err error) error {
+	var exprErr *audience.Error
+	if errors.As(err, &exprErr) {
+		restErr := resterr.RESTErr{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Invalid audience expression " + exprErr.Error(),
+		}
+		return fmt.Errorf("invalid asset data: %w, %w", err, restErr)
+	}
+
+	var fieldErr *assets.FieldError
+	if errors.As(err, &fieldErr) {
+		restErr := resterr.RESTErr{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("Invalid asset field '%s': %v", fieldErr.Field, fieldErr.Err),
+		}
+		return fmt.Errorf("invalid asset data: %w, %w", err, restErr)
+	}
+	return fmt.Errorf("could not decode asset data: %w, %w", err, ErrInvalidAssetPayload)
 }
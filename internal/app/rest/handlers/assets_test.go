@@ -6,11 +6,14 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/alesr/platform-go-challenge/internal/assets"
+	"github.com/alesr/platform-go-challenge/internal/assets/audience"
 	"github.com/alesr/platform-go-challenge/internal/pkg/httputil"
 	"github.com/alesr/resterr"
+	"github.com/oklog/ulid/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -19,20 +22,30 @@ func TestListAssets(t *testing.T) {
 	t.Parallel()
 
 	assetFactory := assets.NewAssetFactory()
-	givenChart := assetFactory.CreateChart("Foo Chart", "Bar Axis", "Qux Axis", []float64{1, 2, 3})
-	givenInsight := assetFactory.CreateInsight("Bar Insight")
-	givenAudience := assetFactory.CreateAudience("male", "BR", 18, 35, 2, 5)
+	givenChart, err := assetFactory.CreateChart("Foo Chart", "Bar Axis", "Qux Axis", []float64{1, 2, 3})
+	require.NoError(t, err)
+	givenInsight, err := assetFactory.CreateInsight("Bar Insight")
+	require.NoError(t, err)
+	givenAudience, err := assetFactory.CreateAudience(
+		[]string{"male"},
+		[]string{"BR"},
+		[]assets.AgeGroup{assets.AgeGroup18To24, assets.AgeGroup25To34, assets.AgeGroup35To44},
+		assets.Between(2, 2),
+		assets.AtMost(5),
+		"",
+	)
+	require.NoError(t, err)
 
 	testCases := []struct {
 		name                      string
-		givenListAssetsMockResult func() ([]assets.Asseter, string, error)
+		givenListAssetsMockResult func() ([]assets.ListedAsset, string, error)
 		expect                    httputil.Response[ListAssetsResponse]
 		expectErr                 bool
 	}{
 		{
 			name: "no assets",
-			givenListAssetsMockResult: func() ([]assets.Asseter, string, error) {
-				return []assets.Asseter{}, "foo-token", nil
+			givenListAssetsMockResult: func() ([]assets.ListedAsset, string, error) {
+				return []assets.ListedAsset{}, "foo-token", nil
 			},
 			expect: httputil.Response[ListAssetsResponse]{
 				Status: "success",
@@ -44,24 +57,19 @@ func TestListAssets(t *testing.T) {
 		},
 		{
 			name: "chart asset",
-			givenListAssetsMockResult: func() ([]assets.Asseter, string, error) {
-				return []assets.Asseter{givenChart}, "chart-token", nil
+			givenListAssetsMockResult: func() ([]assets.ListedAsset, string, error) {
+				return []assets.ListedAsset{{Asset: givenChart}}, "chart-token", nil
 			},
 			expect: httputil.Response[ListAssetsResponse]{
 				Status: "success",
 				Data: ListAssetsResponse{
 					Items: []any{
-						chartResponse{
+						assetResponse{
 							ID:        givenChart.ID,
 							Type:      string(givenChart.Type()),
 							CreatedAt: givenChart.CreatedAt,
 							UpdatedAt: givenChart.UpdatedAt,
-							Data: chartAssetResponse{
-								Title: givenChart.Data.Title,
-								XAxis: givenChart.Data.XAxis,
-								YAxis: givenChart.Data.YAxis,
-								Data:  givenChart.Data.Data,
-							},
+							Data:      givenChart.Data,
 						},
 					},
 					NextPageToken: "chart-token",
@@ -70,21 +78,19 @@ func TestListAssets(t *testing.T) {
 		},
 		{
 			name: "insight asset",
-			givenListAssetsMockResult: func() ([]assets.Asseter, string, error) {
-				return []assets.Asseter{givenInsight}, "insight-token", nil
+			givenListAssetsMockResult: func() ([]assets.ListedAsset, string, error) {
+				return []assets.ListedAsset{{Asset: givenInsight}}, "insight-token", nil
 			},
 			expect: httputil.Response[ListAssetsResponse]{
 				Status: "success",
 				Data: ListAssetsResponse{
 					Items: []any{
-						insightResponse{
+						assetResponse{
 							ID:        givenInsight.ID,
 							Type:      string(givenInsight.Type()),
 							CreatedAt: givenInsight.CreatedAt,
 							UpdatedAt: givenInsight.UpdatedAt,
-							Data: insightAssetResponse{
-								Insight: givenInsight.Data.Insight,
-							},
+							Data:      givenInsight.Data,
 						},
 					},
 					NextPageToken: "insight-token",
@@ -93,26 +99,19 @@ func TestListAssets(t *testing.T) {
 		},
 		{
 			name: "audience asset",
-			givenListAssetsMockResult: func() ([]assets.Asseter, string, error) {
-				return []assets.Asseter{givenAudience}, "audience-token", nil
+			givenListAssetsMockResult: func() ([]assets.ListedAsset, string, error) {
+				return []assets.ListedAsset{{Asset: givenAudience}}, "audience-token", nil
 			},
 			expect: httputil.Response[ListAssetsResponse]{
 				Status: "success",
 				Data: ListAssetsResponse{
 					Items: []any{
-						audienceResponse{
+						assetResponse{
 							ID:        givenAudience.ID,
 							Type:      string(givenAudience.Type()),
 							CreatedAt: givenAudience.CreatedAt,
 							UpdatedAt: givenAudience.UpdatedAt,
-							Data: audienceAssetResponse{
-								Gender:             givenAudience.Data.Gender,
-								BirthCountry:       givenAudience.Data.BirthCountry,
-								AgeMin:             givenAudience.Data.AgeMin,
-								AgeMax:             givenAudience.Data.AgeMax,
-								SocialMediaHours:   givenAudience.Data.SocialMediaHours,
-								LastMonthPurchases: givenAudience.Data.LastMonthPurchases,
-							},
+							Data:      givenAudience.Data,
 						},
 					},
 					NextPageToken: "audience-token",
@@ -121,47 +120,33 @@ func TestListAssets(t *testing.T) {
 		},
 		{
 			name: "all asset types",
-			givenListAssetsMockResult: func() ([]assets.Asseter, string, error) {
-				return []assets.Asseter{givenChart, givenInsight, givenAudience}, "all-token", nil
+			givenListAssetsMockResult: func() ([]assets.ListedAsset, string, error) {
+				return []assets.ListedAsset{{Asset: givenChart}, {Asset: givenInsight}, {Asset: givenAudience}}, "all-token", nil
 			},
 			expect: httputil.Response[ListAssetsResponse]{
 				Status: "success",
 				Data: ListAssetsResponse{
 					Items: []any{
-						chartResponse{
+						assetResponse{
 							ID:        givenChart.ID,
 							Type:      string(givenChart.Type()),
 							CreatedAt: givenChart.CreatedAt,
 							UpdatedAt: givenChart.UpdatedAt,
-							Data: chartAssetResponse{
-								Title: givenChart.Data.Title,
-								XAxis: givenChart.Data.XAxis,
-								YAxis: givenChart.Data.YAxis,
-								Data:  givenChart.Data.Data,
-							},
+							Data:      givenChart.Data,
 						},
-						insightResponse{
+						assetResponse{
 							ID:        givenInsight.ID,
 							Type:      string(givenInsight.Type()),
 							CreatedAt: givenInsight.CreatedAt,
 							UpdatedAt: givenInsight.UpdatedAt,
-							Data: insightAssetResponse{
-								Insight: givenInsight.Data.Insight,
-							},
+							Data:      givenInsight.Data,
 						},
-						audienceResponse{
+						assetResponse{
 							ID:        givenAudience.ID,
 							Type:      string(givenAudience.Type()),
 							CreatedAt: givenAudience.CreatedAt,
 							UpdatedAt: givenAudience.UpdatedAt,
-							Data: audienceAssetResponse{
-								Gender:             givenAudience.Data.Gender,
-								BirthCountry:       givenAudience.Data.BirthCountry,
-								AgeMin:             givenAudience.Data.AgeMin,
-								AgeMax:             givenAudience.Data.AgeMax,
-								SocialMediaHours:   givenAudience.Data.SocialMediaHours,
-								LastMonthPurchases: givenAudience.Data.LastMonthPurchases,
-							},
+							Data:      givenAudience.Data,
 						},
 					},
 					NextPageToken: "all-token",
@@ -170,7 +155,7 @@ func TestListAssets(t *testing.T) {
 		},
 		{
 			name: "error from service",
-			givenListAssetsMockResult: func() ([]assets.Asseter, string, error) {
+			givenListAssetsMockResult: func() ([]assets.ListedAsset, string, error) {
 				return nil, "", assert.AnError
 			},
 			expectErr: true,
@@ -182,7 +167,7 @@ func TestListAssets(t *testing.T) {
 			t.Parallel()
 
 			assetsSvc := &assetsSvcMock{
-				listAssetsFunc: func(ctx context.Context, params *assets.ListAssetsParams) ([]assets.Asseter, string, error) {
+				listAssetsFunc: func(ctx context.Context, params *assets.ListAssetsParams) ([]assets.ListedAsset, string, error) {
 					return tc.givenListAssetsMockResult()
 				},
 			}
@@ -260,6 +245,12 @@ func TestListAssets_Pagination(t *testing.T) {
 			expectStatusCode: http.StatusBadRequest,
 			expectErr:        ErrInvalidPageToken,
 		},
+		{
+			name:             "invalid user id",
+			givenURL:         "/?pageSize=10&maxResults=100&userId=invalid",
+			expectStatusCode: http.StatusBadRequest,
+			expectErr:        ErrInvalidUserID,
+		},
 		{
 			name:             "to default pagination values",
 			givenURL:         "/?pageSize=10&maxResults=100&pageToken=invalid",
@@ -278,10 +269,10 @@ func TestListAssets_Pagination(t *testing.T) {
 			)
 
 			assetsSvc := &assetsSvcMock{
-				listAssetsFunc: func(ctx context.Context, params *assets.ListAssetsParams) ([]assets.Asseter, string, error) {
+				listAssetsFunc: func(ctx context.Context, params *assets.ListAssetsParams) ([]assets.ListedAsset, string, error) {
 					if tc.expectStatusCode == http.StatusOK {
 						capturedParams = params
-						return []assets.Asseter{}, "", nil
+						return []assets.ListedAsset{}, "", nil
 					}
 					return nil, "", assert.AnError
 				},
@@ -320,3 +311,440 @@ func TestListAssets_Pagination(t *testing.T) {
 		})
 	}
 }
+
+func TestListAssets_FavoriteStatus(t *testing.T) {
+	t.Parallel()
+
+	assetFactory := assets.NewAssetFactory()
+	givenChart, err := assetFactory.CreateChart("Foo Chart", "Bar Axis", "Qux Axis", []float64{1, 2, 3})
+	require.NoError(t, err)
+	givenInsight, err := assetFactory.CreateInsight("Bar Insight")
+	require.NoError(t, err)
+
+	userID := ulid.Make().String()
+	favoriteID := ulid.Make().String()
+
+	testCases := []struct {
+		name        string
+		givenURL    string
+		givenListed []assets.ListedAsset
+		expectUser  string
+		expectItems []map[string]any
+	}{
+		{
+			name:     "on behalf of a user",
+			givenURL: "/?pageSize=10&maxResults=100&userId=" + userID,
+			givenListed: []assets.ListedAsset{
+				{Asset: givenChart, FavoriteCount: 3, FavoriteID: favoriteID},
+				{Asset: givenInsight, FavoriteCount: 1},
+			},
+			expectUser: userID,
+			expectItems: []map[string]any{
+				{"favorite_count": float64(3), "is_favorited": true, "favorite_id": favoriteID},
+				{"favorite_count": float64(1), "is_favorited": false},
+			},
+		},
+		{
+			name:     "without a user",
+			givenURL: "/?pageSize=10&maxResults=100",
+			givenListed: []assets.ListedAsset{
+				{Asset: givenChart, FavoriteCount: 3},
+				{Asset: givenInsight, FavoriteCount: 1},
+			},
+			expectItems: []map[string]any{
+				{"favorite_count": float64(3)},
+				{"favorite_count": float64(1)},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := Handler{
+				assetsSvc: &assetsSvcMock{
+					listAssetsFunc: func(ctx context.Context, params *assets.ListAssetsParams) ([]assets.ListedAsset, string, error) {
+						assert.Equal(t, tc.expectUser, params.UserID)
+						return tc.givenListed, "", nil
+					},
+				},
+				errHandler: &errorHandlerMock{},
+			}
+
+			req := httptest.NewRequest(http.MethodGet, tc.givenURL, nil)
+			rec := httptest.NewRecorder()
+
+			handler.ListAssets().ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var resp httputil.Response[struct {
+				Items []map[string]any `json:"items"`
+			}]
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			require.Len(t, resp.Data.Items, len(tc.expectItems))
+
+			for i, expectItem := range tc.expectItems {
+				item := resp.Data.Items[i]
+				for _, key := range []string{"favorite_count", "is_favorited", "favorite_id"} {
+					expected, ok := expectItem[key]
+					if !ok {
+						assert.NotContains(t, item, key)
+						continue
+					}
+					assert.Equal(t, expected, item[key])
+				}
+			}
+		})
+	}
+}
+
+func TestCreateAsset(t *testing.T) {
+	t.Parallel()
+
+	factory := assets.NewAssetFactory()
+
+	expectChart, err := factory.CreateChart("Foo", "X", "Y", []float64{1, 2})
+	require.NoError(t, err)
+
+	expectInsight, err := factory.CreateInsight("Bar")
+	require.NoError(t, err)
+
+	expectAudience, err := factory.CreateAudience(
+		[]string{"Female", "Male"},
+		[]string{"BR"},
+		[]assets.AgeGroup{assets.AgeGroup18To24, assets.AgeGroup25To34},
+		assets.AtLeast(3),
+		assets.Between(1, 5),
+		"",
+	)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name             string
+		givenBody        string
+		expectStatusCode int
+		expectAsset      any
+		expectErr        error
+		expectErrMessage string
+	}{
+		{
+			name:             "chart asset",
+			givenBody:        `{"type":"CHART","data":{"title":"Foo","x_axis":"X","y_axis":"Y","data":[1,2]}}`,
+			expectStatusCode: http.StatusCreated,
+			expectAsset:      expectChart.Data,
+		},
+		{
+			name:             "insight asset",
+			givenBody:        `{"type":"INSIGHT","data":{"insight":"Bar"}}`,
+			expectStatusCode: http.StatusCreated,
+			expectAsset:      expectInsight.Data,
+		},
+		{
+			name:             "audience asset",
+			givenBody:        `{"type":"AUDIENCE","data":{"genders":["Female","Male"],"birth_countries":["BR"],"age_groups":["18-24","25-34"],"social_media_hours":{"min":3},"last_month_purchases":{"min":1,"max":5}}}`,
+			expectStatusCode: http.StatusCreated,
+			expectAsset:      expectAudience.Data,
+		},
+		{
+			name:             "unsupported asset type",
+			givenBody:        `{"type":"REPORT","data":{}}`,
+			expectStatusCode: http.StatusBadRequest,
+			expectErr:        ErrUnsupportedAssetType,
+		},
+		{
+			name:             "malformed payload",
+			givenBody:        `{"type":`,
+			expectStatusCode: http.StatusBadRequest,
+			expectErr:        ErrInvalidAssetPayload,
+		},
+		{
+			name:             "data does not match asset type",
+			givenBody:        `{"type":"INSIGHT","data":{"insight":42}}`,
+			expectStatusCode: http.StatusBadRequest,
+			expectErr:        ErrInvalidAssetPayload,
+		},
+		{
+			name:             "chart without title",
+			givenBody:        `{"type":"CHART","data":{"title":" ","x_axis":"X","y_axis":"Y","data":[1,2]}}`,
+			expectStatusCode: http.StatusBadRequest,
+			expectErr:        assets.ErrEmptyChartTitle,
+			expectErrMessage: "Invalid asset field 'title': chart title is empty",
+		},
+		{
+			name:             "audience with unknown age group",
+			givenBody:        `{"type":"AUDIENCE","data":{"genders":["Female"],"birth_countries":["BR"],"age_groups":["20-30"]}}`,
+			expectStatusCode: http.StatusBadRequest,
+			expectErr:        assets.ErrInvalidAgeGroup,
+		},
+		{
+			name:             "audience with inverted range",
+			givenBody:        `{"type":"AUDIENCE","data":{"genders":["Female"],"birth_countries":["BR"],"age_groups":["18-24"],"social_media_hours":{"min":5,"max":3}}}`,
+			expectStatusCode: http.StatusBadRequest,
+			expectErr:        assets.ErrInvalidRange,
+			expectErrMessage: "Invalid asset field 'social_media_hours'",
+		},
+		{
+			name:             "insight too long",
+			givenBody:        `{"type":"INSIGHT","data":{"insight":"` + strings.Repeat("a", 10_001) + `"}}`,
+			expectStatusCode: http.StatusBadRequest,
+			expectErr:        assets.ErrInsightTooLong,
+			expectErrMessage: "Invalid asset field 'insight': insight is too long: expected up to 10000 characters",
+		},
+		{
+			name:             "audience with invalid expression",
+			givenBody:        `{"type":"AUDIENCE","data":{"genders":["Female"],"birth_countries":["BR"],"age_groups":["18-24"],"expression":"age > 18 AND income > 10"}}`,
+			expectStatusCode: http.StatusBadRequest,
+			expectErr:        audience.ErrInvalidExpression,
+			expectErrMessage: "Invalid audience expression at position 14: unknown attribute 'income'",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				storedAsset   assets.Asseter
+				capturedError error
+			)
+
+			assetsSvc := &assetsSvcMock{
+				createAssetFunc: func(ctx context.Context, asset assets.Asseter) error {
+					storedAsset = asset
+					return nil
+				},
+			}
+
+			errHandler := &errorHandlerMock{
+				handleFunc: func(ctx context.Context, w resterr.Writer, err error) {
+					capturedError = err
+					w.WriteHeader(http.StatusBadRequest)
+				},
+			}
+
+			handler := Handler{
+				assetsSvc:  assetsSvc,
+				errHandler: errHandler,
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/assets", strings.NewReader(tc.givenBody))
+			rec := httptest.NewRecorder()
+
+			handler.CreateAsset().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectStatusCode, rec.Code)
+
+			if tc.expectErr != nil {
+				assert.ErrorIs(t, capturedError, tc.expectErr)
+				assert.Nil(t, storedAsset)
+
+				if tc.expectErrMessage != "" {
+					var restErr resterr.RESTErr
+					require.ErrorAs(t, capturedError, &restErr)
+					assert.Contains(t, restErr.Message, tc.expectErrMessage)
+				}
+				return
+			}
+
+			require.NotNil(t, storedAsset)
+
+			kind, ok := assets.Lookup(storedAsset.Type())
+			require.True(t, ok)
+			assert.Equal(t, tc.expectAsset, kind.Data(storedAsset))
+		})
+	}
+}
+
+func TestPatchAsset(t *testing.T) {
+	t.Parallel()
+
+	factory := assets.NewAssetFactory()
+	givenChart, err := factory.CreateChart("Foo Chart", "Bar Axis", "Qux Axis", []float64{1, 2, 3})
+	require.NoError(t, err)
+
+	expectTitlePatched := givenChart.Data
+	expectTitlePatched.Title = "New Title"
+
+	expectDataPatched := givenChart.Data
+	expectDataPatched.Data = []float64{4}
+
+	testCases := []struct {
+		name             string
+		givenBody        string
+		expectStatusCode int
+		expectData       any
+		expectErr        error
+	}{
+		{
+			name:             "patch title only",
+			givenBody:        `{"data":{"title":"New Title"}}`,
+			expectStatusCode: http.StatusOK,
+			expectData:       expectTitlePatched,
+		},
+		{
+			name:             "patch with matching type",
+			givenBody:        `{"type":"CHART","data":{"data":[4]}}`,
+			expectStatusCode: http.StatusOK,
+			expectData:       expectDataPatched,
+		},
+		{
+			name:             "patch with different type",
+			givenBody:        `{"type":"INSIGHT","data":{"insight":"foo"}}`,
+			expectStatusCode: http.StatusBadRequest,
+			expectErr:        assets.ErrAssetTypeMismatch,
+		},
+		{
+			name:             "patch leaving chart without data",
+			givenBody:        `{"data":{"data":[]}}`,
+			expectStatusCode: http.StatusBadRequest,
+			expectErr:        assets.ErrInvalidChartData,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				capturedError error
+				updatedAsset  assets.Asseter
+			)
+
+			assetsSvc := &assetsSvcMock{
+				fetchAssetFunc: func(ctx context.Context, id string) (assets.Asseter, error) {
+					assert.Equal(t, givenChart.ID, id)
+					return givenChart, nil
+				},
+				updateAssetFunc: func(ctx context.Context, id string, asset assets.Asseter) (assets.Asseter, error) {
+					assert.Equal(t, givenChart.ID, id)
+					updatedAsset = asset
+					return asset, nil
+				},
+			}
+
+			errHandler := &errorHandlerMock{
+				handleFunc: func(ctx context.Context, w resterr.Writer, err error) {
+					capturedError = err
+					w.WriteHeader(http.StatusBadRequest)
+				},
+			}
+
+			handler := Handler{
+				assetsSvc:  assetsSvc,
+				errHandler: errHandler,
+			}
+
+			req := httptest.NewRequest(http.MethodPatch, "/assets/"+givenChart.ID, strings.NewReader(tc.givenBody))
+			req.SetPathValue("asset_id", givenChart.ID)
+			rec := httptest.NewRecorder()
+
+			handler.PatchAsset().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectStatusCode, rec.Code)
+
+			if tc.expectErr != nil {
+				assert.ErrorIs(t, capturedError, tc.expectErr)
+				return
+			}
+
+			var resp httputil.Response[assetResponse]
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			assert.Equal(t, givenChart.ID, resp.Data.ID)
+
+			require.NotNil(t, updatedAsset)
+			assert.Equal(t, givenChart.ID, updatedAsset.Metadata().ID)
+			assert.Equal(t, tc.expectData, updatedAsset.(assets.ChartAsset).Data)
+		})
+	}
+}
+
+func TestGetAssetSize(t *testing.T) {
+	t.Parallel()
+
+	givenID := ulid.Make().String()
+	size := 1234
+
+	testCases := []struct {
+		name             string
+		givenID          string
+		givenSize        *assets.AudienceSize
+		givenErr         error
+		expectStatusCode int
+		expectResponse   AssetSizeResponse
+		expectErr        error
+	}{
+		{
+			name:             "size of an audience",
+			givenID:          givenID,
+			givenSize:        &assets.AudienceSize{Size: &size, Threshold: 10},
+			expectStatusCode: http.StatusOK,
+			expectResponse:   AssetSizeResponse{AssetID: givenID, Size: &size, Threshold: 10},
+		},
+		{
+			name:             "suppressed size",
+			givenID:          givenID,
+			givenSize:        &assets.AudienceSize{Suppressed: true, Threshold: 10},
+			expectStatusCode: http.StatusOK,
+			expectResponse:   AssetSizeResponse{AssetID: givenID, Suppressed: true, Threshold: 10},
+		},
+		{
+			name:             "invalid asset id",
+			givenID:          "invalid",
+			expectStatusCode: http.StatusBadRequest,
+			expectErr:        ErrInvalidAssetID,
+		},
+		{
+			name:             "asset is not an audience",
+			givenID:          givenID,
+			givenErr:         assets.ErrNotAudience,
+			expectStatusCode: http.StatusBadRequest,
+			expectErr:        assets.ErrNotAudience,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var capturedError error
+
+			assetsSvc := &assetsSvcMock{
+				audienceSizeFunc: func(ctx context.Context, id string) (*assets.AudienceSize, error) {
+					assert.Equal(t, tc.givenID, id)
+					return tc.givenSize, tc.givenErr
+				},
+			}
+
+			errHandler := &errorHandlerMock{
+				handleFunc: func(ctx context.Context, w resterr.Writer, err error) {
+					capturedError = err
+					w.WriteHeader(http.StatusBadRequest)
+				},
+			}
+
+			handler := Handler{
+				assetsSvc:  assetsSvc,
+				errHandler: errHandler,
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/assets/"+tc.givenID+"/size", nil)
+			req.SetPathValue("asset_id", tc.givenID)
+			rec := httptest.NewRecorder()
+
+			handler.GetAssetSize().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectStatusCode, rec.Code)
+
+			if tc.expectErr != nil {
+				assert.ErrorIs(t, capturedError, tc.expectErr)
+				return
+			}
+
+			var resp httputil.Response[AssetSizeResponse]
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			assert.Equal(t, tc.expectResponse, resp.Data)
+		})
+	}
+}
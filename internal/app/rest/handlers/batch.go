@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
+	"github.com/alesr/platform-go-challenge/internal/pkg/httputil"
+	"github.com/alesr/resterr"
+)
+
+// MaxBatchOperations is the maximum number of operations in a batch of changes to a user's favorites.
+const MaxBatchOperations = 100
+
+// BatchFavoritesRequest defines the data structure for a batch of changes to a user's favorites.
+type BatchFavoritesRequest struct {
+	Operations []BatchFavoriteOperation `json:"operations"`
+}
+
+// BatchFavoriteOperation defines the data structure for a single change in a batch.
+// Favorites are identified by asset, and the description is ignored when removing them.
+type BatchFavoriteOperation struct {
+	Op          string `json:"op"`
+	AssetID     string `json:"asset_id"`
+	Description string `json:"description"`
+}
+
+func (b *BatchFavoritesRequest) validate() error {
+	if len(b.Operations) == 0 || len(b.Operations) > MaxBatchOperations {
+		return ErrInvalidBatchSize
+	}
+
+	for i, op := range b.Operations {
+		switch favorites.FavoriteOpType(op.Op) {
+		case favorites.FavoriteOpAdd, favorites.FavoriteOpRemove, favorites.FavoriteOpUpdate:
+		default:
+			return fmt.Errorf("operation %d: %w: '%s'", i, ErrInvalidBatchOperation, op.Op)
+		}
+
+		if err := validateID(op.AssetID); err != nil {
+			return fmt.Errorf("operation %d: %w, %v", i, ErrInvalidAssetID, err)
+		}
+
+		if len(op.Description) > MaxDescriptionLength {
+			return fmt.Errorf("operation %d: %w", i, ErrDescriptionMaxLen)
+		}
+	}
+	return nil
+}
+
+// BatchFavoritesResponse defines the data structure for the outcome of a batch, one result per operation.
+type BatchFavoritesResponse struct {
+	Results []BatchFavoriteResult `json:"results"`
+}
+
+// BatchFavoriteResult defines the data structure for the outcome of a single operation in a batch.
+// Error is only present when the operation failed, and carries the same error we'd
+// have returned if the operation had been requested on its own.
+type BatchFavoriteResult struct {
+	Op         string           `json:"op"`
+	AssetID    string           `json:"asset_id"`
+	StatusCode int              `json:"status_code"`
+	Error      *resterr.RESTErr `json:"error,omitempty"`
+}
+
+// BatchFavorites applies a batch of changes to a user's favorites.
+// By default each operation is applied on its own, with ?atomic=true either all are applied or none is.
+func (h *Handler) BatchFavorites() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		userID := r.PathValue("user_id")
+		if err := validateID(userID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate user ID: %w, %v", ErrInvalidUserID, err))
+			return
+		}
+
+		var atomic bool
+		if v := r.URL.Query().Get("atomic"); v != "" {
+			var err error
+			if atomic, err = strconv.ParseBool(v); err != nil {
+				h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not parse atomic flag: %w, %v", ErrInvalidAtomicFlag, err))
+				return
+			}
+		}
+
+		var data BatchFavoritesRequest
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not decode request data: %w, %w", err, ErrInvalidBatchPayload))
+			return
+		}
+
+		if err := data.validate(); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate batch: %w", err))
+			return
+		}
+
+		ops := make([]favorites.FavoriteOp, 0, len(data.Operations))
+		for _, op := range data.Operations {
+			ops = append(ops, favorites.FavoriteOp{
+				Type:        favorites.FavoriteOpType(op.Op),
+				AssetID:     op.AssetID,
+				Description: op.Description,
+			})
+		}
+
+		results, err := h.favoritesSvc.ApplyFavoriteOps(r.Context(), userID, ops, atomic)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not apply batch: %w", err))
+			return
+		}
+
+		resp := BatchFavoritesResponse{Results: make([]BatchFavoriteResult, 0, len(results))}
+		for i, opErr := range results {
+			result := BatchFavoriteResult{
+				Op:         data.Operations[i].Op,
+				AssetID:    data.Operations[i].AssetID,
+				StatusCode: http.StatusOK,
+			}
+			if opErr != nil {
+				restErr := h.errLookup(opErr)
+				result.StatusCode = restErr.StatusCode
+				result.Error = &restErr
+			}
+			resp.Results = append(resp.Results, result)
+		}
+		httputil.RespondWithJSON(w, http.StatusOK, resp)
+	}
+}
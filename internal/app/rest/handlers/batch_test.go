@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
+	"github.com/alesr/platform-go-challenge/internal/pkg/httputil"
+	"github.com/alesr/resterr"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchFavorites(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+	assetID := ulid.Make().String()
+	otherAssetID := ulid.Make().String()
+
+	givenRESTErr := resterr.RESTErr{StatusCode: http.StatusNotFound, Message: "Favorite asset not found"}
+
+	validBody := fmt.Sprintf(`{"operations":[
+		{"op":"add","asset_id":%q,"description":"foo"},
+		{"op":"remove","asset_id":%q}
+	]}`, assetID, otherAssetID)
+
+	tooManyOps := make([]string, MaxBatchOperations+1)
+	for i := range tooManyOps {
+		tooManyOps[i] = fmt.Sprintf(`{"op":"remove","asset_id":%q}`, assetID)
+	}
+
+	testCases := []struct {
+		name          string
+		givenUserID   string
+		givenQuery    string
+		givenBody     string
+		expectAtomic  bool
+		expectedError error
+	}{
+		{
+			name:        "per operation",
+			givenUserID: userID,
+			givenBody:   validBody,
+		},
+		{
+			name:         "atomic",
+			givenUserID:  userID,
+			givenQuery:   "?atomic=true",
+			givenBody:    validBody,
+			expectAtomic: true,
+		},
+		{
+			name:          "invalid user id",
+			givenUserID:   "foo",
+			givenBody:     validBody,
+			expectedError: ErrInvalidUserID,
+		},
+		{
+			name:          "invalid atomic flag",
+			givenUserID:   userID,
+			givenQuery:    "?atomic=foo",
+			givenBody:     validBody,
+			expectedError: ErrInvalidAtomicFlag,
+		},
+		{
+			name:          "invalid payload",
+			givenUserID:   userID,
+			givenBody:     `{"operations":`,
+			expectedError: ErrInvalidBatchPayload,
+		},
+		{
+			name:          "empty batch",
+			givenUserID:   userID,
+			givenBody:     `{"operations":[]}`,
+			expectedError: ErrInvalidBatchSize,
+		},
+		{
+			name:          "too many operations",
+			givenUserID:   userID,
+			givenBody:     `{"operations":[` + strings.Join(tooManyOps, ",") + `]}`,
+			expectedError: ErrInvalidBatchSize,
+		},
+		{
+			name:          "invalid operation",
+			givenUserID:   userID,
+			givenBody:     fmt.Sprintf(`{"operations":[{"op":"star","asset_id":%q}]}`, assetID),
+			expectedError: ErrInvalidBatchOperation,
+		},
+		{
+			name:          "invalid asset id",
+			givenUserID:   userID,
+			givenBody:     `{"operations":[{"op":"add","asset_id":"foo"}]}`,
+			expectedError: ErrInvalidAssetID,
+		},
+		{
+			name:        "description too long",
+			givenUserID: userID,
+			givenBody: fmt.Sprintf(
+				`{"operations":[{"op":"update","asset_id":%q,"description":%q}]}`,
+				assetID, strings.Repeat("a", MaxDescriptionLength+1),
+			),
+			expectedError: ErrDescriptionMaxLen,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var handledErr error
+
+			handler := Handler{
+				errHandler: &errorHandlerMock{
+					handleFunc: func(ctx context.Context, w resterr.Writer, err error) {
+						handledErr = err
+					},
+				},
+				errLookup: func(err error) resterr.RESTErr {
+					assert.ErrorIs(t, err, favorites.ErrFavoriteAssetNotFound)
+					return givenRESTErr
+				},
+				favoritesSvc: &favoritesSvcMock{
+					applyFavoriteOpsFunc: func(ctx context.Context, id string, ops []favorites.FavoriteOp, atomic bool) ([]error, error) {
+						assert.Equal(t, userID, id)
+						assert.Equal(t, tc.expectAtomic, atomic)
+						assert.Equal(t, []favorites.FavoriteOp{
+							{Type: favorites.FavoriteOpAdd, AssetID: assetID, Description: "foo"},
+							{Type: favorites.FavoriteOpRemove, AssetID: otherAssetID},
+						}, ops)
+						return []error{nil, favorites.ErrFavoriteAssetNotFound}, nil
+					},
+				},
+			}
+
+			req := httptest.NewRequest(
+				http.MethodPost,
+				"/users/"+tc.givenUserID+"/favorites:batch"+tc.givenQuery,
+				strings.NewReader(tc.givenBody),
+			)
+			req.SetPathValue("user_id", tc.givenUserID)
+			rec := httptest.NewRecorder()
+
+			handler.BatchFavorites().ServeHTTP(rec, req)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, handledErr, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, handledErr)
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var resp httputil.Response[BatchFavoritesResponse]
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+
+			assert.Equal(t, []BatchFavoriteResult{
+				{Op: "add", AssetID: assetID, StatusCode: http.StatusOK},
+				{Op: "remove", AssetID: otherAssetID, StatusCode: http.StatusNotFound, Error: &givenRESTErr},
+			}, resp.Data.Results)
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
+	"github.com/alesr/platform-go-challenge/internal/pkg/httputil"
+)
+
+// MaxCollectionNameLength is the maximum number of characters in a collection name.
+const MaxCollectionNameLength = 100
+
+// CollectionRequest defines the data structure for creating or renaming a collection.
+type CollectionRequest struct {
+	Name string `json:"name"`
+}
+
+func (c *CollectionRequest) validate() error {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" || utf8.RuneCountInString(c.Name) > MaxCollectionNameLength {
+		return fmt.Errorf("%w: expected between 1 and %d characters", ErrInvalidCollectionName, MaxCollectionNameLength)
+	}
+	return nil
+}
+
+// CollectionResponse defines the data structure for a user's collection.
+type CollectionResponse struct {
+	ID            string    `json:"id"`
+	UserID        string    `json:"user_id"`
+	Name          string    `json:"name"`
+	FavoriteCount int       `json:"favorite_count"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ListCollectionsResponse defines the data structure for listing a user's collections.
+type ListCollectionsResponse struct {
+	Collections []CollectionResponse `json:"collections"`
+}
+
+// CreateCollection creates a collection of favorites for a user.
+func (h *Handler) CreateCollection() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		userID := r.PathValue("user_id")
+		if err := validateID(userID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate user ID: %w, %v", ErrInvalidUserID, err))
+			return
+		}
+
+		var data CollectionRequest
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not decode request data: %w, %w", err, ErrInvalidCollectionPayload))
+			return
+		}
+
+		if err := data.validate(); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate collection: %w", err))
+			return
+		}
+
+		collection, err := h.favoritesSvc.CreateCollection(r.Context(), userID, &favorites.CollectionParams{Name: data.Name})
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not create collection: %w", err))
+			return
+		}
+		httputil.RespondWithJSON(w, http.StatusCreated, toCollectionResponse(*collection))
+	}
+}
+
+// ListCollections lists a user's collections.
+func (h *Handler) ListCollections() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.PathValue("user_id")
+		if err := validateID(userID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate user ID: %w, %v", ErrInvalidUserID, err))
+			return
+		}
+
+		collections, err := h.favoritesSvc.ListCollections(r.Context(), userID)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not list collections: %w", err))
+			return
+		}
+
+		resp := ListCollectionsResponse{Collections: make([]CollectionResponse, 0, len(collections))}
+		for _, c := range collections {
+			resp.Collections = append(resp.Collections, toCollectionResponse(c))
+		}
+		httputil.RespondWithJSON(w, http.StatusOK, resp)
+	}
+}
+
+// GetCollection returns one of a user's collections.
+// The favorites in it are listed with GET /users/{user_id}/favorites?collection_id=.
+func (h *Handler) GetCollection() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, collectionID, err := collectionPathValues(r)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, err)
+			return
+		}
+
+		collection, err := h.favoritesSvc.FetchCollection(r.Context(), collectionID, userID)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not fetch collection: %w", err))
+			return
+		}
+		httputil.RespondWithJSON(w, http.StatusOK, toCollectionResponse(*collection))
+	}
+}
+
+// UpdateCollection renames one of a user's collections.
+func (h *Handler) UpdateCollection() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		userID, collectionID, err := collectionPathValues(r)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, err)
+			return
+		}
+
+		var data CollectionRequest
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not decode request data: %w, %w", err, ErrInvalidCollectionPayload))
+			return
+		}
+
+		if err := data.validate(); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate collection: %w", err))
+			return
+		}
+
+		collection, err := h.favoritesSvc.UpdateCollection(r.Context(), collectionID, userID, &favorites.CollectionParams{Name: data.Name})
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not update collection: %w", err))
+			return
+		}
+		httputil.RespondWithJSON(w, http.StatusOK, toCollectionResponse(*collection))
+	}
+}
+
+// DeleteCollection deletes one of a user's collections, keeping the favorites in it.
+func (h *Handler) DeleteCollection() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, collectionID, err := collectionPathValues(r)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, err)
+			return
+		}
+
+		if err := h.favoritesSvc.DeleteCollection(r.Context(), collectionID, userID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not delete collection: %w", err))
+			return
+		}
+		httputil.RespondWithJSON[any](w, http.StatusNoContent, nil)
+	}
+}
+
+// AddToCollection adds a user's favorite to one of the user's collections.
+func (h *Handler) AddToCollection() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, collectionID, err := collectionPathValues(r)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, err)
+			return
+		}
+
+		favoriteID := r.PathValue("favorite_id")
+		if err := validateID(favoriteID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate favorite ID: %w, %v", ErrInvalidFavoriteID, err))
+			return
+		}
+
+		if err := h.favoritesSvc.AddToCollection(r.Context(), collectionID, favoriteID, userID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not add favorite to collection: %w", err))
+			return
+		}
+		httputil.RespondWithJSON[any](w, http.StatusNoContent, nil)
+	}
+}
+
+// RemoveFromCollection removes a user's favorite from one of the user's collections.
+func (h *Handler) RemoveFromCollection() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, collectionID, err := collectionPathValues(r)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, err)
+			return
+		}
+
+		favoriteID := r.PathValue("favorite_id")
+		if err := validateID(favoriteID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate favorite ID: %w, %v", ErrInvalidFavoriteID, err))
+			return
+		}
+
+		if err := h.favoritesSvc.RemoveFromCollection(r.Context(), collectionID, favoriteID, userID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not remove favorite from collection: %w", err))
+			return
+		}
+		httputil.RespondWithJSON[any](w, http.StatusNoContent, nil)
+	}
+}
+
+// collectionPathValues returns the validated user and collection IDs from the request path.
+func collectionPathValues(r *http.Request) (string, string, error) {
+	userID := r.PathValue("user_id")
+	if err := validateID(userID); err != nil {
+		return "", "", fmt.Errorf("could not validate user ID: %w, %v", ErrInvalidUserID, err)
+	}
+
+	collectionID := r.PathValue("collection_id")
+	if err := validateID(collectionID); err != nil {
+		return "", "", fmt.Errorf("could not validate collection ID: %w, %v", ErrInvalidCollectionID, err)
+	}
+	return userID, collectionID, nil
+}
+
+func toCollectionResponse(c favorites.Collection) CollectionResponse {
+	return CollectionResponse{
+		ID:            c.ID,
+		UserID:        c.UserID,
+		Name:          c.Name,
+		FavoriteCount: c.FavoriteCount,
+		CreatedAt:     c.CreatedAt,
+		UpdatedAt:     c.UpdatedAt,
+	}
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
+	"github.com/alesr/platform-go-challenge/internal/pkg/httputil"
+	"github.com/alesr/resterr"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateCollection(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+
+	testCases := []struct {
+		name          string
+		givenUserID   string
+		givenBody     string
+		expectName    string
+		expectedError error
+	}{
+		{
+			name:        "success",
+			givenUserID: userID,
+			givenBody:   `{"name":"  Quarterly review "}`,
+			expectName:  "Quarterly review",
+		},
+		{
+			name:          "invalid user id",
+			givenUserID:   "foo",
+			givenBody:     `{"name":"Quarterly review"}`,
+			expectedError: ErrInvalidUserID,
+		},
+		{
+			name:          "invalid payload",
+			givenUserID:   userID,
+			givenBody:     `{"name":`,
+			expectedError: ErrInvalidCollectionPayload,
+		},
+		{
+			name:          "blank name",
+			givenUserID:   userID,
+			givenBody:     `{"name":"   "}`,
+			expectedError: ErrInvalidCollectionName,
+		},
+		{
+			name:          "name too long",
+			givenUserID:   userID,
+			givenBody:     fmt.Sprintf(`{"name":%q}`, strings.Repeat("a", MaxCollectionNameLength+1)),
+			expectedError: ErrInvalidCollectionName,
+		},
+		{
+			name:          "name taken",
+			givenUserID:   userID,
+			givenBody:     `{"name":"Taken"}`,
+			expectName:    "Taken",
+			expectedError: favorites.ErrCollectionNameTaken,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var handledErr error
+
+			handler := Handler{
+				errHandler: &errorHandlerMock{
+					handleFunc: func(ctx context.Context, w resterr.Writer, err error) {
+						handledErr = err
+					},
+				},
+				favoritesSvc: &favoritesSvcMock{
+					createCollectionFunc: func(ctx context.Context, uID string, params *favorites.CollectionParams) (*favorites.Collection, error) {
+						assert.Equal(t, userID, uID)
+						assert.Equal(t, tc.expectName, params.Name)
+						if params.Name == "Taken" {
+							return nil, favorites.ErrCollectionNameTaken
+						}
+						return &favorites.Collection{ID: "col-1", UserID: uID, Name: params.Name}, nil
+					},
+				},
+			}
+
+			req := httptest.NewRequest(
+				http.MethodPost,
+				"/users/"+tc.givenUserID+"/collections",
+				strings.NewReader(tc.givenBody),
+			)
+			req.SetPathValue("user_id", tc.givenUserID)
+			rec := httptest.NewRecorder()
+
+			handler.CreateCollection().ServeHTTP(rec, req)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, handledErr, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, handledErr)
+			assert.Equal(t, http.StatusCreated, rec.Code)
+
+			var resp httputil.Response[CollectionResponse]
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			assert.Equal(t, "col-1", resp.Data.ID)
+			assert.Equal(t, tc.expectName, resp.Data.Name)
+		})
+	}
+}
+
+func TestListCollections(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+
+	handler := Handler{
+		errHandler: &errorHandlerMock{
+			handleFunc: func(ctx context.Context, w resterr.Writer, err error) {
+				t.Fatalf("unexpected error: %v", err)
+			},
+		},
+		favoritesSvc: &favoritesSvcMock{
+			listCollectionsFunc: func(ctx context.Context, uID string) ([]favorites.Collection, error) {
+				assert.Equal(t, userID, uID)
+				return []favorites.Collection{
+					{ID: "col-1", UserID: uID, Name: "Marketing", FavoriteCount: 3},
+					{ID: "col-2", UserID: uID, Name: "Sales"},
+				}, nil
+			},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/users/"+userID+"/collections", nil)
+	req.SetPathValue("user_id", userID)
+	rec := httptest.NewRecorder()
+
+	handler.ListCollections().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp httputil.Response[ListCollectionsResponse]
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Len(t, resp.Data.Collections, 2)
+	assert.Equal(t, "Marketing", resp.Data.Collections[0].Name)
+	assert.Equal(t, 3, resp.Data.Collections[0].FavoriteCount)
+	assert.Equal(t, "Sales", resp.Data.Collections[1].Name)
+}
+
+func TestAddToCollection(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+	collectionID := ulid.Make().String()
+	favoriteID := ulid.Make().String()
+
+	testCases := []struct {
+		name            string
+		givenCollection string
+		givenFavorite   string
+		givenSvcErr     error
+		expectedError   error
+	}{
+		{
+			name:            "success",
+			givenCollection: collectionID,
+			givenFavorite:   favoriteID,
+		},
+		{
+			name:            "invalid collection id",
+			givenCollection: "foo",
+			givenFavorite:   favoriteID,
+			expectedError:   ErrInvalidCollectionID,
+		},
+		{
+			name:            "invalid favorite id",
+			givenCollection: collectionID,
+			givenFavorite:   "foo",
+			expectedError:   ErrInvalidFavoriteID,
+		},
+		{
+			name:            "collection not found",
+			givenCollection: collectionID,
+			givenFavorite:   favoriteID,
+			givenSvcErr:     favorites.ErrCollectionNotFound,
+			expectedError:   favorites.ErrCollectionNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var handledErr error
+
+			handler := Handler{
+				errHandler: &errorHandlerMock{
+					handleFunc: func(ctx context.Context, w resterr.Writer, err error) {
+						handledErr = err
+					},
+				},
+				favoritesSvc: &favoritesSvcMock{
+					addToCollectionFunc: func(ctx context.Context, colID, favID, uID string) error {
+						assert.Equal(t, collectionID, colID)
+						assert.Equal(t, favoriteID, favID)
+						assert.Equal(t, userID, uID)
+						return tc.givenSvcErr
+					},
+				},
+			}
+
+			req := httptest.NewRequest(
+				http.MethodPut,
+				"/users/"+userID+"/collections/"+tc.givenCollection+"/favorites/"+tc.givenFavorite,
+				nil,
+			)
+			req.SetPathValue("user_id", userID)
+			req.SetPathValue("collection_id", tc.givenCollection)
+			req.SetPathValue("favorite_id", tc.givenFavorite)
+			rec := httptest.NewRecorder()
+
+			handler.AddToCollection().ServeHTTP(rec, req)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, handledErr, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, handledErr)
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+		})
+	}
+}
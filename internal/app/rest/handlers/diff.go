@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
+	"github.com/alesr/platform-go-challenge/internal/pkg/httputil"
+)
+
+// FavoriteDiffResponse defines the data structure for how the asset of a favorite changed since it was favorited.
+type FavoriteDiffResponse struct {
+	FavoriteID     string                `json:"favorite_id"`
+	AssetID        string                `json:"asset_id"`
+	AssetType      string                `json:"asset_type"`
+	Changed        bool                  `json:"changed"`
+	FavoritedAt    time.Time             `json:"favorited_at"`
+	AssetUpdatedAt time.Time             `json:"asset_updated_at"`
+	Changes        []FieldChangeResponse `json:"changes"`
+}
+
+// FieldChangeResponse defines the data structure for a field of an asset's data that changed.
+// Favorited or Current is null when the field was added or removed.
+type FieldChangeResponse struct {
+	Field     string `json:"field"`
+	Favorited any    `json:"favorited"`
+	Current   any    `json:"current"`
+}
+
+// GetFavoriteDiff compares the asset of a user's favorite with the asset as it was when it was favorited.
+func (h *Handler) GetFavoriteDiff() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.PathValue("user_id")
+		favoriteID := r.PathValue("favorite_id")
+
+		if err := validateID(userID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate user ID: %w, %v", ErrInvalidUserID, err))
+			return
+		}
+
+		if err := validateID(favoriteID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate favorite ID: %w, %v", ErrInvalidFavoriteID, err))
+			return
+		}
+
+		diff, err := h.favoritesSvc.FetchFavoriteDiff(r.Context(), favoriteID, userID)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not fetch favorite diff: %w", err))
+			return
+		}
+		httputil.RespondWithJSON(w, http.StatusOK, toFavoriteDiffResponse(diff))
+	}
+}
+
+func toFavoriteDiffResponse(diff *favorites.FavoriteDiff) FavoriteDiffResponse {
+	changes := make([]FieldChangeResponse, 0, len(diff.Changes))
+	for _, change := range diff.Changes {
+		changes = append(changes, FieldChangeResponse{
+			Field:     change.Field,
+			Favorited: change.Favorited,
+			Current:   change.Current,
+		})
+	}
+
+	return FavoriteDiffResponse{
+		FavoriteID:     diff.FavoriteID,
+		AssetID:        diff.AssetID,
+		AssetType:      string(diff.AssetType),
+		Changed:        len(changes) > 0,
+		FavoritedAt:    diff.FavoritedAt,
+		AssetUpdatedAt: diff.AssetUpdatedAt,
+		Changes:        changes,
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alesr/platform-go-challenge/internal/assets"
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
+	"github.com/alesr/platform-go-challenge/internal/pkg/httputil"
+	"github.com/alesr/resterr"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFavoriteDiff(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+	favoriteID := ulid.Make().String()
+
+	testCases := []struct {
+		name          string
+		givenFavorite string
+		givenChanges  []favorites.FieldChange
+		givenSvcErr   error
+		expectChanged bool
+		expectChanges []FieldChangeResponse
+		expectedError error
+	}{
+		{
+			name:          "changed",
+			givenFavorite: favoriteID,
+			givenChanges:  []favorites.FieldChange{{Field: "insight", Favorited: "Sales are up", Current: "Sales are down"}},
+			expectChanged: true,
+			expectChanges: []FieldChangeResponse{{Field: "insight", Favorited: "Sales are up", Current: "Sales are down"}},
+		},
+		{
+			name:          "unchanged",
+			givenFavorite: favoriteID,
+			expectChanges: []FieldChangeResponse{},
+		},
+		{
+			name:          "invalid favorite id",
+			givenFavorite: "foo",
+			expectedError: ErrInvalidFavoriteID,
+		},
+		{
+			name:          "favorite not found",
+			givenFavorite: favoriteID,
+			givenSvcErr:   favorites.ErrFavoriteAssetNotFound,
+			expectedError: favorites.ErrFavoriteAssetNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var handledErr error
+
+			handler := Handler{
+				errHandler: &errorHandlerMock{
+					handleFunc: func(ctx context.Context, w resterr.Writer, err error) {
+						handledErr = err
+					},
+				},
+				favoritesSvc: &favoritesSvcMock{
+					fetchFavoriteDiffFunc: func(ctx context.Context, favID, uID string) (*favorites.FavoriteDiff, error) {
+						assert.Equal(t, favoriteID, favID)
+						assert.Equal(t, userID, uID)
+						if tc.givenSvcErr != nil {
+							return nil, tc.givenSvcErr
+						}
+						return &favorites.FavoriteDiff{
+							FavoriteID: favID,
+							AssetID:    "asset-1",
+							AssetType:  assets.TypeAssetInsight,
+							Changes:    tc.givenChanges,
+						}, nil
+					},
+				},
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/users/"+userID+"/favorites/"+tc.givenFavorite+"/diff", nil)
+			req.SetPathValue("user_id", userID)
+			req.SetPathValue("favorite_id", tc.givenFavorite)
+			rec := httptest.NewRecorder()
+
+			handler.GetFavoriteDiff().ServeHTTP(rec, req)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, handledErr, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, handledErr)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var resp httputil.Response[FavoriteDiffResponse]
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			assert.Equal(t, favoriteID, resp.Data.FavoriteID)
+			assert.Equal(t, string(assets.TypeAssetInsight), resp.Data.AssetType)
+			assert.Equal(t, tc.expectChanged, resp.Data.Changed)
+			assert.Equal(t, tc.expectChanges, resp.Data.Changes)
+		})
+	}
+}
@@ -2,14 +2,33 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/alesr/platform-go-challenge/internal/assets"
 	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
 	"github.com/alesr/platform-go-challenge/internal/pkg/httputil"
+	"github.com/alesr/resterr"
 )
 
-const MaxDescriptionLength = 128
+const (
+	MaxDescriptionLength = 128
+	MaxFavoriteTags      = 20
+	MaxTagLength         = 32
+	MaxSearchQueryLength = 256
+
+	defaultFavoritesPageSize = 20
+	maxFavoritesPageSize     = 100
+
+	// How long clients are asked to wait before trying again when the favorites queue is full.
+	queueFullRetryAfter = 5 * time.Second
+)
 
 // FavoriteAssetRequest defines the data structure for a request to favorite an asset.
 type FavoriteAssetRequest struct {
@@ -20,23 +39,72 @@ type FavoriteAssetRequest struct {
 
 // ListUserFavoritesResponse defines the data structure for listing user favorites
 type ListUserFavoritesResponse struct {
-	Items []FavoriteAssetResponse `json:"items"`
+	Items         []FavoriteAssetResponse `json:"items"`
+	NextPageToken string                  `json:"next_page_token,omitempty"`
 }
 
 // FavoriteAssetResponse defines the data structure item for a list of favorite assets.
+// Asset holds the same per-type body returned when listing assets.
 type FavoriteAssetResponse struct {
-	ID          string `json:"id"`
-	Description string `json:"description"`
+	ID          string    `json:"id"`
+	AssetID     string    `json:"asset_id"`
+	AssetType   string    `json:"asset_type"`
+	Description string    `json:"description"`
+	Tags        []string  `json:"tags,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Asset       any       `json:"asset,omitempty"`
+	// ChangedSinceFavorited is only present along with Asset.
+	ChangedSinceFavorited *bool `json:"changed_since_favorited,omitempty"`
 }
 
+// FavoriteJobResponse defines the data structure for the status of a favorite job.
+// Error is only present when the job failed, and carries the same error we'd
+// have returned if the favorite had been stored synchronously.
+type FavoriteJobResponse struct {
+	ID        string           `json:"id"`
+	UserID    string           `json:"user_id"`
+	AssetID   string           `json:"asset_id"`
+	Status    string           `json:"status"`
+	Attempts  int              `json:"attempts"`
+	Error     *resterr.RESTErr `json:"error,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// UpdateFavoriteRequest defines the data structure for a request to update a favorite.
+// Tags replace the favorite's tags, and are left as they are when omitted.
 type UpdateFavoriteRequest struct {
-	Description string `json:"description"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
 }
 
+// validate checks the request, and lowercases the tags and removes
+// their surrounding spaces and duplicates, so they're matched whatever their case.
 func (u *UpdateFavoriteRequest) validate() error {
 	if len(u.Description) > MaxDescriptionLength {
 		return ErrDescriptionMaxLen
 	}
+
+	if u.Tags == nil {
+		return nil
+	}
+
+	tags := make([]string, 0, len(u.Tags))
+	for _, tag := range u.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || utf8.RuneCountInString(tag) > MaxTagLength {
+			return ErrInvalidTags
+		}
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	if len(tags) > MaxFavoriteTags {
+		return ErrInvalidTags
+	}
+	u.Tags = tags
 	return nil
 }
 
@@ -62,13 +130,35 @@ func (h *Handler) FavoriteAsset() http.HandlerFunc {
 			Description: data.Description,
 		}
 
-		if err := h.favoritesSvc.FavoriteAsset(r.Context(), &params); err != nil {
+		job, err := h.favoritesSvc.FavoriteAsset(r.Context(), &params)
+		if err != nil {
+			if errors.Is(err, favorites.ErrQueueFull) {
+				w.Header().Set("Retry-After", strconv.Itoa(int(queueFullRetryAfter.Seconds())))
+			}
 			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not favorite asset: %w", err))
 			return
 		}
 
-		// using empty struct since we don't need to return data
-		httputil.RespondWithJSON(w, http.StatusAccepted, struct{}{})
+		w.Header().Set("Location", "/favorite-jobs/"+job.ID)
+		httputil.RespondWithJSON(w, http.StatusAccepted, h.toFavoriteJobResponse(job))
+	}
+}
+
+// GetFavoriteJob returns the status of an asynchronous favorite job.
+func (h *Handler) GetFavoriteJob() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jobID := r.PathValue("job_id")
+		if err := validateID(jobID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate job ID: %w, %v", ErrInvalidJobID, err))
+			return
+		}
+
+		job, err := h.favoritesSvc.FetchFavoriteJob(r.Context(), jobID)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not fetch favorite job: %w", err))
+			return
+		}
+		httputil.RespondWithJSON(w, http.StatusOK, h.toFavoriteJobResponse(job))
 	}
 }
 
@@ -81,14 +171,21 @@ func (h *Handler) GetUserFavorites() http.HandlerFunc {
 			return
 		}
 
-		favorites, err := h.favoritesSvc.FetchUserFavorites(r.Context(), userID)
+		params, err := h.parseListFavoritesParams(r)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not parse list favorites params: %w", err))
+			return
+		}
+
+		favorites, nextPageToken, err := h.favoritesSvc.FetchUserFavorites(r.Context(), userID, params)
 		if err != nil {
 			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not fetch user favorites: %w", err))
 			return
 		}
 
 		httputil.RespondWithJSON(w, http.StatusOK, ListUserFavoritesResponse{
-			Items: toFavoritesResponse(favorites...),
+			Items:         toFavoritesResponse(favorites...),
+			NextPageToken: nextPageToken,
 		})
 	}
 }
@@ -115,8 +212,14 @@ func (h *Handler) UpdateFavorite() http.HandlerFunc {
 			return
 		}
 
+		if err := reqData.validate(); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate request data: %w", err))
+			return
+		}
+
 		params := favorites.UpdateFavoriteParams{
 			Description: reqData.Description,
+			Tags:        reqData.Tags,
 		}
 
 		favAsset, err := h.favoritesSvc.UpdateFavorite(r.Context(), favoriteID, userID, &params)
@@ -152,13 +255,224 @@ func (h *Handler) DeleteFavorite() http.HandlerFunc {
 	}
 }
 
+// OpenFavorite records that the user opened a favorite, which ranks it higher in listings sorted by frecency.
+func (h *Handler) OpenFavorite() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.PathValue("user_id")
+		favoriteID := r.PathValue("favorite_id")
+
+		if err := validateID(userID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate user ID: %w, %v", ErrInvalidUserID, err))
+			return
+		}
+
+		if err := validateID(favoriteID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate favorite ID: %w, %v", ErrInvalidFavoriteID, err))
+			return
+		}
+
+		if err := h.favoritesSvc.RecordFavoriteOpen(r.Context(), favoriteID, userID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not record favorite open: %w", err))
+			return
+		}
+		httputil.RespondWithJSON[any](w, http.StatusNoContent, nil)
+	}
+}
+
+// ClearFavoritesResponse defines the data structure for the outcome of clearing a user's favorites.
+type ClearFavoritesResponse struct {
+	Deleted int64 `json:"deleted"`
+}
+
+// DeleteFavorites deletes a user's favorite of the asset given by ?asset_id=,
+// or all of the user's favorites when no asset is given.
+func (h *Handler) DeleteFavorites() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.PathValue("user_id")
+
+		if err := validateID(userID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate user ID: %w, %v", ErrInvalidUserID, err))
+			return
+		}
+
+		if !r.URL.Query().Has("asset_id") {
+			deleted, err := h.favoritesSvc.ClearFavorites(r.Context(), userID)
+			if err != nil {
+				h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not clear favorites: %w", err))
+				return
+			}
+			httputil.RespondWithJSON(w, http.StatusOK, ClearFavoritesResponse{Deleted: deleted})
+			return
+		}
+
+		assetID := r.URL.Query().Get("asset_id")
+		if err := validateID(assetID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate asset ID: %w, %v", ErrInvalidAssetID, err))
+			return
+		}
+
+		if err := h.favoritesSvc.DeleteFavoriteByAsset(r.Context(), assetID, userID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not delete favorite: %w", err))
+			return
+		}
+		httputil.RespondWithJSON[any](w, http.StatusNoContent, nil)
+	}
+}
+
+// parseListFavoritesParams parses the optional parameters for listing favorites.
+// Page sizes out of bounds fall back to the default and maximum page sizes.
+func (h *Handler) parseListFavoritesParams(r *http.Request) (*favorites.ListFavoritesParams, error) {
+	params := favorites.ListFavoritesParams{PageSize: defaultFavoritesPageSize}
+
+	if v := r.URL.Query().Get("pageSize"); v != "" {
+		pageSize, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPageSize, err)
+		}
+		if pageSize > 0 {
+			params.PageSize = min(pageSize, maxFavoritesPageSize)
+		}
+	}
+
+	if pageToken := r.URL.Query().Get("pageToken"); pageToken != "" {
+		cursor, err := favorites.DecodeCursor(pageToken)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPageToken, err)
+		}
+		params.Cursor = cursor
+	}
+
+	if v := r.URL.Query().Get("waitForWrites"); v != "" {
+		wait, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidWaitForWrites, err)
+		}
+		params.WaitForPendingWrites = wait
+	}
+
+	if r.URL.Query().Has("collection_id") {
+		collectionID := r.URL.Query().Get("collection_id")
+		if err := validateID(collectionID); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCollectionID, err)
+		}
+		params.CollectionID = collectionID
+	}
+
+	if v := r.URL.Query().Get("sort"); v != "" {
+		sort := favorites.FavoriteSort(strings.ToLower(v))
+		switch sort {
+		case favorites.SortPosition, favorites.SortFrecency:
+		default:
+			return nil, fmt.Errorf("%w: '%s'", ErrInvalidSort, v)
+		}
+		params.Sort = sort
+	}
+
+	if q := strings.TrimSpace(r.URL.Query().Get("q")); q != "" {
+		if utf8.RuneCountInString(q) > MaxSearchQueryLength {
+			return nil, ErrInvalidSearchQuery
+		}
+		params.Query = q
+	}
+
+	for _, v := range r.URL.Query()["asset_type"] {
+		assetType := assets.AssetType(strings.ToUpper(v))
+		if _, ok := assets.Lookup(assetType); !ok {
+			return nil, assetTypeFilterError(v)
+		}
+		if !slices.Contains(params.AssetTypes, assetType) {
+			params.AssetTypes = append(params.AssetTypes, assetType)
+		}
+	}
+
+	for _, bound := range []struct {
+		name  string
+		value *time.Time
+	}{
+		{"createdAfter", &params.CreatedAfter},
+		{"createdBefore", &params.CreatedBefore},
+		{"updatedAfter", &params.UpdatedAfter},
+		{"updatedBefore", &params.UpdatedBefore},
+	} {
+		if v := r.URL.Query().Get(bound.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidDateRange, bound.name, err)
+			}
+			*bound.value = t
+		}
+	}
+
+	if isEmptyRange(params.CreatedAfter, params.CreatedBefore) || isEmptyRange(params.UpdatedAfter, params.UpdatedBefore) {
+		return nil, fmt.Errorf("%w: ranges must end after they start", ErrInvalidDateRange)
+	}
+
+	// Search pages are keyed by rank too, and frecency pages by frecency,
+	// so a page token is only good for the kind of listing it came from.
+	if params.Cursor != nil && (params.Cursor.SearchRank != nil) != (params.Query != "") {
+		return nil, fmt.Errorf("%w: page token doesn't match the search query", ErrInvalidPageToken)
+	}
+	if params.Cursor != nil && (params.Cursor.Frecency != nil) != (params.Sort == favorites.SortFrecency) {
+		return nil, fmt.Errorf("%w: page token doesn't match the sort", ErrInvalidPageToken)
+	}
+	return &params, nil
+}
+
+// assetTypeFilterError reports an asset type filter that isn't a registered asset type,
+// listing the ones that are, which the mapped error can't know of as asset types register themselves.
+func assetTypeFilterError(filter string) error {
+	kinds := assets.Kinds()
+	types := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		types = append(types, string(kind.Type()))
+	}
+
+	restErr := resterr.RESTErr{
+		StatusCode: http.StatusBadRequest,
+		Message:    fmt.Sprintf("Invalid asset type filter '%s', expected one of %s", filter, strings.Join(types, ", ")),
+	}
+	return fmt.Errorf("%w: '%s', %w", ErrInvalidAssetTypeFilter, filter, restErr)
+}
+
+// isEmptyRange reports whether a range bounded on both sides doesn't end after it starts.
+func isEmptyRange(from, to time.Time) bool {
+	return !from.IsZero() && !to.IsZero() && !to.After(from)
+}
+
+func (h *Handler) toFavoriteJobResponse(job *favorites.FavoriteJob) FavoriteJobResponse {
+	resp := FavoriteJobResponse{
+		ID:        job.ID,
+		UserID:    job.UserID,
+		AssetID:   job.AssetID,
+		Status:    string(job.Status),
+		Attempts:  job.Attempts,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}
+	if job.Status == favorites.JobStatusFailed && job.Err != nil {
+		restErr := h.errLookup(job.Err)
+		resp.Error = &restErr
+	}
+	return resp
+}
+
 func toFavoritesResponse(favorites ...favorites.FavoriteAsset) []FavoriteAssetResponse {
 	var items []FavoriteAssetResponse
 	for _, favorite := range favorites {
-		items = append(items, FavoriteAssetResponse{
+		item := FavoriteAssetResponse{
 			ID:          favorite.ID,
+			AssetID:     favorite.AssetID,
+			AssetType:   string(favorite.AssetType),
 			Description: favorite.Description,
-		})
+			Tags:        favorite.Tags,
+			CreatedAt:   favorite.CreatedAt,
+			UpdatedAt:   favorite.UpdatedAt,
+		}
+		if favorite.Asset != nil {
+			item.Asset = toTransportAsset(favorite.Asset)
+			item.ChangedSinceFavorited = &favorite.ChangedSinceFavorited
+		}
+		items = append(items, item)
 	}
 	return items
 }
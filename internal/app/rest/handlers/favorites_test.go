@@ -0,0 +1,696 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alesr/platform-go-challenge/internal/assets"
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
+	"github.com/alesr/platform-go-challenge/internal/pkg/httputil"
+	"github.com/alesr/resterr"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToFavoritesResponse(t *testing.T) {
+	t.Parallel()
+
+	givenInsight, err := assets.NewAssetFactory().CreateInsight("Foo insight")
+	require.NoError(t, err)
+	now := time.Now()
+	changed := true
+
+	testCases := []struct {
+		name   string
+		given  favorites.FavoriteAsset
+		expect FavoriteAssetResponse
+	}{
+		{
+			name: "favorite with asset",
+			given: favorites.FavoriteAsset{
+				ID:          "fav-1",
+				AssetID:     givenInsight.ID,
+				AssetType:   assets.TypeAssetInsight,
+				Description: "Foo",
+				Tags:        []string{"sales"},
+				CreatedAt:   now,
+				UpdatedAt:   now,
+				Asset:       givenInsight,
+
+				ChangedSinceFavorited: true,
+			},
+			expect: FavoriteAssetResponse{
+				ID:          "fav-1",
+				AssetID:     givenInsight.ID,
+				AssetType:   string(assets.TypeAssetInsight),
+				Description: "Foo",
+				Tags:        []string{"sales"},
+				CreatedAt:   now,
+				UpdatedAt:   now,
+				Asset: assetResponse{
+					ID:        givenInsight.ID,
+					Type:      string(assets.TypeAssetInsight),
+					CreatedAt: givenInsight.CreatedAt,
+					UpdatedAt: givenInsight.UpdatedAt,
+					Data:      givenInsight.Data,
+				},
+				ChangedSinceFavorited: &changed,
+			},
+		},
+		{
+			name: "favorite without asset",
+			given: favorites.FavoriteAsset{
+				ID:          "fav-2",
+				AssetID:     "asset-2",
+				AssetType:   assets.TypeAssetChart,
+				Description: "Bar",
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			},
+			expect: FavoriteAssetResponse{
+				ID:          "fav-2",
+				AssetID:     "asset-2",
+				AssetType:   string(assets.TypeAssetChart),
+				Description: "Bar",
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := toFavoritesResponse(tc.given)
+
+			assert.Len(t, got, 1)
+			assert.Equal(t, tc.expect, got[0])
+		})
+	}
+}
+
+func TestParseListFavoritesParams(t *testing.T) {
+	t.Parallel()
+
+	givenCursor := favorites.Cursor{Position: "0000001", ID: "fav-1"}
+	searchRank := float32(0.5)
+	givenSearchCursor := favorites.Cursor{Position: "0000001", ID: "fav-1", SearchRank: &searchRank}
+	frecency := 1008.5
+	givenFrecencyCursor := favorites.Cursor{Position: "0000001", ID: "fav-1", Frecency: &frecency}
+	collectionID := ulid.Make().String()
+
+	testCases := []struct {
+		name             string
+		givenURL         string
+		expectParams     *favorites.ListFavoritesParams
+		expectErr        error
+		expectErrMessage string
+	}{
+		{
+			name:         "defaults",
+			givenURL:     "/",
+			expectParams: &favorites.ListFavoritesParams{PageSize: defaultFavoritesPageSize},
+		},
+		{
+			name:         "custom page size",
+			givenURL:     "/?pageSize=5",
+			expectParams: &favorites.ListFavoritesParams{PageSize: 5},
+		},
+		{
+			name:         "page size above maximum",
+			givenURL:     "/?pageSize=5000",
+			expectParams: &favorites.ListFavoritesParams{PageSize: maxFavoritesPageSize},
+		},
+		{
+			name:         "page size below minimum",
+			givenURL:     "/?pageSize=-1",
+			expectParams: &favorites.ListFavoritesParams{PageSize: defaultFavoritesPageSize},
+		},
+		{
+			name:     "page token",
+			givenURL: "/?pageToken=" + givenCursor.Encode(),
+			expectParams: &favorites.ListFavoritesParams{
+				PageSize: defaultFavoritesPageSize,
+				Cursor:   &favorites.Cursor{Position: givenCursor.Position, ID: givenCursor.ID},
+			},
+		},
+		{
+			name:     "wait for writes",
+			givenURL: "/?waitForWrites=true",
+			expectParams: &favorites.ListFavoritesParams{
+				PageSize:             defaultFavoritesPageSize,
+				WaitForPendingWrites: true,
+			},
+		},
+		{
+			name:     "collection",
+			givenURL: "/?collection_id=" + collectionID,
+			expectParams: &favorites.ListFavoritesParams{
+				PageSize:     defaultFavoritesPageSize,
+				CollectionID: collectionID,
+			},
+		},
+		{
+			name:     "search",
+			givenURL: "/?q=%20quarterly+sales%20",
+			expectParams: &favorites.ListFavoritesParams{
+				PageSize: defaultFavoritesPageSize,
+				Query:    "quarterly sales",
+			},
+		},
+		{
+			name:     "search page token",
+			givenURL: "/?q=sales&pageToken=" + givenSearchCursor.Encode(),
+			expectParams: &favorites.ListFavoritesParams{
+				PageSize: defaultFavoritesPageSize,
+				Cursor:   &givenSearchCursor,
+				Query:    "sales",
+			},
+		},
+		{
+			name:      "search query too long",
+			givenURL:  "/?q=" + strings.Repeat("a", MaxSearchQueryLength+1),
+			expectErr: ErrInvalidSearchQuery,
+		},
+		{
+			name:      "search page token without search",
+			givenURL:  "/?pageToken=" + givenSearchCursor.Encode(),
+			expectErr: ErrInvalidPageToken,
+		},
+		{
+			name:      "page token with search",
+			givenURL:  "/?q=sales&pageToken=" + givenCursor.Encode(),
+			expectErr: ErrInvalidPageToken,
+		},
+		{
+			name:     "sort by frecency",
+			givenURL: "/?sort=Frecency",
+			expectParams: &favorites.ListFavoritesParams{
+				PageSize: defaultFavoritesPageSize,
+				Sort:     favorites.SortFrecency,
+			},
+		},
+		{
+			name:     "sort by position",
+			givenURL: "/?sort=position",
+			expectParams: &favorites.ListFavoritesParams{
+				PageSize: defaultFavoritesPageSize,
+				Sort:     favorites.SortPosition,
+			},
+		},
+		{
+			name:     "frecency page token",
+			givenURL: "/?sort=frecency&pageToken=" + givenFrecencyCursor.Encode(),
+			expectParams: &favorites.ListFavoritesParams{
+				PageSize: defaultFavoritesPageSize,
+				Sort:     favorites.SortFrecency,
+				Cursor:   &givenFrecencyCursor,
+			},
+		},
+		{
+			name:      "invalid sort",
+			givenURL:  "/?sort=popularity",
+			expectErr: ErrInvalidSort,
+		},
+		{
+			name:      "frecency page token without frecency sort",
+			givenURL:  "/?pageToken=" + givenFrecencyCursor.Encode(),
+			expectErr: ErrInvalidPageToken,
+		},
+		{
+			name:      "page token with frecency sort",
+			givenURL:  "/?sort=frecency&pageToken=" + givenCursor.Encode(),
+			expectErr: ErrInvalidPageToken,
+		},
+		{
+			name:     "asset types",
+			givenURL: "/?asset_type=CHART&asset_type=audience&asset_type=Chart",
+			expectParams: &favorites.ListFavoritesParams{
+				PageSize:   defaultFavoritesPageSize,
+				AssetTypes: []assets.AssetType{assets.TypeAssetChart, assets.TypeAssetAudience},
+			},
+		},
+		{
+			name:     "date ranges",
+			givenURL: "/?createdAfter=2025-01-01T00:00:00Z&createdBefore=2025-02-01T00:00:00Z&updatedAfter=2025-01-15T00:00:00%2B01:00",
+			expectParams: &favorites.ListFavoritesParams{
+				PageSize:      defaultFavoritesPageSize,
+				CreatedAfter:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				CreatedBefore: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+				UpdatedAfter:  time.Date(2025, 1, 15, 0, 0, 0, 0, time.FixedZone("", 3600)),
+			},
+		},
+		{
+			name:             "invalid asset type",
+			givenURL:         "/?asset_type=VIDEO",
+			expectErr:        ErrInvalidAssetTypeFilter,
+			expectErrMessage: "Invalid asset type filter 'VIDEO', expected one of AUDIENCE, CHART, INSIGHT",
+		},
+		{
+			name:      "invalid date",
+			givenURL:  "/?createdAfter=yesterday",
+			expectErr: ErrInvalidDateRange,
+		},
+		{
+			name:      "range ending before it starts",
+			givenURL:  "/?updatedAfter=2025-02-01T00:00:00Z&updatedBefore=2025-01-01T00:00:00Z",
+			expectErr: ErrInvalidDateRange,
+		},
+		{
+			name:      "empty range",
+			givenURL:  "/?createdAfter=2025-01-01T00:00:00Z&createdBefore=2025-01-01T00:00:00Z",
+			expectErr: ErrInvalidDateRange,
+		},
+		{
+			name:      "invalid collection id",
+			givenURL:  "/?collection_id=foo",
+			expectErr: ErrInvalidCollectionID,
+		},
+		{
+			name:      "invalid page size",
+			givenURL:  "/?pageSize=foo",
+			expectErr: ErrInvalidPageSize,
+		},
+		{
+			name:      "invalid wait for writes",
+			givenURL:  "/?waitForWrites=foo",
+			expectErr: ErrInvalidWaitForWrites,
+		},
+		{
+			name:      "invalid page token",
+			givenURL:  "/?pageToken=foo",
+			expectErr: ErrInvalidPageToken,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := Handler{}
+
+			got, err := handler.parseListFavoritesParams(httptest.NewRequest(http.MethodGet, tc.givenURL, nil))
+
+			if tc.expectErr != nil {
+				assert.ErrorIs(t, err, tc.expectErr)
+
+				if tc.expectErrMessage != "" {
+					var restErr resterr.RESTErr
+					require.ErrorAs(t, err, &restErr)
+					assert.Equal(t, tc.expectErrMessage, restErr.Message)
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectParams, got)
+		})
+	}
+}
+
+func TestUpdateFavoriteRequest_Validate(t *testing.T) {
+	t.Parallel()
+
+	tooManyTags := make([]string, MaxFavoriteTags+1)
+	for i := range tooManyTags {
+		tooManyTags[i] = fmt.Sprintf("tag-%d", i)
+	}
+
+	testCases := []struct {
+		name       string
+		given      UpdateFavoriteRequest
+		expectTags []string
+		expectErr  error
+	}{
+		{
+			name:  "tags left as they are",
+			given: UpdateFavoriteRequest{Description: "Foo"},
+		},
+		{
+			name:       "tags cleared",
+			given:      UpdateFavoriteRequest{Tags: []string{}},
+			expectTags: []string{},
+		},
+		{
+			name:       "tags normalized",
+			given:      UpdateFavoriteRequest{Tags: []string{" Sales ", "q1", "SALES", "Q1"}},
+			expectTags: []string{"sales", "q1"},
+		},
+		{
+			name:      "blank tag",
+			given:     UpdateFavoriteRequest{Tags: []string{"sales", " "}},
+			expectErr: ErrInvalidTags,
+		},
+		{
+			name:      "tag too long",
+			given:     UpdateFavoriteRequest{Tags: []string{strings.Repeat("a", MaxTagLength+1)}},
+			expectErr: ErrInvalidTags,
+		},
+		{
+			name:      "too many tags",
+			given:     UpdateFavoriteRequest{Tags: tooManyTags},
+			expectErr: ErrInvalidTags,
+		},
+		{
+			name:      "description too long",
+			given:     UpdateFavoriteRequest{Description: strings.Repeat("a", MaxDescriptionLength+1)},
+			expectErr: ErrDescriptionMaxLen,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.given.validate()
+
+			if tc.expectErr != nil {
+				assert.ErrorIs(t, err, tc.expectErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectTags, tc.given.Tags)
+		})
+	}
+}
+
+func TestFavoriteAsset(t *testing.T) {
+	t.Parallel()
+
+	givenJob := favorites.FavoriteJob{
+		ID:      ulid.Make().String(),
+		UserID:  ulid.Make().String(),
+		AssetID: ulid.Make().String(),
+		Status:  favorites.JobStatusPending,
+	}
+
+	favoritesSvc := &favoritesSvcMock{
+		favoriteAssetFunc: func(ctx context.Context, params *favorites.FavoriteAssetParams) (*favorites.FavoriteJob, error) {
+			assert.Equal(t, givenJob.UserID, params.UserID)
+			assert.Equal(t, givenJob.AssetID, params.AssetID)
+			return &givenJob, nil
+		},
+	}
+
+	handler := Handler{favoritesSvc: favoritesSvc}
+
+	body := fmt.Sprintf(`{"user_id":%q,"asset_id":%q}`, givenJob.UserID, givenJob.AssetID)
+	req := httptest.NewRequest(http.MethodPost, "/assets/favorite", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler.FavoriteAsset().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "/favorite-jobs/"+givenJob.ID, rec.Header().Get("Location"))
+
+	var resp httputil.Response[FavoriteJobResponse]
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+
+	assert.Equal(t, givenJob.ID, resp.Data.ID)
+	assert.Equal(t, string(favorites.JobStatusPending), resp.Data.Status)
+	assert.Nil(t, resp.Data.Error)
+}
+
+func TestFavoriteAsset_QueueFull(t *testing.T) {
+	t.Parallel()
+
+	var handledErr error
+
+	handler := Handler{
+		errHandler: &errorHandlerMock{
+			handleFunc: func(ctx context.Context, w resterr.Writer, err error) {
+				handledErr = err
+			},
+		},
+		favoritesSvc: &favoritesSvcMock{
+			favoriteAssetFunc: func(ctx context.Context, params *favorites.FavoriteAssetParams) (*favorites.FavoriteJob, error) {
+				return nil, favorites.ErrQueueFull
+			},
+		},
+	}
+
+	body := fmt.Sprintf(`{"user_id":%q,"asset_id":%q}`, ulid.Make().String(), ulid.Make().String())
+	req := httptest.NewRequest(http.MethodPost, "/assets/favorite", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler.FavoriteAsset().ServeHTTP(rec, req)
+
+	assert.ErrorIs(t, handledErr, favorites.ErrQueueFull)
+	assert.Equal(t, "5", rec.Header().Get("Retry-After"))
+}
+
+func TestGetFavoriteJob(t *testing.T) {
+	t.Parallel()
+
+	jobID := ulid.Make().String()
+	givenRESTErr := resterr.RESTErr{StatusCode: http.StatusNotFound, Message: "Asset resource was not found"}
+
+	testCases := []struct {
+		name        string
+		givenJob    favorites.FavoriteJob
+		expectError *resterr.RESTErr
+	}{
+		{
+			name:     "pending job",
+			givenJob: favorites.FavoriteJob{ID: jobID, Status: favorites.JobStatusPending},
+		},
+		{
+			name:     "succeeded job",
+			givenJob: favorites.FavoriteJob{ID: jobID, Status: favorites.JobStatusSucceeded},
+		},
+		{
+			name:        "failed job",
+			givenJob:    favorites.FavoriteJob{ID: jobID, Status: favorites.JobStatusFailed, Err: assets.ErrAssetNotFound},
+			expectError: &givenRESTErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			favoritesSvc := &favoritesSvcMock{
+				fetchFavoriteJobFunc: func(ctx context.Context, id string) (*favorites.FavoriteJob, error) {
+					assert.Equal(t, jobID, id)
+					return &tc.givenJob, nil
+				},
+			}
+
+			handler := Handler{
+				favoritesSvc: favoritesSvc,
+				errLookup: func(err error) resterr.RESTErr {
+					assert.ErrorIs(t, err, assets.ErrAssetNotFound)
+					return givenRESTErr
+				},
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/favorite-jobs/"+jobID, nil)
+			req.SetPathValue("job_id", jobID)
+			rec := httptest.NewRecorder()
+
+			handler.GetFavoriteJob().ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var resp httputil.Response[FavoriteJobResponse]
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+
+			assert.Equal(t, string(tc.givenJob.Status), resp.Data.Status)
+			assert.Equal(t, tc.expectError, resp.Data.Error)
+		})
+	}
+}
+
+func TestDeleteFavorites(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+	assetID := ulid.Make().String()
+
+	testCases := []struct {
+		name           string
+		givenUserID    string
+		givenQuery     string
+		expectCleared  bool
+		expectDeleted  bool
+		expectedStatus int
+		expectedError  error
+	}{
+		{
+			name:           "delete by asset",
+			givenUserID:    userID,
+			givenQuery:     "?asset_id=" + assetID,
+			expectDeleted:  true,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "clear all",
+			givenUserID:    userID,
+			expectCleared:  true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:          "invalid user id",
+			givenUserID:   "foo",
+			givenQuery:    "?asset_id=" + assetID,
+			expectedError: ErrInvalidUserID,
+		},
+		{
+			name:          "invalid asset id",
+			givenUserID:   userID,
+			givenQuery:    "?asset_id=foo",
+			expectedError: ErrInvalidAssetID,
+		},
+		{
+			// an empty asset ID must not be mistaken for clearing all favorites
+			name:          "empty asset id",
+			givenUserID:   userID,
+			givenQuery:    "?asset_id=",
+			expectedError: ErrInvalidAssetID,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				handledErr error
+				cleared    bool
+				deleted    bool
+			)
+
+			handler := Handler{
+				errHandler: &errorHandlerMock{
+					handleFunc: func(ctx context.Context, w resterr.Writer, err error) {
+						handledErr = err
+					},
+				},
+				favoritesSvc: &favoritesSvcMock{
+					deleteFavoriteByAssetFunc: func(ctx context.Context, id, uID string) error {
+						deleted = true
+						assert.Equal(t, assetID, id)
+						assert.Equal(t, userID, uID)
+						return nil
+					},
+					clearFavoritesFunc: func(ctx context.Context, uID string) (int64, error) {
+						cleared = true
+						assert.Equal(t, userID, uID)
+						return 3, nil
+					},
+				},
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/users/"+tc.givenUserID+"/favorites"+tc.givenQuery, nil)
+			req.SetPathValue("user_id", tc.givenUserID)
+			rec := httptest.NewRecorder()
+
+			handler.DeleteFavorites().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectCleared, cleared)
+			assert.Equal(t, tc.expectDeleted, deleted)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, handledErr, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, handledErr)
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+
+			if tc.expectCleared {
+				var resp httputil.Response[ClearFavoritesResponse]
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+				assert.Equal(t, int64(3), resp.Data.Deleted)
+			}
+		})
+	}
+}
+
+func TestOpenFavorite(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+	favoriteID := ulid.Make().String()
+
+	testCases := []struct {
+		name          string
+		givenUserID   string
+		givenFavorite string
+		givenSvcErr   error
+		expectedError error
+	}{
+		{
+			name:          "success",
+			givenUserID:   userID,
+			givenFavorite: favoriteID,
+		},
+		{
+			name:          "invalid user id",
+			givenUserID:   "foo",
+			givenFavorite: favoriteID,
+			expectedError: ErrInvalidUserID,
+		},
+		{
+			name:          "invalid favorite id",
+			givenUserID:   userID,
+			givenFavorite: "foo",
+			expectedError: ErrInvalidFavoriteID,
+		},
+		{
+			name:          "favorite not found",
+			givenUserID:   userID,
+			givenFavorite: favoriteID,
+			givenSvcErr:   favorites.ErrFavoriteAssetNotFound,
+			expectedError: favorites.ErrFavoriteAssetNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var handledErr error
+
+			handler := Handler{
+				errHandler: &errorHandlerMock{
+					handleFunc: func(ctx context.Context, w resterr.Writer, err error) {
+						handledErr = err
+					},
+				},
+				favoritesSvc: &favoritesSvcMock{
+					recordFavoriteOpenFunc: func(ctx context.Context, favID, uID string) error {
+						assert.Equal(t, favoriteID, favID)
+						assert.Equal(t, userID, uID)
+						return tc.givenSvcErr
+					},
+				},
+			}
+
+			// the action is stripped from the favorite ID when routing, see rest.App
+			req := httptest.NewRequest(http.MethodPost, "/users/"+tc.givenUserID+"/favorites/"+tc.givenFavorite+":open", nil)
+			req.SetPathValue("user_id", tc.givenUserID)
+			req.SetPathValue("favorite_id", tc.givenFavorite)
+			rec := httptest.NewRecorder()
+
+			handler.OpenFavorite().ServeHTTP(rec, req)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, handledErr, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, handledErr)
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+		})
+	}
+}
@@ -17,15 +17,37 @@ var (
 	// Enumerate all possible errors returned by the handlers.
 	// If this grows too large, consider moving it to errors.go
 
-	ErrDescriptionMaxLen           = errors.New("description is too long")
-	ErrFavoriteIDRequired          = errors.New("favorite id is required")
-	ErrInvalidFavoriteAssetPayload = errors.New("invalid favorite asset request payload")
-	ErrInvalidFavoriteID           = errors.New("invalid favorite id")
-	ErrInvalidPageMaxResults       = errors.New("invalid page max results")
-	ErrInvalidPageSize             = errors.New("invalid page size")
-	ErrInvalidPageToken            = errors.New("invalid page token")
-	ErrInvalidUserID               = errors.New("invalid user id")
-	ErrUserIDRequired              = errors.New("user id is required")
+	ErrDescriptionMaxLen             = errors.New("description is too long")
+	ErrFavoriteIDRequired            = errors.New("favorite id is required")
+	ErrInvalidAssetID                = errors.New("invalid asset id")
+	ErrInvalidAssetPayload           = errors.New("invalid asset request payload")
+	ErrInvalidAssetTypeFilter        = errors.New("invalid asset type filter")
+	ErrInvalidAtomicFlag             = errors.New("invalid atomic flag")
+	ErrInvalidBatchOperation         = errors.New("invalid batch operation")
+	ErrInvalidBatchPayload           = errors.New("invalid batch request payload")
+	ErrInvalidBatchSize              = errors.New("invalid batch size")
+	ErrInvalidCollectionID           = errors.New("invalid collection id")
+	ErrInvalidCollectionName         = errors.New("invalid collection name")
+	ErrInvalidCollectionPayload      = errors.New("invalid collection request payload")
+	ErrInvalidDateRange              = errors.New("invalid date range")
+	ErrInvalidDeadLetterID           = errors.New("invalid dead letter id")
+	ErrInvalidFavoriteAssetPayload   = errors.New("invalid favorite asset request payload")
+	ErrInvalidFavoriteID             = errors.New("invalid favorite id")
+	ErrInvalidFavoritesOrder         = errors.New("invalid favorites order")
+	ErrInvalidJobID                  = errors.New("invalid job id")
+	ErrInvalidMovePayload            = errors.New("invalid move request payload")
+	ErrInvalidPageMaxResults         = errors.New("invalid page max results")
+	ErrInvalidPageSize               = errors.New("invalid page size")
+	ErrInvalidPageToken              = errors.New("invalid page token")
+	ErrInvalidSearchQuery            = errors.New("invalid search query")
+	ErrInvalidSort                   = errors.New("invalid sort")
+	ErrInvalidSmartCollectionID      = errors.New("invalid smart collection id")
+	ErrInvalidSmartCollectionPayload = errors.New("invalid smart collection request payload")
+	ErrInvalidTags                   = errors.New("invalid favorite tags")
+	ErrInvalidUserID                 = errors.New("invalid user id")
+	ErrInvalidWaitForWrites          = errors.New("invalid wait for writes value")
+	ErrUnsupportedAssetType          = errors.New("unsupported asset type")
+	ErrUserIDRequired                = errors.New("user id is required")
 )
 
 type usersService interface {
@@ -33,23 +55,58 @@ type usersService interface {
 }
 
 type assetsService interface {
-	ListAssets(ctx context.Context, params *assets.ListAssetsParams) ([]assets.Asseter, string, error)
+	ListAssets(ctx context.Context, params *assets.ListAssetsParams) ([]assets.ListedAsset, string, error)
+	CreateAsset(ctx context.Context, asset assets.Asseter) error
+	FetchAsset(ctx context.Context, id string) (assets.Asseter, error)
+	UpdateAsset(ctx context.Context, id string, asset assets.Asseter) (assets.Asseter, error)
+	DeleteAsset(ctx context.Context, id string) error
+	AudienceSize(ctx context.Context, id string) (*assets.AudienceSize, error)
 }
 
 type favoritesService interface {
-	FavoriteAsset(ctx context.Context, params *favorites.FavoriteAssetParams) error
-	FetchUserFavorites(ctx context.Context, userID string) ([]favorites.FavoriteAsset, error)
+	FavoriteAsset(ctx context.Context, params *favorites.FavoriteAssetParams) (*favorites.FavoriteJob, error)
+	FetchFavoriteJob(ctx context.Context, jobID string) (*favorites.FavoriteJob, error)
+	FetchUserFavorites(ctx context.Context, userID string, params *favorites.ListFavoritesParams) ([]favorites.FavoriteAsset, string, error)
 	UpdateFavorite(ctx context.Context, userID, favoriteID string, params *favorites.UpdateFavoriteParams) (*favorites.FavoriteAsset, error)
+	FetchFavoriteDiff(ctx context.Context, favoriteID, userID string) (*favorites.FavoriteDiff, error)
+	RecordFavoriteOpen(ctx context.Context, favoriteID, userID string) error
 	DeleteFavorite(ctx context.Context, favoriteID, userID string) error
+	DeleteFavoriteByAsset(ctx context.Context, assetID, userID string) error
+	ClearFavorites(ctx context.Context, userID string) (int64, error)
+	FetchTrash(ctx context.Context, userID string, params *favorites.ListTrashParams) ([]favorites.TrashedFavorite, string, error)
+	RestoreFavorite(ctx context.Context, favoriteID, userID string) (*favorites.FavoriteAsset, error)
+	ReorderFavorites(ctx context.Context, userID string, favoriteIDs []string) error
+	MoveFavorite(ctx context.Context, favoriteID, userID string, params *favorites.MoveFavoriteParams) error
+	CreateCollection(ctx context.Context, userID string, params *favorites.CollectionParams) (*favorites.Collection, error)
+	ListCollections(ctx context.Context, userID string) ([]favorites.Collection, error)
+	FetchCollection(ctx context.Context, collectionID, userID string) (*favorites.Collection, error)
+	UpdateCollection(ctx context.Context, collectionID, userID string, params *favorites.CollectionParams) (*favorites.Collection, error)
+	DeleteCollection(ctx context.Context, collectionID, userID string) error
+	AddToCollection(ctx context.Context, collectionID, favoriteID, userID string) error
+	RemoveFromCollection(ctx context.Context, collectionID, favoriteID, userID string) error
+	CreateSmartCollection(ctx context.Context, userID string, params *favorites.SmartCollectionParams) (*favorites.SmartCollection, error)
+	ListSmartCollections(ctx context.Context, userID string) ([]favorites.SmartCollection, error)
+	FetchSmartCollection(ctx context.Context, collectionID, userID string) (*favorites.SmartCollection, error)
+	DeleteSmartCollection(ctx context.Context, collectionID, userID string) error
+	FetchSmartCollectionItems(ctx context.Context, collectionID, userID string, params *favorites.ListFavoritesParams) ([]favorites.FavoriteAsset, string, error)
+	ApplyFavoriteOps(ctx context.Context, userID string, ops []favorites.FavoriteOp, atomic bool) ([]error, error)
+	ListDeadLetters(ctx context.Context, params *favorites.ListDeadLettersParams) ([]favorites.DeadLetter, string, error)
+	ReplayDeadLetter(ctx context.Context, id string) (*favorites.FavoriteJob, error)
+	QueueStats(ctx context.Context) (*favorites.QueueStats, error)
 }
 
 type errorHandler interface {
 	Handle(ctx context.Context, w resterr.Writer, err error)
 }
 
+// errorLookupFunc resolves the REST error mapped to an error
+// without writing it as the response.
+type errorLookupFunc func(err error) resterr.RESTErr
+
 type Handler struct {
 	logger       *slog.Logger
 	errHandler   errorHandler
+	errLookup    errorLookupFunc
 	usersSvc     usersService
 	assetsSvc    assetsService
 	favoritesSvc favoritesService
@@ -58,6 +115,7 @@ type Handler struct {
 func New(
 	logger *slog.Logger,
 	errHandler errorHandler,
+	errLookup errorLookupFunc,
 	usersSvc usersService,
 	assetsSvc assetsService,
 	favoritesSvc favoritesService,
@@ -65,6 +123,7 @@ func New(
 	return &Handler{
 		logger:       logger.WithGroup("rest-handlers"),
 		errHandler:   errHandler,
+		errLookup:    errLookup,
 		usersSvc:     usersSvc,
 		assetsSvc:    assetsSvc,
 		favoritesSvc: favoritesSvc,
@@ -26,40 +26,190 @@ func (m *usersSvcMock) ListUsers(ctx context.Context) []users.User { return m.li
 var _ assetsService = &assetsSvcMock{}
 
 type assetsSvcMock struct {
-	listAssetsFunc func(ctx context.Context, params *assets.ListAssetsParams) ([]assets.Asseter, string, error)
+	listAssetsFunc   func(ctx context.Context, params *assets.ListAssetsParams) ([]assets.ListedAsset, string, error)
+	createAssetFunc  func(ctx context.Context, asset assets.Asseter) error
+	fetchAssetFunc   func(ctx context.Context, id string) (assets.Asseter, error)
+	updateAssetFunc  func(ctx context.Context, id string, asset assets.Asseter) (assets.Asseter, error)
+	deleteAssetFunc  func(ctx context.Context, id string) error
+	audienceSizeFunc func(ctx context.Context, id string) (*assets.AudienceSize, error)
 }
 
-func (m *assetsSvcMock) ListAssets(ctx context.Context, params *assets.ListAssetsParams) ([]assets.Asseter, string, error) {
+func (m *assetsSvcMock) ListAssets(ctx context.Context, params *assets.ListAssetsParams) ([]assets.ListedAsset, string, error) {
 	return m.listAssetsFunc(ctx, params)
 }
 
+func (m *assetsSvcMock) CreateAsset(ctx context.Context, asset assets.Asseter) error {
+	return m.createAssetFunc(ctx, asset)
+}
+
+func (m *assetsSvcMock) FetchAsset(ctx context.Context, id string) (assets.Asseter, error) {
+	return m.fetchAssetFunc(ctx, id)
+}
+
+func (m *assetsSvcMock) UpdateAsset(ctx context.Context, id string, asset assets.Asseter) (assets.Asseter, error) {
+	return m.updateAssetFunc(ctx, id, asset)
+}
+
+func (m *assetsSvcMock) DeleteAsset(ctx context.Context, id string) error {
+	return m.deleteAssetFunc(ctx, id)
+}
+
+func (m *assetsSvcMock) AudienceSize(ctx context.Context, id string) (*assets.AudienceSize, error) {
+	return m.audienceSizeFunc(ctx, id)
+}
+
 // Favorites service
 
 var _ favoritesService = &favoritesSvcMock{}
 
 type favoritesSvcMock struct {
-	favoriteAssetFunc      func(ctx context.Context, params *favorites.FavoriteAssetParams) error
-	fetchUserFavoritesFunc func(ctx context.Context, userID string) ([]favorites.FavoriteAsset, error)
-	updateFavoriteFunc     func(ctx context.Context, userID, assetID string, params *favorites.UpdateFavoriteParams) (*favorites.FavoriteAsset, error)
-	deleteFavoriteFunc     func(ctx context.Context, favoriteID, userID string) error
+	favoriteAssetFunc             func(ctx context.Context, params *favorites.FavoriteAssetParams) (*favorites.FavoriteJob, error)
+	fetchFavoriteJobFunc          func(ctx context.Context, jobID string) (*favorites.FavoriteJob, error)
+	fetchUserFavoritesFunc        func(ctx context.Context, userID string, params *favorites.ListFavoritesParams) ([]favorites.FavoriteAsset, string, error)
+	updateFavoriteFunc            func(ctx context.Context, userID, assetID string, params *favorites.UpdateFavoriteParams) (*favorites.FavoriteAsset, error)
+	fetchFavoriteDiffFunc         func(ctx context.Context, favoriteID, userID string) (*favorites.FavoriteDiff, error)
+	deleteFavoriteFunc            func(ctx context.Context, favoriteID, userID string) error
+	deleteFavoriteByAssetFunc     func(ctx context.Context, assetID, userID string) error
+	clearFavoritesFunc            func(ctx context.Context, userID string) (int64, error)
+	fetchTrashFunc                func(ctx context.Context, userID string, params *favorites.ListTrashParams) ([]favorites.TrashedFavorite, string, error)
+	restoreFavoriteFunc           func(ctx context.Context, favoriteID, userID string) (*favorites.FavoriteAsset, error)
+	recordFavoriteOpenFunc        func(ctx context.Context, favoriteID, userID string) error
+	reorderFavoritesFunc          func(ctx context.Context, userID string, favoriteIDs []string) error
+	moveFavoriteFunc              func(ctx context.Context, favoriteID, userID string, params *favorites.MoveFavoriteParams) error
+	createCollectionFunc          func(ctx context.Context, userID string, params *favorites.CollectionParams) (*favorites.Collection, error)
+	listCollectionsFunc           func(ctx context.Context, userID string) ([]favorites.Collection, error)
+	fetchCollectionFunc           func(ctx context.Context, collectionID, userID string) (*favorites.Collection, error)
+	updateCollectionFunc          func(ctx context.Context, collectionID, userID string, params *favorites.CollectionParams) (*favorites.Collection, error)
+	deleteCollectionFunc          func(ctx context.Context, collectionID, userID string) error
+	addToCollectionFunc           func(ctx context.Context, collectionID, favoriteID, userID string) error
+	removeFromCollectionFunc      func(ctx context.Context, collectionID, favoriteID, userID string) error
+	createSmartCollectionFunc     func(ctx context.Context, userID string, params *favorites.SmartCollectionParams) (*favorites.SmartCollection, error)
+	listSmartCollectionsFunc      func(ctx context.Context, userID string) ([]favorites.SmartCollection, error)
+	fetchSmartCollectionFunc      func(ctx context.Context, collectionID, userID string) (*favorites.SmartCollection, error)
+	deleteSmartCollectionFunc     func(ctx context.Context, collectionID, userID string) error
+	fetchSmartCollectionItemsFunc func(ctx context.Context, collectionID, userID string, params *favorites.ListFavoritesParams) ([]favorites.FavoriteAsset, string, error)
+	applyFavoriteOpsFunc          func(ctx context.Context, userID string, ops []favorites.FavoriteOp, atomic bool) ([]error, error)
+	listDeadLettersFunc           func(ctx context.Context, params *favorites.ListDeadLettersParams) ([]favorites.DeadLetter, string, error)
+	replayDeadLetterFunc          func(ctx context.Context, id string) (*favorites.FavoriteJob, error)
+	queueStatsFunc                func(ctx context.Context) (*favorites.QueueStats, error)
 }
 
-func (m *favoritesSvcMock) FavoriteAsset(ctx context.Context, params *favorites.FavoriteAssetParams) error {
+func (m *favoritesSvcMock) FavoriteAsset(ctx context.Context, params *favorites.FavoriteAssetParams) (*favorites.FavoriteJob, error) {
 	return m.favoriteAssetFunc(ctx, params)
 }
 
-func (m *favoritesSvcMock) FetchUserFavorites(ctx context.Context, userID string) ([]favorites.FavoriteAsset, error) {
-	return m.fetchUserFavoritesFunc(ctx, userID)
+func (m *favoritesSvcMock) ApplyFavoriteOps(ctx context.Context, userID string, ops []favorites.FavoriteOp, atomic bool) ([]error, error) {
+	return m.applyFavoriteOpsFunc(ctx, userID, ops, atomic)
+}
+
+func (m *favoritesSvcMock) FetchFavoriteJob(ctx context.Context, jobID string) (*favorites.FavoriteJob, error) {
+	return m.fetchFavoriteJobFunc(ctx, jobID)
+}
+
+func (m *favoritesSvcMock) FetchUserFavorites(ctx context.Context, userID string, params *favorites.ListFavoritesParams) ([]favorites.FavoriteAsset, string, error) {
+	return m.fetchUserFavoritesFunc(ctx, userID, params)
 }
 
 func (m *favoritesSvcMock) UpdateFavorite(ctx context.Context, userID, assetID string, params *favorites.UpdateFavoriteParams) (*favorites.FavoriteAsset, error) {
 	return m.updateFavoriteFunc(ctx, userID, assetID, params)
 }
 
+func (m *favoritesSvcMock) FetchFavoriteDiff(ctx context.Context, favoriteID, userID string) (*favorites.FavoriteDiff, error) {
+	return m.fetchFavoriteDiffFunc(ctx, favoriteID, userID)
+}
+
 func (m *favoritesSvcMock) DeleteFavorite(ctx context.Context, favoriteID, userID string) error {
 	return m.deleteFavoriteFunc(ctx, favoriteID, userID)
 }
 
+func (m *favoritesSvcMock) DeleteFavoriteByAsset(ctx context.Context, assetID, userID string) error {
+	return m.deleteFavoriteByAssetFunc(ctx, assetID, userID)
+}
+
+func (m *favoritesSvcMock) ClearFavorites(ctx context.Context, userID string) (int64, error) {
+	return m.clearFavoritesFunc(ctx, userID)
+}
+
+func (m *favoritesSvcMock) FetchTrash(ctx context.Context, userID string, params *favorites.ListTrashParams) ([]favorites.TrashedFavorite, string, error) {
+	return m.fetchTrashFunc(ctx, userID, params)
+}
+
+func (m *favoritesSvcMock) RestoreFavorite(ctx context.Context, favoriteID, userID string) (*favorites.FavoriteAsset, error) {
+	return m.restoreFavoriteFunc(ctx, favoriteID, userID)
+}
+
+func (m *favoritesSvcMock) RecordFavoriteOpen(ctx context.Context, favoriteID, userID string) error {
+	return m.recordFavoriteOpenFunc(ctx, favoriteID, userID)
+}
+
+func (m *favoritesSvcMock) ReorderFavorites(ctx context.Context, userID string, favoriteIDs []string) error {
+	return m.reorderFavoritesFunc(ctx, userID, favoriteIDs)
+}
+
+func (m *favoritesSvcMock) MoveFavorite(ctx context.Context, favoriteID, userID string, params *favorites.MoveFavoriteParams) error {
+	return m.moveFavoriteFunc(ctx, favoriteID, userID, params)
+}
+
+func (m *favoritesSvcMock) CreateCollection(ctx context.Context, userID string, params *favorites.CollectionParams) (*favorites.Collection, error) {
+	return m.createCollectionFunc(ctx, userID, params)
+}
+
+func (m *favoritesSvcMock) ListCollections(ctx context.Context, userID string) ([]favorites.Collection, error) {
+	return m.listCollectionsFunc(ctx, userID)
+}
+
+func (m *favoritesSvcMock) FetchCollection(ctx context.Context, collectionID, userID string) (*favorites.Collection, error) {
+	return m.fetchCollectionFunc(ctx, collectionID, userID)
+}
+
+func (m *favoritesSvcMock) UpdateCollection(ctx context.Context, collectionID, userID string, params *favorites.CollectionParams) (*favorites.Collection, error) {
+	return m.updateCollectionFunc(ctx, collectionID, userID, params)
+}
+
+func (m *favoritesSvcMock) DeleteCollection(ctx context.Context, collectionID, userID string) error {
+	return m.deleteCollectionFunc(ctx, collectionID, userID)
+}
+
+func (m *favoritesSvcMock) AddToCollection(ctx context.Context, collectionID, favoriteID, userID string) error {
+	return m.addToCollectionFunc(ctx, collectionID, favoriteID, userID)
+}
+
+func (m *favoritesSvcMock) RemoveFromCollection(ctx context.Context, collectionID, favoriteID, userID string) error {
+	return m.removeFromCollectionFunc(ctx, collectionID, favoriteID, userID)
+}
+
+func (m *favoritesSvcMock) CreateSmartCollection(ctx context.Context, userID string, params *favorites.SmartCollectionParams) (*favorites.SmartCollection, error) {
+	return m.createSmartCollectionFunc(ctx, userID, params)
+}
+
+func (m *favoritesSvcMock) ListSmartCollections(ctx context.Context, userID string) ([]favorites.SmartCollection, error) {
+	return m.listSmartCollectionsFunc(ctx, userID)
+}
+
+func (m *favoritesSvcMock) FetchSmartCollection(ctx context.Context, collectionID, userID string) (*favorites.SmartCollection, error) {
+	return m.fetchSmartCollectionFunc(ctx, collectionID, userID)
+}
+
+func (m *favoritesSvcMock) DeleteSmartCollection(ctx context.Context, collectionID, userID string) error {
+	return m.deleteSmartCollectionFunc(ctx, collectionID, userID)
+}
+
+func (m *favoritesSvcMock) FetchSmartCollectionItems(ctx context.Context, collectionID, userID string, params *favorites.ListFavoritesParams) ([]favorites.FavoriteAsset, string, error) {
+	return m.fetchSmartCollectionItemsFunc(ctx, collectionID, userID, params)
+}
+
+func (m *favoritesSvcMock) ListDeadLetters(ctx context.Context, params *favorites.ListDeadLettersParams) ([]favorites.DeadLetter, string, error) {
+	return m.listDeadLettersFunc(ctx, params)
+}
+
+func (m *favoritesSvcMock) ReplayDeadLetter(ctx context.Context, id string) (*favorites.FavoriteJob, error) {
+	return m.replayDeadLetterFunc(ctx, id)
+}
+
+func (m *favoritesSvcMock) QueueStats(ctx context.Context) (*favorites.QueueStats, error) {
+	return m.queueStatsFunc(ctx)
+}
+
 // error handler
 
 var _ errorHandler = &errorHandlerMock{}
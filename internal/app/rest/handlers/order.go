@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
+	"github.com/alesr/platform-go-challenge/internal/pkg/httputil"
+)
+
+// MaxOrderedFavorites is the maximum number of favorites in a request to reorder them.
+const MaxOrderedFavorites = 500
+
+// ReorderFavoritesRequest defines the data structure for reordering a user's favorites.
+// The favorites go first in the user's list, in the given order, followed by the rest.
+type ReorderFavoritesRequest struct {
+	FavoriteIDs []string `json:"favorite_ids"`
+}
+
+func (o *ReorderFavoritesRequest) validate() error {
+	if len(o.FavoriteIDs) == 0 || len(o.FavoriteIDs) > MaxOrderedFavorites {
+		return fmt.Errorf("%w: expected between 1 and %d favorites", ErrInvalidFavoritesOrder, MaxOrderedFavorites)
+	}
+
+	seen := make(map[string]struct{}, len(o.FavoriteIDs))
+	for _, id := range o.FavoriteIDs {
+		if err := validateID(id); err != nil {
+			return fmt.Errorf("%w, %v", ErrInvalidFavoriteID, err)
+		}
+		if _, ok := seen[id]; ok {
+			return fmt.Errorf("%w: favorite '%s' is listed twice", ErrInvalidFavoritesOrder, id)
+		}
+		seen[id] = struct{}{}
+	}
+	return nil
+}
+
+// MoveFavoriteRequest defines the data structure for moving a favorite in the user's list.
+// The favorite is moved before the favorite BeforeID, or to the end of the list if it's empty.
+type MoveFavoriteRequest struct {
+	BeforeID string `json:"before_id"`
+}
+
+func (m *MoveFavoriteRequest) validate() error {
+	if m.BeforeID == "" {
+		return nil
+	}
+	if err := validateID(m.BeforeID); err != nil {
+		return fmt.Errorf("%w, %v", ErrInvalidFavoriteID, err)
+	}
+	return nil
+}
+
+// ReorderFavorites sets the order of a user's favorites.
+func (h *Handler) ReorderFavorites() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		userID := r.PathValue("user_id")
+		if err := validateID(userID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate user ID: %w, %v", ErrInvalidUserID, err))
+			return
+		}
+
+		var data ReorderFavoritesRequest
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not decode request data: %w, %w", err, ErrInvalidFavoritesOrder))
+			return
+		}
+
+		if err := data.validate(); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate order: %w", err))
+			return
+		}
+
+		if err := h.favoritesSvc.ReorderFavorites(r.Context(), userID, data.FavoriteIDs); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not reorder favorites: %w", err))
+			return
+		}
+		httputil.RespondWithJSON[any](w, http.StatusNoContent, nil)
+	}
+}
+
+// MoveFavorite moves a user's favorite before another one, or to the end of the list.
+func (h *Handler) MoveFavorite() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		userID := r.PathValue("user_id")
+		favoriteID := r.PathValue("favorite_id")
+
+		if err := validateID(userID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate user ID: %w, %v", ErrInvalidUserID, err))
+			return
+		}
+
+		if err := validateID(favoriteID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate favorite ID: %w, %v", ErrInvalidFavoriteID, err))
+			return
+		}
+
+		var data MoveFavoriteRequest
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not decode request data: %w, %w", err, ErrInvalidMovePayload))
+			return
+		}
+
+		if err := data.validate(); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate move: %w", err))
+			return
+		}
+
+		params := favorites.MoveFavoriteParams{BeforeID: data.BeforeID}
+		if err := h.favoritesSvc.MoveFavorite(r.Context(), favoriteID, userID, &params); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not move favorite: %w", err))
+			return
+		}
+		httputil.RespondWithJSON[any](w, http.StatusNoContent, nil)
+	}
+}
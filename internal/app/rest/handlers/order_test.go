@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
+	"github.com/alesr/resterr"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReorderFavorites(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+	firstID := ulid.Make().String()
+	secondID := ulid.Make().String()
+
+	tooMany := make([]string, MaxOrderedFavorites+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("%q", ulid.Make().String())
+	}
+
+	testCases := []struct {
+		name          string
+		givenUserID   string
+		givenBody     string
+		expectedError error
+	}{
+		{
+			name:        "success",
+			givenUserID: userID,
+			givenBody:   fmt.Sprintf(`{"favorite_ids":[%q,%q]}`, firstID, secondID),
+		},
+		{
+			name:          "invalid user id",
+			givenUserID:   "foo",
+			givenBody:     fmt.Sprintf(`{"favorite_ids":[%q,%q]}`, firstID, secondID),
+			expectedError: ErrInvalidUserID,
+		},
+		{
+			name:          "invalid payload",
+			givenUserID:   userID,
+			givenBody:     `{"favorite_ids":`,
+			expectedError: ErrInvalidFavoritesOrder,
+		},
+		{
+			name:          "no favorites",
+			givenUserID:   userID,
+			givenBody:     `{"favorite_ids":[]}`,
+			expectedError: ErrInvalidFavoritesOrder,
+		},
+		{
+			name:          "too many favorites",
+			givenUserID:   userID,
+			givenBody:     `{"favorite_ids":[` + strings.Join(tooMany, ",") + `]}`,
+			expectedError: ErrInvalidFavoritesOrder,
+		},
+		{
+			name:          "duplicate favorite",
+			givenUserID:   userID,
+			givenBody:     fmt.Sprintf(`{"favorite_ids":[%q,%q]}`, firstID, firstID),
+			expectedError: ErrInvalidFavoritesOrder,
+		},
+		{
+			name:          "invalid favorite id",
+			givenUserID:   userID,
+			givenBody:     fmt.Sprintf(`{"favorite_ids":[%q,"foo"]}`, firstID),
+			expectedError: ErrInvalidFavoriteID,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				handledErr error
+				svcCalled  bool
+			)
+
+			handler := Handler{
+				errHandler: &errorHandlerMock{
+					handleFunc: func(ctx context.Context, w resterr.Writer, err error) {
+						handledErr = err
+					},
+				},
+				favoritesSvc: &favoritesSvcMock{
+					reorderFavoritesFunc: func(ctx context.Context, id string, favoriteIDs []string) error {
+						svcCalled = true
+						assert.Equal(t, userID, id)
+						assert.Equal(t, []string{firstID, secondID}, favoriteIDs)
+						return nil
+					},
+				},
+			}
+
+			req := httptest.NewRequest(
+				http.MethodPut,
+				"/users/"+tc.givenUserID+"/favorites/order",
+				strings.NewReader(tc.givenBody),
+			)
+			req.SetPathValue("user_id", tc.givenUserID)
+			rec := httptest.NewRecorder()
+
+			handler.ReorderFavorites().ServeHTTP(rec, req)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, handledErr, tc.expectedError)
+				assert.False(t, svcCalled)
+				return
+			}
+
+			require.NoError(t, handledErr)
+			assert.True(t, svcCalled)
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+		})
+	}
+}
+
+func TestMoveFavorite(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+	favoriteID := ulid.Make().String()
+	beforeID := ulid.Make().String()
+
+	testCases := []struct {
+		name           string
+		givenFavorite  string
+		givenBody      string
+		expectBeforeID string
+		expectedError  error
+	}{
+		{
+			name:           "move before another favorite",
+			givenFavorite:  favoriteID,
+			givenBody:      fmt.Sprintf(`{"before_id":%q}`, beforeID),
+			expectBeforeID: beforeID,
+		},
+		{
+			name:          "move to the end",
+			givenFavorite: favoriteID,
+			givenBody:     `{}`,
+		},
+		{
+			name:          "invalid favorite id",
+			givenFavorite: "foo",
+			givenBody:     `{}`,
+			expectedError: ErrInvalidFavoriteID,
+		},
+		{
+			name:          "invalid before id",
+			givenFavorite: favoriteID,
+			givenBody:     `{"before_id":"foo"}`,
+			expectedError: ErrInvalidFavoriteID,
+		},
+		{
+			name:          "invalid payload",
+			givenFavorite: favoriteID,
+			givenBody:     `{"before_id":`,
+			expectedError: ErrInvalidMovePayload,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var handledErr error
+
+			handler := Handler{
+				errHandler: &errorHandlerMock{
+					handleFunc: func(ctx context.Context, w resterr.Writer, err error) {
+						handledErr = err
+					},
+				},
+				favoritesSvc: &favoritesSvcMock{
+					moveFavoriteFunc: func(ctx context.Context, favID, uID string, params *favorites.MoveFavoriteParams) error {
+						assert.Equal(t, favoriteID, favID)
+						assert.Equal(t, userID, uID)
+						assert.Equal(t, tc.expectBeforeID, params.BeforeID)
+						return nil
+					},
+				},
+			}
+
+			req := httptest.NewRequest(
+				http.MethodPost,
+				"/users/"+userID+"/favorites/"+tc.givenFavorite+"/move",
+				strings.NewReader(tc.givenBody),
+			)
+			req.SetPathValue("user_id", userID)
+			req.SetPathValue("favorite_id", tc.givenFavorite)
+			rec := httptest.NewRecorder()
+
+			handler.MoveFavorite().ServeHTTP(rec, req)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, handledErr, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, handledErr)
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+		})
+	}
+}
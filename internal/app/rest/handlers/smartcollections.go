@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
+	"github.com/alesr/platform-go-challenge/internal/pkg/httputil"
+)
+
+// SmartCollectionRequest defines the data structure for creating a smart collection.
+// The rule is validated by the favorites service, see favorites.Rule.
+type SmartCollectionRequest struct {
+	Name string         `json:"name"`
+	Rule favorites.Rule `json:"rule"`
+}
+
+// SmartCollectionResponse defines the data structure for a user's smart collection.
+type SmartCollectionResponse struct {
+	ID        string         `json:"id"`
+	UserID    string         `json:"user_id"`
+	Name      string         `json:"name"`
+	Rule      favorites.Rule `json:"rule"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// ListSmartCollectionsResponse defines the data structure for listing a user's smart collections.
+type ListSmartCollectionsResponse struct {
+	SmartCollections []SmartCollectionResponse `json:"smart_collections"`
+}
+
+// CreateSmartCollection saves a rule over a user's favorites as a smart collection.
+func (h *Handler) CreateSmartCollection() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		userID := r.PathValue("user_id")
+		if err := validateID(userID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate user ID: %w, %v", ErrInvalidUserID, err))
+			return
+		}
+
+		var data SmartCollectionRequest
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not decode request data: %w, %w", err, ErrInvalidSmartCollectionPayload))
+			return
+		}
+
+		name := CollectionRequest{Name: data.Name}
+		if err := name.validate(); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate smart collection: %w", err))
+			return
+		}
+
+		params := favorites.SmartCollectionParams{Name: name.Name, Rule: data.Rule}
+		collection, err := h.favoritesSvc.CreateSmartCollection(r.Context(), userID, &params)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not create smart collection: %w", err))
+			return
+		}
+		httputil.RespondWithJSON(w, http.StatusCreated, toSmartCollectionResponse(*collection))
+	}
+}
+
+// ListSmartCollections lists a user's smart collections.
+func (h *Handler) ListSmartCollections() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.PathValue("user_id")
+		if err := validateID(userID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate user ID: %w, %v", ErrInvalidUserID, err))
+			return
+		}
+
+		collections, err := h.favoritesSvc.ListSmartCollections(r.Context(), userID)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not list smart collections: %w", err))
+			return
+		}
+
+		resp := ListSmartCollectionsResponse{SmartCollections: make([]SmartCollectionResponse, 0, len(collections))}
+		for _, c := range collections {
+			resp.SmartCollections = append(resp.SmartCollections, toSmartCollectionResponse(c))
+		}
+		httputil.RespondWithJSON(w, http.StatusOK, resp)
+	}
+}
+
+// GetSmartCollection returns one of a user's smart collections.
+func (h *Handler) GetSmartCollection() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, collectionID, err := smartCollectionPathValues(r)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, err)
+			return
+		}
+
+		collection, err := h.favoritesSvc.FetchSmartCollection(r.Context(), collectionID, userID)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not fetch smart collection: %w", err))
+			return
+		}
+		httputil.RespondWithJSON(w, http.StatusOK, toSmartCollectionResponse(*collection))
+	}
+}
+
+// DeleteSmartCollection deletes one of a user's smart collections.
+func (h *Handler) DeleteSmartCollection() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, collectionID, err := smartCollectionPathValues(r)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, err)
+			return
+		}
+
+		if err := h.favoritesSvc.DeleteSmartCollection(r.Context(), collectionID, userID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not delete smart collection: %w", err))
+			return
+		}
+		httputil.RespondWithJSON[any](w, http.StatusNoContent, nil)
+	}
+}
+
+// GetSmartCollectionItems lists the user's favorites matching a smart collection's rule.
+// It takes the same query parameters and returns the same page format as GetUserFavorites.
+func (h *Handler) GetSmartCollectionItems() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, collectionID, err := smartCollectionPathValues(r)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, err)
+			return
+		}
+
+		params, err := h.parseListFavoritesParams(r)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not parse list favorites params: %w", err))
+			return
+		}
+
+		favs, nextPageToken, err := h.favoritesSvc.FetchSmartCollectionItems(r.Context(), collectionID, userID, params)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not fetch smart collection items: %w", err))
+			return
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, ListUserFavoritesResponse{
+			Items:         toFavoritesResponse(favs...),
+			NextPageToken: nextPageToken,
+		})
+	}
+}
+
+// smartCollectionPathValues returns the validated user and smart collection IDs from the request path.
+func smartCollectionPathValues(r *http.Request) (string, string, error) {
+	userID := r.PathValue("user_id")
+	if err := validateID(userID); err != nil {
+		return "", "", fmt.Errorf("could not validate user ID: %w, %v", ErrInvalidUserID, err)
+	}
+
+	collectionID := r.PathValue("smart_collection_id")
+	if err := validateID(collectionID); err != nil {
+		return "", "", fmt.Errorf("could not validate smart collection ID: %w, %v", ErrInvalidSmartCollectionID, err)
+	}
+	return userID, collectionID, nil
+}
+
+func toSmartCollectionResponse(c favorites.SmartCollection) SmartCollectionResponse {
+	return SmartCollectionResponse{
+		ID:        c.ID,
+		UserID:    c.UserID,
+		Name:      c.Name,
+		Rule:      c.Rule,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
+	"github.com/alesr/platform-go-challenge/internal/pkg/httputil"
+	"github.com/alesr/resterr"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateSmartCollection(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+
+	testCases := []struct {
+		name          string
+		givenBody     string
+		expectRule    favorites.Rule
+		expectedError error
+	}{
+		{
+			name:      "success",
+			givenBody: `{"name":"German audiences","rule":{"all":[{"field":"asset_type","op":"eq","value":"AUDIENCE"},{"field":"asset.birth_countries","op":"eq","value":"Germany"}]}}`,
+			expectRule: favorites.Rule{All: []favorites.Rule{
+				{Field: "asset_type", Op: favorites.RuleOpEq, Value: "AUDIENCE"},
+				{Field: "asset.birth_countries", Op: favorites.RuleOpEq, Value: "Germany"},
+			}},
+		},
+		{
+			name:          "invalid payload",
+			givenBody:     `{"name":"German audiences","rule":`,
+			expectedError: ErrInvalidSmartCollectionPayload,
+		},
+		{
+			name:          "blank name",
+			givenBody:     `{"name":"","rule":{"field":"asset_type","op":"eq","value":"CHART"}}`,
+			expectedError: ErrInvalidCollectionName,
+		},
+		{
+			name:          "invalid rule",
+			givenBody:     `{"name":"Broken","rule":{"field":"foo","op":"eq","value":"bar"}}`,
+			expectRule:    favorites.Rule{Field: "foo", Op: favorites.RuleOpEq, Value: "bar"},
+			expectedError: favorites.ErrInvalidRule,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var handledErr error
+
+			handler := Handler{
+				errHandler: &errorHandlerMock{
+					handleFunc: func(ctx context.Context, w resterr.Writer, err error) {
+						handledErr = err
+					},
+				},
+				favoritesSvc: &favoritesSvcMock{
+					createSmartCollectionFunc: func(ctx context.Context, uID string, params *favorites.SmartCollectionParams) (*favorites.SmartCollection, error) {
+						assert.Equal(t, userID, uID)
+						assert.Equal(t, tc.expectRule, params.Rule)
+						if err := params.Rule.Validate(); err != nil {
+							return nil, err
+						}
+						return &favorites.SmartCollection{ID: "smart-1", UserID: uID, Name: params.Name, Rule: params.Rule}, nil
+					},
+				},
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/users/"+userID+"/smart-collections", strings.NewReader(tc.givenBody))
+			req.SetPathValue("user_id", userID)
+			rec := httptest.NewRecorder()
+
+			handler.CreateSmartCollection().ServeHTTP(rec, req)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, handledErr, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, handledErr)
+			assert.Equal(t, http.StatusCreated, rec.Code)
+
+			var resp httputil.Response[SmartCollectionResponse]
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			assert.Equal(t, "smart-1", resp.Data.ID)
+			assert.Equal(t, tc.expectRule, resp.Data.Rule)
+		})
+	}
+}
+
+func TestGetSmartCollectionItems(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+	collectionID := ulid.Make().String()
+
+	testCases := []struct {
+		name            string
+		givenCollection string
+		givenQuery      string
+		givenSvcErr     error
+		expectedError   error
+	}{
+		{
+			name:            "success",
+			givenCollection: collectionID,
+			givenQuery:      "?pageSize=5",
+		},
+		{
+			name:            "invalid smart collection id",
+			givenCollection: "foo",
+			expectedError:   ErrInvalidSmartCollectionID,
+		},
+		{
+			name:            "invalid page token",
+			givenCollection: collectionID,
+			givenQuery:      "?pageToken=foo",
+			expectedError:   ErrInvalidPageToken,
+		},
+		{
+			name:            "smart collection not found",
+			givenCollection: collectionID,
+			givenQuery:      "?pageSize=5",
+			givenSvcErr:     favorites.ErrSmartCollectionNotFound,
+			expectedError:   favorites.ErrSmartCollectionNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var handledErr error
+
+			handler := Handler{
+				errHandler: &errorHandlerMock{
+					handleFunc: func(ctx context.Context, w resterr.Writer, err error) {
+						handledErr = err
+					},
+				},
+				favoritesSvc: &favoritesSvcMock{
+					fetchSmartCollectionItemsFunc: func(ctx context.Context, colID, uID string, params *favorites.ListFavoritesParams) ([]favorites.FavoriteAsset, string, error) {
+						assert.Equal(t, collectionID, colID)
+						assert.Equal(t, userID, uID)
+						assert.Equal(t, 5, params.PageSize)
+						if tc.givenSvcErr != nil {
+							return nil, "", tc.givenSvcErr
+						}
+						return []favorites.FavoriteAsset{{ID: "fav-1"}}, "next", nil
+					},
+				},
+			}
+
+			req := httptest.NewRequest(
+				http.MethodGet,
+				"/users/"+userID+"/smart-collections/"+tc.givenCollection+"/items"+tc.givenQuery,
+				nil,
+			)
+			req.SetPathValue("user_id", userID)
+			req.SetPathValue("smart_collection_id", tc.givenCollection)
+			rec := httptest.NewRecorder()
+
+			handler.GetSmartCollectionItems().ServeHTTP(rec, req)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, handledErr, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, handledErr)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var resp httputil.Response[ListUserFavoritesResponse]
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			require.Len(t, resp.Data.Items, 1)
+			assert.Equal(t, "fav-1", resp.Data.Items[0].ID)
+			assert.Equal(t, "next", resp.Data.NextPageToken)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
+	"github.com/alesr/platform-go-challenge/internal/pkg/httputil"
+)
+
+// ListTrashResponse defines the data structure for listing a user's deleted favorites.
+type ListTrashResponse struct {
+	Items         []TrashedFavoriteResponse `json:"items"`
+	NextPageToken string                    `json:"next_page_token,omitempty"`
+}
+
+// TrashedFavoriteResponse defines the data structure item for a list of deleted favorites.
+// PurgeAt is when the favorite is gone for good, unless it's restored before.
+type TrashedFavoriteResponse struct {
+	FavoriteAssetResponse
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// GetTrash returns a list of the user's deleted favorites that can still be restored.
+func (h *Handler) GetTrash() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.PathValue("user_id")
+		if err := validateID(userID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate user ID: %w, %v", ErrInvalidUserID, err))
+			return
+		}
+
+		params, err := parseListTrashParams(r)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not parse list trash params: %w", err))
+			return
+		}
+
+		trashed, nextPageToken, err := h.favoritesSvc.FetchTrash(r.Context(), userID, params)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not fetch trash: %w", err))
+			return
+		}
+
+		items := make([]TrashedFavoriteResponse, 0, len(trashed))
+		for _, favorite := range trashed {
+			items = append(items, TrashedFavoriteResponse{
+				FavoriteAssetResponse: toFavoritesResponse(favorite.FavoriteAsset)[0],
+				DeletedAt:             favorite.DeletedAt,
+				PurgeAt:               favorite.PurgeAt,
+			})
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, ListTrashResponse{
+			Items:         items,
+			NextPageToken: nextPageToken,
+		})
+	}
+}
+
+// RestoreFavorite brings a user's deleted favorite back from the trash.
+func (h *Handler) RestoreFavorite() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.PathValue("user_id")
+		favoriteID := r.PathValue("favorite_id")
+
+		if err := validateID(userID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate user ID: %w, %v", ErrInvalidUserID, err))
+			return
+		}
+
+		if err := validateID(favoriteID); err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not validate favorite ID: %w, %v", ErrInvalidFavoriteID, err))
+			return
+		}
+
+		favorite, err := h.favoritesSvc.RestoreFavorite(r.Context(), favoriteID, userID)
+		if err != nil {
+			h.errHandler.Handle(r.Context(), w, fmt.Errorf("could not restore favorite: %w", err))
+			return
+		}
+		httputil.RespondWithJSON(w, http.StatusOK, toFavoritesResponse(*favorite)[0])
+	}
+}
+
+// parseListTrashParams parses the optional pagination parameters for listing the trash.
+// Page sizes out of bounds fall back to the default and maximum page sizes, as for favorites.
+func parseListTrashParams(r *http.Request) (*favorites.ListTrashParams, error) {
+	params := favorites.ListTrashParams{PageSize: defaultFavoritesPageSize}
+
+	if v := r.URL.Query().Get("pageSize"); v != "" {
+		pageSize, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPageSize, err)
+		}
+		if pageSize > 0 {
+			params.PageSize = min(pageSize, maxFavoritesPageSize)
+		}
+	}
+
+	if pageToken := r.URL.Query().Get("pageToken"); pageToken != "" {
+		cursor, err := favorites.DecodeTrashCursor(pageToken)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPageToken, err)
+		}
+		params.Cursor = cursor
+	}
+	return &params, nil
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alesr/platform-go-challenge/internal/assets"
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
+	"github.com/alesr/platform-go-challenge/internal/pkg/httputil"
+	"github.com/alesr/resterr"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTrash(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+	deletedAt := time.Date(2025, 2, 18, 9, 12, 3, 0, time.UTC)
+	givenCursor := favorites.TrashCursor{DeletedAt: deletedAt, ID: ulid.Make().String()}
+
+	givenInsight, err := assets.NewAssetFactory().CreateInsight("Sales are up")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name           string
+		givenQuery     string
+		givenSvcErr    error
+		expectedParams *favorites.ListTrashParams
+		expectedError  error
+	}{
+		{
+			name:           "defaults",
+			expectedParams: &favorites.ListTrashParams{PageSize: defaultFavoritesPageSize},
+		},
+		{
+			name:           "page size and token",
+			givenQuery:     "?pageSize=500&pageToken=" + givenCursor.Encode(),
+			expectedParams: &favorites.ListTrashParams{PageSize: maxFavoritesPageSize, Cursor: &givenCursor},
+		},
+		{
+			name:          "invalid page size",
+			givenQuery:    "?pageSize=foo",
+			expectedError: ErrInvalidPageSize,
+		},
+		{
+			name:          "invalid page token",
+			givenQuery:    "?pageToken=foo",
+			expectedError: ErrInvalidPageToken,
+		},
+		{
+			name:           "service error",
+			givenSvcErr:    assert.AnError,
+			expectedParams: &favorites.ListTrashParams{PageSize: defaultFavoritesPageSize},
+			expectedError:  assert.AnError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var handledErr error
+
+			handler := Handler{
+				errHandler: &errorHandlerMock{
+					handleFunc: func(ctx context.Context, w resterr.Writer, err error) {
+						handledErr = err
+					},
+				},
+				favoritesSvc: &favoritesSvcMock{
+					fetchTrashFunc: func(ctx context.Context, uID string, params *favorites.ListTrashParams) ([]favorites.TrashedFavorite, string, error) {
+						assert.Equal(t, userID, uID)
+						assert.Equal(t, tc.expectedParams.PageSize, params.PageSize)
+						if tc.expectedParams.Cursor != nil {
+							require.NotNil(t, params.Cursor)
+							assert.True(t, tc.expectedParams.Cursor.DeletedAt.Equal(params.Cursor.DeletedAt))
+							assert.Equal(t, tc.expectedParams.Cursor.ID, params.Cursor.ID)
+						}
+						if tc.givenSvcErr != nil {
+							return nil, "", tc.givenSvcErr
+						}
+						return []favorites.TrashedFavorite{{
+							FavoriteAsset: favorites.FavoriteAsset{
+								ID:        "fav-1",
+								AssetID:   "asset-1",
+								AssetType: assets.TypeAssetInsight,
+								Asset:     givenInsight,
+							},
+							DeletedAt: deletedAt,
+							PurgeAt:   deletedAt.Add(time.Hour),
+						}}, "next", nil
+					},
+				},
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/users/"+userID+"/favorites/trash"+tc.givenQuery, nil)
+			req.SetPathValue("user_id", userID)
+			rec := httptest.NewRecorder()
+
+			handler.GetTrash().ServeHTTP(rec, req)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, handledErr, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, handledErr)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var resp httputil.Response[ListTrashResponse]
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			require.Len(t, resp.Data.Items, 1)
+			assert.Equal(t, "fav-1", resp.Data.Items[0].ID)
+			assert.NotNil(t, resp.Data.Items[0].Asset)
+			assert.Equal(t, deletedAt, resp.Data.Items[0].DeletedAt)
+			assert.Equal(t, deletedAt.Add(time.Hour), resp.Data.Items[0].PurgeAt)
+			assert.Equal(t, "next", resp.Data.NextPageToken)
+		})
+	}
+}
+
+func TestRestoreFavorite(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+	favoriteID := ulid.Make().String()
+
+	testCases := []struct {
+		name          string
+		givenFavorite string
+		givenSvcErr   error
+		expectedError error
+	}{
+		{
+			name:          "success",
+			givenFavorite: favoriteID,
+		},
+		{
+			name:          "invalid favorite id",
+			givenFavorite: "foo",
+			expectedError: ErrInvalidFavoriteID,
+		},
+		{
+			name:          "favorite not in trash",
+			givenFavorite: favoriteID,
+			givenSvcErr:   favorites.ErrFavoriteAssetNotFound,
+			expectedError: favorites.ErrFavoriteAssetNotFound,
+		},
+		{
+			name:          "asset favorited again",
+			givenFavorite: favoriteID,
+			givenSvcErr:   favorites.ErrFavoriteAlreadyExists,
+			expectedError: favorites.ErrFavoriteAlreadyExists,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var handledErr error
+
+			handler := Handler{
+				errHandler: &errorHandlerMock{
+					handleFunc: func(ctx context.Context, w resterr.Writer, err error) {
+						handledErr = err
+					},
+				},
+				favoritesSvc: &favoritesSvcMock{
+					restoreFavoriteFunc: func(ctx context.Context, favID, uID string) (*favorites.FavoriteAsset, error) {
+						assert.Equal(t, favoriteID, favID)
+						assert.Equal(t, userID, uID)
+						if tc.givenSvcErr != nil {
+							return nil, tc.givenSvcErr
+						}
+						return &favorites.FavoriteAsset{ID: favID, AssetID: "asset-1", AssetType: assets.TypeAssetInsight}, nil
+					},
+				},
+			}
+
+			// the action is stripped from the favorite ID when routing, see rest.App
+			req := httptest.NewRequest(http.MethodPost, "/users/"+userID+"/favorites/"+tc.givenFavorite+":restore", nil)
+			req.SetPathValue("user_id", userID)
+			req.SetPathValue("favorite_id", tc.givenFavorite)
+			rec := httptest.NewRecorder()
+
+			handler.RestoreFavorite().ServeHTTP(rec, req)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, handledErr, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, handledErr)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var resp httputil.Response[FavoriteAssetResponse]
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			assert.Equal(t, favoriteID, resp.Data.ID)
+		})
+	}
+}
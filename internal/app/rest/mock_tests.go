@@ -8,13 +8,43 @@ import (
 var fallbackHandlerFunc func(w http.ResponseWriter, r *http.Request)
 
 type handlersMock struct {
-	shutdownFunc         func(ctx context.Context) error
-	listAssetsFunc       func() http.HandlerFunc
-	listUsersFunc        func() http.HandlerFunc
-	favoriteAssetFunc    func() http.HandlerFunc
-	getuserFavoritesFunc func() http.HandlerFunc
-	updateFavoriteFunc   func() http.HandlerFunc
-	deleteFavoriteFunc   func() http.HandlerFunc
+	shutdownFunc                func(ctx context.Context) error
+	listAssetsFunc              func() http.HandlerFunc
+	createAssetFunc             func() http.HandlerFunc
+	getAssetFunc                func() http.HandlerFunc
+	replaceAssetFunc            func() http.HandlerFunc
+	patchAssetFunc              func() http.HandlerFunc
+	deleteAssetFunc             func() http.HandlerFunc
+	getAssetSizeFunc            func() http.HandlerFunc
+	listUsersFunc               func() http.HandlerFunc
+	favoriteAssetFunc           func() http.HandlerFunc
+	getFavoriteJobFunc          func() http.HandlerFunc
+	getuserFavoritesFunc        func() http.HandlerFunc
+	updateFavoriteFunc          func() http.HandlerFunc
+	getFavoriteDiffFunc         func() http.HandlerFunc
+	deleteFavoriteFunc          func() http.HandlerFunc
+	deleteFavoritesFunc         func() http.HandlerFunc
+	batchFavoritesFunc          func() http.HandlerFunc
+	reorderFavoritesFunc        func() http.HandlerFunc
+	moveFavoriteFunc            func() http.HandlerFunc
+	getTrashFunc                func() http.HandlerFunc
+	restoreFavoriteFunc         func() http.HandlerFunc
+	openFavoriteFunc            func() http.HandlerFunc
+	createCollectionFunc        func() http.HandlerFunc
+	listCollectionsFunc         func() http.HandlerFunc
+	getCollectionFunc           func() http.HandlerFunc
+	updateCollectionFunc        func() http.HandlerFunc
+	deleteCollectionFunc        func() http.HandlerFunc
+	addToCollectionFunc         func() http.HandlerFunc
+	removeFromCollectionFunc    func() http.HandlerFunc
+	createSmartCollectionFunc   func() http.HandlerFunc
+	listSmartCollectionsFunc    func() http.HandlerFunc
+	getSmartCollectionFunc      func() http.HandlerFunc
+	deleteSmartCollectionFunc   func() http.HandlerFunc
+	getSmartCollectionItemsFunc func() http.HandlerFunc
+	listDeadLettersFunc         func() http.HandlerFunc
+	replayDeadLetterFunc        func() http.HandlerFunc
+	getQueueStatsFunc           func() http.HandlerFunc
 }
 
 func (m *handlersMock) Shutdown(ctx context.Context) error {
@@ -28,6 +58,48 @@ func (m *handlersMock) ListAssets() http.HandlerFunc {
 	return m.listAssetsFunc()
 }
 
+func (m *handlersMock) CreateAsset() http.HandlerFunc {
+	if m.createAssetFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.createAssetFunc()
+}
+
+func (m *handlersMock) GetAsset() http.HandlerFunc {
+	if m.getAssetFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.getAssetFunc()
+}
+
+func (m *handlersMock) ReplaceAsset() http.HandlerFunc {
+	if m.replaceAssetFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.replaceAssetFunc()
+}
+
+func (m *handlersMock) PatchAsset() http.HandlerFunc {
+	if m.patchAssetFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.patchAssetFunc()
+}
+
+func (m *handlersMock) DeleteAsset() http.HandlerFunc {
+	if m.deleteAssetFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.deleteAssetFunc()
+}
+
+func (m *handlersMock) GetAssetSize() http.HandlerFunc {
+	if m.getAssetSizeFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.getAssetSizeFunc()
+}
+
 func (m *handlersMock) ListUsers() http.HandlerFunc {
 	if m.listUsersFunc == nil {
 		return fallbackHandlerFunc
@@ -42,6 +114,13 @@ func (m *handlersMock) FavoriteAsset() http.HandlerFunc {
 	return m.favoriteAssetFunc()
 }
 
+func (m *handlersMock) GetFavoriteJob() http.HandlerFunc {
+	if m.getFavoriteJobFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.getFavoriteJobFunc()
+}
+
 func (m *handlersMock) GetUserFavorites() http.HandlerFunc {
 	if m.getuserFavoritesFunc == nil {
 		return fallbackHandlerFunc
@@ -56,9 +135,170 @@ func (m *handlersMock) UpdateFavorite() http.HandlerFunc {
 	return m.updateFavoriteFunc()
 }
 
+func (m *handlersMock) GetFavoriteDiff() http.HandlerFunc {
+	if m.getFavoriteDiffFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.getFavoriteDiffFunc()
+}
+
 func (m *handlersMock) DeleteFavorite() http.HandlerFunc {
 	if m.deleteFavoriteFunc == nil {
 		return fallbackHandlerFunc
 	}
 	return m.deleteFavoriteFunc()
 }
+
+func (m *handlersMock) DeleteFavorites() http.HandlerFunc {
+	if m.deleteFavoritesFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.deleteFavoritesFunc()
+}
+
+func (m *handlersMock) ReorderFavorites() http.HandlerFunc {
+	if m.reorderFavoritesFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.reorderFavoritesFunc()
+}
+
+func (m *handlersMock) MoveFavorite() http.HandlerFunc {
+	if m.moveFavoriteFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.moveFavoriteFunc()
+}
+
+func (m *handlersMock) GetTrash() http.HandlerFunc {
+	if m.getTrashFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.getTrashFunc()
+}
+
+func (m *handlersMock) RestoreFavorite() http.HandlerFunc {
+	if m.restoreFavoriteFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.restoreFavoriteFunc()
+}
+
+func (m *handlersMock) OpenFavorite() http.HandlerFunc {
+	if m.openFavoriteFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.openFavoriteFunc()
+}
+
+func (m *handlersMock) CreateCollection() http.HandlerFunc {
+	if m.createCollectionFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.createCollectionFunc()
+}
+
+func (m *handlersMock) ListCollections() http.HandlerFunc {
+	if m.listCollectionsFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.listCollectionsFunc()
+}
+
+func (m *handlersMock) GetCollection() http.HandlerFunc {
+	if m.getCollectionFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.getCollectionFunc()
+}
+
+func (m *handlersMock) UpdateCollection() http.HandlerFunc {
+	if m.updateCollectionFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.updateCollectionFunc()
+}
+
+func (m *handlersMock) DeleteCollection() http.HandlerFunc {
+	if m.deleteCollectionFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.deleteCollectionFunc()
+}
+
+func (m *handlersMock) AddToCollection() http.HandlerFunc {
+	if m.addToCollectionFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.addToCollectionFunc()
+}
+
+func (m *handlersMock) RemoveFromCollection() http.HandlerFunc {
+	if m.removeFromCollectionFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.removeFromCollectionFunc()
+}
+
+func (m *handlersMock) CreateSmartCollection() http.HandlerFunc {
+	if m.createSmartCollectionFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.createSmartCollectionFunc()
+}
+
+func (m *handlersMock) ListSmartCollections() http.HandlerFunc {
+	if m.listSmartCollectionsFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.listSmartCollectionsFunc()
+}
+
+func (m *handlersMock) GetSmartCollection() http.HandlerFunc {
+	if m.getSmartCollectionFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.getSmartCollectionFunc()
+}
+
+func (m *handlersMock) DeleteSmartCollection() http.HandlerFunc {
+	if m.deleteSmartCollectionFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.deleteSmartCollectionFunc()
+}
+
+func (m *handlersMock) GetSmartCollectionItems() http.HandlerFunc {
+	if m.getSmartCollectionItemsFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.getSmartCollectionItemsFunc()
+}
+
+func (m *handlersMock) ListDeadLetters() http.HandlerFunc {
+	if m.listDeadLettersFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.listDeadLettersFunc()
+}
+
+func (m *handlersMock) ReplayDeadLetter() http.HandlerFunc {
+	if m.replayDeadLetterFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.replayDeadLetterFunc()
+}
+
+func (m *handlersMock) BatchFavorites() http.HandlerFunc {
+	if m.batchFavoritesFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.batchFavoritesFunc()
+}
+
+func (m *handlersMock) GetQueueStats() http.HandlerFunc {
+	if m.getQueueStatsFunc == nil {
+		return fallbackHandlerFunc
+	}
+	return m.getQueueStatsFunc()
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/alesr/platform-go-challenge/internal/pkg/httputil/middleware"
@@ -16,11 +17,41 @@ const defaultShutdownTimeout = 30 * time.Second
 type handlers interface {
 	Shutdown(ctx context.Context) error
 	ListAssets() http.HandlerFunc
+	CreateAsset() http.HandlerFunc
+	GetAsset() http.HandlerFunc
+	ReplaceAsset() http.HandlerFunc
+	PatchAsset() http.HandlerFunc
+	DeleteAsset() http.HandlerFunc
+	GetAssetSize() http.HandlerFunc
 	ListUsers() http.HandlerFunc
 	FavoriteAsset() http.HandlerFunc
+	GetFavoriteJob() http.HandlerFunc
 	GetUserFavorites() http.HandlerFunc
 	UpdateFavorite() http.HandlerFunc
+	GetFavoriteDiff() http.HandlerFunc
 	DeleteFavorite() http.HandlerFunc
+	DeleteFavorites() http.HandlerFunc
+	BatchFavorites() http.HandlerFunc
+	ReorderFavorites() http.HandlerFunc
+	MoveFavorite() http.HandlerFunc
+	GetTrash() http.HandlerFunc
+	RestoreFavorite() http.HandlerFunc
+	OpenFavorite() http.HandlerFunc
+	CreateCollection() http.HandlerFunc
+	ListCollections() http.HandlerFunc
+	GetCollection() http.HandlerFunc
+	UpdateCollection() http.HandlerFunc
+	DeleteCollection() http.HandlerFunc
+	AddToCollection() http.HandlerFunc
+	RemoveFromCollection() http.HandlerFunc
+	CreateSmartCollection() http.HandlerFunc
+	ListSmartCollections() http.HandlerFunc
+	GetSmartCollection() http.HandlerFunc
+	DeleteSmartCollection() http.HandlerFunc
+	GetSmartCollectionItems() http.HandlerFunc
+	ListDeadLetters() http.HandlerFunc
+	ReplayDeadLetter() http.HandlerFunc
+	GetQueueStats() http.HandlerFunc
 }
 
 // App represents our RESTful application instance.
@@ -52,11 +83,43 @@ func (app *App) Start() error {
 	// Register endpoints
 
 	app.handleFuncWithMiddleware("GET /assets", app.handlers.ListAssets())
+	app.handleFuncWithMiddleware("POST /assets", app.handlers.CreateAsset())
+	app.handleFuncWithMiddleware("GET /assets/{asset_id}", app.handlers.GetAsset())
+	app.handleFuncWithMiddleware("PUT /assets/{asset_id}", app.handlers.ReplaceAsset())
+	app.handleFuncWithMiddleware("PATCH /assets/{asset_id}", app.handlers.PatchAsset())
+	app.handleFuncWithMiddleware("DELETE /assets/{asset_id}", app.handlers.DeleteAsset())
+	app.handleFuncWithMiddleware("GET /assets/{asset_id}/size", app.handlers.GetAssetSize())
 	app.handleFuncWithMiddleware("GET /users", app.handlers.ListUsers())
 	app.handleFuncWithMiddleware("POST /assets/favorite", app.handlers.FavoriteAsset())
+	app.handleFuncWithMiddleware("GET /favorite-jobs/{job_id}", app.handlers.GetFavoriteJob())
 	app.handleFuncWithMiddleware("GET /users/{user_id}/favorites", app.handlers.GetUserFavorites())
+	app.handleFuncWithMiddleware("DELETE /users/{user_id}/favorites", app.handlers.DeleteFavorites())
 	app.handleFuncWithMiddleware("PATCH /users/{user_id}/favorites/{favorite_id}", app.handlers.UpdateFavorite())
 	app.handleFuncWithMiddleware("DELETE /users/{user_id}/favorites/{favorite_id}", app.handlers.DeleteFavorite())
+	app.handleFuncWithMiddleware("GET /users/{user_id}/favorites/{favorite_id}/diff", app.handlers.GetFavoriteDiff())
+	app.handleFuncWithMiddleware("POST /users/{user_id}/favorites:batch", app.handlers.BatchFavorites())
+	app.handleFuncWithMiddleware("PUT /users/{user_id}/favorites/order", app.handlers.ReorderFavorites())
+	app.handleFuncWithMiddleware("POST /users/{user_id}/favorites/{favorite_id}/move", app.handlers.MoveFavorite())
+	app.handleFuncWithMiddleware("GET /users/{user_id}/favorites/trash", app.handlers.GetTrash())
+	app.handleActions("POST /users/{user_id}/favorites/{favorite_id}", map[string]http.HandlerFunc{
+		"restore": app.handlers.RestoreFavorite(),
+		"open":    app.handlers.OpenFavorite(),
+	})
+	app.handleFuncWithMiddleware("POST /users/{user_id}/collections", app.handlers.CreateCollection())
+	app.handleFuncWithMiddleware("GET /users/{user_id}/collections", app.handlers.ListCollections())
+	app.handleFuncWithMiddleware("GET /users/{user_id}/collections/{collection_id}", app.handlers.GetCollection())
+	app.handleFuncWithMiddleware("PATCH /users/{user_id}/collections/{collection_id}", app.handlers.UpdateCollection())
+	app.handleFuncWithMiddleware("DELETE /users/{user_id}/collections/{collection_id}", app.handlers.DeleteCollection())
+	app.handleFuncWithMiddleware("PUT /users/{user_id}/collections/{collection_id}/favorites/{favorite_id}", app.handlers.AddToCollection())
+	app.handleFuncWithMiddleware("DELETE /users/{user_id}/collections/{collection_id}/favorites/{favorite_id}", app.handlers.RemoveFromCollection())
+	app.handleFuncWithMiddleware("POST /users/{user_id}/smart-collections", app.handlers.CreateSmartCollection())
+	app.handleFuncWithMiddleware("GET /users/{user_id}/smart-collections", app.handlers.ListSmartCollections())
+	app.handleFuncWithMiddleware("GET /users/{user_id}/smart-collections/{smart_collection_id}", app.handlers.GetSmartCollection())
+	app.handleFuncWithMiddleware("DELETE /users/{user_id}/smart-collections/{smart_collection_id}", app.handlers.DeleteSmartCollection())
+	app.handleFuncWithMiddleware("GET /users/{user_id}/smart-collections/{smart_collection_id}/items", app.handlers.GetSmartCollectionItems())
+	app.handleFuncWithMiddleware("GET /admin/queue", app.handlers.GetQueueStats())
+	app.handleFuncWithMiddleware("GET /admin/dead-letters", app.handlers.ListDeadLetters())
+	app.handleFuncWithMiddleware("POST /admin/dead-letters/{dead_letter_id}/replay", app.handlers.ReplayDeadLetter())
 
 	go func() {
 		app.logger.Info("Starting server", slog.String("addr", app.Addr))
@@ -100,3 +163,23 @@ func (app *App) handleFuncWithMiddleware(
 	}
 	app.Handler.(*http.ServeMux).HandleFunc(path, handler)
 }
+
+// handleActions registers custom methods on a resource, as in POST /things/{thing_id}:action.
+// ServeMux wildcards match whole path segments only, so the pattern's last wildcard matches
+// the resource ID along with the action, and requests are dispatched on the action after the colon.
+// Handlers see the wildcard set to the resource ID alone. Unknown actions are not found.
+func (app *App) handleActions(pattern string, actions map[string]http.HandlerFunc) {
+	wildcard := pattern[strings.LastIndex(pattern, "{")+1 : strings.LastIndex(pattern, "}")]
+
+	app.handleFuncWithMiddleware(pattern, func(w http.ResponseWriter, r *http.Request) {
+		id, action, _ := strings.Cut(r.PathValue(wildcard), ":")
+		handler, ok := actions[action]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		r.SetPathValue(wildcard, id)
+		handler(w, r)
+	})
+}
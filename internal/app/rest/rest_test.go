@@ -107,3 +107,53 @@ func TestApp_Shutdown(t *testing.T) {
 
 	assert.True(t, handlersShutdownCalled)
 }
+
+func TestApp_HandleActions(t *testing.T) {
+	t.Parallel()
+
+	app := NewApp(logutil.NewNoop(), &http.Server{}, &handlersMock{})
+
+	var gotThingID string
+	app.handleActions("POST /things/{thing_id}", map[string]http.HandlerFunc{
+		"poke": func(w http.ResponseWriter, r *http.Request) {
+			gotThingID = r.PathValue("thing_id")
+			w.WriteHeader(http.StatusTeapot)
+		},
+	})
+
+	testCases := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedID     string
+	}{
+		{
+			name:           "known action",
+			path:           "/things/123:poke",
+			expectedStatus: http.StatusTeapot,
+			expectedID:     "123",
+		},
+		{
+			name:           "unknown action",
+			path:           "/things/123:prod",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "no action",
+			path:           "/things/123",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotThingID = ""
+
+			rec := httptest.NewRecorder()
+			app.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tc.path, nil))
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Equal(t, tc.expectedID, gotThingID)
+		})
+	}
+}
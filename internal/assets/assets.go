@@ -2,6 +2,8 @@ package assets
 
 import (
 	"time"
+
+	"github.com/oklog/ulid/v2"
 )
 
 const (
@@ -18,26 +20,31 @@ type (
 	// Type aliases for brevity when working with generic assets.
 	// Apart the interface and the asset type (string), these are
 	// the only asset types that are part of the service API.
-	ChartAsset    = asset[chart]
-	InsightAsset  = asset[insight]
-	AudienceAsset = asset[audience]
+	ChartAsset    = Asset[chart]
+	InsightAsset  = Asset[insight]
+	AudienceAsset = Asset[audience]
 
 	// AssetType represent the type of an asset (Chart, Insight, or Audience)
 	AssetType string
 
 	// Asseter defines the interface an asset must implement
 	// to be passed between services and layers.
-	Asseter interface{ Type() AssetType }
-
-	// assetData is our type constraint for asset data.
-	// Any new asset type must be registered here.
-	assetData interface {
-		chart | insight | audience
+	Asseter interface {
+		Type() AssetType
+		Metadata() Metadata
 	}
 )
 
-// asset defines the generic asset which implements the Asseter interface.
-type asset[T assetData] struct {
+// Metadata holds what all assets have in common, whatever their type.
+type Metadata struct {
+	ID        string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Asset defines the generic asset which implements the Asseter interface.
+// T is the data of the asset type, as registered with Register.
+type Asset[T any] struct {
 	ID        string
 	CreatedAt time.Time
 	UpdatedAt time.Time
@@ -45,35 +52,44 @@ type asset[T assetData] struct {
 	assetType AssetType
 }
 
-// Type implements the Asseter interface.
-func (a asset[T]) Type() AssetType {
-	return a.assetType
+// NewAsset creates a new asset of the given type, with a new ID.
+// The factory methods should be preferred for the built-in asset types.
+func NewAsset[T any](t AssetType, data T) Asset[T] {
+	now := time.Now()
+	return Asset[T]{
+		ID:        ulid.Make().String(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Data:      data,
+		assetType: t,
+	}
 }
 
-// Chart defines the data structure of a chart asset.
-type chart struct {
-	Title string
-	XAxis string
-	YAxis string
-	Data  []float64
+// Type implements the Asseter interface.
+func (a Asset[T]) Type() AssetType {
+	return a.assetType
 }
 
-// insight defines the data structure of an insight asset.
-type insight struct{ Insight string }
-
-// audience defines the data structure of an audience.
-type audience struct {
-	Gender             string
-	BirthCountry       string
-	AgeMin             int
-	AgeMax             int
-	SocialMediaHours   int
-	LastMonthPurchases int
+// Metadata implements the Asseter interface.
+func (a Asset[T]) Metadata() Metadata {
+	return Metadata{ID: a.ID, CreatedAt: a.CreatedAt, UpdatedAt: a.UpdatedAt}
 }
 
 // ListAssetsParams defines pagination parameters for listing assets.
+// UserID is optional, when given the assets are listed on behalf of that user.
 type ListAssetsParams struct {
 	PageSize   int
 	PageToken  string
 	MaxResults int
+	UserID     string
+}
+
+// ListedAsset is an asset as listed in the catalog, along with how it's been favorited.
+type ListedAsset struct {
+	Asset Asseter
+	// FavoriteCount is the number of users who favorited the asset.
+	FavoriteCount int64
+	// FavoriteID is the ID of the listing user's favorite of the asset,
+	// empty if the assets weren't listed on behalf of a user or the user hasn't favorited it.
+	FavoriteID string
 }
@@ -0,0 +1,87 @@
+package assets
+
+import "slices"
+
+// AgeGroup is one of the standard age buckets audiences are segmented by.
+type AgeGroup string
+
+const (
+	// Enumerate age groups
+
+	AgeGroup13To17 AgeGroup = "13-17"
+	AgeGroup18To24 AgeGroup = "18-24"
+	AgeGroup25To34 AgeGroup = "25-34"
+	AgeGroup35To44 AgeGroup = "35-44"
+	AgeGroup45To54 AgeGroup = "45-54"
+	AgeGroup55To64 AgeGroup = "55-64"
+	AgeGroup65Plus AgeGroup = "65+"
+)
+
+var ageGroups = []AgeGroup{
+	AgeGroup13To17,
+	AgeGroup18To24,
+	AgeGroup25To34,
+	AgeGroup35To44,
+	AgeGroup45To54,
+	AgeGroup55To64,
+	AgeGroup65Plus,
+}
+
+// AgeGroups returns all the age groups, youngest first.
+func AgeGroups() []AgeGroup {
+	return slices.Clone(ageGroups)
+}
+
+// Valid reports whether g is one of the standard age groups.
+func (g AgeGroup) Valid() bool {
+	return slices.Contains(ageGroups, g)
+}
+
+// Ages returns the range of ages of the age group, open above for the oldest one,
+// and false if g isn't one of the standard age groups.
+func (g AgeGroup) Ages() (Range, bool) {
+	switch g {
+	case AgeGroup13To17:
+		return Between(13, 17), true
+	case AgeGroup18To24:
+		return Between(18, 24), true
+	case AgeGroup25To34:
+		return Between(25, 34), true
+	case AgeGroup35To44:
+		return Between(35, 44), true
+	case AgeGroup45To54:
+		return Between(45, 54), true
+	case AgeGroup55To64:
+		return Between(55, 64), true
+	case AgeGroup65Plus:
+		return AtLeast(65), true
+	}
+	return Range{}, false
+}
+
+// Range is a range of whole numbers, bounds included. A range without Min or Max is open on that side,
+// so {"min": 3} is 3 or more, and a range without bounds holds any number.
+type Range struct {
+	Min *int `json:"min,omitempty"`
+	Max *int `json:"max,omitempty"`
+}
+
+// Between returns the closed range from min to max.
+func Between(min, max int) Range {
+	return Range{Min: &min, Max: &max}
+}
+
+// AtLeast returns the range of n or more.
+func AtLeast(n int) Range {
+	return Range{Min: &n}
+}
+
+// AtMost returns the range of up to n.
+func AtMost(n int) Range {
+	return Range{Max: &n}
+}
+
+// Contains reports whether n is within the range.
+func (r Range) Contains(n int) bool {
+	return (r.Min == nil || n >= *r.Min) && (r.Max == nil || n <= *r.Max)
+}
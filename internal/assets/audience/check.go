@@ -0,0 +1,114 @@
+package audience
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Type is the type of the values of an attribute.
+type Type string
+
+const (
+	// Enumerate attribute types
+
+	TypeString Type = "string"
+	TypeNumber Type = "number"
+)
+
+// attributes maps the attributes of the people expressions are about to their types.
+var attributes = map[string]Type{
+	"gender":               TypeString,
+	"birth_country":        TypeString,
+	"age":                  TypeNumber,
+	"social_media_hours":   TypeNumber,
+	"last_month_purchases": TypeNumber,
+}
+
+// typeOps lists the comparison operators each type accepts.
+var typeOps = map[Type][]Op{
+	TypeString: {OpEq, OpNe},
+	TypeNumber: {OpEq, OpNe, OpLt, OpLte, OpGt, OpGte},
+}
+
+// check type-checks a parsed expression: attributes must be known,
+// and compared with operators and values of their type.
+func check(e Expr) error {
+	switch e := e.(type) {
+	case *And:
+		return checkAll(e.Exprs)
+
+	case *Or:
+		return checkAll(e.Exprs)
+
+	case *Not:
+		return check(e.Expr)
+
+	case *Compare:
+		t, err := attributeType(e.Attr, e.attrPos)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(typeOps[t], e.Op) {
+			return errorf(e.opPos, "operator '%s' can't be used on %s attribute '%s'", e.Op, t, e.Attr)
+		}
+		return checkValue(e.Attr, t, e.Value, e.valuePos)
+
+	case *In:
+		t, err := attributeType(e.Attr, e.attrPos)
+		if err != nil {
+			return err
+		}
+		for i, v := range e.Values {
+			if err := checkValue(e.Attr, t, v, e.valuePos[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *Between:
+		t, err := attributeType(e.Attr, e.attrPos)
+		if err != nil {
+			return err
+		}
+		if t != TypeNumber {
+			return errorf(e.attrPos, "BETWEEN can't be used on %s attribute '%s'", t, e.Attr)
+		}
+		if e.Low > e.High {
+			return errorf(e.lowPos, "lower bound %s is above upper bound %s", formatValue(e.Low), formatValue(e.High))
+		}
+		return nil
+	}
+	return nil
+}
+
+func checkAll(exprs []Expr) error {
+	for _, e := range exprs {
+		if err := check(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func attributeType(attr string, pos int) (Type, error) {
+	t, ok := attributes[attr]
+	if !ok {
+		return "", errorf(pos, "unknown attribute '%s', expected one of %s", attr, strings.Join(slices.Sorted(maps.Keys(attributes)), ", "))
+	}
+	return t, nil
+}
+
+func checkValue(attr string, t Type, v Value, pos int) error {
+	switch v.(type) {
+	case string:
+		if t == TypeString {
+			return nil
+		}
+	case float64:
+		if t == TypeNumber {
+			return nil
+		}
+	}
+	return errorf(pos, "attribute '%s' expects a %s value", attr, t)
+}
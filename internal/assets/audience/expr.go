@@ -0,0 +1,159 @@
+// Package audience implements the expression language audiences are defined with, as in
+//
+//	gender IN ("Female") AND (age BETWEEN 18 AND 24 OR social_media_hours > 5)
+//
+// An expression is a boolean combination, with AND, OR, NOT and parentheses, of predicates
+// on the attributes of the people making up an audience. Parse checks an expression is well
+// formed and well typed, and expressions print in a canonical form, so equivalent spellings
+// of an expression are stored the same way.
+package audience
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Expr is a parsed and type-checked expression, one of And, Or, Not, Compare, In or Between.
+// Its String method prints it in canonical form, which parses back to the same expression.
+type Expr interface {
+	String() string
+	// precedence orders how tightly expressions bind, so they are parenthesized only where needed.
+	precedence() int
+}
+
+const (
+	precedenceOr = iota + 1
+	precedenceAnd
+	precedenceNot
+	precedencePredicate
+)
+
+// Op is a comparison operator.
+type Op string
+
+const (
+	// Enumerate comparison operators
+
+	OpEq  Op = "="
+	OpNe  Op = "!="
+	OpLt  Op = "<"
+	OpLte Op = "<="
+	OpGt  Op = ">"
+	OpGte Op = ">="
+)
+
+// Value is the value of a literal, a string or a float64 depending on the type of its attribute.
+type Value any
+
+// And matches when all of its expressions match.
+type And struct {
+	Exprs []Expr
+}
+
+// Or matches when any of its expressions matches.
+type Or struct {
+	Exprs []Expr
+}
+
+// Not matches when its expression doesn't.
+type Not struct {
+	Expr Expr
+}
+
+// Compare matches when the attribute compares to the value with the operator.
+type Compare struct {
+	Attr  string
+	Op    Op
+	Value Value
+
+	attrPos, opPos, valuePos int
+}
+
+// In matches when the attribute is one of the values, or none of them if negated.
+type In struct {
+	Attr    string
+	Values  []Value
+	Negated bool
+
+	attrPos, opPos int
+	valuePos       []int
+}
+
+// Between matches when the attribute is within the bounds, included, or out of them if negated.
+type Between struct {
+	Attr      string
+	Low, High float64
+	Negated   bool
+
+	attrPos, lowPos, highPos int
+}
+
+func (e *And) precedence() int     { return precedenceAnd }
+func (e *Or) precedence() int      { return precedenceOr }
+func (e *Not) precedence() int     { return precedenceNot }
+func (e *Compare) precedence() int { return precedencePredicate }
+func (e *In) precedence() int      { return precedencePredicate }
+func (e *Between) precedence() int { return precedencePredicate }
+
+func (e *And) String() string {
+	return join(e.Exprs, " AND ", precedenceAnd)
+}
+
+func (e *Or) String() string {
+	return join(e.Exprs, " OR ", precedenceOr)
+}
+
+func (e *Not) String() string {
+	return "NOT " + parenthesize(e.Expr, precedenceNot)
+}
+
+func (e *Compare) String() string {
+	return e.Attr + " " + string(e.Op) + " " + formatValue(e.Value)
+}
+
+func (e *In) String() string {
+	values := make([]string, 0, len(e.Values))
+	for _, v := range e.Values {
+		values = append(values, formatValue(v))
+	}
+	return e.Attr + negation(e.Negated) + " IN (" + strings.Join(values, ", ") + ")"
+}
+
+func (e *Between) String() string {
+	return e.Attr + negation(e.Negated) + " BETWEEN " + formatValue(e.Low) + " AND " + formatValue(e.High)
+}
+
+func join(exprs []Expr, separator string, precedence int) string {
+	parts := make([]string, 0, len(exprs))
+	for _, e := range exprs {
+		parts = append(parts, parenthesize(e, precedence))
+	}
+	return strings.Join(parts, separator)
+}
+
+// parenthesize prints e, in parentheses if it binds less tightly than the expression it's part of.
+// Expressions of the same precedence are parenthesized too, as nested ANDs and ORs are flattened
+// when parsed, so they never nest unless it's a NOT of a NOT.
+func parenthesize(e Expr, precedence int) string {
+	if e.precedence() < precedence || (e.precedence() == precedence && precedence != precedenceNot) {
+		return "(" + e.String() + ")"
+	}
+	return e.String()
+}
+
+func negation(negated bool) string {
+	if negated {
+		return " NOT"
+	}
+	return ""
+}
+
+func formatValue(v Value) string {
+	switch v := v.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return strconv.Quote(v)
+	}
+	return ""
+}
@@ -0,0 +1,436 @@
+package audience
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// Bounds on an expression, which is parsed recursively.
+	maxExpressionLength = 2000
+	maxDepth            = 32
+)
+
+// ErrInvalidExpression is wrapped by every error parsing an expression.
+var ErrInvalidExpression = errors.New("invalid audience expression")
+
+// Error is an error in an expression, at the 1-based position of the character it's about.
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("at position %d: %s", e.Pos, e.Msg)
+}
+
+func (e *Error) Unwrap() error {
+	return ErrInvalidExpression
+}
+
+func errorf(pos int, format string, args ...any) error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Parse parses and type-checks an expression, returning an *Error for the first problem found.
+// Keywords and attributes are case-insensitive, strings are double-quoted with Go escapes.
+func Parse(src string) (Expr, error) {
+	if utf8.RuneCountInString(src) > maxExpressionLength {
+		return nil, errorf(maxExpressionLength+1, "expression is longer than %d characters", maxExpressionLength)
+	}
+
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser{tokens: tokens}
+	expr, err := p.parseOr(1)
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, errorf(tok.pos, "unexpected %s, expected AND, OR or the end of the expression", tok)
+	}
+
+	if err := check(expr); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+// Canonical returns the canonical form of an expression, see Parse.
+func Canonical(src string) (string, error) {
+	expr, err := Parse(src)
+	if err != nil {
+		return "", err
+	}
+	return expr.String(), nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenKeyword
+	tokenString
+	tokenNumber
+	tokenOp
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	// text is the token as written, keywords upper-cased and operators in canonical form.
+	text  string
+	value Value
+	pos   int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenString:
+		return "string " + t.text
+	case tokenNumber:
+		return "number " + t.text
+	}
+	return "'" + t.text + "'"
+}
+
+var keywords = map[string]bool{"AND": true, "OR": true, "NOT": true, "IN": true, "BETWEEN": true}
+
+// operators maps the spellings of operators to their canonical form, longest first.
+var operators = []struct {
+	text string
+	op   Op
+}{
+	{"!=", OpNe},
+	{"<>", OpNe},
+	{"<=", OpLte},
+	{">=", OpGte},
+	{"==", OpEq},
+	{"=", OpEq},
+	{"<", OpLt},
+	{">", OpGt},
+}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: pos})
+			i++
+
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: pos})
+			i++
+
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: pos})
+			i++
+
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				if runes[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(runes) {
+				return nil, errorf(pos, "unterminated string")
+			}
+
+			text := string(runes[i : end+1])
+			s, err := strconv.Unquote(text)
+			if err != nil {
+				return nil, errorf(pos, "invalid string %s", text)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, value: s, pos: pos})
+			i = end + 1
+
+		case unicode.IsDigit(r):
+			end := i
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.') {
+				end++
+			}
+
+			text := string(runes[i:end])
+			n, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, errorf(pos, "invalid number %s", text)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, value: n, pos: pos})
+			i = end
+
+		case r == '_' || unicode.IsLetter(r):
+			end := i
+			for end < len(runes) && (runes[end] == '_' || unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end])) {
+				end++
+			}
+
+			text := string(runes[i:end])
+			if upper := strings.ToUpper(text); keywords[upper] {
+				tokens = append(tokens, token{kind: tokenKeyword, text: upper, pos: pos})
+			} else {
+				tokens = append(tokens, token{kind: tokenIdent, text: strings.ToLower(text), pos: pos})
+			}
+			i = end
+
+		default:
+			matched := false
+			for _, o := range operators {
+				if strings.HasPrefix(string(runes[i:min(i+2, len(runes))]), o.text) {
+					tokens = append(tokens, token{kind: tokenOp, text: string(o.op), pos: pos})
+					i += len(o.text)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, errorf(pos, "unexpected character '%c'", r)
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(runes) + 1}), nil
+}
+
+// parser is a recursive descent parser of the grammar:
+//
+//	or        = and { "OR" and }
+//	and       = unary { "AND" unary }
+//	unary     = "NOT" unary | "(" or ")" | predicate
+//	predicate = attribute ( op literal
+//	            | [ "NOT" ] "IN" "(" literal { "," literal } ")"
+//	            | [ "NOT" ] "BETWEEN" number "AND" number )
+//
+// Its methods take the depth of the expression they parse, bounded by maxDepth.
+type parser struct {
+	tokens []token
+	next   int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) advance() token {
+	tok := p.tokens[p.next]
+	if tok.kind != tokenEOF {
+		p.next++
+	}
+	return tok
+}
+
+func (p *parser) isKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.kind == tokenKeyword && tok.text == keyword
+}
+
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	tok := p.advance()
+	if tok.kind != kind {
+		return tok, errorf(tok.pos, "unexpected %s, expected %s", tok, what)
+	}
+	return tok, nil
+}
+
+func (p *parser) parseOr(depth int) (Expr, error) {
+	if depth > maxDepth {
+		return nil, errorf(p.peek().pos, "expression is nested more than %d levels deep", maxDepth)
+	}
+
+	first, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	exprs := []Expr{first}
+	for p.isKeyword("OR") {
+		p.advance()
+		e, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, e)
+	}
+
+	if len(exprs) == 1 {
+		return first, nil
+	}
+	return &Or{Exprs: flatten[*Or](exprs, func(e *Or) []Expr { return e.Exprs })}, nil
+}
+
+func (p *parser) parseAnd(depth int) (Expr, error) {
+	first, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	exprs := []Expr{first}
+	for p.isKeyword("AND") {
+		p.advance()
+		e, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, e)
+	}
+
+	if len(exprs) == 1 {
+		return first, nil
+	}
+	return &And{Exprs: flatten[*And](exprs, func(e *And) []Expr { return e.Exprs })}, nil
+}
+
+// flatten splices the expressions of the same kind as the one they are part of into it,
+// so (a AND b) AND c is the same expression as a AND b AND c.
+func flatten[T Expr](exprs []Expr, children func(T) []Expr) []Expr {
+	flat := make([]Expr, 0, len(exprs))
+	for _, e := range exprs {
+		if same, ok := e.(T); ok {
+			flat = append(flat, children(same)...)
+			continue
+		}
+		flat = append(flat, e)
+	}
+	return flat
+}
+
+func (p *parser) parseUnary(depth int) (Expr, error) {
+	if depth > maxDepth {
+		return nil, errorf(p.peek().pos, "expression is nested more than %d levels deep", maxDepth)
+	}
+
+	switch tok := p.peek(); {
+	case tok.kind == tokenKeyword && tok.text == "NOT":
+		p.advance()
+		e, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Expr: e}, nil
+
+	case tok.kind == tokenLParen:
+		p.advance()
+		e, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRParen, "')'"); err != nil {
+			return nil, err
+		}
+		return e, nil
+
+	case tok.kind == tokenIdent:
+		return p.parsePredicate()
+
+	default:
+		return nil, errorf(tok.pos, "unexpected %s, expected an attribute, NOT or '('", tok)
+	}
+}
+
+func (p *parser) parsePredicate() (Expr, error) {
+	attr := p.advance()
+
+	negated := false
+	if p.isKeyword("NOT") {
+		p.advance()
+		negated = true
+		if !p.isKeyword("IN") && !p.isKeyword("BETWEEN") {
+			tok := p.peek()
+			return nil, errorf(tok.pos, "unexpected %s, expected IN or BETWEEN after NOT", tok)
+		}
+	}
+
+	switch tok := p.advance(); {
+	case tok.kind == tokenOp:
+		value, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		return &Compare{
+			Attr:     attr.text,
+			Op:       Op(tok.text),
+			Value:    value.value,
+			attrPos:  attr.pos,
+			opPos:    tok.pos,
+			valuePos: value.pos,
+		}, nil
+
+	case tok.kind == tokenKeyword && tok.text == "IN":
+		if _, err := p.expect(tokenLParen, "'(' after IN"); err != nil {
+			return nil, err
+		}
+
+		in := In{Attr: attr.text, Negated: negated, attrPos: attr.pos, opPos: tok.pos}
+		for {
+			value, err := p.parseLiteral()
+			if err != nil {
+				return nil, err
+			}
+			in.Values = append(in.Values, value.value)
+			in.valuePos = append(in.valuePos, value.pos)
+
+			next := p.advance()
+			if next.kind == tokenRParen {
+				return &in, nil
+			}
+			if next.kind != tokenComma {
+				return nil, errorf(next.pos, "unexpected %s, expected ',' or ')'", next)
+			}
+		}
+
+	case tok.kind == tokenKeyword && tok.text == "BETWEEN":
+		low, err := p.expect(tokenNumber, "a number after BETWEEN")
+		if err != nil {
+			return nil, err
+		}
+		if and := p.advance(); and.kind != tokenKeyword || and.text != "AND" {
+			return nil, errorf(and.pos, "unexpected %s, expected AND between the bounds of BETWEEN", and)
+		}
+		high, err := p.expect(tokenNumber, "a number as the upper bound of BETWEEN")
+		if err != nil {
+			return nil, err
+		}
+		return &Between{
+			Attr:    attr.text,
+			Low:     low.value.(float64),
+			High:    high.value.(float64),
+			Negated: negated,
+			attrPos: attr.pos,
+			lowPos:  low.pos,
+			highPos: high.pos,
+		}, nil
+
+	default:
+		return nil, errorf(tok.pos, "unexpected %s, expected a comparison, IN or BETWEEN after attribute '%s'", tok, attr.text)
+	}
+}
+
+func (p *parser) parseLiteral() (token, error) {
+	tok := p.advance()
+	if tok.kind != tokenString && tok.kind != tokenNumber {
+		return tok, errorf(tok.pos, "unexpected %s, expected a string or a number", tok)
+	}
+	return tok, nil
+}
@@ -0,0 +1,251 @@
+package audience
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name            string
+		givenExpression string
+		expectCanonical string
+	}{
+		{
+			name:            "the example of the challenge",
+			givenExpression: `gender IN ("Female") AND (age BETWEEN 18 AND 24 OR social_media_hours > 5)`,
+			expectCanonical: `gender IN ("Female") AND (age BETWEEN 18 AND 24 OR social_media_hours > 5)`,
+		},
+		{
+			name:            "keywords and attributes in any case",
+			givenExpression: `Gender in ("Male","Female") and not Age between 18 and 24`,
+			expectCanonical: `gender IN ("Male", "Female") AND NOT age BETWEEN 18 AND 24`,
+		},
+		{
+			name:            "operator spellings",
+			givenExpression: `age == 30 OR age <> 31 OR age>=2.50`,
+			expectCanonical: `age = 30 OR age != 31 OR age >= 2.5`,
+		},
+		{
+			name:            "redundant parentheses",
+			givenExpression: `((age > 18)) AND ((gender = "Male" AND birth_country = "Italy"))`,
+			expectCanonical: `age > 18 AND gender = "Male" AND birth_country = "Italy"`,
+		},
+		{
+			name:            "AND binds tighter than OR",
+			givenExpression: `age > 18 OR gender = "Male" AND birth_country = "Italy"`,
+			expectCanonical: `age > 18 OR gender = "Male" AND birth_country = "Italy"`,
+		},
+		{
+			name:            "OR within AND keeps its parentheses",
+			givenExpression: `(age > 18 OR gender = "Male") AND birth_country = "Italy"`,
+			expectCanonical: `(age > 18 OR gender = "Male") AND birth_country = "Italy"`,
+		},
+		{
+			name:            "negated predicates and groups",
+			givenExpression: `birth_country NOT IN ("Italy") AND NOT (age < 18 OR age NOT BETWEEN 20 AND 30) AND NOT NOT age = 1`,
+			expectCanonical: `birth_country NOT IN ("Italy") AND NOT (age < 18 OR age NOT BETWEEN 20 AND 30) AND NOT NOT age = 1`,
+		},
+		{
+			name:            "escaped strings",
+			givenExpression: `birth_country = "Côte d\"Ivoire"`,
+			expectCanonical: `birth_country = "Côte d\"Ivoire"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Parse(tc.givenExpression)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectCanonical, got.String())
+
+			// the canonical form parses back to the same expression
+			again, err := Parse(got.String())
+			require.NoError(t, err)
+			assert.Equal(t, got.String(), again.String())
+		})
+	}
+}
+
+func TestParse_Tree(t *testing.T) {
+	t.Parallel()
+
+	got, err := Parse(`gender IN ("Female") AND (age BETWEEN 18 AND 24 OR social_media_hours > 5)`)
+	require.NoError(t, err)
+
+	and, ok := got.(*And)
+	require.True(t, ok)
+	require.Len(t, and.Exprs, 2)
+
+	in, ok := and.Exprs[0].(*In)
+	require.True(t, ok)
+	assert.Equal(t, "gender", in.Attr)
+	assert.Equal(t, []Value{"Female"}, in.Values)
+
+	or, ok := and.Exprs[1].(*Or)
+	require.True(t, ok)
+	require.Len(t, or.Exprs, 2)
+
+	between, ok := or.Exprs[0].(*Between)
+	require.True(t, ok)
+	assert.Equal(t, 18.0, between.Low)
+	assert.Equal(t, 24.0, between.High)
+
+	compare, ok := or.Exprs[1].(*Compare)
+	require.True(t, ok)
+	assert.Equal(t, OpGt, compare.Op)
+	assert.Equal(t, Value(5.0), compare.Value)
+}
+
+func TestParse_Errors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name            string
+		givenExpression string
+		expectPos       int
+		expectMsg       string
+	}{
+		{
+			name:            "empty",
+			givenExpression: ``,
+			expectPos:       1,
+			expectMsg:       "unexpected end of expression, expected an attribute, NOT or '('",
+		},
+		{
+			name:            "unknown attribute",
+			givenExpression: `age > 18 AND income > 10`,
+			expectPos:       14,
+			expectMsg:       "unknown attribute 'income'",
+		},
+		{
+			name:            "value of another type",
+			givenExpression: `gender = 1`,
+			expectPos:       10,
+			expectMsg:       "attribute 'gender' expects a string value",
+		},
+		{
+			name:            "value of another type in a list",
+			givenExpression: `age IN (18, "20")`,
+			expectPos:       13,
+			expectMsg:       "attribute 'age' expects a number value",
+		},
+		{
+			name:            "operator not allowed on type",
+			givenExpression: `gender > "F"`,
+			expectPos:       8,
+			expectMsg:       "operator '>' can't be used on string attribute 'gender'",
+		},
+		{
+			name:            "BETWEEN on strings",
+			givenExpression: `gender BETWEEN 1 AND 2`,
+			expectPos:       1,
+			expectMsg:       "BETWEEN can't be used on string attribute 'gender'",
+		},
+		{
+			name:            "inverted BETWEEN",
+			givenExpression: `age BETWEEN 30 AND 18`,
+			expectPos:       13,
+			expectMsg:       "lower bound 30 is above upper bound 18",
+		},
+		{
+			name:            "missing closing parenthesis",
+			givenExpression: `(age > 18`,
+			expectPos:       10,
+			expectMsg:       "unexpected end of expression, expected ')'",
+		},
+		{
+			name:            "dangling operator",
+			givenExpression: `age > 18 AND`,
+			expectPos:       13,
+			expectMsg:       "unexpected end of expression",
+		},
+		{
+			name:            "missing operator",
+			givenExpression: `age 18`,
+			expectPos:       5,
+			expectMsg:       "expected a comparison, IN or BETWEEN after attribute 'age'",
+		},
+		{
+			name:            "trailing tokens",
+			givenExpression: `age > 18 age < 30`,
+			expectPos:       10,
+			expectMsg:       "expected AND, OR or the end of the expression",
+		},
+		{
+			name:            "unterminated string",
+			givenExpression: `gender = "Fem`,
+			expectPos:       10,
+			expectMsg:       "unterminated string",
+		},
+		{
+			name:            "invalid escape",
+			givenExpression: `gender = "F\q"`,
+			expectPos:       10,
+			expectMsg:       "invalid string",
+		},
+		{
+			name:            "invalid number",
+			givenExpression: `age > 1.2.3`,
+			expectPos:       7,
+			expectMsg:       "invalid number 1.2.3",
+		},
+		{
+			name:            "unexpected character",
+			givenExpression: `age > 18 & age < 30`,
+			expectPos:       10,
+			expectMsg:       "unexpected character '&'",
+		},
+		{
+			name:            "NOT before a comparison",
+			givenExpression: `age NOT > 18`,
+			expectPos:       9,
+			expectMsg:       "expected IN or BETWEEN after NOT",
+		},
+		{
+			name:            "empty IN",
+			givenExpression: `gender IN ()`,
+			expectPos:       12,
+			expectMsg:       "expected a string or a number",
+		},
+		{
+			name:            "positions count characters",
+			givenExpression: `birth_country = "Côte" AND foo = 1`,
+			expectPos:       28,
+			expectMsg:       "unknown attribute 'foo'",
+		},
+		{
+			name:            "too deep",
+			givenExpression: strings.Repeat("(", maxDepth) + "age > 1" + strings.Repeat(")", maxDepth),
+			expectPos:       maxDepth + 1,
+			expectMsg:       "nested more than",
+		},
+		{
+			name:            "too long",
+			givenExpression: strings.Repeat(" ", maxExpressionLength) + "age > 1",
+			expectPos:       maxExpressionLength + 1,
+			expectMsg:       "longer than",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Parse(tc.givenExpression)
+			require.ErrorIs(t, err, ErrInvalidExpression)
+
+			var exprErr *Error
+			require.ErrorAs(t, err, &exprErr)
+			assert.Equal(t, tc.expectPos, exprErr.Pos, exprErr.Error())
+			assert.Contains(t, exprErr.Msg, tc.expectMsg)
+		})
+	}
+}
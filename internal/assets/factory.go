@@ -1,12 +1,10 @@
 package assets
 
-import (
-	"time"
+import "fmt"
 
-	"github.com/oklog/ulid/v2"
-)
-
-// AssetFactory is responsible for creating different types of assets
+// AssetFactory is responsible for creating different types of assets.
+// Assets are created through the registry, so they are validated and normalized like the assets created
+// through the API, and a *FieldError wrapping one of the asset validation errors is returned for invalid data.
 type AssetFactory struct{}
 
 // NewAssetFactory creates a new instance of AssetFactory
@@ -15,50 +13,40 @@ func NewAssetFactory() *AssetFactory {
 }
 
 // CreateChart creates a new chart asset.
-func (f *AssetFactory) CreateChart(title, xAxis, yAxis string, data []float64) ChartAsset {
-	return ChartAsset{
-		ID:        ulid.Make().String(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		Data: chart{
-			Title: title,
-			XAxis: xAxis,
-			YAxis: yAxis,
-			Data:  data,
-		},
-		assetType: TypeAssetChart,
-	}
+func (f *AssetFactory) CreateChart(title, xAxis, yAxis string, data []float64) (ChartAsset, error) {
+	return newValidAsset(TypeAssetChart, chart{
+		Title: title,
+		XAxis: xAxis,
+		YAxis: yAxis,
+		Data:  data,
+	})
 }
 
 // CreateInsight creates a new insight asset.
-func (f *AssetFactory) CreateInsight(data string) InsightAsset {
-	return InsightAsset{
-		ID:        ulid.Make().String(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		Data: insight{
-			Insight: data,
-		},
-		assetType: TypeAssetInsight,
-	}
+func (f *AssetFactory) CreateInsight(data string) (InsightAsset, error) {
+	return newValidAsset(TypeAssetInsight, insight{
+		Insight: data,
+	})
 }
 
-// CreateAudience creates a new audience asset.
+// CreateAudience creates a new audience asset. The expression is optional, see the audience package.
 func (f *AssetFactory) CreateAudience(
-	gender, birthCountry string, ageMin, ageMax, socialMediaHours, lastMonthPurchases int,
-) AudienceAsset {
-	return AudienceAsset{
-		ID:        ulid.Make().String(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		Data: audience{
-			Gender:             gender,
-			BirthCountry:       birthCountry,
-			AgeMin:             ageMin,
-			AgeMax:             ageMax,
-			SocialMediaHours:   socialMediaHours,
-			LastMonthPurchases: lastMonthPurchases,
-		},
-		assetType: TypeAssetAudience,
+	genders, birthCountries []string, ageGroups []AgeGroup, socialMediaHours, lastMonthPurchases Range, expression string,
+) (AudienceAsset, error) {
+	return newValidAsset(TypeAssetAudience, audience{
+		Genders:            genders,
+		BirthCountries:     birthCountries,
+		AgeGroups:          ageGroups,
+		SocialMediaHours:   socialMediaHours,
+		LastMonthPurchases: lastMonthPurchases,
+		Expression:         expression,
+	})
+}
+
+func newValidAsset[T any](t AssetType, data T) (Asset[T], error) {
+	k, ok := Lookup(t)
+	if !ok {
+		return Asset[T]{}, fmt.Errorf("asset type '%s' is not registered", t)
 	}
+	return k.(*kind[T]).newAsset("", data)
 }
@@ -1,9 +1,12 @@
 package assets
 
 import (
+	"math"
+	"strings"
 	"testing"
 	"time"
 
+	audienceexpr "github.com/alesr/platform-go-challenge/internal/assets/audience"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -14,12 +17,13 @@ func TestAssetFactory_CreateChart(t *testing.T) {
 	factory := NewAssetFactory()
 
 	testCases := []struct {
-		name       string
-		givenTitle string
-		givenXAxis string
-		givenYAxis string
-		givenData  []float64
-		expectErr  bool
+		name             string
+		givenTitle       string
+		givenXAxis       string
+		givenYAxis       string
+		givenData        []float64
+		expectErr        error
+		expectErrorField string
 	}{
 		{
 			name:       "valid chart creation",
@@ -29,18 +33,48 @@ func TestAssetFactory_CreateChart(t *testing.T) {
 			givenData:  []float64{100, 200, 300},
 		},
 		{
-			name:       "empty title",
-			givenTitle: "",
-			givenXAxis: "Month",
-			givenYAxis: "Revenue",
-			givenData:  []float64{100, 200, 300},
+			name:             "empty title",
+			givenTitle:       "  ",
+			givenXAxis:       "Month",
+			givenYAxis:       "Revenue",
+			givenData:        []float64{100, 200, 300},
+			expectErr:        ErrEmptyChartTitle,
+			expectErrorField: "title",
 		},
 		{
-			name:       "empty data",
-			givenTitle: "Monthly Revenue",
-			givenXAxis: "Month",
-			givenYAxis: "Revenue",
-			givenData:  []float64{},
+			name:             "empty axis label",
+			givenTitle:       "Monthly Revenue",
+			givenXAxis:       "Month",
+			givenData:        []float64{100, 200, 300},
+			expectErr:        ErrEmptyAxisLabel,
+			expectErrorField: "y_axis",
+		},
+		{
+			name:             "title too long",
+			givenTitle:       strings.Repeat("a", maxAssetStringFieldLength+1),
+			givenXAxis:       "Month",
+			givenYAxis:       "Revenue",
+			givenData:        []float64{100, 200, 300},
+			expectErr:        ErrFieldTooLong,
+			expectErrorField: "title",
+		},
+		{
+			name:             "empty data",
+			givenTitle:       "Monthly Revenue",
+			givenXAxis:       "Month",
+			givenYAxis:       "Revenue",
+			givenData:        []float64{},
+			expectErr:        ErrInvalidChartData,
+			expectErrorField: "data",
+		},
+		{
+			name:             "data not finite",
+			givenTitle:       "Monthly Revenue",
+			givenXAxis:       "Month",
+			givenYAxis:       "Revenue",
+			givenData:        []float64{100, math.NaN()},
+			expectErr:        ErrInvalidChartData,
+			expectErrorField: "data",
 		},
 	}
 
@@ -48,7 +82,18 @@ func TestAssetFactory_CreateChart(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			got := factory.CreateChart(tc.givenTitle, tc.givenXAxis, tc.givenYAxis, tc.givenData)
+			got, err := factory.CreateChart(tc.givenTitle, tc.givenXAxis, tc.givenYAxis, tc.givenData)
+
+			if tc.expectErr != nil {
+				assert.ErrorIs(t, err, tc.expectErr)
+
+				var fieldErr *FieldError
+				require.ErrorAs(t, err, &fieldErr)
+				assert.Equal(t, tc.expectErrorField, fieldErr.Field)
+				return
+			}
+
+			require.NoError(t, err)
 
 			require.NotEmpty(t, got.ID)
 			assert.Equal(t, TypeAssetChart, got.Type())
@@ -74,6 +119,7 @@ func TestAssetFactory_CreateInsight(t *testing.T) {
 	testCases := []struct {
 		givenName string
 		givenData string
+		expectErr error
 	}{
 		{
 			givenName: "valid insight creation",
@@ -82,6 +128,12 @@ func TestAssetFactory_CreateInsight(t *testing.T) {
 		{
 			givenName: "empty data",
 			givenData: "",
+			expectErr: ErrEmptyInsight,
+		},
+		{
+			givenName: "data too long",
+			givenData: strings.Repeat("a", maxInsightLength+1),
+			expectErr: ErrInsightTooLong,
 		},
 	}
 
@@ -89,7 +141,14 @@ func TestAssetFactory_CreateInsight(t *testing.T) {
 		t.Run(tc.givenName, func(t *testing.T) {
 			t.Parallel()
 
-			got := factory.CreateInsight(tc.givenData)
+			got, err := factory.CreateInsight(tc.givenData)
+
+			if tc.expectErr != nil {
+				assert.ErrorIs(t, err, tc.expectErr)
+				return
+			}
+
+			require.NoError(t, err)
 
 			require.NotEmpty(t, got.ID)
 			assert.Equal(t, TypeAssetInsight, got.Type())
@@ -109,30 +168,135 @@ func TestAssetFactory_CreateAudience(t *testing.T) {
 
 	testCases := []struct {
 		givenName               string
-		givenGender             string
-		givenBirthCountry       string
-		givenAgeMin             int
-		givenAgeMax             int
-		givenSocialMediaHours   int
-		givenLastMonthPurchases int
+		givenGenders            []string
+		givenBirthCountries     []string
+		givenAgeGroups          []AgeGroup
+		givenSocialMediaHours   Range
+		givenLastMonthPurchases Range
+		givenExpression         string
+		expectExpression        string
+		expectErr               error
+		expectErrorField        string
 	}{
 		{
 			givenName:               "valid audience creation",
-			givenGender:             "Female",
-			givenBirthCountry:       "US",
-			givenAgeMin:             25,
-			givenAgeMax:             34,
-			givenSocialMediaHours:   3,
-			givenLastMonthPurchases: 5,
-		},
-		{
-			givenName:               "zero values",
-			givenGender:             "",
-			givenBirthCountry:       "",
-			givenAgeMin:             0,
-			givenAgeMax:             0,
-			givenSocialMediaHours:   0,
-			givenLastMonthPurchases: 0,
+			givenGenders:            []string{"Female"},
+			givenBirthCountries:     []string{"US"},
+			givenAgeGroups:          []AgeGroup{AgeGroup25To34},
+			givenSocialMediaHours:   Between(3, 3),
+			givenLastMonthPurchases: Between(5, 5),
+		},
+		{
+			givenName:               "sets and open ranges",
+			givenGenders:            []string{"Female", "Male"},
+			givenBirthCountries:     []string{"US", "Brazil", "Italy"},
+			givenAgeGroups:          []AgeGroup{AgeGroup18To24, AgeGroup25To34, AgeGroup65Plus},
+			givenSocialMediaHours:   AtLeast(3),
+			givenLastMonthPurchases: AtMost(5),
+		},
+		{
+			givenName:           "expression in canonical form",
+			givenGenders:        []string{"Female"},
+			givenBirthCountries: []string{"US"},
+			givenAgeGroups:      []AgeGroup{AgeGroup18To24},
+			givenExpression:     `(age between 18 and 20) or social_media_hours>5`,
+			expectExpression:    `age BETWEEN 18 AND 20 OR social_media_hours > 5`,
+		},
+		{
+			givenName:           "invalid expression",
+			givenGenders:        []string{"Female"},
+			givenBirthCountries: []string{"US"},
+			givenAgeGroups:      []AgeGroup{AgeGroup18To24},
+			givenExpression:     `age > "18"`,
+			expectErr:           audienceexpr.ErrInvalidExpression,
+			expectErrorField:    "expression",
+		},
+		{
+			givenName:           "unbounded ranges",
+			givenGenders:        []string{"Female"},
+			givenBirthCountries: []string{"US"},
+			givenAgeGroups:      []AgeGroup{AgeGroup13To17},
+		},
+		{
+			givenName:        "zero values",
+			expectErr:        ErrEmptyGender,
+			expectErrorField: "genders",
+		},
+		{
+			givenName:        "blank gender",
+			givenGenders:     []string{"Female", " "},
+			expectErr:        ErrEmptyGender,
+			expectErrorField: "genders",
+		},
+		{
+			givenName:        "no birth countries",
+			givenGenders:     []string{"Female"},
+			expectErr:        ErrEmptyBirthCountry,
+			expectErrorField: "birth_countries",
+		},
+		{
+			givenName:           "duplicate birth countries",
+			givenGenders:        []string{"Female"},
+			givenBirthCountries: []string{"US", "Italy", "US"},
+			expectErr:           ErrDuplicateValue,
+			expectErrorField:    "birth_countries",
+		},
+		{
+			givenName:           "too many birth countries",
+			givenGenders:        []string{"Female"},
+			givenBirthCountries: strings.Split(strings.Repeat("US,", maxAudienceValues)+"US", ","),
+			expectErr:           ErrTooManyValues,
+			expectErrorField:    "birth_countries",
+		},
+		{
+			givenName:           "no age groups",
+			givenGenders:        []string{"Female"},
+			givenBirthCountries: []string{"US"},
+			expectErr:           ErrInvalidAgeGroup,
+			expectErrorField:    "age_groups",
+		},
+		{
+			givenName:           "unknown age group",
+			givenGenders:        []string{"Female"},
+			givenBirthCountries: []string{"US"},
+			givenAgeGroups:      []AgeGroup{"24-35"},
+			expectErr:           ErrInvalidAgeGroup,
+			expectErrorField:    "age_groups",
+		},
+		{
+			givenName:           "duplicate age groups",
+			givenGenders:        []string{"Female"},
+			givenBirthCountries: []string{"US"},
+			givenAgeGroups:      []AgeGroup{AgeGroup25To34, AgeGroup25To34},
+			expectErr:           ErrDuplicateValue,
+			expectErrorField:    "age_groups",
+		},
+		{
+			givenName:             "more social media hours than a day has",
+			givenGenders:          []string{"Female"},
+			givenBirthCountries:   []string{"US"},
+			givenAgeGroups:        []AgeGroup{AgeGroup25To34},
+			givenSocialMediaHours: AtLeast(25),
+			expectErr:             ErrInvalidSocialMediaHours,
+			expectErrorField:      "social_media_hours",
+		},
+		{
+			givenName:             "inverted range",
+			givenGenders:          []string{"Female"},
+			givenBirthCountries:   []string{"US"},
+			givenAgeGroups:        []AgeGroup{AgeGroup25To34},
+			givenSocialMediaHours: Between(5, 3),
+			expectErr:             ErrInvalidRange,
+			expectErrorField:      "social_media_hours",
+		},
+		{
+			givenName:               "negative purchases",
+			givenGenders:            []string{"Female"},
+			givenBirthCountries:     []string{"US"},
+			givenAgeGroups:          []AgeGroup{AgeGroup25To34},
+			givenLastMonthPurchases: AtMost(-1),
+			expectErr:               ErrInvalidPurchases,
+			expectErrorField:        "last_month_purchases",
 		},
 	}
 
@@ -140,28 +304,52 @@ func TestAssetFactory_CreateAudience(t *testing.T) {
 		t.Run(tc.givenName, func(t *testing.T) {
 			t.Parallel()
 
-			got := factory.CreateAudience(
-				tc.givenGender,
-				tc.givenBirthCountry,
-				tc.givenAgeMin,
-				tc.givenAgeMax,
+			got, err := factory.CreateAudience(
+				tc.givenGenders,
+				tc.givenBirthCountries,
+				tc.givenAgeGroups,
 				tc.givenSocialMediaHours,
 				tc.givenLastMonthPurchases,
+				tc.givenExpression,
 			)
 
+			if tc.expectErr != nil {
+				assert.ErrorIs(t, err, tc.expectErr)
+
+				var fieldErr *FieldError
+				require.ErrorAs(t, err, &fieldErr)
+				assert.Equal(t, tc.expectErrorField, fieldErr.Field)
+				return
+			}
+
+			require.NoError(t, err)
+
 			require.NotEmpty(t, got.ID)
 			assert.Equal(t, TypeAssetAudience, got.Type())
 			assert.NotZero(t, got.CreatedAt)
 			assert.NotZero(t, got.UpdatedAt)
-			assert.Equal(t, tc.givenGender, got.Data.Gender)
-			assert.Equal(t, tc.givenBirthCountry, got.Data.BirthCountry)
-			assert.Equal(t, tc.givenAgeMin, got.Data.AgeMin)
-			assert.Equal(t, tc.givenAgeMax, got.Data.AgeMax)
+			assert.Equal(t, tc.givenGenders, got.Data.Genders)
+			assert.Equal(t, tc.givenBirthCountries, got.Data.BirthCountries)
+			assert.Equal(t, tc.givenAgeGroups, got.Data.AgeGroups)
 			assert.Equal(t, tc.givenSocialMediaHours, got.Data.SocialMediaHours)
 			assert.Equal(t, tc.givenLastMonthPurchases, got.Data.LastMonthPurchases)
+			assert.Equal(t, tc.expectExpression, got.Data.Expression)
 
 			assert.WithinDuration(t, time.Now(), got.CreatedAt, time.Second)
 			assert.WithinDuration(t, time.Now(), got.UpdatedAt, time.Second)
 		})
 	}
 }
+
+func TestRange_Contains(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, Between(1, 5).Contains(1))
+	assert.True(t, Between(1, 5).Contains(5))
+	assert.False(t, Between(1, 5).Contains(6))
+	assert.True(t, AtLeast(3).Contains(24))
+	assert.False(t, AtLeast(3).Contains(2))
+	assert.True(t, AtMost(3).Contains(0))
+	assert.False(t, AtMost(3).Contains(4))
+	assert.True(t, Range{}.Contains(1000))
+}
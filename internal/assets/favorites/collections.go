@@ -0,0 +1,119 @@
+package favorites
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Collection is a named group of a user's favorites, like a folder.
+// A favorite can belong to several of the user's collections.
+type Collection struct {
+	ID            string
+	UserID        string
+	Name          string
+	FavoriteCount int
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// CollectionParams defines the information needed to create or rename a collection.
+type CollectionParams struct {
+	Name string
+}
+
+// CreateCollection creates a collection for the user.
+// Collection names are unique per user, ErrCollectionNameTaken is returned otherwise.
+func (s *Service) CreateCollection(ctx context.Context, userID string, params *CollectionParams) (*Collection, error) {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	collection, err := s.repository.CreateCollection(ctx, userID, params)
+	if err != nil {
+		return nil, fmt.Errorf("could not create collection: %w", err)
+	}
+	return collection, nil
+}
+
+// ListCollections returns all of the user's collections, by name.
+func (s *Service) ListCollections(ctx context.Context, userID string) ([]Collection, error) {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	collections, err := s.repository.ListCollections(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not list collections: %w", err)
+	}
+	return collections, nil
+}
+
+// FetchCollection returns one of the user's collections.
+// The collection must belong to the user, which will be checked by the repository.
+func (s *Service) FetchCollection(ctx context.Context, collectionID, userID string) (*Collection, error) {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	collection, err := s.repository.FetchCollection(ctx, collectionID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch collection: %w", err)
+	}
+	return collection, nil
+}
+
+// UpdateCollection renames one of the user's collections.
+func (s *Service) UpdateCollection(ctx context.Context, collectionID, userID string, params *CollectionParams) (*Collection, error) {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	collection, err := s.repository.UpdateCollection(ctx, collectionID, userID, params)
+	if err != nil {
+		return nil, fmt.Errorf("could not update collection: %w", err)
+	}
+	return collection, nil
+}
+
+// DeleteCollection deletes one of the user's collections. The favorites in it are kept.
+func (s *Service) DeleteCollection(ctx context.Context, collectionID, userID string) error {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.repository.DeleteCollection(ctx, collectionID, userID); err != nil {
+		return fmt.Errorf("could not delete collection: %w", err)
+	}
+	return nil
+}
+
+// AddToCollection adds one of the user's favorites to one of the user's collections.
+// Adding a favorite that's already in the collection does nothing.
+// It runs after the favorites the user queued before, so a favorite that was just queued can be added.
+func (s *Service) AddToCollection(ctx context.Context, collectionID, favoriteID, userID string) error {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.waitForPendingWrites(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.repository.AddToCollection(ctx, collectionID, favoriteID, userID); err != nil {
+		return fmt.Errorf("could not add favorite to collection: %w", err)
+	}
+	return nil
+}
+
+// RemoveFromCollection removes a favorite from one of the user's collections.
+// The favorite itself is kept.
+func (s *Service) RemoveFromCollection(ctx context.Context, collectionID, favoriteID, userID string) error {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.repository.RemoveFromCollection(ctx, collectionID, favoriteID, userID); err != nil {
+		return fmt.Errorf("could not remove favorite from collection: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package favorites
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alesr/platform-go-challenge/internal/pkg/logutil"
+	"github.com/alesr/platform-go-challenge/internal/users"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_CreateCollection(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+	givenParams := CollectionParams{Name: "Quarterly review"}
+
+	testCases := []struct {
+		name                 string
+		givenFetchUserResult func() (*users.User, error)
+		givenCreateErr       error
+		expectRepoCalled     bool
+		expectedError        error
+	}{
+		{
+			name: "success",
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
+			},
+			expectRepoCalled: true,
+		},
+		{
+			name: "user not found",
+			givenFetchUserResult: func() (*users.User, error) {
+				return nil, users.ErrUserNotFound
+			},
+			expectedError: users.ErrUserNotFound,
+		},
+		{
+			name: "name taken",
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
+			},
+			givenCreateErr:   ErrCollectionNameTaken,
+			expectRepoCalled: true,
+			expectedError:    ErrCollectionNameTaken,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var repoCalled bool
+
+			userSvc := userSvcMock{
+				fetchUserFunc: func(ctx context.Context, id string) (*users.User, error) {
+					assert.Equal(t, userID, id)
+					return tc.givenFetchUserResult()
+				},
+			}
+
+			repo := repoMock{
+				createCollectionFunc: func(ctx context.Context, uID string, params *CollectionParams) (*Collection, error) {
+					repoCalled = true
+					assert.Equal(t, userID, uID)
+					assert.Equal(t, &givenParams, params)
+					if tc.givenCreateErr != nil {
+						return nil, tc.givenCreateErr
+					}
+					return &Collection{ID: "col-1", UserID: uID, Name: params.Name}, nil
+				},
+			}
+
+			svc := NewService(logutil.NewNoop(), &repo, &userSvc)
+
+			collection, err := svc.CreateCollection(context.TODO(), userID, &givenParams)
+
+			assert.Equal(t, tc.expectRepoCalled, repoCalled)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "col-1", collection.ID)
+			assert.Equal(t, givenParams.Name, collection.Name)
+		})
+	}
+}
+
+func TestService_AddToCollection(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+
+	testCases := []struct {
+		name                 string
+		givenFetchUserResult func() (*users.User, error)
+		givenAddErr          error
+		expectRepoCalled     bool
+		expectedError        error
+	}{
+		{
+			name: "success",
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
+			},
+			expectRepoCalled: true,
+		},
+		{
+			name: "user not found",
+			givenFetchUserResult: func() (*users.User, error) {
+				return nil, users.ErrUserNotFound
+			},
+			expectedError: users.ErrUserNotFound,
+		},
+		{
+			name: "collection not found",
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
+			},
+			givenAddErr:      ErrCollectionNotFound,
+			expectRepoCalled: true,
+			expectedError:    ErrCollectionNotFound,
+		},
+		{
+			name: "favorite not found",
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
+			},
+			givenAddErr:      ErrFavoriteAssetNotFound,
+			expectRepoCalled: true,
+			expectedError:    ErrFavoriteAssetNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var repoCalled bool
+
+			userSvc := userSvcMock{
+				fetchUserFunc: func(ctx context.Context, id string) (*users.User, error) {
+					return tc.givenFetchUserResult()
+				},
+			}
+
+			repo := repoMock{
+				latestPendingFavoriteJobIDFunc: func(ctx context.Context, id string) (string, error) {
+					return "", nil
+				},
+				addToCollectionFunc: func(ctx context.Context, collectionID, favoriteID, uID string) error {
+					repoCalled = true
+					assert.Equal(t, "col-1", collectionID)
+					assert.Equal(t, "fav-1", favoriteID)
+					assert.Equal(t, userID, uID)
+					return tc.givenAddErr
+				},
+			}
+
+			svc := NewService(logutil.NewNoop(), &repo, &userSvc)
+
+			err := svc.AddToCollection(context.TODO(), "col-1", "fav-1", userID)
+
+			assert.Equal(t, tc.expectRepoCalled, repoCalled)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestService_FetchUserFavorites_Collection(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+
+	testCases := []struct {
+		name                string
+		givenFetchCollErr   error
+		expectListingCalled bool
+		expectedError       error
+	}{
+		{
+			name:                "collection found",
+			expectListingCalled: true,
+		},
+		{
+			name:              "collection not found",
+			givenFetchCollErr: ErrCollectionNotFound,
+			expectedError:     ErrCollectionNotFound,
+		},
+		{
+			name:              "repository error",
+			givenFetchCollErr: errors.New("connection refused"),
+			expectedError:     errors.New("connection refused"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var listingCalled bool
+
+			userSvc := userSvcMock{
+				fetchUserFunc: func(ctx context.Context, id string) (*users.User, error) {
+					return &users.User{}, nil
+				},
+			}
+
+			repo := repoMock{
+				fetchCollectionFunc: func(ctx context.Context, collectionID, uID string) (*Collection, error) {
+					assert.Equal(t, "col-1", collectionID)
+					assert.Equal(t, userID, uID)
+					if tc.givenFetchCollErr != nil {
+						return nil, tc.givenFetchCollErr
+					}
+					return &Collection{ID: collectionID, UserID: uID}, nil
+				},
+				getuserfavoritesFunc: func(ctx context.Context, uID string, params *ListFavoritesParams) ([]FavoriteAsset, error) {
+					listingCalled = true
+					assert.Equal(t, "col-1", params.CollectionID)
+					return []FavoriteAsset{{ID: "fav-1"}}, nil
+				},
+			}
+
+			svc := NewService(logutil.NewNoop(), &repo, &userSvc)
+
+			favs, _, err := svc.FetchUserFavorites(context.TODO(), userID, &ListFavoritesParams{
+				PageSize:     10,
+				CollectionID: "col-1",
+			})
+
+			assert.Equal(t, tc.expectListingCalled, listingCalled)
+			if tc.expectedError != nil {
+				require.Error(t, err)
+				if errors.Is(tc.expectedError, ErrCollectionNotFound) {
+					assert.ErrorIs(t, err, tc.expectedError)
+				} else {
+					assert.Contains(t, err.Error(), tc.expectedError.Error())
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, favs, 1)
+		})
+	}
+}
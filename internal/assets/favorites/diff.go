@@ -0,0 +1,96 @@
+package favorites
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/alesr/platform-go-challenge/internal/assets"
+)
+
+// AssetSnapshot is the data of an asset at some point in time,
+// keyed like the asset's data in the API, as in "title" or "birth_country".
+// Values are the ones decoded from JSON, so numbers are float64 and lists []any.
+type AssetSnapshot map[string]any
+
+// FavoriteSnapshots pairs the snapshot of the asset a favorite was favorited with
+// with a snapshot of the asset as it is now.
+type FavoriteSnapshots struct {
+	FavoriteID     string
+	AssetID        string
+	AssetType      assets.AssetType
+	FavoritedAt    time.Time
+	AssetUpdatedAt time.Time
+	Favorited      AssetSnapshot
+	Current        AssetSnapshot
+}
+
+// FieldChange is a field of an asset's data that changed since the asset was favorited.
+// Favorited or Current is nil when the field was added or removed.
+type FieldChange struct {
+	Field     string
+	Favorited any
+	Current   any
+}
+
+// FavoriteDiff describes how the asset of a favorite changed since it was favorited.
+type FavoriteDiff struct {
+	FavoriteID     string
+	AssetID        string
+	AssetType      assets.AssetType
+	FavoritedAt    time.Time
+	AssetUpdatedAt time.Time
+	// Changes are ordered by field, and empty when the asset didn't change.
+	Changes []FieldChange
+}
+
+// FetchFavoriteDiff compares the asset of one of the user's favorites
+// with the asset as it was when the user favorited it.
+// The favorite must belong to the user, which will be checked by the repository.
+func (s *Service) FetchFavoriteDiff(ctx context.Context, favoriteID, userID string) (*FavoriteDiff, error) {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	snapshots, err := s.repository.FetchFavoriteSnapshots(ctx, favoriteID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch favorite snapshots: %w", err)
+	}
+
+	return &FavoriteDiff{
+		FavoriteID:     snapshots.FavoriteID,
+		AssetID:        snapshots.AssetID,
+		AssetType:      snapshots.AssetType,
+		FavoritedAt:    snapshots.FavoritedAt,
+		AssetUpdatedAt: snapshots.AssetUpdatedAt,
+		Changes:        diffSnapshots(snapshots.Favorited, snapshots.Current),
+	}, nil
+}
+
+// diffSnapshots returns the fields whose values differ between two snapshots, ordered by field.
+func diffSnapshots(favorited, current AssetSnapshot) []FieldChange {
+	fields := make([]string, 0, len(current))
+	for field := range favorited {
+		fields = append(fields, field)
+	}
+	for field := range current {
+		if _, ok := favorited[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	slices.Sort(fields)
+
+	changes := []FieldChange{}
+	for _, field := range fields {
+		if !reflect.DeepEqual(favorited[field], current[field]) {
+			changes = append(changes, FieldChange{
+				Field:     field,
+				Favorited: favorited[field],
+				Current:   current[field],
+			})
+		}
+	}
+	return changes
+}
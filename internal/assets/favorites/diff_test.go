@@ -0,0 +1,136 @@
+package favorites
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alesr/platform-go-challenge/internal/pkg/logutil"
+	"github.com/alesr/platform-go-challenge/internal/users"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	t.Parallel()
+
+	chart := AssetSnapshot{"title": "Sales", "x_axis": "Month", "y_axis": "Revenue", "data": []any{1.0, 2.0}}
+
+	testCases := []struct {
+		name           string
+		givenFavorited AssetSnapshot
+		givenCurrent   AssetSnapshot
+		expected       []FieldChange
+	}{
+		{
+			name:           "unchanged",
+			givenFavorited: chart,
+			givenCurrent:   AssetSnapshot{"title": "Sales", "x_axis": "Month", "y_axis": "Revenue", "data": []any{1.0, 2.0}},
+			expected:       []FieldChange{},
+		},
+		{
+			name:           "changed fields in order",
+			givenFavorited: chart,
+			givenCurrent:   AssetSnapshot{"title": "Revenue", "x_axis": "Month", "y_axis": "Revenue", "data": []any{1.0, 3.0}},
+			expected: []FieldChange{
+				{Field: "data", Favorited: []any{1.0, 2.0}, Current: []any{1.0, 3.0}},
+				{Field: "title", Favorited: "Sales", Current: "Revenue"},
+			},
+		},
+		{
+			name:           "added and removed fields",
+			givenFavorited: AssetSnapshot{"age_min": 18.0, "gender": "F"},
+			givenCurrent:   AssetSnapshot{"age_min": 18.0, "age_max": 24.0},
+			expected: []FieldChange{
+				{Field: "age_max", Current: 24.0},
+				{Field: "gender", Favorited: "F"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, diffSnapshots(tc.givenFavorited, tc.givenCurrent))
+		})
+	}
+}
+
+func TestService_FetchFavoriteDiff(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+
+	testCases := []struct {
+		name             string
+		givenUserErr     error
+		givenRepoErr     error
+		expectRepoCalled bool
+		expectedError    error
+	}{
+		{
+			name:             "success",
+			expectRepoCalled: true,
+		},
+		{
+			name:          "user not found",
+			givenUserErr:  users.ErrUserNotFound,
+			expectedError: users.ErrUserNotFound,
+		},
+		{
+			name:             "favorite not found",
+			givenRepoErr:     ErrFavoriteAssetNotFound,
+			expectRepoCalled: true,
+			expectedError:    ErrFavoriteAssetNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var repoCalled bool
+
+			userSvc := userSvcMock{
+				fetchUserFunc: func(ctx context.Context, id string) (*users.User, error) {
+					assert.Equal(t, userID, id)
+					if tc.givenUserErr != nil {
+						return nil, tc.givenUserErr
+					}
+					return &users.User{}, nil
+				},
+			}
+
+			repo := repoMock{
+				fetchFavoriteSnapshotsFunc: func(ctx context.Context, favoriteID, uID string) (*FavoriteSnapshots, error) {
+					repoCalled = true
+					assert.Equal(t, "fav-1", favoriteID)
+					assert.Equal(t, userID, uID)
+					if tc.givenRepoErr != nil {
+						return nil, tc.givenRepoErr
+					}
+					return &FavoriteSnapshots{
+						FavoriteID: favoriteID,
+						AssetID:    "asset-1",
+						Favorited:  AssetSnapshot{"insight": "Sales are up"},
+						Current:    AssetSnapshot{"insight": "Sales are down"},
+					}, nil
+				},
+			}
+
+			svc := NewService(logutil.NewNoop(), &repo, &userSvc)
+
+			diff, err := svc.FetchFavoriteDiff(context.TODO(), "fav-1", userID)
+
+			assert.Equal(t, tc.expectRepoCalled, repoCalled)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "asset-1", diff.AssetID)
+			assert.Equal(t, []FieldChange{{Field: "insight", Favorited: "Sales are up", Current: "Sales are down"}}, diff.Changes)
+		})
+	}
+}
@@ -1,6 +1,11 @@
 package favorites
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/alesr/platform-go-challenge/internal/assets"
@@ -13,8 +18,25 @@ type FavoriteAsset struct {
 	AssetID     string
 	AssetType   assets.AssetType
 	Description string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	Tags        []string
+	// Position is the rank key the user's favorites are ordered by, see rankutil.
+	Position  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	// Asset is the favorited asset itself. It's only populated
+	// when listing favorites and nil otherwise.
+	Asset assets.Asseter
+	// ChangedSinceFavorited tells whether the asset's data changed since it was favorited,
+	// see FavoriteDiff. Like Asset, it's only set when listing favorites.
+	ChangedSinceFavorited bool
+
+	// SearchRank is how well the favorite matches the search query
+	// it was listed with, see ListFavoritesParams.Query.
+	SearchRank float32
+	// Frecency ranks the favorite by how often and how recently the user opened it, see SortFrecency.
+	// It's only meaningful compared with the frecency of other favorites.
+	Frecency float64
 }
 
 // FavoriteAssetParams defines the information needed to mark an asset as favorite.
@@ -24,8 +46,194 @@ type FavoriteAssetParams struct {
 	Description string
 }
 
+// FavoriteOpType is the kind of change a FavoriteOp makes to a user's favorites.
+type FavoriteOpType string
+
+const (
+	// Enumerate favorite op types
+
+	FavoriteOpAdd    FavoriteOpType = "add"
+	FavoriteOpRemove FavoriteOpType = "remove"
+	FavoriteOpUpdate FavoriteOpType = "update"
+)
+
+// FavoriteOp is a single change in a batch of changes to a user's favorites.
+// Favorites are identified by their asset, since users can only favorite an asset once.
+type FavoriteOp struct {
+	Type        FavoriteOpType
+	AssetID     string
+	Description string // for adds and updates
+}
+
+// JobStatus represents the processing status of an asynchronous favorite job.
+type JobStatus string
+
+const (
+	// Enumerate job statuses
+
+	JobStatusPending   JobStatus = "PENDING"
+	JobStatusSucceeded JobStatus = "SUCCEEDED"
+	JobStatusFailed    JobStatus = "FAILED"
+)
+
+// FavoriteJob tracks the asynchronous processing of a request to favorite an asset.
+type FavoriteJob struct {
+	ID          string
+	UserID      string
+	AssetID     string
+	Description string
+	Status      JobStatus
+	Attempts    int   // how many times the job was picked up for processing
+	Err         error // set when the job failed
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// QueueStats describes the state of the favorites queue.
+// Pending jobs are counted across all consumers, while the buffer
+// and rejection figures are specific to the process reporting them.
+type QueueStats struct {
+	PendingJobs    int64
+	MaxPendingJobs int
+	BufferedTasks  int
+	BufferCapacity int
+	RejectedJobs   int64
+}
+
+// DeadLetter is a favorite job that failed for good.
+// It keeps everything needed to replay the job once the cause is sorted out.
+type DeadLetter struct {
+	ID          string
+	JobID       string
+	UserID      string
+	AssetID     string
+	Description string
+	Attempts    int
+	LastError   string
+	CreatedAt   time.Time
+}
+
+// ListDeadLettersParams defines pagination parameters for listing dead letters.
+type ListDeadLettersParams struct {
+	PageSize int
+	// PageToken is the ID of the last dead letter of the previous page.
+	PageToken string
+}
+
 // UpdateFavoriteParams defines the information needed
 // to update an existing asset marked as favorite.
 type UpdateFavoriteParams struct {
 	Description string
+	// Tags replace the favorite's tags. Nil leaves them as they are.
+	Tags []string
+}
+
+// MoveFavoriteParams defines where to move a favorite to in the user's list.
+type MoveFavoriteParams struct {
+	// BeforeID is the favorite to move the favorite before.
+	// Empty moves the favorite to the end of the list.
+	BeforeID string
+}
+
+// FavoriteSort is the order user favorites are listed in.
+type FavoriteSort string
+
+const (
+	// Enumerate favorite sorts
+
+	// SortPosition lists favorites in the order the user arranged them, new favorites first.
+	SortPosition FavoriteSort = "position"
+	// SortFrecency lists the favorites the user opened most often and most recently first,
+	// see Service.RecordFavoriteOpen. Favorites never opened follow, by position.
+	SortFrecency FavoriteSort = "frecency"
+)
+
+// ListFavoritesParams defines the parameters for listing user favorites.
+type ListFavoritesParams struct {
+	PageSize int
+	// Cursor points to the last favorite of the previous page.
+	// A nil cursor means the first page.
+	Cursor *Cursor
+	// WaitForPendingWrites makes the listing wait for the favorites
+	// the user has queued, so they are part of the result.
+	WaitForPendingWrites bool
+	// CollectionID limits the listing to the favorites in one of the user's collections.
+	// An empty ID lists all of the user's favorites.
+	CollectionID string
+	// Rule limits the listing to the favorites matching it, see SmartCollection.
+	// It must be valid, see Rule.Validate.
+	Rule *Rule
+	// Sort is the order the favorites are listed in. Empty means SortPosition.
+	Sort FavoriteSort
+	// Query is a full-text search over the favorites' descriptions and tags.
+	// When set, only the matching favorites are listed, best matches first, then in Sort order.
+	Query string
+	// AssetTypes limits the listing to the favorites of assets of these types.
+	// No types lists the favorites of all types.
+	AssetTypes []assets.AssetType
+	// CreatedAfter, CreatedBefore, UpdatedAfter and UpdatedBefore limit the listing to
+	// the favorites created or updated in a time range, including its start but not its end.
+	// Zero times leave the range open on that side.
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+}
+
+// Cursor is a keyset position in the list of user favorites,
+// which is ordered by position and then by favorite ID.
+// Search results are ordered by search rank first, which SearchRank holds,
+// and favorites sorted by frecency by frecency before position, which Frecency holds.
+type Cursor struct {
+	Position   string
+	ID         string
+	SearchRank *float32
+	Frecency   *float64
+}
+
+// Encode returns the cursor as an opaque page token.
+func (c Cursor) Encode() string {
+	parts := []string{c.Position, c.ID}
+	if c.SearchRank != nil || c.Frecency != nil {
+		var rank string
+		if c.SearchRank != nil {
+			rank = strconv.FormatFloat(float64(*c.SearchRank), 'g', -1, 32)
+		}
+		parts = append(parts, rank)
+	}
+	if c.Frecency != nil {
+		parts = append(parts, strconv.FormatFloat(*c.Frecency, 'g', -1, 64))
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(strings.Join(parts, "|")))
+}
+
+// DecodeCursor parses a page token created by Cursor.Encode.
+func DecodeCursor(token string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode page token: %w", err)
+	}
+
+	parts := strings.Split(string(raw), "|")
+	if len(parts) < 2 || len(parts) > 4 || parts[0] == "" || parts[1] == "" {
+		return nil, errors.New("malformed page token")
+	}
+
+	cursor := Cursor{Position: parts[0], ID: parts[1]}
+	if len(parts) > 2 && (parts[2] != "" || len(parts) == 3) {
+		rank, err := strconv.ParseFloat(parts[2], 32)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse search rank: %w", err)
+		}
+		searchRank := float32(rank)
+		cursor.SearchRank = &searchRank
+	}
+	if len(parts) == 4 {
+		frecency, err := strconv.ParseFloat(parts[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse frecency: %w", err)
+		}
+		cursor.Frecency = &frecency
+	}
+	return &cursor, nil
 }
@@ -0,0 +1,41 @@
+package favorites
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/alesr/platform-go-challenge/internal/assets"
+)
+
+// How long it takes for the weight of an open to halve in the frecency of a favorite.
+// An asset opened every day for a week is ranked below one opened once today
+// if the week ended more than about three half-lives ago.
+const frecencyHalfLife = 14 * 24 * time.Hour
+
+// frecencyWeight returns the weight of an open at t, in log space, where the repository adds it to a favorite's frecency.
+// Rather than decaying every favorite's score as time goes by, later opens weigh exponentially more,
+// which orders favorites the same way: the score of opens at t1..tn is Σ 2^((ti-now)/halfLife), and
+// the frecency, ln Σ 2^(ti/halfLife), only differs from its log by a term every favorite shares at a given time.
+func frecencyWeight(t time.Time, halfLife time.Duration) float64 {
+	return float64(t.UnixMilli()) / float64(halfLife.Milliseconds()) * math.Ln2
+}
+
+// RecordFavoriteOpen records that the user opened one of their favorites, which ranks it higher
+// when favorites are sorted by frecency, see SortFrecency.
+// The favorite must belong to the user, which will be checked by the repository.
+func (s *Service) RecordFavoriteOpen(ctx context.Context, favoriteID, userID string) error {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return err
+	}
+
+	// Detach context to prevent cancellation while writing data.
+	ctx, cancel := context.WithTimeout(context.Background(), assets.BackgroundCtxTimeout)
+	defer cancel()
+
+	if err := s.repository.RecordFavoriteOpen(ctx, favoriteID, userID, frecencyWeight(time.Now(), frecencyHalfLife)); err != nil {
+		return fmt.Errorf("could not record favorite open: %w", err)
+	}
+	return nil
+}
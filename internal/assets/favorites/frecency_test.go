@@ -0,0 +1,112 @@
+package favorites
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/alesr/platform-go-challenge/internal/pkg/logutil"
+	"github.com/alesr/platform-go-challenge/internal/users"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrecencyWeight(t *testing.T) {
+	t.Parallel()
+
+	// logAddExp adds weights in log space, as the repository does
+	logAddExp := func(a, b float64) float64 {
+		return math.Max(a, b) + math.Log1p(math.Exp(-math.Abs(a-b)))
+	}
+
+	now := time.Now()
+	halfLife := 24 * time.Hour
+	weight := func(ago time.Duration) float64 {
+		return frecencyWeight(now.Add(-ago), halfLife)
+	}
+
+	// an open weighs twice as much as one a half-life before
+	assert.InDelta(t, math.Ln2, weight(0)-weight(halfLife), 1e-9)
+
+	// two opens a half-life ago weigh as much as one now
+	assert.InDelta(t, weight(0), logAddExp(weight(halfLife), weight(halfLife)), 1e-9)
+
+	// frequent opens beat a recent one until they are old enough
+	frequent := math.Inf(-1)
+	for day := range 7 {
+		frequent = logAddExp(frequent, weight(time.Duration(day)*halfLife))
+	}
+	assert.Greater(t, frequent, weight(0))
+	assert.Less(t, frequent-3*math.Ln2, weight(0))
+}
+
+func TestService_RecordFavoriteOpen(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+
+	testCases := []struct {
+		name             string
+		givenUserErr     error
+		givenRepoErr     error
+		expectRepoCalled bool
+		expectedError    error
+	}{
+		{
+			name:             "success",
+			expectRepoCalled: true,
+		},
+		{
+			name:          "user not found",
+			givenUserErr:  users.ErrUserNotFound,
+			expectedError: users.ErrUserNotFound,
+		},
+		{
+			name:             "favorite not found",
+			givenRepoErr:     ErrFavoriteAssetNotFound,
+			expectRepoCalled: true,
+			expectedError:    ErrFavoriteAssetNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var repoCalled bool
+
+			userSvc := userSvcMock{
+				fetchUserFunc: func(ctx context.Context, id string) (*users.User, error) {
+					assert.Equal(t, userID, id)
+					if tc.givenUserErr != nil {
+						return nil, tc.givenUserErr
+					}
+					return &users.User{}, nil
+				},
+			}
+
+			repo := repoMock{
+				recordFavoriteOpenFunc: func(ctx context.Context, favID, uID string, weight float64) error {
+					repoCalled = true
+					assert.Equal(t, "fav-1", favID)
+					assert.Equal(t, userID, uID)
+					assert.InDelta(t, frecencyWeight(time.Now(), frecencyHalfLife), weight, 1e-3)
+					return tc.givenRepoErr
+				},
+			}
+
+			svc := NewService(logutil.NewNoop(), &repo, &userSvc)
+
+			err := svc.RecordFavoriteOpen(context.TODO(), "fav-1", userID)
+
+			assert.Equal(t, tc.expectRepoCalled, repoCalled)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
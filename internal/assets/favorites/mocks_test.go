@@ -2,6 +2,7 @@ package favorites
 
 import (
 	"context"
+	"time"
 
 	"github.com/alesr/platform-go-challenge/internal/users"
 )
@@ -11,18 +12,107 @@ import (
 var _ Repository = &repoMock{}
 
 type repoMock struct {
-	storeFavoriteAssetFunc func(ctx context.Context, params *FavoriteAssetParams) error
-	getuserfavoritesFunc   func(ctx context.Context, userID string) ([]FavoriteAsset, error)
-	updatefavoriteFunc     func(ctx context.Context, favID, userID string, params *UpdateFavoriteParams) (*FavoriteAsset, error)
-	deleteFavoriteFunc     func(ctx context.Context, favoriteID, userID string) error
+	enqueueFavoriteJobFunc         func(ctx context.Context, params *FavoriteAssetParams, maxPending int) (*FavoriteJob, error)
+	countPendingFavoriteJobsFunc   func(ctx context.Context) (int64, error)
+	claimFavoriteJobsFunc          func(ctx context.Context, limit int, lease time.Duration) ([]FavoriteJob, error)
+	completeFavoriteJobFunc        func(ctx context.Context, jobID string, attempt int) error
+	retryFavoriteJobFunc           func(ctx context.Context, jobID string, attempt int, runAt time.Time, jobErr error) error
+	deadLetterFavoriteJobFunc      func(ctx context.Context, jobID string, attempt int, jobErr error) error
+	releaseFavoriteJobsFunc        func(ctx context.Context, jobIDs []string) error
+	fetchFavoriteJobFunc           func(ctx context.Context, jobID string) (*FavoriteJob, error)
+	latestPendingFavoriteJobIDFunc func(ctx context.Context, userID string) (string, error)
+	purgeFavoriteJobsFunc          func(ctx context.Context, before time.Time) (int64, error)
+	listDeadLettersFunc            func(ctx context.Context, params *ListDeadLettersParams) ([]DeadLetter, error)
+	replayDeadLetterFunc           func(ctx context.Context, id string) (*FavoriteJob, error)
+	storeFavoriteAssetsFunc        func(ctx context.Context, params []*FavoriteAssetParams) ([]error, error)
+	applyFavoriteOpsFunc           func(ctx context.Context, userID string, ops []FavoriteOp, atomic bool) ([]error, error)
+	getuserfavoritesFunc           func(ctx context.Context, userID string, params *ListFavoritesParams) ([]FavoriteAsset, error)
+	fetchFavoriteSnapshotsFunc     func(ctx context.Context, favoriteID, userID string) (*FavoriteSnapshots, error)
+	updatefavoriteFunc             func(ctx context.Context, favID, userID string, params *UpdateFavoriteParams) (*FavoriteAsset, error)
+	deleteFavoriteFunc             func(ctx context.Context, favoriteID, userID string) error
+	deleteFavoriteByAssetFunc      func(ctx context.Context, assetID, userID string) error
+	deleteUserFavoritesFunc        func(ctx context.Context, userID string) (int64, error)
+	getTrashedFavoritesFunc        func(ctx context.Context, userID string, params *ListTrashParams, deletedAfter time.Time) ([]TrashedFavorite, error)
+	restoreFavoriteFunc            func(ctx context.Context, favoriteID, userID string, deletedAfter time.Time) (*FavoriteAsset, error)
+	recordFavoriteOpenFunc         func(ctx context.Context, favoriteID, userID string, weight float64) error
+	purgeTrashedFavoritesFunc      func(ctx context.Context, before time.Time) (int64, error)
+	reorderFavoritesFunc           func(ctx context.Context, userID string, favoriteIDs []string) error
+	moveFavoriteFunc               func(ctx context.Context, favoriteID, userID string, params *MoveFavoriteParams) error
+	createCollectionFunc           func(ctx context.Context, userID string, params *CollectionParams) (*Collection, error)
+	listCollectionsFunc            func(ctx context.Context, userID string) ([]Collection, error)
+	fetchCollectionFunc            func(ctx context.Context, collectionID, userID string) (*Collection, error)
+	updateCollectionFunc           func(ctx context.Context, collectionID, userID string, params *CollectionParams) (*Collection, error)
+	deleteCollectionFunc           func(ctx context.Context, collectionID, userID string) error
+	addToCollectionFunc            func(ctx context.Context, collectionID, favoriteID, userID string) error
+	removeFromCollectionFunc       func(ctx context.Context, collectionID, favoriteID, userID string) error
+	createSmartCollectionFunc      func(ctx context.Context, userID string, params *SmartCollectionParams) (*SmartCollection, error)
+	listSmartCollectionsFunc       func(ctx context.Context, userID string) ([]SmartCollection, error)
+	fetchSmartCollectionFunc       func(ctx context.Context, collectionID, userID string) (*SmartCollection, error)
+	deleteSmartCollectionFunc      func(ctx context.Context, collectionID, userID string) error
 }
 
-func (m *repoMock) StoreFavoriteAsset(ctx context.Context, params *FavoriteAssetParams) error {
-	return m.storeFavoriteAssetFunc(ctx, params)
+func (m *repoMock) EnqueueFavoriteJob(ctx context.Context, params *FavoriteAssetParams, maxPending int) (*FavoriteJob, error) {
+	return m.enqueueFavoriteJobFunc(ctx, params, maxPending)
 }
 
-func (m *repoMock) GetUserFavorites(ctx context.Context, userID string) ([]FavoriteAsset, error) {
-	return m.getuserfavoritesFunc(ctx, userID)
+func (m *repoMock) CountPendingFavoriteJobs(ctx context.Context) (int64, error) {
+	return m.countPendingFavoriteJobsFunc(ctx)
+}
+
+func (m *repoMock) ClaimFavoriteJobs(ctx context.Context, limit int, lease time.Duration) ([]FavoriteJob, error) {
+	return m.claimFavoriteJobsFunc(ctx, limit, lease)
+}
+
+func (m *repoMock) CompleteFavoriteJob(ctx context.Context, jobID string, attempt int) error {
+	return m.completeFavoriteJobFunc(ctx, jobID, attempt)
+}
+
+func (m *repoMock) RetryFavoriteJob(ctx context.Context, jobID string, attempt int, runAt time.Time, jobErr error) error {
+	return m.retryFavoriteJobFunc(ctx, jobID, attempt, runAt, jobErr)
+}
+
+func (m *repoMock) DeadLetterFavoriteJob(ctx context.Context, jobID string, attempt int, jobErr error) error {
+	return m.deadLetterFavoriteJobFunc(ctx, jobID, attempt, jobErr)
+}
+
+func (m *repoMock) ReleaseFavoriteJobs(ctx context.Context, jobIDs []string) error {
+	return m.releaseFavoriteJobsFunc(ctx, jobIDs)
+}
+
+func (m *repoMock) FetchFavoriteJob(ctx context.Context, jobID string) (*FavoriteJob, error) {
+	return m.fetchFavoriteJobFunc(ctx, jobID)
+}
+
+func (m *repoMock) LatestPendingFavoriteJobID(ctx context.Context, userID string) (string, error) {
+	return m.latestPendingFavoriteJobIDFunc(ctx, userID)
+}
+
+func (m *repoMock) PurgeFavoriteJobs(ctx context.Context, before time.Time) (int64, error) {
+	return m.purgeFavoriteJobsFunc(ctx, before)
+}
+
+func (m *repoMock) ListDeadLetters(ctx context.Context, params *ListDeadLettersParams) ([]DeadLetter, error) {
+	return m.listDeadLettersFunc(ctx, params)
+}
+
+func (m *repoMock) ReplayDeadLetter(ctx context.Context, id string) (*FavoriteJob, error) {
+	return m.replayDeadLetterFunc(ctx, id)
+}
+
+func (m *repoMock) StoreFavoriteAssets(ctx context.Context, params []*FavoriteAssetParams) ([]error, error) {
+	return m.storeFavoriteAssetsFunc(ctx, params)
+}
+
+func (m *repoMock) ApplyFavoriteOps(ctx context.Context, userID string, ops []FavoriteOp, atomic bool) ([]error, error) {
+	return m.applyFavoriteOpsFunc(ctx, userID, ops, atomic)
+}
+
+func (m *repoMock) GetUserFavorites(ctx context.Context, userID string, params *ListFavoritesParams) ([]FavoriteAsset, error) {
+	return m.getuserfavoritesFunc(ctx, userID, params)
+}
+
+func (m *repoMock) FetchFavoriteSnapshots(ctx context.Context, favoriteID, userID string) (*FavoriteSnapshots, error) {
+	return m.fetchFavoriteSnapshotsFunc(ctx, favoriteID, userID)
 }
 
 func (m *repoMock) UpdateFavorite(ctx context.Context, favID, userID string, params *UpdateFavoriteParams) (*FavoriteAsset, error) {
@@ -33,6 +123,82 @@ func (m *repoMock) DeleteFavorite(ctx context.Context, favoriteID, userID string
 	return m.deleteFavoriteFunc(ctx, favoriteID, userID)
 }
 
+func (m *repoMock) DeleteFavoriteByAsset(ctx context.Context, assetID, userID string) error {
+	return m.deleteFavoriteByAssetFunc(ctx, assetID, userID)
+}
+
+func (m *repoMock) DeleteUserFavorites(ctx context.Context, userID string) (int64, error) {
+	return m.deleteUserFavoritesFunc(ctx, userID)
+}
+
+func (m *repoMock) GetTrashedFavorites(ctx context.Context, userID string, params *ListTrashParams, deletedAfter time.Time) ([]TrashedFavorite, error) {
+	return m.getTrashedFavoritesFunc(ctx, userID, params, deletedAfter)
+}
+
+func (m *repoMock) RestoreFavorite(ctx context.Context, favoriteID, userID string, deletedAfter time.Time) (*FavoriteAsset, error) {
+	return m.restoreFavoriteFunc(ctx, favoriteID, userID, deletedAfter)
+}
+
+func (m *repoMock) RecordFavoriteOpen(ctx context.Context, favoriteID, userID string, weight float64) error {
+	return m.recordFavoriteOpenFunc(ctx, favoriteID, userID, weight)
+}
+
+func (m *repoMock) PurgeTrashedFavorites(ctx context.Context, before time.Time) (int64, error) {
+	return m.purgeTrashedFavoritesFunc(ctx, before)
+}
+
+func (m *repoMock) ReorderFavorites(ctx context.Context, userID string, favoriteIDs []string) error {
+	return m.reorderFavoritesFunc(ctx, userID, favoriteIDs)
+}
+
+func (m *repoMock) MoveFavorite(ctx context.Context, favoriteID, userID string, params *MoveFavoriteParams) error {
+	return m.moveFavoriteFunc(ctx, favoriteID, userID, params)
+}
+
+func (m *repoMock) CreateCollection(ctx context.Context, userID string, params *CollectionParams) (*Collection, error) {
+	return m.createCollectionFunc(ctx, userID, params)
+}
+
+func (m *repoMock) ListCollections(ctx context.Context, userID string) ([]Collection, error) {
+	return m.listCollectionsFunc(ctx, userID)
+}
+
+func (m *repoMock) FetchCollection(ctx context.Context, collectionID, userID string) (*Collection, error) {
+	return m.fetchCollectionFunc(ctx, collectionID, userID)
+}
+
+func (m *repoMock) UpdateCollection(ctx context.Context, collectionID, userID string, params *CollectionParams) (*Collection, error) {
+	return m.updateCollectionFunc(ctx, collectionID, userID, params)
+}
+
+func (m *repoMock) DeleteCollection(ctx context.Context, collectionID, userID string) error {
+	return m.deleteCollectionFunc(ctx, collectionID, userID)
+}
+
+func (m *repoMock) AddToCollection(ctx context.Context, collectionID, favoriteID, userID string) error {
+	return m.addToCollectionFunc(ctx, collectionID, favoriteID, userID)
+}
+
+func (m *repoMock) RemoveFromCollection(ctx context.Context, collectionID, favoriteID, userID string) error {
+	return m.removeFromCollectionFunc(ctx, collectionID, favoriteID, userID)
+}
+
+func (m *repoMock) CreateSmartCollection(ctx context.Context, userID string, params *SmartCollectionParams) (*SmartCollection, error) {
+	return m.createSmartCollectionFunc(ctx, userID, params)
+}
+
+func (m *repoMock) ListSmartCollections(ctx context.Context, userID string) ([]SmartCollection, error) {
+	return m.listSmartCollectionsFunc(ctx, userID)
+}
+
+func (m *repoMock) FetchSmartCollection(ctx context.Context, collectionID, userID string) (*SmartCollection, error) {
+	return m.fetchSmartCollectionFunc(ctx, collectionID, userID)
+}
+
+func (m *repoMock) DeleteSmartCollection(ctx context.Context, collectionID, userID string) error {
+	return m.deleteSmartCollectionFunc(ctx, collectionID, userID)
+}
+
 // User service mock
 
 var _ usersService = &userSvcMock{}
@@ -0,0 +1,200 @@
+package favorites
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/alesr/platform-go-challenge/internal/assets"
+)
+
+// jobErrors are the errors a favorite job can fail with that mean something to clients.
+// Job errors are persisted as text, so these are the ones we can restore when reading jobs back.
+var jobErrors = []error{assets.ErrAssetNotFound}
+
+// persistableJobError unwraps err to the known job error it matches, if any,
+// so it's persisted in a form restoreJobError recognizes.
+func persistableJobError(err error) error {
+	for _, known := range jobErrors {
+		if errors.Is(err, known) {
+			return known
+		}
+	}
+	return err
+}
+
+// restoreJobError maps a job error read back from the repository to the known error it stands for.
+// Unknown errors are returned as they are.
+func restoreJobError(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, known := range jobErrors {
+		if err.Error() == known.Error() {
+			return known
+		}
+	}
+	return err
+}
+
+type queueConsumerConfig struct {
+	lease        time.Duration
+	pollInterval time.Duration
+	retention    time.Duration
+}
+
+// queueConsumer claims pending favorite jobs from the repository and hands them over to the worker pool.
+// It never claims more jobs than the pool's buffer has room for, so jobs it can't process soon
+// stay in the repository for other consumers to claim.
+// Jobs stay in the repository until they are completed, so nothing is lost if the consumer
+// stops halfway: claimed jobs are either released or claimed again once their lease expires.
+type queueConsumer struct {
+	logger     *slog.Logger
+	repository Repository
+	workerPool *workerPool
+	cfg        queueConsumerConfig
+	lastPurged time.Time
+	wakeCh     chan struct{}
+	done       chan struct{}
+	stopped    chan struct{}
+}
+
+func newQueueConsumer(logger *slog.Logger, repo Repository, wp *workerPool, cfg queueConsumerConfig) *queueConsumer {
+	c := &queueConsumer{
+		logger:     logger.WithGroup("queue-consumer"),
+		repository: repo,
+		workerPool: wp,
+		cfg:        cfg,
+		lastPurged: time.Now(),
+		wakeCh:     make(chan struct{}, 1),
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+
+	go c.run()
+	return c
+}
+
+func (c *queueConsumer) run() {
+	defer close(c.stopped)
+
+	ticker := time.NewTicker(c.cfg.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Filling up the buffer likely means more jobs are waiting, so we claim again right away.
+		if limit := c.workerPool.free(); limit > 0 && c.claim(limit) == limit {
+			select {
+			case <-c.done:
+				return
+			default:
+				continue
+			}
+		}
+
+		c.purge()
+
+		select {
+		case <-c.done:
+			return
+		case <-c.wakeCh:
+		case <-c.workerPool.space():
+		case <-ticker.C:
+		}
+	}
+}
+
+// claim leases up to limit jobs and submits them to the worker pool.
+// Each user's jobs are submitted together, so they're stored in the same batch, in order.
+// It returns the number of jobs handed over.
+func (c *queueConsumer) claim(limit int) int {
+	ctx, cancel := context.WithTimeout(context.Background(), assets.BackgroundCtxTimeout)
+	defer cancel()
+
+	jobs, err := c.repository.ClaimFavoriteJobs(ctx, limit, c.cfg.lease)
+	if err != nil {
+		c.logger.Error("failed to claim favorite jobs", slog.String("error", err.Error()))
+		return 0
+	}
+
+	// jobs come grouped by user
+	for start := 0; start < len(jobs); {
+		end := start + 1
+		for end < len(jobs) && jobs[end].UserID == jobs[start].UserID {
+			end++
+		}
+
+		tasks := make([]*favoriteTask, 0, end-start)
+		for _, job := range jobs[start:end] {
+			tasks = append(tasks, &favoriteTask{
+				jobID:    job.ID,
+				attempts: job.Attempts,
+				params: &FavoriteAssetParams{
+					UserID:      job.UserID,
+					AssetID:     job.AssetID,
+					Description: job.Description,
+				},
+			})
+		}
+
+		if err := c.workerPool.submit(tasks...); err != nil {
+			// We only claim what fits in the buffer, so this means the pool was stopped.
+			c.release(ctx, jobs[start:])
+			return start
+		}
+		start = end
+	}
+	return len(jobs)
+}
+
+// release gives back jobs we claimed but won't process.
+// If it fails, the jobs are claimed again when their lease expires.
+func (c *queueConsumer) release(ctx context.Context, jobs []FavoriteJob) {
+	ids := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		ids = append(ids, job.ID)
+	}
+
+	if err := c.repository.ReleaseFavoriteJobs(ctx, ids); err != nil {
+		c.logger.Error("failed to release favorite jobs", slog.String("error", err.Error()))
+	}
+}
+
+// purge removes finished jobs past the retention period.
+// It runs at most once per retention period, the same way for every consumer,
+// so a handful of replicas purging at the same time is harmless.
+func (c *queueConsumer) purge() {
+	if time.Since(c.lastPurged) < c.cfg.retention {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), assets.BackgroundCtxTimeout)
+	defer cancel()
+
+	purged, err := c.repository.PurgeFavoriteJobs(ctx, time.Now().Add(-c.cfg.retention))
+	if err != nil {
+		c.logger.Error("failed to purge favorite jobs", slog.String("error", err.Error()))
+		return
+	}
+
+	c.logger.Debug("purged favorite jobs", slog.Int64("count", purged))
+	c.lastPurged = time.Now()
+}
+
+// wake makes the consumer claim jobs without waiting for the next poll.
+// It never blocks: if a wake-up is already pending, that one is enough.
+func (c *queueConsumer) wake() {
+	select {
+	case c.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+// stop stops claiming jobs and waits for the jobs already
+// handed over to the worker pool to be processed.
+func (c *queueConsumer) stop() {
+	close(c.done)
+	<-c.stopped
+	c.workerPool.stop()
+}
@@ -0,0 +1,237 @@
+package favorites
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alesr/platform-go-challenge/internal/pkg/logutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueueConsumer(t *testing.T) {
+	t.Parallel()
+
+	givenJobs := []FavoriteJob{
+		{ID: "job-1", UserID: "user-1", AssetID: "asset-1", Description: "foo"},
+		{ID: "job-2", UserID: "user-2", AssetID: "asset-2"},
+		{ID: "job-3", UserID: "user-3", AssetID: "asset-3"},
+	}
+
+	var (
+		mu        sync.Mutex
+		claimed   int
+		processed []string
+	)
+
+	repo := repoMock{
+		claimFavoriteJobsFunc: func(ctx context.Context, limit int, lease time.Duration) ([]FavoriteJob, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			assert.LessOrEqual(t, limit, 2)
+			assert.Equal(t, time.Minute, lease)
+
+			// hand out the jobs in batches of limit, then nothing
+			start := min(claimed, len(givenJobs))
+			end := min(start+limit, len(givenJobs))
+			claimed = end
+			return givenJobs[start:end], nil
+		},
+	}
+
+	processBatch := func(ctx context.Context, tasks []*favoriteTask) []error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		for _, task := range tasks {
+			for _, job := range givenJobs {
+				if job.ID == task.jobID {
+					assert.Equal(t, job.UserID, task.params.UserID)
+					assert.Equal(t, job.AssetID, task.params.AssetID)
+					assert.Equal(t, job.Description, task.params.Description)
+				}
+			}
+			processed = append(processed, task.jobID)
+		}
+		return make([]error, len(tasks))
+	}
+
+	// a single worker with room for two tasks
+	wp := newWorkerPool(logutil.NewNoop(), 1, 2, batchConfig{size: 2, linger: time.Millisecond}, processBatch)
+	c := newQueueConsumer(logutil.NewNoop(), &repo, wp, queueConsumerConfig{
+		lease:        time.Minute,
+		pollInterval: time.Hour,
+		retention:    time.Hour,
+	})
+
+	// The consumer never claims more than the buffer holds, and it tops the buffer up as
+	// the worker frees it, without waiting for the poll interval to pick up the last job.
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(processed) == len(givenJobs)
+	}, time.Second, 10*time.Millisecond)
+
+	c.stop()
+
+	assert.ElementsMatch(t, []string{"job-1", "job-2", "job-3"}, processed)
+}
+
+func TestQueueConsumer_SubmitsUserJobsTogether(t *testing.T) {
+	t.Parallel()
+
+	givenJobs := []FavoriteJob{
+		{ID: "job-1", UserID: "user-1", AssetID: "asset-1"},
+		{ID: "job-2", UserID: "user-1", AssetID: "asset-2"},
+		{ID: "job-3", UserID: "user-2", AssetID: "asset-3"},
+	}
+
+	repo := repoMock{
+		claimFavoriteJobsFunc: func(ctx context.Context, limit int, lease time.Duration) ([]FavoriteJob, error) {
+			return givenJobs, nil
+		},
+	}
+
+	var batches [][]string
+	processBatch := func(ctx context.Context, tasks []*favoriteTask) []error {
+		var batch []string
+		for _, task := range tasks {
+			batch = append(batch, task.jobID)
+		}
+		batches = append(batches, batch)
+		return make([]error, len(tasks))
+	}
+
+	// a single worker taking one task at a time, unless they're submitted together
+	wp := newWorkerPool(logutil.NewNoop(), 1, len(givenJobs), batchConfig{size: 1}, processBatch)
+
+	// build the consumer by hand so it doesn't start claiming on its own
+	c := queueConsumer{
+		logger:     logutil.NewNoop(),
+		repository: &repo,
+		workerPool: wp,
+	}
+
+	submitted := c.claim(len(givenJobs))
+	wp.stop()
+
+	assert.Equal(t, len(givenJobs), submitted)
+	assert.Equal(t, [][]string{{"job-1", "job-2"}, {"job-3"}}, batches)
+}
+
+func TestQueueConsumer_Wake(t *testing.T) {
+	t.Parallel()
+
+	claimedCh := make(chan struct{}, 10)
+
+	repo := repoMock{
+		claimFavoriteJobsFunc: func(ctx context.Context, limit int, lease time.Duration) ([]FavoriteJob, error) {
+			claimedCh <- struct{}{}
+			return nil, nil
+		},
+	}
+
+	wp := newWorkerPool(logutil.NewNoop(), 1, 1, batchConfig{size: 1}, noopProcessBatch)
+
+	c := newQueueConsumer(logutil.NewNoop(), &repo, wp, queueConsumerConfig{
+		lease:        time.Minute,
+		pollInterval: time.Hour,
+		retention:    time.Hour,
+	})
+	defer c.stop()
+
+	// first claim happens on start
+	<-claimedCh
+
+	c.wake()
+
+	select {
+	case <-claimedCh:
+	case <-time.After(time.Second):
+		t.Fatal("consumer did not claim jobs after waking up")
+	}
+}
+
+func TestQueueConsumer_ReleaseWhenPoolStopped(t *testing.T) {
+	t.Parallel()
+
+	givenJobs := []FavoriteJob{{ID: "job-1"}, {ID: "job-2"}}
+
+	var released []string
+
+	repo := repoMock{
+		claimFavoriteJobsFunc: func(ctx context.Context, limit int, lease time.Duration) ([]FavoriteJob, error) {
+			return givenJobs, nil
+		},
+		releaseFavoriteJobsFunc: func(ctx context.Context, jobIDs []string) error {
+			released = append(released, jobIDs...)
+			return nil
+		},
+	}
+
+	wp := newWorkerPool(logutil.NewNoop(), 1, len(givenJobs), batchConfig{size: 1}, noopProcessBatch)
+	wp.stop()
+
+	// build the consumer by hand so it doesn't start claiming on its own
+	c := queueConsumer{
+		logger:     logutil.NewNoop(),
+		repository: &repo,
+		workerPool: wp,
+	}
+
+	submitted := c.claim(len(givenJobs))
+
+	assert.Zero(t, submitted)
+	assert.Equal(t, []string{"job-1", "job-2"}, released)
+}
+
+func TestQueueConsumer_Purge(t *testing.T) {
+	t.Parallel()
+
+	var purgedBefore time.Time
+
+	c := queueConsumer{
+		logger: logutil.NewNoop(),
+		repository: &repoMock{
+			purgeFavoriteJobsFunc: func(ctx context.Context, before time.Time) (int64, error) {
+				purgedBefore = before
+				return 1, nil
+			},
+		},
+		cfg:        queueConsumerConfig{retention: time.Hour},
+		lastPurged: time.Now(),
+	}
+
+	// too early to purge
+	c.purge()
+	assert.True(t, purgedBefore.IsZero())
+
+	c.lastPurged = time.Now().Add(-2 * time.Hour)
+	c.purge()
+
+	require.False(t, purgedBefore.IsZero())
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), purgedBefore, time.Second)
+	assert.WithinDuration(t, time.Now(), c.lastPurged, time.Second)
+}
+
+func TestJobErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, known := range jobErrors {
+		persisted := persistableJobError(fmt.Errorf("wrapped: %w", known))
+		assert.Equal(t, known, persisted)
+
+		// what we read back from the repository is just the message
+		restored := restoreJobError(errors.New(persisted.Error()))
+		assert.Equal(t, known, restored)
+	}
+
+	assert.Nil(t, restoreJobError(nil))
+	assert.Equal(t, assert.AnError, persistableJobError(assert.AnError))
+	assert.Equal(t, assert.AnError, restoreJobError(assert.AnError))
+}
@@ -0,0 +1,33 @@
+package favorites
+
+import (
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// retryPolicy decides whether a failed favorite job is worth another attempt, and when.
+type retryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+}
+
+// shouldRetry reports whether a job that failed with err after the given number of attempts should be retried.
+// Only transient errors are retried, anything else would fail the same way again.
+func (p retryPolicy) shouldRetry(err error, attempts int) bool {
+	return errors.Is(err, ErrTransient) && attempts < p.maxAttempts
+}
+
+// backoff returns how long to wait before the next attempt.
+// The delay doubles with each attempt up to maxDelay, and half of it is random
+// so jobs that failed together, say during a database failover, don't all come back at once.
+func (p retryPolicy) backoff(attempts int) time.Duration {
+	delay := p.maxDelay
+	if shift := attempts - 1; shift < 32 {
+		delay = min(p.baseDelay<<max(shift, 0), p.maxDelay)
+	}
+
+	half := delay / 2
+	return half + rand.N(half+1)
+}
@@ -0,0 +1,83 @@
+package favorites
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alesr/platform-go-challenge/internal/assets"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_ShouldRetry(t *testing.T) {
+	t.Parallel()
+
+	policy := retryPolicy{maxAttempts: 3}
+
+	testCases := []struct {
+		name          string
+		givenErr      error
+		givenAttempts int
+		expected      bool
+	}{
+		{
+			name:          "transient error",
+			givenErr:      fmt.Errorf("%w: connection reset", ErrTransient),
+			givenAttempts: 1,
+			expected:      true,
+		},
+		{
+			name:          "transient error on the last attempt",
+			givenErr:      fmt.Errorf("%w: connection reset", ErrTransient),
+			givenAttempts: 3,
+			expected:      false,
+		},
+		{
+			name:          "permanent error",
+			givenErr:      assets.ErrAssetNotFound,
+			givenAttempts: 1,
+			expected:      false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.expected, policy.shouldRetry(tc.givenErr, tc.givenAttempts))
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	t.Parallel()
+
+	policy := retryPolicy{
+		baseDelay: time.Second,
+		maxDelay:  time.Minute,
+	}
+
+	testCases := []struct {
+		givenAttempts int
+		expectedMax   time.Duration
+	}{
+		{givenAttempts: 0, expectedMax: time.Second},
+		{givenAttempts: 1, expectedMax: time.Second},
+		{givenAttempts: 2, expectedMax: 2 * time.Second},
+		{givenAttempts: 3, expectedMax: 4 * time.Second},
+		{givenAttempts: 7, expectedMax: time.Minute},
+		{givenAttempts: 100, expectedMax: time.Minute},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("attempt %d", tc.givenAttempts), func(t *testing.T) {
+			t.Parallel()
+
+			// jitter keeps the delay between half and the full exponential delay
+			for range 100 {
+				delay := policy.backoff(tc.givenAttempts)
+				assert.GreaterOrEqual(t, delay, tc.expectedMax/2)
+				assert.LessOrEqual(t, delay, tc.expectedMax)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package favorites
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alesr/platform-go-challenge/internal/assets"
+)
+
+const (
+	// Bounds on the size of a rule, which is compiled into a single query.
+	maxRuleDepth      = 8
+	maxRuleConditions = 50
+	maxRuleInValues   = 100
+
+	// maxRuleWithinDays bounds "within_days" conditions to about ten years.
+	maxRuleWithinDays = 3650
+)
+
+// RuleOp is the comparison a rule condition makes between a field and its value.
+type RuleOp string
+
+const (
+	// Enumerate rule operators
+
+	RuleOpEq         RuleOp = "eq"
+	RuleOpNe         RuleOp = "ne"
+	RuleOpLt         RuleOp = "lt"
+	RuleOpLte        RuleOp = "lte"
+	RuleOpGt         RuleOp = "gt"
+	RuleOpGte        RuleOp = "gte"
+	RuleOpIn         RuleOp = "in"
+	RuleOpContains   RuleOp = "contains"
+	RuleOpWithinDays RuleOp = "within_days"
+)
+
+// RuleFieldType is the type of the values a rule field holds,
+// which decides the operators and values a condition on it accepts.
+type RuleFieldType string
+
+const (
+	// Enumerate rule field types
+
+	RuleFieldString RuleFieldType = "string"
+	RuleFieldNumber RuleFieldType = "number"
+	RuleFieldTime   RuleFieldType = "time"
+)
+
+// ruleFields lists the favorite's own fields rules can look at.
+// Rules can look at the favorited asset's data as well, under "asset.", see RuleFieldTypeOf.
+var ruleFields = map[string]RuleFieldType{
+	"asset_type":  RuleFieldString,
+	"description": RuleFieldString,
+	"created_at":  RuleFieldTime,
+	"updated_at":  RuleFieldTime,
+}
+
+// assetRuleFields maps the types of asset fields rules can look at to the types rules see them as.
+// Rules look at sets of strings one string at a time, and at ranges one bound at a time.
+var assetRuleFields = map[assets.FieldType]RuleFieldType{
+	assets.FieldString:  RuleFieldString,
+	assets.FieldStrings: RuleFieldString,
+	assets.FieldNumber:  RuleFieldNumber,
+	assets.FieldRange:   RuleFieldNumber,
+}
+
+const (
+	// Enumerate the bounds of range fields rules can look at
+
+	RuleBoundMin = "min"
+	RuleBoundMax = "max"
+)
+
+// ruleOps lists the operators each field type accepts.
+var ruleOps = map[RuleFieldType][]RuleOp{
+	RuleFieldString: {RuleOpEq, RuleOpNe, RuleOpIn, RuleOpContains},
+	RuleFieldNumber: {RuleOpEq, RuleOpNe, RuleOpLt, RuleOpLte, RuleOpGt, RuleOpGte, RuleOpIn},
+	RuleFieldTime:   {RuleOpLt, RuleOpLte, RuleOpGt, RuleOpGte, RuleOpWithinDays},
+}
+
+// RuleFieldTypeOf returns the type of a rule field, and false if rules can't look at it.
+// Fields under "asset." are fields of the registered asset types, named as in the API, see LookupAssetRuleField.
+// Asset fields of other asset types hold no value, so no condition on them matches.
+func RuleFieldTypeOf(field string) (RuleFieldType, bool) {
+	if t, ok := ruleFields[field]; ok {
+		return t, true
+	}
+
+	assetField, ok := LookupAssetRuleField(field)
+	if !ok {
+		return "", false
+	}
+	return assetRuleFields[assetField.Type], true
+}
+
+// AssetRuleField is a field of the favorited asset's data a rule field looks at.
+type AssetRuleField struct {
+	assets.Field
+	// Bound is the bound of a range field the rule field looks at, RuleBoundMin or RuleBoundMax.
+	Bound string
+}
+
+// LookupAssetRuleField returns the asset field a rule field under "asset." looks at, and false if there is none.
+// String and number fields are looked at as they are, as in "asset.title". Conditions on sets of strings,
+// as in "asset.birth_countries", match when any of the strings matches. The bounds of ranges are looked at
+// as numbers, as in "asset.social_media_hours.min", and an open bound holds no value.
+func LookupAssetRuleField(field string) (AssetRuleField, bool) {
+	name, ok := strings.CutPrefix(field, "asset.")
+	if !ok {
+		return AssetRuleField{}, false
+	}
+
+	if f, ok := assets.LookupField(name); ok {
+		if f.Type == assets.FieldRange {
+			return AssetRuleField{}, false
+		}
+		_, ok := assetRuleFields[f.Type]
+		return AssetRuleField{Field: f}, ok
+	}
+
+	name, bound, ok := strings.Cut(name, ".")
+	if !ok || (bound != RuleBoundMin && bound != RuleBoundMax) {
+		return AssetRuleField{}, false
+	}
+
+	f, ok := assets.LookupField(name)
+	if !ok || f.Type != assets.FieldRange {
+		return AssetRuleField{}, false
+	}
+	return AssetRuleField{Field: f, Bound: bound}, true
+}
+
+// Rule is a predicate over a user's favorites, stored as JSON with a smart collection.
+// A rule is exactly one of:
+//   - all: matches when all of its rules match
+//   - any: matches when any of its rules matches
+//   - not: matches when its rule doesn't
+//   - a condition comparing field to value with op
+//
+// Values are strings for string fields, numbers for number fields and RFC 3339 timestamps
+// for time fields, or a list of those for "in". "within_days" takes a number of days,
+// matching times from that many days ago until now.
+type Rule struct {
+	All []Rule `json:"all,omitempty"`
+	Any []Rule `json:"any,omitempty"`
+	Not *Rule  `json:"not,omitempty"`
+
+	Field string `json:"field,omitempty"`
+	Op    RuleOp `json:"op,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// Validate checks the rule can be evaluated, returning an error wrapping ErrInvalidRule otherwise.
+func (r *Rule) Validate() error {
+	conditions := 0
+	if err := r.validate(1, &conditions); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+	return nil
+}
+
+func (r *Rule) validate(depth int, conditions *int) error {
+	if depth > maxRuleDepth {
+		return fmt.Errorf("rules can't be nested more than %d levels deep", maxRuleDepth)
+	}
+
+	kinds := 0
+	for _, set := range []bool{r.All != nil, r.Any != nil, r.Not != nil, r.Field != "" || r.Op != ""} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return errors.New("expected exactly one of all, any, not or a condition")
+	}
+
+	switch {
+	case r.All != nil || r.Any != nil:
+		rules := r.All
+		if r.Any != nil {
+			rules = r.Any
+		}
+		if len(rules) == 0 {
+			return errors.New("all and any need at least one rule")
+		}
+		for i := range rules {
+			if err := rules[i].validate(depth+1, conditions); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case r.Not != nil:
+		return r.Not.validate(depth+1, conditions)
+	}
+
+	*conditions++
+	if *conditions > maxRuleConditions {
+		return fmt.Errorf("rules can't have more than %d conditions", maxRuleConditions)
+	}
+	return r.validateCondition()
+}
+
+func (r *Rule) validateCondition() error {
+	fieldType, ok := RuleFieldTypeOf(r.Field)
+	if !ok {
+		return fmt.Errorf("unknown field '%s'", r.Field)
+	}
+
+	if !slices.Contains(ruleOps[fieldType], r.Op) {
+		return fmt.Errorf("operator '%s' can't be used on %s field '%s'", r.Op, fieldType, r.Field)
+	}
+
+	switch r.Op {
+	case RuleOpWithinDays:
+		days, ok := r.Value.(float64)
+		if !ok || days != math.Trunc(days) || days < 1 || days > maxRuleWithinDays {
+			return fmt.Errorf("field '%s' expects a whole number of days between 1 and %d", r.Field, maxRuleWithinDays)
+		}
+		return nil
+
+	case RuleOpIn:
+		values, ok := r.Value.([]any)
+		if !ok || len(values) == 0 || len(values) > maxRuleInValues {
+			return fmt.Errorf("field '%s' expects a list of 1 to %d values", r.Field, maxRuleInValues)
+		}
+		for _, v := range values {
+			if err := validateRuleValue(r.Field, fieldType, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return validateRuleValue(r.Field, fieldType, r.Value)
+}
+
+func validateRuleValue(field string, fieldType RuleFieldType, value any) error {
+	switch fieldType {
+	case RuleFieldString:
+		if _, ok := value.(string); ok {
+			return nil
+		}
+
+	case RuleFieldNumber:
+		if _, ok := value.(float64); ok {
+			return nil
+		}
+
+	case RuleFieldTime:
+		if s, ok := value.(string); ok {
+			if _, err := time.Parse(time.RFC3339, s); err == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("field '%s' expects an RFC 3339 timestamp", field)
+	}
+	return fmt.Errorf("field '%s' expects a %s value", field, fieldType)
+}
@@ -0,0 +1,142 @@
+package favorites
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRule_Validate(t *testing.T) {
+	t.Parallel()
+
+	tooManyConditions := `{"any":[` + strings.TrimSuffix(strings.Repeat(`{"field":"asset.social_media_hours.min","op":"gt","value":1},`, maxRuleConditions+1), ",") + `]}`
+	tooDeep := strings.Repeat(`{"not":`, maxRuleDepth) + `{"field":"asset_type","op":"eq","value":"CHART"}` + strings.Repeat(`}`, maxRuleDepth)
+
+	testCases := []struct {
+		name        string
+		givenRule   string
+		expectValid bool
+	}{
+		{
+			name:        "audiences born in Germany",
+			givenRule:   `{"all":[{"field":"asset_type","op":"eq","value":"AUDIENCE"},{"field":"asset.birth_countries","op":"eq","value":"Germany"}]}`,
+			expectValid: true,
+		},
+		{
+			name:        "charts favorited in the last 30 days",
+			givenRule:   `{"all":[{"field":"asset_type","op":"eq","value":"CHART"},{"field":"created_at","op":"within_days","value":30}]}`,
+			expectValid: true,
+		},
+		{
+			name:        "any with not and in",
+			givenRule:   `{"any":[{"not":{"field":"asset.genders","op":"in","value":["M","F"]}},{"field":"asset.last_month_purchases.max","op":"lte","value":30}]}`,
+			expectValid: true,
+		},
+		{
+			name:        "time comparison",
+			givenRule:   `{"field":"updated_at","op":"gte","value":"2025-01-01T00:00:00Z"}`,
+			expectValid: true,
+		},
+		{
+			name:      "empty rule",
+			givenRule: `{}`,
+		},
+		{
+			name:      "empty all",
+			givenRule: `{"all":[]}`,
+		},
+		{
+			name:      "both all and condition",
+			givenRule: `{"all":[{"field":"asset_type","op":"eq","value":"CHART"}],"field":"asset_type","op":"eq","value":"CHART"}`,
+		},
+		{
+			name:      "unknown field",
+			givenRule: `{"field":"user_id","op":"eq","value":"foo"}`,
+		},
+		{
+			name:      "unknown asset field",
+			givenRule: `{"field":"asset.foo","op":"eq","value":"bar"}`,
+		},
+		{
+			// lists of numbers, like a chart's data, aren't fields rules can look at
+			name:      "asset field of unsupported type",
+			givenRule: `{"field":"asset.data","op":"eq","value":1}`,
+		},
+		{
+			name:        "bound of a range",
+			givenRule:   `{"field":"asset.social_media_hours.max","op":"lt","value":3}`,
+			expectValid: true,
+		},
+		{
+			name:      "range without bound",
+			givenRule: `{"field":"asset.social_media_hours","op":"lt","value":3}`,
+		},
+		{
+			name:      "bound of a field other than a range",
+			givenRule: `{"field":"asset.title.min","op":"eq","value":"foo"}`,
+		},
+		{
+			name:      "unknown bound",
+			givenRule: `{"field":"asset.social_media_hours.avg","op":"eq","value":3}`,
+		},
+		{
+			name:      "operator not allowed on field",
+			givenRule: `{"field":"asset.title","op":"gt","value":"foo"}`,
+		},
+		{
+			name:      "string where number expected",
+			givenRule: `{"field":"asset.social_media_hours.min","op":"eq","value":"30"}`,
+		},
+		{
+			name:      "missing value",
+			givenRule: `{"field":"asset.birth_countries","op":"eq"}`,
+		},
+		{
+			name:      "invalid timestamp",
+			givenRule: `{"field":"created_at","op":"gt","value":"yesterday"}`,
+		},
+		{
+			name:      "fractional days",
+			givenRule: `{"field":"created_at","op":"within_days","value":1.5}`,
+		},
+		{
+			name:      "empty in",
+			givenRule: `{"field":"asset_type","op":"in","value":[]}`,
+		},
+		{
+			name:      "mixed in values",
+			givenRule: `{"field":"asset.social_media_hours.min","op":"in","value":[18,"21"]}`,
+		},
+		{
+			name:      "invalid nested rule",
+			givenRule: `{"all":[{"field":"asset_type","op":"eq","value":"CHART"},{"field":"foo","op":"eq","value":"bar"}]}`,
+		},
+		{
+			name:      "too many conditions",
+			givenRule: tooManyConditions,
+		},
+		{
+			name:      "too deep",
+			givenRule: tooDeep,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var rule Rule
+			require.NoError(t, json.Unmarshal([]byte(tc.givenRule), &rule))
+
+			err := rule.Validate()
+			if tc.expectValid {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidRule)
+		})
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"sync/atomic"
+	"time"
 
 	"github.com/alesr/platform-go-challenge/internal/assets"
 	"github.com/alesr/platform-go-challenge/internal/users"
@@ -13,15 +15,63 @@ import (
 var (
 	// Enumerate service errors
 
-	ErrFavoriteAssetNotFound = errors.New("favorite asset not found")
-	ErrInvalidAssetID        = errors.New("invalid asset id")
+	ErrBatchAborted            = errors.New("operation not applied, another operation in the batch failed")
+	ErrCollectionNameTaken     = errors.New("collection name already taken")
+	ErrCollectionNotFound      = errors.New("collection not found")
+	ErrDeadLetterNotFound      = errors.New("dead letter not found")
+	ErrFavoriteAlreadyExists   = errors.New("asset already favorited")
+	ErrFavoriteAssetNotFound   = errors.New("favorite asset not found")
+	ErrFavoriteJobNotFound     = errors.New("favorite job not found")
+	ErrInvalidAssetID          = errors.New("invalid asset id")
+	ErrInvalidRule             = errors.New("invalid smart collection rule")
+	ErrLeaseLost               = errors.New("favorite job lease lost")
+	ErrPendingWrites           = errors.New("favorites still being processed")
+	ErrQueueFull               = errors.New("favorites queue is full")
+	ErrSmartCollectionNotFound = errors.New("smart collection not found")
+
+	// ErrTransient is wrapped by repository errors that might go away if the
+	// operation is retried, like a dropped connection or a serialization failure.
+	ErrTransient = errors.New("transient error")
 )
 
 type Repository interface {
-	StoreFavoriteAsset(ctx context.Context, params *FavoriteAssetParams) error
-	GetUserFavorites(ctx context.Context, userID string) ([]FavoriteAsset, error)
+	EnqueueFavoriteJob(ctx context.Context, params *FavoriteAssetParams, maxPending int) (*FavoriteJob, error)
+	CountPendingFavoriteJobs(ctx context.Context) (int64, error)
+	ClaimFavoriteJobs(ctx context.Context, limit int, lease time.Duration) ([]FavoriteJob, error)
+	CompleteFavoriteJob(ctx context.Context, jobID string, attempt int) error
+	RetryFavoriteJob(ctx context.Context, jobID string, attempt int, runAt time.Time, jobErr error) error
+	DeadLetterFavoriteJob(ctx context.Context, jobID string, attempt int, jobErr error) error
+	ReleaseFavoriteJobs(ctx context.Context, jobIDs []string) error
+	FetchFavoriteJob(ctx context.Context, jobID string) (*FavoriteJob, error)
+	LatestPendingFavoriteJobID(ctx context.Context, userID string) (string, error)
+	PurgeFavoriteJobs(ctx context.Context, before time.Time) (int64, error)
+	ListDeadLetters(ctx context.Context, params *ListDeadLettersParams) ([]DeadLetter, error)
+	ReplayDeadLetter(ctx context.Context, id string) (*FavoriteJob, error)
+	StoreFavoriteAssets(ctx context.Context, params []*FavoriteAssetParams) ([]error, error)
+	ApplyFavoriteOps(ctx context.Context, userID string, ops []FavoriteOp, atomic bool) ([]error, error)
+	GetUserFavorites(ctx context.Context, userID string, params *ListFavoritesParams) ([]FavoriteAsset, error)
+	FetchFavoriteSnapshots(ctx context.Context, favoriteID, userID string) (*FavoriteSnapshots, error)
+	RecordFavoriteOpen(ctx context.Context, favoriteID, userID string, weight float64) error
 	UpdateFavorite(ctx context.Context, favID, userID string, params *UpdateFavoriteParams) (*FavoriteAsset, error)
 	DeleteFavorite(ctx context.Context, favoriteID, userID string) error
+	DeleteFavoriteByAsset(ctx context.Context, assetID, userID string) error
+	DeleteUserFavorites(ctx context.Context, userID string) (int64, error)
+	GetTrashedFavorites(ctx context.Context, userID string, params *ListTrashParams, deletedAfter time.Time) ([]TrashedFavorite, error)
+	RestoreFavorite(ctx context.Context, favoriteID, userID string, deletedAfter time.Time) (*FavoriteAsset, error)
+	PurgeTrashedFavorites(ctx context.Context, before time.Time) (int64, error)
+	ReorderFavorites(ctx context.Context, userID string, favoriteIDs []string) error
+	MoveFavorite(ctx context.Context, favoriteID, userID string, params *MoveFavoriteParams) error
+	CreateCollection(ctx context.Context, userID string, params *CollectionParams) (*Collection, error)
+	ListCollections(ctx context.Context, userID string) ([]Collection, error)
+	FetchCollection(ctx context.Context, collectionID, userID string) (*Collection, error)
+	UpdateCollection(ctx context.Context, collectionID, userID string, params *CollectionParams) (*Collection, error)
+	DeleteCollection(ctx context.Context, collectionID, userID string) error
+	AddToCollection(ctx context.Context, collectionID, favoriteID, userID string) error
+	RemoveFromCollection(ctx context.Context, collectionID, favoriteID, userID string) error
+	CreateSmartCollection(ctx context.Context, userID string, params *SmartCollectionParams) (*SmartCollection, error)
+	ListSmartCollections(ctx context.Context, userID string) ([]SmartCollection, error)
+	FetchSmartCollection(ctx context.Context, collectionID, userID string) (*SmartCollection, error)
+	DeleteSmartCollection(ctx context.Context, collectionID, userID string) error
 }
 
 type usersService interface {
@@ -30,63 +80,259 @@ type usersService interface {
 
 // Service provides asset favorite service for managing user favorites.
 type Service struct {
-	logger     *slog.Logger
-	repository Repository
-	usersSvc   usersService
-	workerPool *workerPool
+	logger      *slog.Logger
+	repository  Repository
+	usersSvc    usersService
+	retryPolicy retryPolicy
+	writesWait  writesWait
+	consumer    *queueConsumer
+	purger      *trashPurger
+
+	// Queue limits and batching, see the With* options.
+	bufferSize     int
+	maxPendingJobs int
+	batch          batchConfig
+
+	// How long deleted favorites can be restored, see WithTrashRetention.
+	trashRetention time.Duration
+
+	// Number of favorites refused because the queue was full.
+	rejected atomic.Int64
 }
 
 const (
 	workerpoolJobs = 10
+
+	// Defaults for batching favorites in the worker pool.
+	// The linger time adds to the latency of every favorite when the queue is quiet,
+	// so it's kept short: under load, batches fill up long before it's up.
+	defaultBatchSize   = 25
+	defaultBatchLinger = 20 * time.Millisecond
+
+	// Defaults for the queue limits.
+	// The buffer holds two batches per worker, so workers don't wait on the consumer.
+	defaultBufferSize     = 2 * workerpoolJobs * defaultBatchSize
+	defaultMaxPendingJobs = 10_000
+
+	// How long a consumer holds on to a claimed job before other consumers can claim it.
+	// It must be comfortably longer than the time it takes to process a job.
+	jobLease = 4 * assets.BackgroundCtxTimeout
+
+	// How often consumers look for new jobs when they are not woken up by a new favorite.
+	jobPollInterval = time.Second
+
+	// How long we keep finished jobs so clients can poll their status.
+	jobRetention = time.Hour
+
+	// Retry settings for jobs failing with transient errors.
+	// With these, a job keeps being retried for up to about eight minutes before it's dead lettered.
+	jobMaxAttempts    = 10
+	jobRetryBaseDelay = time.Second
+	jobRetryMaxDelay  = 5 * time.Minute
+
+	// How long synchronous operations wait for the user's pending favorites
+	// to be stored before giving up with ErrPendingWrites, and how often they check.
+	pendingWritesTimeout      = 5 * time.Second
+	pendingWritesPollInterval = 100 * time.Millisecond
+
+	// How long deleted favorites stay in the trash by default, and how often expired ones are purged.
+	defaultTrashRetention = 30 * 24 * time.Hour
+	trashPurgeInterval    = time.Hour
 )
 
+// writesWait bounds how long waitForPendingWrites waits, and how often it checks.
+type writesWait struct {
+	timeout      time.Duration
+	pollInterval time.Duration
+}
+
+// Option configures the favorites service.
+type Option func(*Service)
+
+// WithBufferSize sets how many claimed jobs the consumers of this process
+// can hold in memory while they wait for a free worker.
+func WithBufferSize(size int) Option {
+	return func(s *Service) {
+		if size > 0 {
+			s.bufferSize = size
+		}
+	}
+}
+
+// WithMaxPendingJobs sets how many jobs can wait in the queue, across all consumers,
+// before new favorites are refused with ErrQueueFull.
+func WithMaxPendingJobs(max int) Option {
+	return func(s *Service) {
+		if max > 0 {
+			s.maxPendingJobs = max
+		}
+	}
+}
+
+// WithBatchSize sets how many favorites a worker stores at once.
+func WithBatchSize(size int) Option {
+	return func(s *Service) {
+		if size > 0 {
+			s.batch.size = size
+		}
+	}
+}
+
+// WithBatchLinger sets how long a worker waits for more favorites
+// to fill up a batch before storing the ones it has.
+func WithBatchLinger(linger time.Duration) Option {
+	return func(s *Service) {
+		if linger > 0 {
+			s.batch.linger = linger
+		}
+	}
+}
+
 // NewService creates a new asset favorite service.
-func NewService(logger *slog.Logger, repo Repository, usersSvc usersService) *Service {
-	return &Service{
+// Favorite jobs are only processed once StartConsumers is called.
+func NewService(logger *slog.Logger, repo Repository, usersSvc usersService, opts ...Option) *Service {
+	s := &Service{
 		logger:     logger.WithGroup("assets-service"),
 		repository: repo,
 		usersSvc:   usersSvc,
-		workerPool: newWorkerPool(logger, workerpoolJobs, repo.StoreFavoriteAsset),
+		retryPolicy: retryPolicy{
+			maxAttempts: jobMaxAttempts,
+			baseDelay:   jobRetryBaseDelay,
+			maxDelay:    jobRetryMaxDelay,
+		},
+		writesWait: writesWait{
+			timeout:      pendingWritesTimeout,
+			pollInterval: pendingWritesPollInterval,
+		},
+		bufferSize:     defaultBufferSize,
+		maxPendingJobs: defaultMaxPendingJobs,
+		batch: batchConfig{
+			size:   defaultBatchSize,
+			linger: defaultBatchLinger,
+		},
+		trashRetention: defaultTrashRetention,
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
+}
+
+// StartConsumers starts processing queued favorite jobs, and purging expired favorites from the trash,
+// in the background until Shutdown is called. It's safe to run consumers in several processes against
+// the same database. It must be called once, before the service starts taking requests.
+func (s *Service) StartConsumers() {
+	s.purger = newTrashPurger(s.logger, s.repository, s.trashRetention, trashPurgeInterval)
+
+	wp := newWorkerPool(s.logger, workerpoolJobs, s.bufferSize, s.batch, s.processFavoriteTasks)
+	s.consumer = newQueueConsumer(s.logger, s.repository, wp, queueConsumerConfig{
+		lease:        jobLease,
+		pollInterval: jobPollInterval,
+		retention:    jobRetention,
+	})
 }
 
 // FavoriteAsset marks an asset as favorite for a user.
-func (s *Service) FavoriteAsset(ctx context.Context, params *FavoriteAssetParams) error {
+// The favorite is queued before returning and stored asynchronously by the consumers.
+// The returned job can be used to track its outcome. If the queue already holds
+// as many pending jobs as allowed, the favorite is refused with ErrQueueFull.
+func (s *Service) FavoriteAsset(ctx context.Context, params *FavoriteAssetParams) (*FavoriteJob, error) {
 	// In a real-case scenario, peharps we could get the user ID from the context after
 	// some auth mechanism. This would help us  decoupling assets from users service.
-	if _, err := s.usersSvc.FetchUser(ctx, params.UserID); err != nil {
-		if errors.Is(err, users.ErrUserNotFound) {
-			return err
+	if err := s.ensureUser(ctx, params.UserID); err != nil {
+		return nil, err
+	}
+
+	// Detach context so the job is queued even if the client goes away.
+	ctx, cancel := context.WithTimeout(context.Background(), assets.BackgroundCtxTimeout)
+	defer cancel()
+
+	job, err := s.repository.EnqueueFavoriteJob(ctx, params, s.maxPendingJobs)
+	if err != nil {
+		if errors.Is(err, ErrQueueFull) {
+			s.rejected.Add(1)
+			return nil, err
 		}
-		return fmt.Errorf("could not fetch user id '%s': %w", params.UserID, err)
+		return nil, fmt.Errorf("could not enqueue favorite job: %w", err)
 	}
 
-	// I'm assuming the user doesn't need to get a confirmation that the asset was marked as favorite.
-	s.workerPool.submit(params)
-	return nil
+	// Consumers poll for jobs anyway, this only saves the wait when they run in this process.
+	if s.consumer != nil {
+		s.consumer.wake()
+	}
+	return job, nil
+}
+
+// FetchFavoriteJob returns the current status of a favorite job.
+func (s *Service) FetchFavoriteJob(ctx context.Context, jobID string) (*FavoriteJob, error) {
+	job, err := s.repository.FetchFavoriteJob(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, ErrFavoriteJobNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("could not fetch favorite job: %w", err)
+	}
+
+	job.Err = restoreJobError(job.Err)
+	return job, nil
 }
 
-// FetchUserFavorites fetches the user's favorite assets.
-func (s *Service) FetchUserFavorites(ctx context.Context, userID string) ([]FavoriteAsset, error) {
+// FetchUserFavorites fetches a page of the user's favorite assets.
+// The returned page token is empty when there are no more pages to fetch.
+// With WaitForPendingWrites set, the favorites the user had queued are
+// stored before the page is fetched, so they show up in it.
+func (s *Service) FetchUserFavorites(ctx context.Context, userID string, params *ListFavoritesParams) ([]FavoriteAsset, string, error) {
 	// We could live without this check and just return an empty slice if we can't find any favorites for this user.
 	// But only the big picture of the system and business requirements would tell us the appropriate approach here.
-	if _, err := s.usersSvc.FetchUser(ctx, userID); err != nil {
-		if errors.Is(err, users.ErrUserNotFound) {
-			return nil, fmt.Errorf("user not found: %w", err)
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return nil, "", err
+	}
+
+	// An unknown collection is an error rather than an empty list,
+	// so clients can tell it apart from an empty collection.
+	if params.CollectionID != "" {
+		if _, err := s.repository.FetchCollection(ctx, params.CollectionID, userID); err != nil {
+			return nil, "", fmt.Errorf("could not fetch collection: %w", err)
+		}
+	}
+
+	if params.WaitForPendingWrites {
+		if err := s.waitForPendingWrites(ctx, userID); err != nil {
+			return nil, "", err
 		}
-		return nil, fmt.Errorf("could not fetch user: %w", err)
 	}
 
-	favorites, err := s.repository.GetUserFavorites(ctx, userID)
+	favorites, err := s.repository.GetUserFavorites(ctx, userID, params)
 	if err != nil {
-		return nil, fmt.Errorf("could not get user favorites: %w", err)
+		return nil, "", fmt.Errorf("could not get user favorites: %w", err)
 	}
-	return favorites, nil
+
+	// A full page means there might be more favorites to fetch.
+	// At worst, the client gets an empty page at the end.
+	var nextPageToken string
+	if len(favorites) > 0 && len(favorites) == params.PageSize {
+		last := favorites[len(favorites)-1]
+		cursor := Cursor{Position: last.Position, ID: last.ID}
+		if params.Query != "" {
+			cursor.SearchRank = &last.SearchRank
+		}
+		if params.Sort == SortFrecency {
+			cursor.Frecency = &last.Frecency
+		}
+		nextPageToken = cursor.Encode()
+	}
+	return favorites, nextPageToken, nil
 }
 
 // UpdateFavorite updates a user's favorite asset.
 // The favorite must belong to the user that created it which will be checked by the repository.
+// It runs after the favorites the user queued before, see waitForPendingWrites.
 func (s *Service) UpdateFavorite(ctx context.Context, favID, userID string, params *UpdateFavoriteParams) (*FavoriteAsset, error) {
+	if err := s.waitForPendingWrites(ctx, userID); err != nil {
+		return nil, err
+	}
+
 	// Detach context to prevent cancellation while writing data.
 	ctx, cancel := context.WithTimeout(context.Background(), assets.BackgroundCtxTimeout)
 	defer cancel()
@@ -98,53 +344,312 @@ func (s *Service) UpdateFavorite(ctx context.Context, favID, userID string, para
 	return favAsset, nil
 }
 
-// DeleteFavorite deletes a user's favorite asset.
+// DeleteFavorite moves a user's favorite asset to the trash, where it can be restored from, see RestoreFavorite.
 // The favorite must belong to the user that created it which will be checked by the repository.
+// It runs after the favorites the user queued before, see waitForPendingWrites.
 func (s *Service) DeleteFavorite(ctx context.Context, favoriteID, userID string) error {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.waitForPendingWrites(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.repository.DeleteFavorite(ctx, favoriteID, userID); err != nil {
+		return fmt.Errorf("could not delete favorite: %w", err)
+	}
+	return nil
+}
+
+// DeleteFavoriteByAsset moves a user's favorite of the given asset to the trash.
+// Like DeleteFavorite, only the user's own favorite can be deleted, and it runs after the favorites the user queued before.
+func (s *Service) DeleteFavoriteByAsset(ctx context.Context, assetID, userID string) error {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.waitForPendingWrites(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.repository.DeleteFavoriteByAsset(ctx, assetID, userID); err != nil {
+		return fmt.Errorf("could not delete favorite: %w", err)
+	}
+	return nil
+}
+
+// ClearFavorites moves all of a user's favorites to the trash and returns how many were moved.
+// It runs after the favorites the user queued before, so none of them is left behind.
+func (s *Service) ClearFavorites(ctx context.Context, userID string) (int64, error) {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return 0, err
+	}
+
+	if err := s.waitForPendingWrites(ctx, userID); err != nil {
+		return 0, err
+	}
+
+	deleted, err := s.repository.DeleteUserFavorites(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("could not clear favorites: %w", err)
+	}
+	return deleted, nil
+}
+
+// ReorderFavorites puts the given favorites at the top of the user's list, in the given order.
+// The user's other favorites follow them, in the order they had.
+// It runs after the favorites the user queued before, so they are part of the new order.
+func (s *Service) ReorderFavorites(ctx context.Context, userID string, favoriteIDs []string) error {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.waitForPendingWrites(ctx, userID); err != nil {
+		return err
+	}
+
+	// Detach context to prevent cancellation while writing data.
+	ctx, cancel := context.WithTimeout(context.Background(), assets.BackgroundCtxTimeout)
+	defer cancel()
+
+	if err := s.repository.ReorderFavorites(ctx, userID, favoriteIDs); err != nil {
+		return fmt.Errorf("could not reorder favorites: %w", err)
+	}
+	return nil
+}
+
+// MoveFavorite moves a user's favorite before another one, or to the end of the list.
+// Only the moved favorite changes position, the rest of the list is left as it is.
+func (s *Service) MoveFavorite(ctx context.Context, favoriteID, userID string, params *MoveFavoriteParams) error {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.waitForPendingWrites(ctx, userID); err != nil {
+		return err
+	}
+
+	// Detach context to prevent cancellation while writing data.
+	ctx, cancel := context.WithTimeout(context.Background(), assets.BackgroundCtxTimeout)
+	defer cancel()
+
+	if err := s.repository.MoveFavorite(ctx, favoriteID, userID, params); err != nil {
+		return fmt.Errorf("could not move favorite: %w", err)
+	}
+	return nil
+}
+
+// ApplyFavoriteOps applies a batch of changes to a user's favorites synchronously, in order.
+// The returned slice holds the outcome of each op, in the same order as ops.
+// With atomic set, either all ops are applied or none is: if one fails, the others end with ErrBatchAborted.
+// Otherwise every op is applied on its own. Either way, the ops run after the favorites the user queued before.
+func (s *Service) ApplyFavoriteOps(ctx context.Context, userID string, ops []FavoriteOp, atomic bool) ([]error, error) {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.waitForPendingWrites(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	// Detach context to prevent cancellation while writing data.
+	ctx, cancel := context.WithTimeout(context.Background(), assets.BackgroundCtxTimeout)
+	defer cancel()
+
+	results, err := s.repository.ApplyFavoriteOps(ctx, userID, ops, atomic)
+	if err != nil {
+		return nil, fmt.Errorf("could not apply favorite operations: %w", err)
+	}
+	return results, nil
+}
+
+// QueueStats returns the current state of the favorites queue for monitoring.
+func (s *Service) QueueStats(ctx context.Context) (*QueueStats, error) {
+	pending, err := s.repository.CountPendingFavoriteJobs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not count pending favorite jobs: %w", err)
+	}
+
+	stats := QueueStats{
+		PendingJobs:    pending,
+		MaxPendingJobs: s.maxPendingJobs,
+		BufferCapacity: s.bufferSize,
+		RejectedJobs:   s.rejected.Load(),
+	}
+
+	if s.consumer != nil {
+		stats.BufferedTasks = s.consumer.workerPool.buffered()
+	}
+	return &stats, nil
+}
+
+// ListDeadLetters fetches a page of favorite jobs that failed for good, most recent first.
+// The returned page token is empty when there are no more pages to fetch.
+func (s *Service) ListDeadLetters(ctx context.Context, params *ListDeadLettersParams) ([]DeadLetter, string, error) {
+	deadLetters, err := s.repository.ListDeadLetters(ctx, params)
+	if err != nil {
+		return nil, "", fmt.Errorf("could not list dead letters: %w", err)
+	}
+
+	var nextPageToken string
+	if len(deadLetters) > 0 && len(deadLetters) == params.PageSize {
+		nextPageToken = deadLetters[len(deadLetters)-1].ID
+	}
+	return deadLetters, nextPageToken, nil
+}
+
+// ReplayDeadLetter queues the favorite job behind a dead letter again and removes the dead letter.
+// The returned job tracks the new attempt.
+func (s *Service) ReplayDeadLetter(_ context.Context, id string) (*FavoriteJob, error) {
+	// Detach context to prevent cancellation while writing data.
+	ctx, cancel := context.WithTimeout(context.Background(), assets.BackgroundCtxTimeout)
+	defer cancel()
+
+	job, err := s.repository.ReplayDeadLetter(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrDeadLetterNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("could not replay dead letter: %w", err)
+	}
+
+	if s.consumer != nil {
+		s.consumer.wake()
+	}
+	return job, nil
+}
+
+// processFavoriteTasks stores a batch of favorites handed over by the worker pool
+// and records the outcome on each of their jobs. The worker pool already runs it
+// with a context detached from the original requests.
+// If the batch fails as a whole with an error that isn't transient, a single bad favorite
+// could be to blame, so the favorites are stored one at a time to find out which.
+func (s *Service) processFavoriteTasks(ctx context.Context, tasks []*favoriteTask) []error {
+	params := make([]*FavoriteAssetParams, 0, len(tasks))
+	for _, task := range tasks {
+		params = append(params, task.params)
+	}
+
+	results, err := s.repository.StoreFavoriteAssets(ctx, params)
+	if err != nil && len(tasks) > 1 && !errors.Is(err, ErrTransient) {
+		errs := make([]error, 0, len(tasks))
+		for _, task := range tasks {
+			errs = append(errs, s.processFavoriteTasks(ctx, []*favoriteTask{task})...)
+		}
+		return errs
+	}
+
+	errs := make([]error, len(tasks))
+	for i, task := range tasks {
+		storeErr := err
+		if storeErr == nil {
+			storeErr = results[i]
+		}
+		errs[i] = s.settleFavoriteTask(ctx, task, storeErr)
+	}
+	return errs
+}
+
+// ensureUser checks the user exists before acting on their behalf.
+func (s *Service) ensureUser(ctx context.Context, userID string) error {
 	if _, err := s.usersSvc.FetchUser(ctx, userID); err != nil {
 		if errors.Is(err, users.ErrUserNotFound) {
 			return err
 		}
 		return fmt.Errorf("could not fetch user: %w", err)
 	}
-	if err := s.repository.DeleteFavorite(ctx, favoriteID, userID); err != nil {
-		return fmt.Errorf("could not delete favorite: %w", err)
-	}
 	return nil
 }
 
-func (s *Service) handleAsyncFavorite(params *FavoriteAssetParams) {
-	// Create a context detached from the original to avoid cancellation due to the request being closed.
-	// Yet, use a timeout to prevent goroutines from running indefinitely (pgx will handle it)
-	ctx, cancel := context.WithTimeout(context.Background(), assets.BackgroundCtxTimeout)
+// settleFavoriteTask records the outcome of storing a favorite on its job.
+// Transient failures are retried later with backoff, anything else
+// fails the job and leaves a dead letter behind for someone to look at.
+func (s *Service) settleFavoriteTask(ctx context.Context, task *favoriteTask, err error) error {
+	if err == nil {
+		if err := s.repository.CompleteFavoriteJob(ctx, task.jobID, task.attempts); err != nil {
+			// The job stays pending and is claimed again once its lease expires,
+			// or, with ErrLeaseLost, it already was and is settled by whoever claimed it.
+			// Storing a favorite is an upsert, so doing it twice is harmless.
+			return fmt.Errorf("could not complete favorite job: %w", err)
+		}
+		return nil
+	}
+
+	if s.retryPolicy.shouldRetry(err, task.attempts) {
+		runAt := time.Now().Add(s.retryPolicy.backoff(task.attempts))
+		if retryErr := s.repository.RetryFavoriteJob(ctx, task.jobID, task.attempts, runAt, err); retryErr != nil {
+			return fmt.Errorf("could not schedule favorite job retry: %v (original error: %w)", retryErr, err)
+		}
+		return fmt.Errorf("could not store favorite asset, retrying at %s: %w", runAt.Format(time.RFC3339), err)
+	}
+
+	if dlErr := s.repository.DeadLetterFavoriteJob(ctx, task.jobID, task.attempts, persistableJobError(err)); dlErr != nil {
+		return fmt.Errorf("could not dead letter favorite job: %v (original error: %w)", dlErr, err)
+	}
+	return fmt.Errorf("could not store favorite asset: %w", err)
+}
+
+// waitForPendingWrites blocks until the favorite jobs the user has pending are finished.
+// Consumers process each user's jobs in the order they were queued,
+// so waiting for the latest one is enough. Jobs queued while waiting are not waited for.
+// Synchronous operations call this first so they can't overtake favorites queued before them,
+// like a delete landing before the favorite it's meant to remove.
+// If the jobs are not finished in time, e.g. because one is waiting to be retried, it gives up with ErrPendingWrites.
+func (s *Service) waitForPendingWrites(ctx context.Context, userID string) error {
+	jobID, err := s.repository.LatestPendingFavoriteJobID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("could not fetch pending favorite jobs: %w", err)
+	}
+	if jobID == "" {
+		return nil
+	}
+
+	if s.consumer != nil {
+		s.consumer.wake()
+	}
+
+	ctx, cancel := context.WithTimeoutCause(ctx, s.writesWait.timeout, ErrPendingWrites)
 	defer cancel()
 
-	if err := s.repository.StoreFavoriteAsset(ctx, params); err != nil {
-		if errors.Is(err, assets.ErrAssetNotFound) {
-			s.logger.Error("Asset not found when trying to favorite",
-				slog.String("user_id", params.UserID),
-				slog.String("asset_id", params.AssetID),
-				slog.String("error", err.Error()),
-			)
-			return
+	ticker := time.NewTicker(s.writesWait.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		job, err := s.repository.FetchFavoriteJob(ctx, jobID)
+		if err != nil {
+			switch {
+			case errors.Is(err, ErrFavoriteJobNotFound):
+				// Purged, so it finished a while ago.
+				return nil
+			case ctx.Err() != nil:
+				return context.Cause(ctx)
+			default:
+				return fmt.Errorf("could not fetch pending favorite job: %w", err)
+			}
+		}
+		if job.Status != JobStatusPending {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-ticker.C:
 		}
-		s.logger.Error("Failed to store favorite asset",
-			"error", err,
-			"user_id", params.UserID,
-			"asset_id", params.AssetID,
-		)
-		// NOTE: I should stop writing tests and collect metrics to generate
-		// alerts in case we see too many errors here =]
 	}
 }
 
 func (s *Service) Shutdown(ctx context.Context) error {
 	s.logger.Info("Shutting down favorites service")
 
+	if s.consumer == nil {
+		return nil
+	}
+
 	doneCh := make(chan struct{})
 
 	go func() {
-		s.workerPool.stop()
+		s.purger.stop()
+		s.consumer.stop()
 		close(doneCh)
 	}()
 
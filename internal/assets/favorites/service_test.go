@@ -3,9 +3,13 @@ package favorites
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/alesr/platform-go-challenge/internal/assets"
 	"github.com/alesr/platform-go-challenge/internal/pkg/logutil"
 	"github.com/alesr/platform-go-challenge/internal/users"
 	"github.com/oklog/ulid/v2"
@@ -18,54 +22,1311 @@ func TestService_FavoriteAsset(t *testing.T) {
 
 	userID := ulid.Make()
 
+	givenParams := FavoriteAssetParams{
+		UserID:      userID.String(),
+		AssetID:     "asset-123",
+		Description: "my favorite asset",
+	}
+
+	givenJob := FavoriteJob{
+		ID:          ulid.Make().String(),
+		UserID:      givenParams.UserID,
+		AssetID:     givenParams.AssetID,
+		Description: givenParams.Description,
+		Status:      JobStatusPending,
+	}
+
+	testCases := []struct {
+		name                          string
+		givenFetchUserResult          func() (*users.User, error)
+		givenEnqueueFavoriteJobResult func() (*FavoriteJob, error)
+		expectedJob                   *FavoriteJob
+		expectedRejections            int64
+		expectedError                 error
+	}{
+		{
+			name: "success",
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
+			},
+			givenEnqueueFavoriteJobResult: func() (*FavoriteJob, error) {
+				return &givenJob, nil
+			},
+			expectedJob: &givenJob,
+		},
+		{
+			name: "user not found",
+			givenFetchUserResult: func() (*users.User, error) {
+				return nil, users.ErrUserNotFound
+			},
+			expectedError: users.ErrUserNotFound,
+		},
+		{
+			name: "user service rand error",
+			givenFetchUserResult: func() (*users.User, error) {
+				return nil, assert.AnError
+			},
+			expectedError: assert.AnError,
+		},
+		{
+			name: "queue full",
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
+			},
+			givenEnqueueFavoriteJobResult: func() (*FavoriteJob, error) {
+				return nil, ErrQueueFull
+			},
+			expectedRejections: 1,
+			expectedError:      ErrQueueFull,
+		},
+		{
+			name: "enqueue error",
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
+			},
+			givenEnqueueFavoriteJobResult: func() (*FavoriteJob, error) {
+				return nil, assert.AnError
+			},
+			expectedError: assert.AnError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				userSvcCalled bool
+				repoCalled    bool
+			)
+
+			userSvc := userSvcMock{
+				fetchUserFunc: func(ctx context.Context, id string) (*users.User, error) {
+					userSvcCalled = true
+					assert.Equal(t, givenParams.UserID, id)
+					return tc.givenFetchUserResult()
+				},
+			}
+
+			repo := repoMock{
+				enqueueFavoriteJobFunc: func(ctx context.Context, params *FavoriteAssetParams, maxPending int) (*FavoriteJob, error) {
+					repoCalled = true
+					assert.Equal(t, &givenParams, params)
+					assert.Equal(t, 42, maxPending)
+					return tc.givenEnqueueFavoriteJobResult()
+				},
+			}
+
+			svc := NewService(logutil.NewNoop(), &repo, &userSvc, WithMaxPendingJobs(42))
+
+			job, err := svc.FavoriteAsset(context.TODO(), &givenParams)
+
+			assert.True(t, userSvcCalled)
+
+			if tc.expectedError != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, job)
+				assert.Equal(t, tc.expectedRejections, svc.rejected.Load())
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, repoCalled)
+			assert.Equal(t, tc.expectedJob, job)
+		})
+	}
+}
+
+func TestService_ProcessFavoriteTasks_Outcome(t *testing.T) {
+	t.Parallel()
+
+	jobID := ulid.Make().String()
+	givenParams := &FavoriteAssetParams{
+		UserID:  ulid.Make().String(),
+		AssetID: "asset-123",
+	}
+
+	transientErr := fmt.Errorf("%w: connection reset", ErrTransient)
+
+	testCases := []struct {
+		name                          string
+		givenAttempts                 int
+		givenStoreFavoriteAssetResult error
+		givenJobUpdateResult          error
+		expectCompleted               bool
+		expectRetried                 bool
+		expectDeadLetterErr           error
+		expectedError                 error
+	}{
+		{
+			name:            "success completes the job",
+			givenAttempts:   1,
+			expectCompleted: true,
+		},
+		{
+			name:                 "complete job error",
+			givenAttempts:        1,
+			givenJobUpdateResult: assert.AnError,
+			expectCompleted:      true,
+			expectedError:        assert.AnError,
+		},
+		{
+			name:                          "transient error is retried",
+			givenAttempts:                 1,
+			givenStoreFavoriteAssetResult: transientErr,
+			expectRetried:                 true,
+			expectedError:                 ErrTransient,
+		},
+		{
+			name:                          "transient error is dead lettered after the last attempt",
+			givenAttempts:                 jobMaxAttempts,
+			givenStoreFavoriteAssetResult: transientErr,
+			expectDeadLetterErr:           transientErr,
+			expectedError:                 ErrTransient,
+		},
+		{
+			name:                          "asset not found is dead lettered right away",
+			givenAttempts:                 1,
+			givenStoreFavoriteAssetResult: fmt.Errorf("wrapped: %w", assets.ErrAssetNotFound),
+			// known errors are persisted unwrapped so they can be restored later
+			expectDeadLetterErr: assets.ErrAssetNotFound,
+			expectedError:       assets.ErrAssetNotFound,
+		},
+		{
+			name:                          "dead letter error",
+			givenAttempts:                 1,
+			givenStoreFavoriteAssetResult: assets.ErrAssetNotFound,
+			givenJobUpdateResult:          assert.AnError,
+			expectDeadLetterErr:           assets.ErrAssetNotFound,
+			expectedError:                 assets.ErrAssetNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				completed     bool
+				retried       bool
+				deadLetterErr error
+			)
+
+			repo := repoMock{
+				storeFavoriteAssetsFunc: func(ctx context.Context, params []*FavoriteAssetParams) ([]error, error) {
+					assert.Equal(t, []*FavoriteAssetParams{givenParams}, params)
+					return []error{tc.givenStoreFavoriteAssetResult}, nil
+				},
+				completeFavoriteJobFunc: func(ctx context.Context, id string, attempt int) error {
+					assert.Equal(t, jobID, id)
+					assert.Equal(t, tc.givenAttempts, attempt)
+					completed = true
+					return tc.givenJobUpdateResult
+				},
+				retryFavoriteJobFunc: func(ctx context.Context, id string, attempt int, runAt time.Time, jobErr error) error {
+					assert.Equal(t, jobID, id)
+					assert.Equal(t, tc.givenAttempts, attempt)
+					assert.True(t, runAt.After(time.Now()))
+					assert.ErrorIs(t, jobErr, ErrTransient)
+					retried = true
+					return tc.givenJobUpdateResult
+				},
+				deadLetterFavoriteJobFunc: func(ctx context.Context, id string, attempt int, jobErr error) error {
+					assert.Equal(t, jobID, id)
+					assert.Equal(t, tc.givenAttempts, attempt)
+					deadLetterErr = jobErr
+					return tc.givenJobUpdateResult
+				},
+			}
+
+			svc := NewService(logutil.NewNoop(), &repo, nil)
+
+			errs := svc.processFavoriteTasks(context.TODO(), []*favoriteTask{{
+				jobID:    jobID,
+				attempts: tc.givenAttempts,
+				params:   givenParams,
+			}})
+			require.Len(t, errs, 1)
+			err := errs[0]
+
+			assert.Equal(t, tc.expectCompleted, completed)
+			assert.Equal(t, tc.expectRetried, retried)
+			assert.Equal(t, tc.expectDeadLetterErr, deadLetterErr)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestService_ProcessFavoriteTasks_Batch(t *testing.T) {
+	t.Parallel()
+
+	givenTasks := []*favoriteTask{
+		{jobID: "job-1", attempts: 1, params: &FavoriteAssetParams{UserID: "user-1", AssetID: "asset-1"}},
+		{jobID: "job-2", attempts: 1, params: &FavoriteAssetParams{UserID: "user-2", AssetID: "asset-2"}},
+	}
+
+	transientErr := fmt.Errorf("%w: connection reset", ErrTransient)
+
+	testCases := []struct {
+		name               string
+		givenStoreResults  func(params []*FavoriteAssetParams) ([]error, error)
+		expectedStoreCalls int
+		expectCompleted    []string
+		expectRetried      []string
+		expectDeadLettered []string
+	}{
+		{
+			name: "results are settled per favorite",
+			givenStoreResults: func(params []*FavoriteAssetParams) ([]error, error) {
+				return []error{nil, assets.ErrAssetNotFound}, nil
+			},
+			expectedStoreCalls: 1,
+			expectCompleted:    []string{"job-1"},
+			expectDeadLettered: []string{"job-2"},
+		},
+		{
+			name: "transient batch error retries every favorite",
+			givenStoreResults: func(params []*FavoriteAssetParams) ([]error, error) {
+				return nil, transientErr
+			},
+			expectedStoreCalls: 1,
+			expectRetried:      []string{"job-1", "job-2"},
+		},
+		{
+			name: "other batch errors store favorites one at a time",
+			givenStoreResults: func(params []*FavoriteAssetParams) ([]error, error) {
+				if len(params) > 1 {
+					return nil, assert.AnError
+				}
+				if params[0].AssetID == "asset-2" {
+					return nil, assert.AnError
+				}
+				return []error{nil}, nil
+			},
+			expectedStoreCalls: 3,
+			expectCompleted:    []string{"job-1"},
+			expectDeadLettered: []string{"job-2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				storeCalls                       int
+				completed, retried, deadLettered []string
+			)
+
+			repo := repoMock{
+				storeFavoriteAssetsFunc: func(ctx context.Context, params []*FavoriteAssetParams) ([]error, error) {
+					storeCalls++
+					return tc.givenStoreResults(params)
+				},
+				completeFavoriteJobFunc: func(ctx context.Context, id string, attempt int) error {
+					completed = append(completed, id)
+					return nil
+				},
+				retryFavoriteJobFunc: func(ctx context.Context, id string, attempt int, runAt time.Time, jobErr error) error {
+					retried = append(retried, id)
+					return nil
+				},
+				deadLetterFavoriteJobFunc: func(ctx context.Context, id string, attempt int, jobErr error) error {
+					deadLettered = append(deadLettered, id)
+					return nil
+				},
+			}
+
+			svc := NewService(logutil.NewNoop(), &repo, nil)
+
+			errs := svc.processFavoriteTasks(context.TODO(), givenTasks)
+			require.Len(t, errs, len(givenTasks))
+
+			assert.Equal(t, tc.expectedStoreCalls, storeCalls)
+			assert.Equal(t, tc.expectCompleted, completed)
+			assert.Equal(t, tc.expectRetried, retried)
+			assert.Equal(t, tc.expectDeadLettered, deadLettered)
+
+			for i, task := range givenTasks {
+				if slices.Contains(tc.expectCompleted, task.jobID) {
+					assert.NoError(t, errs[i])
+				} else {
+					assert.Error(t, errs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestService_QueueStats(t *testing.T) {
+	t.Parallel()
+
+	repo := repoMock{
+		countPendingFavoriteJobsFunc: func(ctx context.Context) (int64, error) {
+			return 7, nil
+		},
+	}
+
+	svc := NewService(logutil.NewNoop(), &repo, nil, WithBufferSize(5), WithMaxPendingJobs(100))
+	svc.rejected.Add(3)
+
+	stats, err := svc.QueueStats(context.TODO())
+	require.NoError(t, err)
+
+	assert.Equal(t, &QueueStats{
+		PendingJobs:    7,
+		MaxPendingJobs: 100,
+		BufferedTasks:  0,
+		BufferCapacity: 5,
+		RejectedJobs:   3,
+	}, stats)
+
+	repo.countPendingFavoriteJobsFunc = func(ctx context.Context) (int64, error) {
+		return 0, assert.AnError
+	}
+
+	_, err = svc.QueueStats(context.TODO())
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestNewService_Options(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(logutil.NewNoop(), &repoMock{}, nil)
+	assert.Equal(t, defaultBufferSize, svc.bufferSize)
+	assert.Equal(t, defaultMaxPendingJobs, svc.maxPendingJobs)
+	assert.Equal(t, batchConfig{size: defaultBatchSize, linger: defaultBatchLinger}, svc.batch)
+	assert.Equal(t, defaultTrashRetention, svc.trashRetention)
+
+	svc = NewService(logutil.NewNoop(), &repoMock{}, nil,
+		WithBufferSize(3), WithMaxPendingJobs(9), WithBatchSize(4), WithBatchLinger(time.Second),
+		WithTrashRetention(time.Hour),
+	)
+	assert.Equal(t, 3, svc.bufferSize)
+	assert.Equal(t, 9, svc.maxPendingJobs)
+	assert.Equal(t, batchConfig{size: 4, linger: time.Second}, svc.batch)
+	assert.Equal(t, time.Hour, svc.trashRetention)
+
+	// invalid values keep the defaults
+	svc = NewService(logutil.NewNoop(), &repoMock{}, nil,
+		WithBufferSize(0), WithMaxPendingJobs(-1), WithBatchSize(0), WithBatchLinger(-time.Second),
+		WithTrashRetention(0),
+	)
+	assert.Equal(t, defaultBufferSize, svc.bufferSize)
+	assert.Equal(t, defaultMaxPendingJobs, svc.maxPendingJobs)
+	assert.Equal(t, batchConfig{size: defaultBatchSize, linger: defaultBatchLinger}, svc.batch)
+	assert.Equal(t, defaultTrashRetention, svc.trashRetention)
+}
+
+func TestService_ListDeadLetters(t *testing.T) {
+	t.Parallel()
+
+	givenDeadLetters := []DeadLetter{
+		{ID: "dl-2", JobID: "job-2", LastError: "asset not found"},
+		{ID: "dl-1", JobID: "job-1", LastError: "asset not found"},
+	}
+
+	testCases := []struct {
+		name              string
+		givenParams       *ListDeadLettersParams
+		givenRepoResult   func() ([]DeadLetter, error)
+		expectedPageToken string
+		expectedError     error
+	}{
+		{
+			name:        "partial page",
+			givenParams: &ListDeadLettersParams{PageSize: 10},
+			givenRepoResult: func() ([]DeadLetter, error) {
+				return givenDeadLetters, nil
+			},
+		},
+		{
+			name:        "full page returns next page token",
+			givenParams: &ListDeadLettersParams{PageSize: 2},
+			givenRepoResult: func() ([]DeadLetter, error) {
+				return givenDeadLetters, nil
+			},
+			expectedPageToken: "dl-1",
+		},
+		{
+			name:        "repository error",
+			givenParams: &ListDeadLettersParams{PageSize: 2},
+			givenRepoResult: func() ([]DeadLetter, error) {
+				return nil, assert.AnError
+			},
+			expectedError: assert.AnError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := repoMock{
+				listDeadLettersFunc: func(ctx context.Context, params *ListDeadLettersParams) ([]DeadLetter, error) {
+					assert.Equal(t, tc.givenParams, params)
+					return tc.givenRepoResult()
+				},
+			}
+
+			svc := NewService(logutil.NewNoop(), &repo, nil)
+
+			deadLetters, pageToken, err := svc.ListDeadLetters(context.TODO(), tc.givenParams)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, givenDeadLetters, deadLetters)
+			assert.Equal(t, tc.expectedPageToken, pageToken)
+		})
+	}
+}
+
+func TestService_ReplayDeadLetter(t *testing.T) {
+	t.Parallel()
+
+	givenJob := FavoriteJob{ID: ulid.Make().String(), Status: JobStatusPending}
+
+	testCases := []struct {
+		name            string
+		givenRepoResult func() (*FavoriteJob, error)
+		expectedError   error
+	}{
+		{
+			name: "success",
+			givenRepoResult: func() (*FavoriteJob, error) {
+				return &givenJob, nil
+			},
+		},
+		{
+			name: "dead letter not found",
+			givenRepoResult: func() (*FavoriteJob, error) {
+				return nil, ErrDeadLetterNotFound
+			},
+			expectedError: ErrDeadLetterNotFound,
+		},
+		{
+			name: "repository error",
+			givenRepoResult: func() (*FavoriteJob, error) {
+				return nil, assert.AnError
+			},
+			expectedError: assert.AnError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := repoMock{
+				replayDeadLetterFunc: func(ctx context.Context, id string) (*FavoriteJob, error) {
+					assert.Equal(t, "dl-1", id)
+					return tc.givenRepoResult()
+				},
+			}
+
+			svc := NewService(logutil.NewNoop(), &repo, nil)
+
+			job, err := svc.ReplayDeadLetter(context.TODO(), "dl-1")
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, job)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, &givenJob, job)
+		})
+	}
+}
+
+func TestService_FetchUserFavorites(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make()
+
+	givenFavorites := []FavoriteAsset{
+		{
+			ID:          "fav-1",
+			UserID:      userID.String(),
+			AssetID:     "asset-1",
+			Description: "my favorite asset 1",
+			Position:    "a",
+		},
+		{
+			ID:          "fav-2",
+			UserID:      userID.String(),
+			AssetID:     "asset-2",
+			Description: "my favorite asset 2",
+			Position:    "b",
+		},
+	}
+
+	lastSearchRank := float32(0.3)
+	lastFrecency := 1008.5
+
+	testCases := []struct {
+		name                        string
+		givenUserID                 string
+		givenParams                 *ListFavoritesParams
+		givenFetchUserResult        func() (*users.User, error)
+		givenGetUserFavoritesResult func() ([]FavoriteAsset, error)
+		expectedFavorites           []FavoriteAsset
+		expectedPageToken           string
+		expectedError               error
+	}{
+		{
+			name:        "success",
+			givenUserID: userID.String(),
+			givenParams: &ListFavoritesParams{PageSize: 10},
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
+			},
+			givenGetUserFavoritesResult: func() ([]FavoriteAsset, error) {
+				return givenFavorites, nil
+			},
+			expectedFavorites: givenFavorites,
+		},
+		{
+			name:        "full page returns next page token",
+			givenUserID: userID.String(),
+			givenParams: &ListFavoritesParams{PageSize: 2},
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
+			},
+			givenGetUserFavoritesResult: func() ([]FavoriteAsset, error) {
+				return givenFavorites, nil
+			},
+			expectedFavorites: givenFavorites,
+			expectedPageToken: Cursor{Position: givenFavorites[1].Position, ID: "fav-2"}.Encode(),
+		},
+		{
+			name:        "full page of search results returns ranked page token",
+			givenUserID: userID.String(),
+			givenParams: &ListFavoritesParams{PageSize: 2, Query: "asset"},
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
+			},
+			givenGetUserFavoritesResult: func() ([]FavoriteAsset, error) {
+				return []FavoriteAsset{
+					{ID: "fav-1", Position: "a", SearchRank: 0.6},
+					{ID: "fav-2", Position: "b", SearchRank: 0.3},
+				}, nil
+			},
+			expectedFavorites: []FavoriteAsset{
+				{ID: "fav-1", Position: "a", SearchRank: 0.6},
+				{ID: "fav-2", Position: "b", SearchRank: 0.3},
+			},
+			expectedPageToken: Cursor{Position: "b", ID: "fav-2", SearchRank: &lastSearchRank}.Encode(),
+		},
+		{
+			name:        "full page sorted by frecency returns page token with frecency",
+			givenUserID: userID.String(),
+			givenParams: &ListFavoritesParams{PageSize: 2, Sort: SortFrecency},
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
+			},
+			givenGetUserFavoritesResult: func() ([]FavoriteAsset, error) {
+				return []FavoriteAsset{
+					{ID: "fav-2", Position: "b", Frecency: 1010},
+					{ID: "fav-1", Position: "a", Frecency: 1008.5},
+				}, nil
+			},
+			expectedFavorites: []FavoriteAsset{
+				{ID: "fav-2", Position: "b", Frecency: 1010},
+				{ID: "fav-1", Position: "a", Frecency: 1008.5},
+			},
+			expectedPageToken: Cursor{Position: "a", ID: "fav-1", Frecency: &lastFrecency}.Encode(),
+		},
+		{
+			name:        "wait for pending writes",
+			givenUserID: userID.String(),
+			givenParams: &ListFavoritesParams{PageSize: 10, WaitForPendingWrites: true},
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
+			},
+			givenGetUserFavoritesResult: func() ([]FavoriteAsset, error) {
+				return givenFavorites, nil
+			},
+			expectedFavorites: givenFavorites,
+		},
+		{
+			name:        "user not found",
+			givenUserID: userID.String(),
+			givenParams: &ListFavoritesParams{PageSize: 10},
+			givenFetchUserResult: func() (*users.User, error) {
+				return nil, users.ErrUserNotFound
+			},
+			givenGetUserFavoritesResult: func() ([]FavoriteAsset, error) {
+				return nil, nil
+			},
+			expectedError: users.ErrUserNotFound,
+		},
+		{
+			name:        "user service random error",
+			givenUserID: userID.String(),
+			givenParams: &ListFavoritesParams{PageSize: 10},
+			givenFetchUserResult: func() (*users.User, error) {
+				return nil, assert.AnError
+			},
+			givenGetUserFavoritesResult: func() ([]FavoriteAsset, error) {
+				return nil, nil
+			},
+			expectedError: assert.AnError,
+		},
+		{
+			name:        "repository error",
+			givenUserID: userID.String(),
+			givenParams: &ListFavoritesParams{PageSize: 10},
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
+			},
+			givenGetUserFavoritesResult: func() ([]FavoriteAsset, error) {
+				return nil, assert.AnError
+			},
+			expectedError: assert.AnError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				userSvcCalled bool
+				repoCalled    bool
+				waited        bool
+			)
+
+			userSvc := userSvcMock{
+				fetchUserFunc: func(ctx context.Context, id string) (*users.User, error) {
+					userSvcCalled = true
+					assert.Equal(t, tc.givenUserID, id)
+					return tc.givenFetchUserResult()
+				},
+			}
+
+			repo := repoMock{
+				latestPendingFavoriteJobIDFunc: func(ctx context.Context, userID string) (string, error) {
+					waited = true
+					return "", nil
+				},
+				getuserfavoritesFunc: func(ctx context.Context, userID string, params *ListFavoritesParams) ([]FavoriteAsset, error) {
+					repoCalled = true
+					assert.Equal(t, tc.givenUserID, userID)
+					assert.Equal(t, tc.givenParams, params)
+					return tc.givenGetUserFavoritesResult()
+				},
+			}
+
+			svc := NewService(logutil.NewNoop(), &repo, &userSvc)
+
+			favorites, nextPageToken, err := svc.FetchUserFavorites(context.TODO(), tc.givenUserID, tc.givenParams)
+
+			assert.True(t, userSvcCalled)
+
+			if tc.expectedError != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, repoCalled)
+			assert.Equal(t, tc.givenParams.WaitForPendingWrites, waited)
+			assert.Equal(t, tc.expectedFavorites, favorites)
+			assert.Equal(t, tc.expectedPageToken, nextPageToken)
+		})
+	}
+}
+
+func TestCursor_EncodeDecode(t *testing.T) {
+	t.Parallel()
+
+	given := Cursor{
+		Position: "0000001i",
+		ID:       ulid.Make().String(),
+	}
+
+	got, err := DecodeCursor(given.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, &given, got)
+
+	// search ranks survive the round trip exactly
+	searchRank := float32(0.0607927)
+	given.SearchRank = &searchRank
+
+	got, err = DecodeCursor(given.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, &given, got)
+
+	// so do frecencies, along with ranks or not, including the one of favorites never opened
+	frecency := 1008.123456789
+	given.Frecency = &frecency
+
+	got, err = DecodeCursor(given.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, &given, got)
+
+	neverOpened := math.Inf(-1)
+	given.SearchRank, given.Frecency = nil, &neverOpened
+
+	got, err = DecodeCursor(given.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, &given, got)
+
+	// not base64, no separator, no position, no ID, invalid rank, invalid frecency, empty frecency
+	for _, invalid := range []string{
+		"not base64!", "Zm9v", "fGJhcg", "Zm9vfA", "Zm9vfGJhcnxiYXo", "Zm9vfGJhcnx8YmF6", "Zm9vfGJhcnwwLjF8",
+	} {
+		_, err := DecodeCursor(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestService_UpdateFavorite(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make()
+	favoriteID := ulid.Make()
+
+	givenParams := &UpdateFavoriteParams{
+		Description: "updated description",
+	}
+
+	givenFavoriteAsset := &FavoriteAsset{
+		ID:          favoriteID.String(),
+		UserID:      userID.String(),
+		AssetID:     "asset-123",
+		Description: givenParams.Description,
+	}
+
+	testCases := []struct {
+		name                      string
+		givenFavoriteID           string
+		givenUserID               string
+		givenParams               *UpdateFavoriteParams
+		givenUpdateFavoriteResult func() (*FavoriteAsset, error)
+		expectedFavorite          *FavoriteAsset
+		expectedError             error
+	}{
+		{
+			name:            "success",
+			givenFavoriteID: favoriteID.String(),
+			givenUserID:     userID.String(),
+			givenParams:     givenParams,
+			givenUpdateFavoriteResult: func() (*FavoriteAsset, error) {
+				return givenFavoriteAsset, nil
+			},
+			expectedFavorite: givenFavoriteAsset,
+		},
+		{
+			name:            "favorite not found",
+			givenFavoriteID: favoriteID.String(),
+			givenUserID:     userID.String(),
+			givenParams:     givenParams,
+			givenUpdateFavoriteResult: func() (*FavoriteAsset, error) {
+				return nil, ErrFavoriteAssetNotFound
+			},
+			expectedError: ErrFavoriteAssetNotFound,
+		},
+		{
+			name:            "repository error",
+			givenFavoriteID: favoriteID.String(),
+			givenUserID:     userID.String(),
+			givenParams:     givenParams,
+			givenUpdateFavoriteResult: func() (*FavoriteAsset, error) {
+				return nil, assert.AnError
+			},
+			expectedError: assert.AnError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var repoCalled bool
+
+			repo := repoMock{
+				latestPendingFavoriteJobIDFunc: func(ctx context.Context, userID string) (string, error) {
+					return "", nil
+				},
+				updatefavoriteFunc: func(ctx context.Context, favID, userID string, params *UpdateFavoriteParams) (*FavoriteAsset, error) {
+					repoCalled = true
+					assert.Equal(t, tc.givenFavoriteID, favID)
+					assert.Equal(t, tc.givenUserID, userID)
+					assert.Equal(t, tc.givenParams, params)
+					return tc.givenUpdateFavoriteResult()
+				},
+			}
+
+			svc := NewService(logutil.NewNoop(), &repo, nil)
+
+			favorite, err := svc.UpdateFavorite(context.TODO(), tc.givenFavoriteID, tc.givenUserID, tc.givenParams)
+
+			assert.True(t, repoCalled)
+
+			if tc.expectedError != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, favorite)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, favorite)
+			assert.Equal(t, tc.expectedFavorite, favorite)
+		})
+	}
+}
+
+func TestService_DeleteFavorite(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make()
+	favoriteID := ulid.Make()
+
+	testCases := []struct {
+		name                      string
+		givenFavoriteID           string
+		givenUserID               string
+		givenFetchUserResult      func() (*users.User, error)
+		givenDeleteFavoriteResult func() error
+		expectedError             error
+	}{
+		{
+			name:            "success",
+			givenFavoriteID: favoriteID.String(),
+			givenUserID:     userID.String(),
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
+			},
+			givenDeleteFavoriteResult: func() error {
+				return nil
+			},
+		},
+		{
+			name:            "user not found",
+			givenFavoriteID: favoriteID.String(),
+			givenUserID:     userID.String(),
+			givenFetchUserResult: func() (*users.User, error) {
+				return nil, users.ErrUserNotFound
+			},
+			givenDeleteFavoriteResult: func() error {
+				return nil
+			},
+			expectedError: users.ErrUserNotFound,
+		},
+		{
+			name:            "user service error",
+			givenFavoriteID: favoriteID.String(),
+			givenUserID:     userID.String(),
+			givenFetchUserResult: func() (*users.User, error) {
+				return nil, assert.AnError
+			},
+			givenDeleteFavoriteResult: func() error {
+				return nil
+			},
+			expectedError: assert.AnError,
+		},
+		{
+			name:            "favorite not found",
+			givenFavoriteID: favoriteID.String(),
+			givenUserID:     userID.String(),
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
+			},
+			givenDeleteFavoriteResult: func() error {
+				return ErrFavoriteAssetNotFound
+			},
+			expectedError: ErrFavoriteAssetNotFound,
+		},
+		{
+			name:            "repository error",
+			givenFavoriteID: favoriteID.String(),
+			givenUserID:     userID.String(),
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
+			},
+			givenDeleteFavoriteResult: func() error {
+				return assert.AnError
+			},
+			expectedError: assert.AnError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				userSvcCalled bool
+				repoCalled    bool
+			)
+
+			userSvc := userSvcMock{
+				fetchUserFunc: func(ctx context.Context, id string) (*users.User, error) {
+					userSvcCalled = true
+					assert.Equal(t, tc.givenUserID, id)
+					return tc.givenFetchUserResult()
+				},
+			}
+
+			repo := repoMock{
+				latestPendingFavoriteJobIDFunc: func(ctx context.Context, userID string) (string, error) {
+					return "", nil
+				},
+				deleteFavoriteFunc: func(ctx context.Context, favoriteID, userID string) error {
+					repoCalled = true
+					assert.Equal(t, tc.givenFavoriteID, favoriteID)
+					assert.Equal(t, tc.givenUserID, userID)
+					return tc.givenDeleteFavoriteResult()
+				},
+			}
+
+			svc := NewService(logutil.NewNoop(), &repo, &userSvc)
+
+			err := svc.DeleteFavorite(context.TODO(), tc.givenFavoriteID, tc.givenUserID)
+
+			assert.True(t, userSvcCalled)
+
+			if tc.expectedError != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tc.expectedError)
+				// if error is from user service, repository should not be called
+				if errors.Is(tc.expectedError, users.ErrUserNotFound) ||
+					tc.expectedError == assert.AnError && !repoCalled {
+					assert.False(t, repoCalled)
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, repoCalled)
+		})
+	}
+}
+
+func TestService_ApplyFavoriteOps(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+
+	givenOps := []FavoriteOp{
+		{Type: FavoriteOpAdd, AssetID: "asset-1", Description: "foo"},
+		{Type: FavoriteOpRemove, AssetID: "asset-2"},
+	}
+
+	testCases := []struct {
+		name                 string
+		givenFetchUserResult func() (*users.User, error)
+		givenPendingJobID    string
+		givenApplyResult     func() ([]error, error)
+		expectedResults      []error
+		expectedError        error
+	}{
+		{
+			name: "success",
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
+			},
+			givenApplyResult: func() ([]error, error) {
+				return []error{nil, ErrFavoriteAssetNotFound}, nil
+			},
+			expectedResults: []error{nil, ErrFavoriteAssetNotFound},
+		},
+		{
+			name: "user not found",
+			givenFetchUserResult: func() (*users.User, error) {
+				return nil, users.ErrUserNotFound
+			},
+			expectedError: users.ErrUserNotFound,
+		},
+		{
+			name: "pending writes",
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
+			},
+			givenPendingJobID: "job-1",
+			expectedError:     ErrPendingWrites,
+		},
+		{
+			name: "repository error",
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
+			},
+			givenApplyResult: func() ([]error, error) {
+				return nil, assert.AnError
+			},
+			expectedError: assert.AnError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			userSvc := userSvcMock{
+				fetchUserFunc: func(ctx context.Context, id string) (*users.User, error) {
+					assert.Equal(t, userID, id)
+					return tc.givenFetchUserResult()
+				},
+			}
+
+			repo := repoMock{
+				latestPendingFavoriteJobIDFunc: func(ctx context.Context, id string) (string, error) {
+					return tc.givenPendingJobID, nil
+				},
+				fetchFavoriteJobFunc: func(ctx context.Context, jobID string) (*FavoriteJob, error) {
+					return &FavoriteJob{ID: jobID, Status: JobStatusPending}, nil
+				},
+				applyFavoriteOpsFunc: func(ctx context.Context, id string, ops []FavoriteOp, atomic bool) ([]error, error) {
+					assert.Equal(t, userID, id)
+					assert.Equal(t, givenOps, ops)
+					assert.True(t, atomic)
+					return tc.givenApplyResult()
+				},
+			}
+
+			svc := NewService(logutil.NewNoop(), &repo, &userSvc)
+			svc.writesWait = writesWait{timeout: 10 * time.Millisecond, pollInterval: time.Millisecond}
+
+			results, err := svc.ApplyFavoriteOps(context.TODO(), userID, givenOps, true)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, results)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedResults, results)
+		})
+	}
+}
+
+func TestService_WaitForPendingWrites(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+	jobID := ulid.Make().String()
+
+	testCases := []struct {
+		name               string
+		givenLatestJobID   func() (string, error)
+		givenJobStatuses   []JobStatus
+		givenFetchJobError error
+		expectedFetches    int
+		expectedError      error
+	}{
+		{
+			name: "no pending jobs",
+			givenLatestJobID: func() (string, error) {
+				return "", nil
+			},
+		},
+		{
+			name: "pending job finishes",
+			givenLatestJobID: func() (string, error) {
+				return jobID, nil
+			},
+			givenJobStatuses: []JobStatus{JobStatusPending, JobStatusPending, JobStatusSucceeded},
+			expectedFetches:  3,
+		},
+		{
+			name: "failed job counts as finished",
+			givenLatestJobID: func() (string, error) {
+				return jobID, nil
+			},
+			givenJobStatuses: []JobStatus{JobStatusFailed},
+			expectedFetches:  1,
+		},
+		{
+			name: "pending job was purged",
+			givenLatestJobID: func() (string, error) {
+				return jobID, nil
+			},
+			givenFetchJobError: ErrFavoriteJobNotFound,
+			expectedFetches:    1,
+		},
+		{
+			name: "pending job does not finish in time",
+			givenLatestJobID: func() (string, error) {
+				return jobID, nil
+			},
+			givenJobStatuses: []JobStatus{JobStatusPending},
+			expectedError:    ErrPendingWrites,
+		},
+		{
+			name: "repository error",
+			givenLatestJobID: func() (string, error) {
+				return "", assert.AnError
+			},
+			expectedError: assert.AnError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var fetches int
+
+			repo := repoMock{
+				latestPendingFavoriteJobIDFunc: func(ctx context.Context, id string) (string, error) {
+					assert.Equal(t, userID, id)
+					return tc.givenLatestJobID()
+				},
+				fetchFavoriteJobFunc: func(ctx context.Context, id string) (*FavoriteJob, error) {
+					assert.Equal(t, jobID, id)
+					fetches++
+					if tc.givenFetchJobError != nil {
+						return nil, tc.givenFetchJobError
+					}
+					// stay on the last status once we run out of them
+					status := tc.givenJobStatuses[min(fetches, len(tc.givenJobStatuses))-1]
+					return &FavoriteJob{ID: id, Status: status}, nil
+				},
+			}
+
+			svc := NewService(logutil.NewNoop(), &repo, nil)
+			svc.writesWait = writesWait{timeout: 100 * time.Millisecond, pollInterval: time.Millisecond}
+
+			err := svc.waitForPendingWrites(context.TODO(), userID)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedFetches, fetches)
+		})
+	}
+}
+
+func TestService_FetchFavoriteJob(t *testing.T) {
+	t.Parallel()
+
+	jobID := ulid.Make().String()
+
+	testCases := []struct {
+		name                        string
+		givenFetchFavoriteJobResult func() (*FavoriteJob, error)
+		expectedJobErr              error
+		expectedError               error
+	}{
+		{
+			name: "pending job",
+			givenFetchFavoriteJobResult: func() (*FavoriteJob, error) {
+				return &FavoriteJob{ID: jobID, Status: JobStatusPending}, nil
+			},
+		},
+		{
+			name: "known job error is restored",
+			givenFetchFavoriteJobResult: func() (*FavoriteJob, error) {
+				return &FavoriteJob{
+					ID:     jobID,
+					Status: JobStatusFailed,
+					Err:    errors.New(assets.ErrAssetNotFound.Error()),
+				}, nil
+			},
+			expectedJobErr: assets.ErrAssetNotFound,
+		},
+		{
+			name: "unknown job error is kept",
+			givenFetchFavoriteJobResult: func() (*FavoriteJob, error) {
+				return &FavoriteJob{ID: jobID, Status: JobStatusFailed, Err: assert.AnError}, nil
+			},
+			expectedJobErr: assert.AnError,
+		},
+		{
+			name: "job not found",
+			givenFetchFavoriteJobResult: func() (*FavoriteJob, error) {
+				return nil, ErrFavoriteJobNotFound
+			},
+			expectedError: ErrFavoriteJobNotFound,
+		},
+		{
+			name: "repository error",
+			givenFetchFavoriteJobResult: func() (*FavoriteJob, error) {
+				return nil, assert.AnError
+			},
+			expectedError: assert.AnError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := repoMock{
+				fetchFavoriteJobFunc: func(ctx context.Context, id string) (*FavoriteJob, error) {
+					assert.Equal(t, jobID, id)
+					return tc.givenFetchFavoriteJobResult()
+				},
+			}
+
+			svc := NewService(logutil.NewNoop(), &repo, nil)
+
+			job, err := svc.FetchFavoriteJob(context.TODO(), jobID)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, job)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, jobID, job.ID)
+			assert.Equal(t, tc.expectedJobErr, job.Err)
+		})
+	}
+}
+
+func TestService_DeleteFavoriteByAsset(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+	assetID := ulid.Make().String()
+
 	testCases := []struct {
-		name                          string
-		givenParams                   FavoriteAssetParams
-		givenFetchUserResult          func() (*users.User, error)
-		givenStoreFavoriteAssetResult func() error
-		expectedError                 error
+		name                 string
+		givenFetchUserResult func() (*users.User, error)
+		givenDeleteResult    error
+		expectRepoCalled     bool
+		expectedError        error
 	}{
 		{
 			name: "success",
-			givenParams: FavoriteAssetParams{
-				UserID:      userID.String(),
-				AssetID:     "asset-123",
-				Description: "my favorite asset",
-			},
 			givenFetchUserResult: func() (*users.User, error) {
 				return &users.User{}, nil
 			},
-			givenStoreFavoriteAssetResult: func() error {
-				return nil
-			},
+			expectRepoCalled: true,
 		},
 		{
 			name: "user not found",
-			givenParams: FavoriteAssetParams{
-				UserID:  userID.String(),
-				AssetID: "asset-123",
-			},
 			givenFetchUserResult: func() (*users.User, error) {
 				return nil, users.ErrUserNotFound
 			},
-			givenStoreFavoriteAssetResult: func() error {
-				return nil
-			},
 			expectedError: users.ErrUserNotFound,
 		},
 		{
-			name: "user service rand error",
-			givenParams: FavoriteAssetParams{
-				UserID:  userID.String(),
-				AssetID: "asset-123",
-			},
+			name: "favorite not found",
 			givenFetchUserResult: func() (*users.User, error) {
-				return nil, assert.AnError
-			},
-			givenStoreFavoriteAssetResult: func() error {
-				return nil
+				return &users.User{}, nil
 			},
-			expectedError: assert.AnError,
+			givenDeleteResult: ErrFavoriteAssetNotFound,
+			expectRepoCalled:  true,
+			expectedError:     ErrFavoriteAssetNotFound,
 		},
 	}
 
@@ -73,126 +1334,86 @@ func TestService_FavoriteAsset(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			var (
-				userSvcCalled bool
-				repoCalled    bool
-			)
+			var repoCalled bool
 
 			userSvc := userSvcMock{
 				fetchUserFunc: func(ctx context.Context, id string) (*users.User, error) {
-					userSvcCalled = true
-					assert.Equal(t, tc.givenParams.UserID, id)
+					assert.Equal(t, userID, id)
 					return tc.givenFetchUserResult()
 				},
 			}
 
 			repo := repoMock{
-				storeFavoriteAssetFunc: func(ctx context.Context, params *FavoriteAssetParams) error {
+				latestPendingFavoriteJobIDFunc: func(ctx context.Context, id string) (string, error) {
+					return "", nil
+				},
+				deleteFavoriteByAssetFunc: func(ctx context.Context, aID, uID string) error {
 					repoCalled = true
-					assert.Equal(t, &tc.givenParams, params)
-					return tc.givenStoreFavoriteAssetResult()
+					assert.Equal(t, assetID, aID)
+					assert.Equal(t, userID, uID)
+					return tc.givenDeleteResult
 				},
 			}
 
 			svc := NewService(logutil.NewNoop(), &repo, &userSvc)
 
-			// create a context with timeout for the entire test
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-
-			err := svc.FavoriteAsset(ctx, &tc.givenParams)
-
-			assert.True(t, userSvcCalled)
+			err := svc.DeleteFavoriteByAsset(context.TODO(), assetID, userID)
 
+			assert.Equal(t, tc.expectRepoCalled, repoCalled)
 			if tc.expectedError != nil {
-				require.Error(t, err)
 				assert.ErrorIs(t, err, tc.expectedError)
 				return
 			}
-
 			require.NoError(t, err)
-
-			// wait for worker pool to process the task
-			err = svc.Shutdown(ctx)
-			require.NoError(t, err)
-
-			// now we can check if the repository was called
-			if tc.expectedError == nil {
-				assert.True(t, repoCalled)
-			}
 		})
 	}
 }
 
-func TestService_FetchUserFavorites(t *testing.T) {
+func TestService_ClearFavorites(t *testing.T) {
 	t.Parallel()
 
-	userID := ulid.Make()
-
-	givenFavorites := []FavoriteAsset{
-		{
-			ID:          "fav-1",
-			UserID:      userID.String(),
-			AssetID:     "asset-1",
-			Description: "my favorite asset 1",
-		},
-		{
-			ID:          "fav-2",
-			UserID:      userID.String(),
-			AssetID:     "asset-2",
-			Description: "my favorite asset 2",
-		},
-	}
+	userID := ulid.Make().String()
 
 	testCases := []struct {
-		name                        string
-		givenUserID                 string
-		givenFetchUserResult        func() (*users.User, error)
-		givenGetUserFavoritesResult func() ([]FavoriteAsset, error)
-		expectedFavorites           []FavoriteAsset
-		expectedError               error
+		name                 string
+		givenFetchUserResult func() (*users.User, error)
+		givenPendingJobID    string
+		givenDeleteResult    func() (int64, error)
+		expectedDeleted      int64
+		expectedError        error
 	}{
 		{
-			name:        "success",
-			givenUserID: userID.String(),
+			name: "success",
 			givenFetchUserResult: func() (*users.User, error) {
 				return &users.User{}, nil
 			},
-			givenGetUserFavoritesResult: func() ([]FavoriteAsset, error) {
-				return givenFavorites, nil
+			givenDeleteResult: func() (int64, error) {
+				return 2, nil
 			},
-			expectedFavorites: givenFavorites,
+			expectedDeleted: 2,
 		},
 		{
-			name:        "user not found",
-			givenUserID: userID.String(),
+			name: "user not found",
 			givenFetchUserResult: func() (*users.User, error) {
 				return nil, users.ErrUserNotFound
 			},
-			givenGetUserFavoritesResult: func() ([]FavoriteAsset, error) {
-				return nil, nil
-			},
 			expectedError: users.ErrUserNotFound,
 		},
 		{
-			name:        "user service random error",
-			givenUserID: userID.String(),
+			name: "pending writes",
 			givenFetchUserResult: func() (*users.User, error) {
-				return nil, assert.AnError
-			},
-			givenGetUserFavoritesResult: func() ([]FavoriteAsset, error) {
-				return nil, nil
+				return &users.User{}, nil
 			},
-			expectedError: assert.AnError,
+			givenPendingJobID: "job-1",
+			expectedError:     ErrPendingWrites,
 		},
 		{
-			name:        "repository error",
-			givenUserID: userID.String(),
+			name: "repository error",
 			givenFetchUserResult: func() (*users.User, error) {
 				return &users.User{}, nil
 			},
-			givenGetUserFavoritesResult: func() ([]FavoriteAsset, error) {
-				return nil, assert.AnError
+			givenDeleteResult: func() (int64, error) {
+				return 0, assert.AnError
 			},
 			expectedError: assert.AnError,
 		},
@@ -202,101 +1423,86 @@ func TestService_FetchUserFavorites(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			var (
-				userSvcCalled bool
-				repoCalled    bool
-			)
-
 			userSvc := userSvcMock{
 				fetchUserFunc: func(ctx context.Context, id string) (*users.User, error) {
-					userSvcCalled = true
-					assert.Equal(t, tc.givenUserID, id)
+					assert.Equal(t, userID, id)
 					return tc.givenFetchUserResult()
 				},
 			}
 
 			repo := repoMock{
-				getuserfavoritesFunc: func(ctx context.Context, userID string) ([]FavoriteAsset, error) {
-					repoCalled = true
-					assert.Equal(t, tc.givenUserID, userID)
-					return tc.givenGetUserFavoritesResult()
+				latestPendingFavoriteJobIDFunc: func(ctx context.Context, id string) (string, error) {
+					return tc.givenPendingJobID, nil
+				},
+				fetchFavoriteJobFunc: func(ctx context.Context, jobID string) (*FavoriteJob, error) {
+					return &FavoriteJob{ID: jobID, Status: JobStatusPending}, nil
+				},
+				deleteUserFavoritesFunc: func(ctx context.Context, id string) (int64, error) {
+					assert.Equal(t, userID, id)
+					return tc.givenDeleteResult()
 				},
 			}
 
 			svc := NewService(logutil.NewNoop(), &repo, &userSvc)
+			svc.writesWait = writesWait{timeout: 10 * time.Millisecond, pollInterval: time.Millisecond}
 
-			favorites, err := svc.FetchUserFavorites(context.TODO(), tc.givenUserID)
-
-			assert.True(t, userSvcCalled)
+			deleted, err := svc.ClearFavorites(context.TODO(), userID)
 
 			if tc.expectedError != nil {
-				require.Error(t, err)
 				assert.ErrorIs(t, err, tc.expectedError)
 				return
 			}
 
 			require.NoError(t, err)
-			assert.True(t, repoCalled)
-			assert.Equal(t, tc.expectedFavorites, favorites)
+			assert.Equal(t, tc.expectedDeleted, deleted)
 		})
 	}
 }
 
-func TestService_UpdateFavorite(t *testing.T) {
+func TestService_ReorderFavorites(t *testing.T) {
 	t.Parallel()
 
-	userID := ulid.Make()
-	favoriteID := ulid.Make()
-
-	givenParams := &UpdateFavoriteParams{
-		Description: "updated description",
-	}
-
-	givenFavoriteAsset := &FavoriteAsset{
-		ID:          favoriteID.String(),
-		UserID:      userID.String(),
-		AssetID:     "asset-123",
-		Description: givenParams.Description,
-	}
+	userID := ulid.Make().String()
+	givenIDs := []string{"fav-2", "fav-1"}
 
 	testCases := []struct {
-		name                      string
-		givenFavoriteID           string
-		givenUserID               string
-		givenParams               *UpdateFavoriteParams
-		givenUpdateFavoriteResult func() (*FavoriteAsset, error)
-		expectedFavorite          *FavoriteAsset
-		expectedError             error
+		name                 string
+		givenFetchUserResult func() (*users.User, error)
+		givenPendingJobID    string
+		givenReorderResult   error
+		expectRepoCalled     bool
+		expectedError        error
 	}{
 		{
-			name:            "success",
-			givenFavoriteID: favoriteID.String(),
-			givenUserID:     userID.String(),
-			givenParams:     givenParams,
-			givenUpdateFavoriteResult: func() (*FavoriteAsset, error) {
-				return givenFavoriteAsset, nil
+			name: "success",
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
 			},
-			expectedFavorite: givenFavoriteAsset,
+			expectRepoCalled: true,
 		},
 		{
-			name:            "favorite not found",
-			givenFavoriteID: favoriteID.String(),
-			givenUserID:     userID.String(),
-			givenParams:     givenParams,
-			givenUpdateFavoriteResult: func() (*FavoriteAsset, error) {
-				return nil, ErrFavoriteAssetNotFound
+			name: "user not found",
+			givenFetchUserResult: func() (*users.User, error) {
+				return nil, users.ErrUserNotFound
 			},
-			expectedError: ErrFavoriteAssetNotFound,
+			expectedError: users.ErrUserNotFound,
 		},
 		{
-			name:            "repository error",
-			givenFavoriteID: favoriteID.String(),
-			givenUserID:     userID.String(),
-			givenParams:     givenParams,
-			givenUpdateFavoriteResult: func() (*FavoriteAsset, error) {
-				return nil, assert.AnError
+			name: "pending writes",
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
 			},
-			expectedError: assert.AnError,
+			givenPendingJobID: "job-1",
+			expectedError:     ErrPendingWrites,
+		},
+		{
+			name: "favorite not found",
+			givenFetchUserResult: func() (*users.User, error) {
+				return &users.User{}, nil
+			},
+			givenReorderResult: ErrFavoriteAssetNotFound,
+			expectRepoCalled:   true,
+			expectedError:      ErrFavoriteAssetNotFound,
 		},
 	}
 
@@ -306,108 +1512,78 @@ func TestService_UpdateFavorite(t *testing.T) {
 
 			var repoCalled bool
 
+			userSvc := userSvcMock{
+				fetchUserFunc: func(ctx context.Context, id string) (*users.User, error) {
+					assert.Equal(t, userID, id)
+					return tc.givenFetchUserResult()
+				},
+			}
+
 			repo := repoMock{
-				updatefavoriteFunc: func(ctx context.Context, favID, userID string, params *UpdateFavoriteParams) (*FavoriteAsset, error) {
+				latestPendingFavoriteJobIDFunc: func(ctx context.Context, id string) (string, error) {
+					return tc.givenPendingJobID, nil
+				},
+				fetchFavoriteJobFunc: func(ctx context.Context, jobID string) (*FavoriteJob, error) {
+					return &FavoriteJob{ID: jobID, Status: JobStatusPending}, nil
+				},
+				reorderFavoritesFunc: func(ctx context.Context, id string, favoriteIDs []string) error {
 					repoCalled = true
-					assert.Equal(t, tc.givenFavoriteID, favID)
-					assert.Equal(t, tc.givenUserID, userID)
-					assert.Equal(t, tc.givenParams, params)
-					return tc.givenUpdateFavoriteResult()
+					assert.Equal(t, userID, id)
+					assert.Equal(t, givenIDs, favoriteIDs)
+					return tc.givenReorderResult
 				},
 			}
 
-			svc := NewService(logutil.NewNoop(), &repo, nil)
-
-			favorite, err := svc.UpdateFavorite(context.TODO(), tc.givenFavoriteID, tc.givenUserID, tc.givenParams)
+			svc := NewService(logutil.NewNoop(), &repo, &userSvc)
+			svc.writesWait = writesWait{timeout: 10 * time.Millisecond, pollInterval: time.Millisecond}
 
-			assert.True(t, repoCalled)
+			err := svc.ReorderFavorites(context.TODO(), userID, givenIDs)
 
+			assert.Equal(t, tc.expectRepoCalled, repoCalled)
 			if tc.expectedError != nil {
-				require.Error(t, err)
 				assert.ErrorIs(t, err, tc.expectedError)
-				assert.Nil(t, favorite)
 				return
 			}
-
 			require.NoError(t, err)
-			assert.NotNil(t, favorite)
-			assert.Equal(t, tc.expectedFavorite, favorite)
 		})
 	}
 }
 
-func TestService_DeleteFavorite(t *testing.T) {
+func TestService_MoveFavorite(t *testing.T) {
 	t.Parallel()
 
-	userID := ulid.Make()
-	favoriteID := ulid.Make()
+	userID := ulid.Make().String()
+	givenParams := MoveFavoriteParams{BeforeID: "fav-1"}
 
 	testCases := []struct {
-		name                      string
-		givenFavoriteID           string
-		givenUserID               string
-		givenFetchUserResult      func() (*users.User, error)
-		givenDeleteFavoriteResult func() error
-		expectedError             error
+		name                 string
+		givenFetchUserResult func() (*users.User, error)
+		givenMoveResult      error
+		expectRepoCalled     bool
+		expectedError        error
 	}{
 		{
-			name:            "success",
-			givenFavoriteID: favoriteID.String(),
-			givenUserID:     userID.String(),
+			name: "success",
 			givenFetchUserResult: func() (*users.User, error) {
 				return &users.User{}, nil
 			},
-			givenDeleteFavoriteResult: func() error {
-				return nil
-			},
+			expectRepoCalled: true,
 		},
 		{
-			name:            "user not found",
-			givenFavoriteID: favoriteID.String(),
-			givenUserID:     userID.String(),
+			name: "user not found",
 			givenFetchUserResult: func() (*users.User, error) {
 				return nil, users.ErrUserNotFound
 			},
-			givenDeleteFavoriteResult: func() error {
-				return nil
-			},
 			expectedError: users.ErrUserNotFound,
 		},
 		{
-			name:            "user service error",
-			givenFavoriteID: favoriteID.String(),
-			givenUserID:     userID.String(),
-			givenFetchUserResult: func() (*users.User, error) {
-				return nil, assert.AnError
-			},
-			givenDeleteFavoriteResult: func() error {
-				return nil
-			},
-			expectedError: assert.AnError,
-		},
-		{
-			name:            "favorite not found",
-			givenFavoriteID: favoriteID.String(),
-			givenUserID:     userID.String(),
-			givenFetchUserResult: func() (*users.User, error) {
-				return &users.User{}, nil
-			},
-			givenDeleteFavoriteResult: func() error {
-				return ErrFavoriteAssetNotFound
-			},
-			expectedError: ErrFavoriteAssetNotFound,
-		},
-		{
-			name:            "repository error",
-			givenFavoriteID: favoriteID.String(),
-			givenUserID:     userID.String(),
+			name: "favorite not found",
 			givenFetchUserResult: func() (*users.User, error) {
 				return &users.User{}, nil
 			},
-			givenDeleteFavoriteResult: func() error {
-				return assert.AnError
-			},
-			expectedError: assert.AnError,
+			givenMoveResult:  ErrFavoriteAssetNotFound,
+			expectRepoCalled: true,
+			expectedError:    ErrFavoriteAssetNotFound,
 		},
 	}
 
@@ -415,47 +1591,38 @@ func TestService_DeleteFavorite(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			var (
-				userSvcCalled bool
-				repoCalled    bool
-			)
+			var repoCalled bool
 
 			userSvc := userSvcMock{
 				fetchUserFunc: func(ctx context.Context, id string) (*users.User, error) {
-					userSvcCalled = true
-					assert.Equal(t, tc.givenUserID, id)
+					assert.Equal(t, userID, id)
 					return tc.givenFetchUserResult()
 				},
 			}
 
 			repo := repoMock{
-				deleteFavoriteFunc: func(ctx context.Context, favoriteID, userID string) error {
+				latestPendingFavoriteJobIDFunc: func(ctx context.Context, id string) (string, error) {
+					return "", nil
+				},
+				moveFavoriteFunc: func(ctx context.Context, favID, uID string, params *MoveFavoriteParams) error {
 					repoCalled = true
-					assert.Equal(t, tc.givenFavoriteID, favoriteID)
-					assert.Equal(t, tc.givenUserID, userID)
-					return tc.givenDeleteFavoriteResult()
+					assert.Equal(t, "fav-2", favID)
+					assert.Equal(t, userID, uID)
+					assert.Equal(t, &givenParams, params)
+					return tc.givenMoveResult
 				},
 			}
 
 			svc := NewService(logutil.NewNoop(), &repo, &userSvc)
 
-			err := svc.DeleteFavorite(context.TODO(), tc.givenFavoriteID, tc.givenUserID)
-
-			assert.True(t, userSvcCalled)
+			err := svc.MoveFavorite(context.TODO(), "fav-2", userID, &givenParams)
 
+			assert.Equal(t, tc.expectRepoCalled, repoCalled)
 			if tc.expectedError != nil {
-				require.Error(t, err)
 				assert.ErrorIs(t, err, tc.expectedError)
-				// if error is from user service, repository should not be called
-				if errors.Is(tc.expectedError, users.ErrUserNotFound) ||
-					tc.expectedError == assert.AnError && !repoCalled {
-					assert.False(t, repoCalled)
-				}
 				return
 			}
-
 			require.NoError(t, err)
-			assert.True(t, repoCalled)
 		})
 	}
 }
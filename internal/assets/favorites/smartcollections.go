@@ -0,0 +1,94 @@
+package favorites
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SmartCollection is a saved rule over a user's favorites.
+// Unlike a Collection, its favorites aren't stored but matched every time it's listed,
+// so it keeps up with the user's favorites without anyone curating it.
+type SmartCollection struct {
+	ID        string
+	UserID    string
+	Name      string
+	Rule      Rule
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// SmartCollectionParams defines the information needed to create a smart collection.
+type SmartCollectionParams struct {
+	Name string
+	Rule Rule
+}
+
+// CreateSmartCollection saves a rule over the user's favorites as a smart collection.
+// Names are unique among the user's smart collections, ErrCollectionNameTaken is returned otherwise.
+func (s *Service) CreateSmartCollection(ctx context.Context, userID string, params *SmartCollectionParams) (*SmartCollection, error) {
+	if err := params.Rule.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	collection, err := s.repository.CreateSmartCollection(ctx, userID, params)
+	if err != nil {
+		return nil, fmt.Errorf("could not create smart collection: %w", err)
+	}
+	return collection, nil
+}
+
+// ListSmartCollections returns all of the user's smart collections, by name.
+func (s *Service) ListSmartCollections(ctx context.Context, userID string) ([]SmartCollection, error) {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	collections, err := s.repository.ListSmartCollections(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not list smart collections: %w", err)
+	}
+	return collections, nil
+}
+
+// FetchSmartCollection returns one of the user's smart collections.
+func (s *Service) FetchSmartCollection(ctx context.Context, collectionID, userID string) (*SmartCollection, error) {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	collection, err := s.repository.FetchSmartCollection(ctx, collectionID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch smart collection: %w", err)
+	}
+	return collection, nil
+}
+
+// DeleteSmartCollection deletes one of the user's smart collections.
+func (s *Service) DeleteSmartCollection(ctx context.Context, collectionID, userID string) error {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.repository.DeleteSmartCollection(ctx, collectionID, userID); err != nil {
+		return fmt.Errorf("could not delete smart collection: %w", err)
+	}
+	return nil
+}
+
+// FetchSmartCollectionItems returns a page of the user's favorites matching the smart collection's rule,
+// in the user's order, along with the token for the next page. It pages like FetchUserFavorites.
+func (s *Service) FetchSmartCollectionItems(ctx context.Context, collectionID, userID string, params *ListFavoritesParams) ([]FavoriteAsset, string, error) {
+	collection, err := s.FetchSmartCollection(ctx, collectionID, userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	itemsParams := *params
+	itemsParams.Rule = &collection.Rule
+	return s.FetchUserFavorites(ctx, userID, &itemsParams)
+}
@@ -0,0 +1,162 @@
+package favorites
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alesr/platform-go-challenge/internal/pkg/logutil"
+	"github.com/alesr/platform-go-challenge/internal/users"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_CreateSmartCollection(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+	validRule := Rule{Field: "asset_type", Op: RuleOpEq, Value: "CHART"}
+
+	testCases := []struct {
+		name             string
+		givenRule        Rule
+		givenUserErr     error
+		expectRepoCalled bool
+		expectedError    error
+	}{
+		{
+			name:             "success",
+			givenRule:        validRule,
+			expectRepoCalled: true,
+		},
+		{
+			name:          "invalid rule",
+			givenRule:     Rule{Field: "foo", Op: RuleOpEq, Value: "bar"},
+			expectedError: ErrInvalidRule,
+		},
+		{
+			name:          "user not found",
+			givenRule:     validRule,
+			givenUserErr:  users.ErrUserNotFound,
+			expectedError: users.ErrUserNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var repoCalled bool
+
+			userSvc := userSvcMock{
+				fetchUserFunc: func(ctx context.Context, id string) (*users.User, error) {
+					assert.Equal(t, userID, id)
+					if tc.givenUserErr != nil {
+						return nil, tc.givenUserErr
+					}
+					return &users.User{}, nil
+				},
+			}
+
+			repo := repoMock{
+				createSmartCollectionFunc: func(ctx context.Context, uID string, params *SmartCollectionParams) (*SmartCollection, error) {
+					repoCalled = true
+					assert.Equal(t, userID, uID)
+					return &SmartCollection{ID: "smart-1", UserID: uID, Name: params.Name, Rule: params.Rule}, nil
+				},
+			}
+
+			svc := NewService(logutil.NewNoop(), &repo, &userSvc)
+
+			collection, err := svc.CreateSmartCollection(context.TODO(), userID, &SmartCollectionParams{
+				Name: "Charts",
+				Rule: tc.givenRule,
+			})
+
+			assert.Equal(t, tc.expectRepoCalled, repoCalled)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "smart-1", collection.ID)
+			assert.Equal(t, tc.givenRule, collection.Rule)
+		})
+	}
+}
+
+func TestService_FetchSmartCollectionItems(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+	rule := Rule{All: []Rule{
+		{Field: "asset_type", Op: RuleOpEq, Value: "AUDIENCE"},
+		{Field: "asset.birth_countries", Op: RuleOpEq, Value: "Germany"},
+	}}
+
+	testCases := []struct {
+		name             string
+		givenFetchErr    error
+		expectRepoCalled bool
+		expectedError    error
+	}{
+		{
+			name:             "success",
+			expectRepoCalled: true,
+		},
+		{
+			name:          "smart collection not found",
+			givenFetchErr: ErrSmartCollectionNotFound,
+			expectedError: ErrSmartCollectionNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var repoCalled bool
+
+			userSvc := userSvcMock{
+				fetchUserFunc: func(ctx context.Context, id string) (*users.User, error) {
+					return &users.User{}, nil
+				},
+			}
+
+			repo := repoMock{
+				fetchSmartCollectionFunc: func(ctx context.Context, collectionID, uID string) (*SmartCollection, error) {
+					assert.Equal(t, "smart-1", collectionID)
+					assert.Equal(t, userID, uID)
+					if tc.givenFetchErr != nil {
+						return nil, tc.givenFetchErr
+					}
+					return &SmartCollection{ID: collectionID, UserID: uID, Rule: rule}, nil
+				},
+				getuserfavoritesFunc: func(ctx context.Context, uID string, params *ListFavoritesParams) ([]FavoriteAsset, error) {
+					repoCalled = true
+					assert.Equal(t, userID, uID)
+					require.NotNil(t, params.Rule)
+					assert.Equal(t, rule, *params.Rule)
+					return []FavoriteAsset{{ID: "fav-1", Position: "1"}, {ID: "fav-2", Position: "2"}}, nil
+				},
+			}
+
+			svc := NewService(logutil.NewNoop(), &repo, &userSvc)
+
+			givenParams := ListFavoritesParams{PageSize: 2}
+			favs, nextPageToken, err := svc.FetchSmartCollectionItems(context.TODO(), "smart-1", userID, &givenParams)
+
+			assert.Equal(t, tc.expectRepoCalled, repoCalled)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, favs, 2)
+			assert.Equal(t, Cursor{Position: "2", ID: "fav-2"}.Encode(), nextPageToken)
+
+			// the caller's params are left as they were
+			assert.Nil(t, givenParams.Rule)
+		})
+	}
+}
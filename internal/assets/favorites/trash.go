@@ -0,0 +1,178 @@
+package favorites
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/alesr/platform-go-challenge/internal/assets"
+)
+
+// TrashedFavorite is a deleted favorite, kept in the trash until it's restored or purged.
+type TrashedFavorite struct {
+	FavoriteAsset
+	DeletedAt time.Time
+	// PurgeAt is when the favorite is purged for good, unless it's restored before.
+	PurgeAt time.Time
+}
+
+// ListTrashParams defines the parameters for listing a user's trash.
+type ListTrashParams struct {
+	PageSize int
+	// Cursor points to the last favorite of the previous page.
+	// A nil cursor means the first page.
+	Cursor *TrashCursor
+}
+
+// TrashCursor is a keyset position in a user's trash,
+// which is ordered by deletion time, most recent first, and then by favorite ID.
+type TrashCursor struct {
+	DeletedAt time.Time
+	ID        string
+}
+
+// Encode returns the cursor as an opaque page token.
+func (c TrashCursor) Encode() string {
+	token := c.DeletedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(token))
+}
+
+// DecodeTrashCursor parses a page token created by TrashCursor.Encode.
+func DecodeTrashCursor(token string) (*TrashCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode page token: %w", err)
+	}
+
+	deletedAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return nil, errors.New("malformed page token")
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, deletedAt)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse deletion time: %w", err)
+	}
+	return &TrashCursor{DeletedAt: t, ID: id}, nil
+}
+
+// WithTrashRetention sets how long deleted favorites stay in the trash, where they can be restored,
+// before they are purged for good.
+func WithTrashRetention(retention time.Duration) Option {
+	return func(s *Service) {
+		if retention > 0 {
+			s.trashRetention = retention
+		}
+	}
+}
+
+// FetchTrash fetches a page of the user's deleted favorites that can still be restored, most recently deleted first.
+// The returned page token is empty when there are no more pages to fetch.
+func (s *Service) FetchTrash(ctx context.Context, userID string, params *ListTrashParams) ([]TrashedFavorite, string, error) {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return nil, "", err
+	}
+
+	trashed, err := s.repository.GetTrashedFavorites(ctx, userID, params, time.Now().Add(-s.trashRetention))
+	if err != nil {
+		return nil, "", fmt.Errorf("could not get trashed favorites: %w", err)
+	}
+
+	for i := range trashed {
+		trashed[i].PurgeAt = trashed[i].DeletedAt.Add(s.trashRetention)
+	}
+
+	var nextPageToken string
+	if len(trashed) > 0 && len(trashed) == params.PageSize {
+		last := trashed[len(trashed)-1]
+		nextPageToken = TrashCursor{DeletedAt: last.DeletedAt, ID: last.ID}.Encode()
+	}
+	return trashed, nextPageToken, nil
+}
+
+// RestoreFavorite brings one of the user's deleted favorites back from the trash, along with its description,
+// tags and collections. ErrFavoriteAlreadyExists is returned if the user favorited the same asset again since.
+// It runs after the favorites the user queued before, see waitForPendingWrites.
+func (s *Service) RestoreFavorite(ctx context.Context, favoriteID, userID string) (*FavoriteAsset, error) {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.waitForPendingWrites(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	// Detach context to prevent cancellation while writing data.
+	ctx, cancel := context.WithTimeout(context.Background(), assets.BackgroundCtxTimeout)
+	defer cancel()
+
+	favorite, err := s.repository.RestoreFavorite(ctx, favoriteID, userID, time.Now().Add(-s.trashRetention))
+	if err != nil {
+		return nil, fmt.Errorf("could not restore favorite: %w", err)
+	}
+	return favorite, nil
+}
+
+// trashPurger periodically removes the favorites that have been in the trash for longer than the retention period.
+// Purging is idempotent, so a handful of replicas purging at the same time is harmless.
+type trashPurger struct {
+	logger     *slog.Logger
+	repository Repository
+	retention  time.Duration
+	interval   time.Duration
+	done       chan struct{}
+	stopped    chan struct{}
+}
+
+func newTrashPurger(logger *slog.Logger, repo Repository, retention, interval time.Duration) *trashPurger {
+	p := &trashPurger{
+		logger:     logger.WithGroup("trash-purger"),
+		repository: repo,
+		retention:  retention,
+		interval:   interval,
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+
+	go p.run()
+	return p
+}
+
+func (p *trashPurger) run() {
+	defer close(p.stopped)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge()
+
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *trashPurger) purge() {
+	ctx, cancel := context.WithTimeout(context.Background(), assets.BackgroundCtxTimeout)
+	defer cancel()
+
+	purged, err := p.repository.PurgeTrashedFavorites(ctx, time.Now().Add(-p.retention))
+	if err != nil {
+		p.logger.Error("failed to purge trashed favorites", slog.String("error", err.Error()))
+		return
+	}
+	p.logger.Debug("purged trashed favorites", slog.Int64("count", purged))
+}
+
+// stop stops purging and waits for a purge in progress to finish.
+func (p *trashPurger) stop() {
+	close(p.done)
+	<-p.stopped
+}
@@ -0,0 +1,214 @@
+package favorites
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alesr/platform-go-challenge/internal/pkg/logutil"
+	"github.com/alesr/platform-go-challenge/internal/users"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrashCursor_EncodeDecode(t *testing.T) {
+	t.Parallel()
+
+	given := TrashCursor{DeletedAt: time.Date(2025, 2, 18, 9, 12, 3, 456789000, time.UTC), ID: ulid.Make().String()}
+
+	got, err := DecodeTrashCursor(given.Encode())
+	require.NoError(t, err)
+	assert.True(t, given.DeletedAt.Equal(got.DeletedAt))
+	assert.Equal(t, given.ID, got.ID)
+
+	for _, token := range []string{"!!!", "bm8tc2VwYXJhdG9y", "bm90LWEtdGltZXxpZA"} {
+		_, err := DecodeTrashCursor(token)
+		assert.Error(t, err, token)
+	}
+}
+
+func TestService_FetchTrash(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+	deletedAt := time.Now().Add(-time.Hour)
+
+	testCases := []struct {
+		name                  string
+		givenUserErr          error
+		givenPageSize         int
+		expectRepoCalled      bool
+		expectedNextPageToken string
+		expectedError         error
+	}{
+		{
+			name:                  "full page",
+			givenPageSize:         1,
+			expectRepoCalled:      true,
+			expectedNextPageToken: TrashCursor{DeletedAt: deletedAt, ID: "fav-1"}.Encode(),
+		},
+		{
+			name:             "last page",
+			givenPageSize:    2,
+			expectRepoCalled: true,
+		},
+		{
+			name:          "user not found",
+			givenUserErr:  users.ErrUserNotFound,
+			givenPageSize: 1,
+			expectedError: users.ErrUserNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var repoCalled bool
+
+			userSvc := userSvcMock{
+				fetchUserFunc: func(ctx context.Context, id string) (*users.User, error) {
+					assert.Equal(t, userID, id)
+					if tc.givenUserErr != nil {
+						return nil, tc.givenUserErr
+					}
+					return &users.User{}, nil
+				},
+			}
+
+			repo := repoMock{
+				getTrashedFavoritesFunc: func(ctx context.Context, uID string, params *ListTrashParams, deletedAfter time.Time) ([]TrashedFavorite, error) {
+					repoCalled = true
+					assert.Equal(t, userID, uID)
+					assert.WithinDuration(t, time.Now().Add(-24*time.Hour), deletedAfter, time.Second)
+					return []TrashedFavorite{{FavoriteAsset: FavoriteAsset{ID: "fav-1"}, DeletedAt: deletedAt}}, nil
+				},
+			}
+
+			svc := NewService(logutil.NewNoop(), &repo, &userSvc, WithTrashRetention(24*time.Hour))
+
+			trashed, nextPageToken, err := svc.FetchTrash(context.TODO(), userID, &ListTrashParams{PageSize: tc.givenPageSize})
+
+			assert.Equal(t, tc.expectRepoCalled, repoCalled)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, trashed, 1)
+			assert.Equal(t, deletedAt.Add(24*time.Hour), trashed[0].PurgeAt)
+			assert.Equal(t, tc.expectedNextPageToken, nextPageToken)
+		})
+	}
+}
+
+func TestService_RestoreFavorite(t *testing.T) {
+	t.Parallel()
+
+	userID := ulid.Make().String()
+
+	testCases := []struct {
+		name             string
+		givenUserErr     error
+		givenRepoErr     error
+		expectRepoCalled bool
+		expectedError    error
+	}{
+		{
+			name:             "success",
+			expectRepoCalled: true,
+		},
+		{
+			name:          "user not found",
+			givenUserErr:  users.ErrUserNotFound,
+			expectedError: users.ErrUserNotFound,
+		},
+		{
+			name:             "favorite not in trash",
+			givenRepoErr:     ErrFavoriteAssetNotFound,
+			expectRepoCalled: true,
+			expectedError:    ErrFavoriteAssetNotFound,
+		},
+		{
+			name:             "asset favorited again",
+			givenRepoErr:     ErrFavoriteAlreadyExists,
+			expectRepoCalled: true,
+			expectedError:    ErrFavoriteAlreadyExists,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var repoCalled bool
+
+			userSvc := userSvcMock{
+				fetchUserFunc: func(ctx context.Context, id string) (*users.User, error) {
+					assert.Equal(t, userID, id)
+					if tc.givenUserErr != nil {
+						return nil, tc.givenUserErr
+					}
+					return &users.User{}, nil
+				},
+			}
+
+			repo := repoMock{
+				latestPendingFavoriteJobIDFunc: func(ctx context.Context, id string) (string, error) {
+					return "", nil
+				},
+				restoreFavoriteFunc: func(ctx context.Context, favID, uID string, deletedAfter time.Time) (*FavoriteAsset, error) {
+					repoCalled = true
+					assert.Equal(t, "fav-1", favID)
+					assert.Equal(t, userID, uID)
+					assert.WithinDuration(t, time.Now().Add(-defaultTrashRetention), deletedAfter, time.Second)
+					if tc.givenRepoErr != nil {
+						return nil, tc.givenRepoErr
+					}
+					return &FavoriteAsset{ID: favID, UserID: uID}, nil
+				},
+			}
+
+			svc := NewService(logutil.NewNoop(), &repo, &userSvc)
+
+			favorite, err := svc.RestoreFavorite(context.TODO(), "fav-1", userID)
+
+			assert.Equal(t, tc.expectRepoCalled, repoCalled)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "fav-1", favorite.ID)
+		})
+	}
+}
+
+func TestTrashPurger(t *testing.T) {
+	t.Parallel()
+
+	purgedBefore := make(chan time.Time, 1)
+
+	repo := repoMock{
+		purgeTrashedFavoritesFunc: func(ctx context.Context, before time.Time) (int64, error) {
+			select {
+			case purgedBefore <- before:
+			default:
+			}
+			return 1, nil
+		},
+	}
+
+	p := newTrashPurger(logutil.NewNoop(), &repo, time.Hour, time.Hour)
+
+	// purges right away, without waiting for the interval
+	select {
+	case before := <-purgedBefore:
+		assert.WithinDuration(t, time.Now().Add(-time.Hour), before, time.Second)
+	case <-time.After(time.Second):
+		t.Fatal("trash was not purged")
+	}
+
+	p.stop()
+}
@@ -2,35 +2,63 @@ package favorites
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/alesr/platform-go-challenge/internal/assets"
 )
 
-type storeFavoriteFunc func(ctx context.Context, params *FavoriteAssetParams) error
+// processBatchFunc processes a batch of tasks and returns the error each of them ended with, if any.
+type processBatchFunc func(ctx context.Context, tasks []*favoriteTask) []error
 
+// favoriteTask is the unit of work handled by the worker pool.
+// The job ID lets the processing function report back the outcome.
 type favoriteTask struct {
-	params        *FavoriteAssetParams
-	storeFavorite storeFavoriteFunc
+	jobID    string
+	attempts int // including the current one
+	params   *FavoriteAssetParams
 }
 
+// errPoolStopped is returned when submitting tasks to a stopped worker pool.
+var errPoolStopped = errors.New("worker pool stopped")
+
+// batchConfig controls how workers coalesce tasks into batches.
+// A worker processes a batch once it holds size tasks, or linger after it picked up the first one.
+type batchConfig struct {
+	size   int
+	linger time.Duration
+}
+
+// workerPool processes favorite tasks in batches with a fixed number of workers.
+// Tasks wait in a bounded buffer until a worker is free, and submitting
+// never blocks: when the buffer is full the tasks are rejected instead.
+// Tasks submitted together are never split up, so they always end up in the same batch, in order.
 type workerPool struct {
-	tasksCh       chan *FavoriteAssetParams
-	logger        *slog.Logger
-	jobs          int
-	storeFavorite storeFavoriteFunc
-	wg            sync.WaitGroup
-	done          chan struct{}
+	mu           sync.Mutex
+	stopped      bool
+	tasksCh      chan []*favoriteTask
+	bufferSize   int
+	queued       atomic.Int64 // tasks waiting in tasksCh, which holds groups of them
+	spaceCh      chan struct{}
+	logger       *slog.Logger
+	jobs         int
+	batch        batchConfig
+	processBatch processBatchFunc
+	wg           sync.WaitGroup
 }
 
-func newWorkerPool(logger *slog.Logger, jobs int, storeFavorite storeFavoriteFunc) *workerPool {
+func newWorkerPool(logger *slog.Logger, jobs, bufferSize int, batch batchConfig, processBatch processBatchFunc) *workerPool {
 	wp := &workerPool{
-		tasksCh:       make(chan *FavoriteAssetParams),
-		logger:        logger.WithGroup("worker-pool"),
-		jobs:          jobs,
-		storeFavorite: storeFavorite,
-		done:          make(chan struct{}),
+		tasksCh:      make(chan []*favoriteTask, bufferSize),
+		bufferSize:   bufferSize,
+		spaceCh:      make(chan struct{}, 1),
+		logger:       logger.WithGroup("worker-pool"),
+		jobs:         jobs,
+		batch:        batch,
+		processBatch: processBatch,
 	}
 
 	wp.wg.Add(jobs)
@@ -40,54 +68,117 @@ func newWorkerPool(logger *slog.Logger, jobs int, storeFavorite storeFavoriteFun
 	return wp
 }
 
+// worker processes batches of tasks until the pool is stopped and the buffer is drained.
 func (wp *workerPool) worker() {
 	defer wp.wg.Done()
 
-	for {
-		select {
-		case <-wp.done:
-			return
-		case params, ok := <-wp.tasksCh:
-			if !ok {
-				return
-			}
+	for tasks := range wp.tasksCh {
+		batch := wp.collect(tasks)
 
-			wp.logger.Debug(
-				"processing favorite asset",
-				slog.String("asset_id", params.AssetID),
-				slog.String("user_id", params.UserID),
-			)
+		wp.logger.Debug("processing favorite assets", slog.Int("batch_size", len(batch)))
 
-			ctx, cancel := context.WithTimeout(context.Background(), assets.BackgroundCtxTimeout)
+		ctx, cancel := context.WithTimeout(context.Background(), assets.BackgroundCtxTimeout)
 
-			if err := wp.storeFavorite(ctx, params); err != nil {
+		for i, err := range wp.processBatch(ctx, batch) {
+			if err != nil {
 				wp.logger.Error("failed to store favorite",
-					"user_id", params.UserID,
-					"asset_id", params.AssetID,
+					"job_id", batch[i].jobID,
+					"user_id", batch[i].params.UserID,
+					"asset_id", batch[i].params.AssetID,
 					"error", err,
 				)
 			}
-			cancel()
+		}
+		cancel()
+
+		// Let whoever feeds the pool know once the buffer is half empty,
+		// so it doesn't have to wait for its next poll to top it up.
+		if wp.buffered() <= wp.bufferSize/2 {
+			select {
+			case wp.spaceCh <- struct{}{}:
+			default:
+			}
 		}
 	}
 }
 
-func (wp *workerPool) submit(params *FavoriteAssetParams) {
-	select {
-	case <-wp.done:
-		// if the pool is stopped, walk away
-		return
-	default:
+// collect starts a batch with first and adds the tasks that come in until the
+// batch is full or the linger time is up. It returns right away once the pool
+// is stopped and the buffer is drained, as no more tasks are coming.
+// Tasks submitted together are added all at once, so a batch can end up over its size.
+func (wp *workerPool) collect(first []*favoriteTask) []*favoriteTask {
+	wp.queued.Add(-int64(len(first)))
+	batch := first
+
+	timer := time.NewTimer(wp.batch.linger)
+	defer timer.Stop()
+
+	for len(batch) < wp.batch.size {
 		select {
-		case <-wp.done: // double-checked locking pattern
-			return
-		case wp.tasksCh <- params:
+		case tasks, ok := <-wp.tasksCh:
+			if !ok {
+				return batch
+			}
+			wp.queued.Add(-int64(len(tasks)))
+			batch = append(batch, tasks...)
+		case <-timer.C:
+			return batch
 		}
 	}
+	return batch
 }
 
+// submit buffers the tasks for the next free worker without blocking,
+// to be processed in the same batch, in order.
+// It returns ErrQueueFull if the buffer hasn't room for all of them, and errPoolStopped
+// if the pool was stopped and the tasks won't be processed.
+func (wp *workerPool) submit(tasks ...*favoriteTask) error {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if wp.stopped {
+		return errPoolStopped
+	}
+
+	if wp.free() < len(tasks) {
+		return ErrQueueFull
+	}
+
+	// counted before they're sent, so a worker picking them up right away never brings the count below zero
+	wp.queued.Add(int64(len(tasks)))
+	select {
+	case wp.tasksCh <- tasks:
+		return nil
+	default:
+		wp.queued.Add(-int64(len(tasks)))
+		return ErrQueueFull
+	}
+}
+
+// free returns how many tasks can be submitted before the buffer is full.
+func (wp *workerPool) free() int {
+	return wp.bufferSize - wp.buffered()
+}
+
+// buffered returns how many tasks are waiting for a worker.
+func (wp *workerPool) buffered() int {
+	return int(wp.queued.Load())
+}
+
+// space signals when the buffer has room for more tasks.
+func (wp *workerPool) space() <-chan struct{} {
+	return wp.spaceCh
+}
+
+// stop stops accepting tasks and waits for the workers to
+// process the ones already buffered before returning.
 func (wp *workerPool) stop() {
-	close(wp.done)
-	close(wp.tasksCh)
+	wp.mu.Lock()
+	if !wp.stopped {
+		wp.stopped = true
+		close(wp.tasksCh)
+	}
+	wp.mu.Unlock()
+
 	wp.wg.Wait()
 }
@@ -3,14 +3,30 @@ package favorites
 import (
 	"context"
 	"fmt"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/alesr/platform-go-challenge/internal/pkg/logutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+func noopProcessBatch(ctx context.Context, tasks []*favoriteTask) []error {
+	return make([]error, len(tasks))
+}
+
+func newTestTask(i int) *favoriteTask {
+	return &favoriteTask{
+		jobID: fmt.Sprintf("job-%d", i),
+		params: &FavoriteAssetParams{
+			UserID:  fmt.Sprintf("user-%d", i),
+			AssetID: fmt.Sprintf("asset-%d", i),
+		},
+	}
+}
+
 func TestWorkerPool(t *testing.T) {
 	t.Parallel()
 
@@ -38,82 +54,200 @@ func TestWorkerPool(t *testing.T) {
 			t.Parallel()
 
 			var processedTasks atomic.Int32
-			storeFunc := func(ctx context.Context, params *FavoriteAssetParams) error {
-				processedTasks.Add(1)
+			storeFunc := func(ctx context.Context, tasks []*favoriteTask) []error {
+				processedTasks.Add(int32(len(tasks)))
+
+				errs := make([]error, len(tasks))
 				if tc.expectErr {
-					return assert.AnError
+					for i := range errs {
+						errs[i] = assert.AnError
+					}
 				}
-				return nil
+				return errs
 			}
 
-			wp := newWorkerPool(logutil.NewNoop(), concurrency, storeFunc)
+			wp := newWorkerPool(logutil.NewNoop(), concurrency, tc.givenNumOfTasks, batchConfig{size: 2, linger: time.Millisecond}, storeFunc)
 
 			// send tasks
 			for i := 0; i < tc.givenNumOfTasks; i++ {
-				wp.submit(&FavoriteAssetParams{
-					UserID:  fmt.Sprintf("user-%d", i),
-					AssetID: fmt.Sprintf("asset-%d", i),
-				})
+				require.NoError(t, wp.submit(newTestTask(i)))
 			}
 
-			// This test might fail if we add moar tasks
-			// and the workers are stopped before all tasks are processed.
-			// If you see this failing, sleep for a bit.
-			// time.Sleep(100 * time.Millisecond)
-			// Looking forward to try https://danp.net/posts/synctest-experiment/
-
+			// stop drains the buffer, so every submitted task is processed
 			wp.stop()
 			assert.Equal(t, int32(tc.givenNumOfTasks), processedTasks.Load())
 		})
 	}
 }
 
-func TestWorkerPool_StopBeforeSubmit(t *testing.T) {
+func TestWorkerPool_Batching(t *testing.T) {
 	t.Parallel()
 
-	storeFunc := func(ctx context.Context, params *FavoriteAssetParams) error {
-		return nil
+	testCases := []struct {
+		name             string
+		givenNumOfTasks  int
+		givenBatch       batchConfig
+		expectBatchSizes []int
+	}{
+		{
+			name:             "full batches are processed without lingering",
+			givenNumOfTasks:  6,
+			givenBatch:       batchConfig{size: 3, linger: time.Hour},
+			expectBatchSizes: []int{3, 3},
+		},
+		{
+			name:             "partial batch is processed once the linger time is up",
+			givenNumOfTasks:  2,
+			givenBatch:       batchConfig{size: 10, linger: 10 * time.Millisecond},
+			expectBatchSizes: []int{2},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mu         sync.Mutex
+				batchSizes []int
+			)
+
+			storeFunc := func(ctx context.Context, tasks []*favoriteTask) []error {
+				mu.Lock()
+				defer mu.Unlock()
+				batchSizes = append(batchSizes, len(tasks))
+				return make([]error, len(tasks))
+			}
+
+			// a single worker, so the tasks can't be spread over several batches
+			wp := newWorkerPool(logutil.NewNoop(), 1, tc.givenNumOfTasks, tc.givenBatch, storeFunc)
+			defer wp.stop()
+
+			for i := 0; i < tc.givenNumOfTasks; i++ {
+				require.NoError(t, wp.submit(newTestTask(i)))
+			}
+
+			assert.Eventually(t, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return assert.ObjectsAreEqual(tc.expectBatchSizes, batchSizes)
+			}, time.Second, 5*time.Millisecond)
+		})
 	}
+}
+
+func TestWorkerPool_SubmittedTogether(t *testing.T) {
+	t.Parallel()
+
+	var (
+		startedCh = make(chan struct{}, 2)
+		releaseCh = make(chan struct{})
+		batches   [][]string
+	)
+
+	storeFunc := func(ctx context.Context, tasks []*favoriteTask) []error {
+		var batch []string
+		for _, task := range tasks {
+			batch = append(batch, task.jobID)
+		}
+		batches = append(batches, batch)
+
+		startedCh <- struct{}{}
+		<-releaseCh
+		return make([]error, len(tasks))
+	}
+
+	wp := newWorkerPool(logutil.NewNoop(), 1, 4, batchConfig{size: 1}, storeFunc)
+
+	// keep the only worker busy
+	require.NoError(t, wp.submit(newTestTask(0)))
+	<-startedCh
+
+	require.NoError(t, wp.submit(newTestTask(1), newTestTask(2), newTestTask(3)))
+	assert.Equal(t, 3, wp.buffered())
+	assert.Equal(t, 1, wp.free())
+
+	// tasks submitted together are buffered all at once or not at all
+	err := wp.submit(newTestTask(4), newTestTask(5))
+	assert.ErrorIs(t, err, ErrQueueFull)
+
+	close(releaseCh)
+	wp.stop()
+
+	// and they stay in one batch, in order, even over the batch size
+	assert.Equal(t, [][]string{{"job-0"}, {"job-1", "job-2", "job-3"}}, batches)
+	assert.Zero(t, wp.buffered())
+}
+
+func TestWorkerPool_StopBeforeSubmit(t *testing.T) {
+	t.Parallel()
 
 	concurrency := 1
-	wp := newWorkerPool(logutil.NewNoop(), concurrency, storeFunc)
+	wp := newWorkerPool(logutil.NewNoop(), concurrency, 1, batchConfig{size: 1}, noopProcessBatch)
 
 	// stop the worker pool immediately
 	wp.stop()
 
-	var submitted bool
-	assert.Eventually(t, func() bool {
-		wp.submit(&FavoriteAssetParams{
-			UserID:  "user-1",
-			AssetID: "asset-1",
-		})
-		submitted = true
-		return submitted
-	}, time.Second, 100*time.Millisecond)
+	err := wp.submit(newTestTask(1))
+	assert.ErrorIs(t, err, errPoolStopped)
+}
+
+func TestWorkerPool_BufferFull(t *testing.T) {
+	t.Parallel()
+
+	var (
+		startedCh = make(chan struct{})
+		releaseCh = make(chan struct{})
+	)
+
+	storeFunc := func(ctx context.Context, tasks []*favoriteTask) []error {
+		startedCh <- struct{}{}
+		<-releaseCh
+		return make([]error, len(tasks))
+	}
+
+	wp := newWorkerPool(logutil.NewNoop(), 1, 1, batchConfig{size: 1}, storeFunc)
 
-	// submission should not block after stop
-	// since we use wg to wait for all tasks to be processed
-	assert.True(t, submitted)
+	// keep the only worker busy
+	require.NoError(t, wp.submit(newTestTask(1)))
+	<-startedCh
+
+	// the second task waits in the buffer, the third doesn't fit
+	require.NoError(t, wp.submit(newTestTask(2)))
+	assert.Equal(t, 1, wp.buffered())
+	assert.Zero(t, wp.free())
+
+	err := wp.submit(newTestTask(3))
+	assert.ErrorIs(t, err, ErrQueueFull)
+
+	close(releaseCh)
+	<-startedCh
+
+	// the worker signals there's room once it picks up the buffered task
+	select {
+	case <-wp.space():
+	case <-time.After(time.Second):
+		t.Fatal("worker pool did not signal space in the buffer")
+	}
+
+	wp.stop()
 }
 
 func TestWorkerPool_ContextCancel(t *testing.T) {
 	t.Parallel()
 
 	var ctxCanceled bool
-	mockStoreFavorite := func(ctx context.Context, params *FavoriteAssetParams) error {
+	mockStoreFavorites := func(ctx context.Context, tasks []*favoriteTask) []error {
 		if ctx != nil && ctx.Err() == nil {
 			ctxCanceled = true
 		}
-		return nil
+		return make([]error, len(tasks))
 	}
 
 	concurrency := 1
-	wp := newWorkerPool(logutil.NewNoop(), concurrency, mockStoreFavorite)
+	wp := newWorkerPool(logutil.NewNoop(), concurrency, 1, batchConfig{size: 1}, mockStoreFavorites)
 
-	wp.submit(&FavoriteAssetParams{
-		UserID:  "user-1",
-		AssetID: "asset-1",
-	})
+	_ = wp.submit(newTestTask(1))
 
 	wp.stop()
 
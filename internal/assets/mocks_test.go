@@ -9,14 +9,40 @@ import (
 var _ Repository = &repoMock{}
 
 type repoMock struct {
-	storeAssetFunc func(ctx context.Context, asset Asseter) error
-	listAssetsFunc func(ctx context.Context, params *ListAssetsParams) ([]Asseter, string, error)
+	storeAssetFunc  func(ctx context.Context, asset Asseter) error
+	listAssetsFunc  func(ctx context.Context, params *ListAssetsParams) ([]ListedAsset, string, error)
+	fetchAssetFunc  func(ctx context.Context, id string) (Asseter, error)
+	updateAssetFunc func(ctx context.Context, asset Asseter) (Asseter, error)
+	deleteAssetFunc func(ctx context.Context, id string) error
+
+	storeRespondentsFunc func(ctx context.Context, respondents []Respondent) error
+	countRespondentsFunc func(ctx context.Context, audience AudienceAsset) (int, error)
 }
 
 func (m *repoMock) StoreAsset(ctx context.Context, asset Asseter) error {
 	return m.storeAssetFunc(ctx, asset)
 }
 
-func (m *repoMock) ListAssets(ctx context.Context, params *ListAssetsParams) ([]Asseter, string, error) {
+func (m *repoMock) ListAssets(ctx context.Context, params *ListAssetsParams) ([]ListedAsset, string, error) {
 	return m.listAssetsFunc(ctx, params)
 }
+
+func (m *repoMock) FetchAsset(ctx context.Context, id string) (Asseter, error) {
+	return m.fetchAssetFunc(ctx, id)
+}
+
+func (m *repoMock) UpdateAsset(ctx context.Context, asset Asseter) (Asseter, error) {
+	return m.updateAssetFunc(ctx, asset)
+}
+
+func (m *repoMock) DeleteAsset(ctx context.Context, id string) error {
+	return m.deleteAssetFunc(ctx, id)
+}
+
+func (m *repoMock) StoreRespondents(ctx context.Context, respondents []Respondent) error {
+	return m.storeRespondentsFunc(ctx, respondents)
+}
+
+func (m *repoMock) CountRespondents(ctx context.Context, audience AudienceAsset) (int, error) {
+	return m.countRespondentsFunc(ctx, audience)
+}
@@ -3,175 +3,120 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/alesr/platform-go-challenge/internal/assets"
+	"github.com/jackc/pgx/v5"
 )
 
+// selectAssetsQuery combines the tables of all the registered asset types into a single result set,
+// with the data of each asset as a JSON object keyed like in the API.
+// Callers append their own filtering, ordering and limits to it.
+func selectAssetsQuery() string {
+	selects := make([]string, 0, len(assets.Kinds()))
+	for _, kind := range assets.Kinds() {
+		fields := make([]string, 0, len(kind.Fields()))
+		for _, f := range kind.Fields() {
+			fields = append(fields, fmt.Sprintf("'%s', %s", f.Name, f.Column))
+		}
+
+		selects = append(selects, fmt.Sprintf(`
+        (SELECT
+            id,
+            '%s' as asset_type,
+            jsonb_build_object(%s) as data,
+            created_at,
+            updated_at
+        FROM %s)`,
+			kind.Type(), strings.Join(fields, ", "), kind.Table(),
+		))
+	}
+	return "\n    SELECT * FROM (" + strings.Join(selects, "\n        UNION ALL") + "\n    ) combined"
+}
+
+// StoreAsset inserts the asset into the table of its type.
 func (r *Repository) StoreAsset(ctx context.Context, asset assets.Asseter) error {
-	switch v := asset.(type) {
-	case assets.ChartAsset:
-		return r.storeChartAsset(ctx, v)
-	case assets.InsightAsset:
-		return r.storeInsightAsset(ctx, v)
-	case assets.AudienceAsset:
-		return r.storeAudienceAsset(ctx, v)
-	default:
-		return errors.New("unsupported asset type")
+	kind, data, err := encodeAsset(asset)
+	if err != nil {
+		return err
+	}
+
+	record, err := storageRecord(kind, data)
+	if err != nil {
+		return err
 	}
+
+	columns := storageColumns(kind)
+	metadata := asset.Metadata()
+
+	if _, err := r.db.Exec(ctx, fmt.Sprintf(`
+        INSERT INTO %[1]s (id, %[2]s, created_at, updated_at)
+        SELECT $1::text, %[3]s, $3::timestamptz, $4::timestamptz
+        FROM jsonb_populate_record(NULL::%[1]s, $2::jsonb) r`,
+		kind.Table(), strings.Join(columns, ", "), prefixColumns("r.", columns)),
+		metadata.ID,
+		record,
+		metadata.CreatedAt,
+		metadata.UpdatedAt,
+	); err != nil {
+		return fmt.Errorf("could not insert %s asset: %w", strings.ToLower(string(kind.Type())), err)
+	}
+	return nil
 }
 
-func (r *Repository) ListAssets(ctx context.Context, params *assets.ListAssetsParams) ([]assets.Asseter, string, error) {
+// ListAssets returns a page of assets along with how many users favorited each of them,
+// and the listing user's favorite of each, if any, all from a single query.
+// Counts are read from asset_favorite_counts, which a trigger keeps up to date as favorites come and go.
+func (r *Repository) ListAssets(ctx context.Context, params *assets.ListAssetsParams) ([]assets.ListedAsset, string, error) {
 	var lastID string
 	if params.PageToken != "" {
 		lastID = params.PageToken
 	}
 
-	query := `
-    SELECT * FROM (
-        (SELECT
-            id,
-            'CHART' as asset_type,
-            title,
-            x_axis,
-            y_axis,
-            data,
-            NULL as insight_data,
-            NULL as gender,
-            NULL as birth_country,
-            NULL::integer as age_min,
-            NULL::integer as age_max,
-            NULL::integer as social_media_hours,
-            NULL::integer as last_month_purchases,
-            created_at,
-            updated_at
-        FROM chart_assets)
-        UNION ALL
-        (SELECT
-            id,
-            'INSIGHT' as asset_type,
-            NULL as title,
-            NULL as x_axis,
-            NULL as y_axis,
-            NULL::float[] as data,
-            data as insight_data,
-            NULL as gender,
-            NULL as birth_country,
-            NULL::integer as age_min,
-            NULL::integer as age_max,
-            NULL::integer as social_media_hours,
-            NULL::integer as last_month_purchases,
-            created_at,
-            updated_at
-        FROM insight_assets)
-        UNION ALL
-        (SELECT
-            id,
-            'AUDIENCE' as asset_type,
-            NULL as title,
-            NULL as x_axis,
-            NULL as y_axis,
-            NULL::float[] as data,
-            NULL as insight_data,
-            gender,
-            birth_country,
-            age_min,
-            age_max,
-            social_media_hours,
-            last_month_purchases,
-            created_at,
-            updated_at
-        FROM audience_assets)
-    ) combined
-    WHERE ($1 = '' OR combined.id > $1)
-    ORDER BY combined.id
-    LIMIT $2`
+	query := fmt.Sprintf(`
+        SELECT
+            a.*,
+            COALESCE(c.favorite_count, 0),
+            f.id
+        FROM (%s) a
+        LEFT JOIN asset_favorite_counts c ON c.asset_id = a.id
+        LEFT JOIN user_favorites f ON f.asset_id = a.id AND f.user_id = $3 AND f.deleted_at IS NULL
+        WHERE ($1 = '' OR a.id > $1)
+        ORDER BY a.id
+        LIMIT $2`,
+		selectAssetsQuery(),
+	)
 
-	rows, err := r.db.Query(ctx, query, lastID, params.PageSize)
+	rows, err := r.db.Query(ctx, query, lastID, params.PageSize, params.UserID)
 	if err != nil {
 		return nil, "", fmt.Errorf("could not query assets: %w", err)
 	}
 	defer rows.Close()
 
-	factory := assets.NewAssetFactory()
 	var (
-		result     []assets.Asseter
+		result     []assets.ListedAsset
 		lastIDSeen string
 	)
 
 	for rows.Next() {
 		var (
-			id                 string
-			assetType          string
-			title              sql.NullString
-			xAxis              sql.NullString
-			yAxis              sql.NullString
-			data               []float64
-			insightData        sql.NullString
-			gender             sql.NullString
-			birthCountry       sql.NullString
-			ageMin             sql.NullInt32
-			ageMax             sql.NullInt32
-			socialMediaHours   sql.NullInt32
-			lastMonthPurchases sql.NullInt32
-			createdAt          time.Time
-			updatedAt          time.Time
+			ar         assetRow
+			listed     assets.ListedAsset
+			favoriteID sql.NullString
 		)
-
-		if err := rows.Scan(
-			&id,
-			&assetType,
-			&title,
-			&xAxis,
-			&yAxis,
-			&data,
-			&insightData,
-			&gender,
-			&birthCountry,
-			&ageMin,
-			&ageMax,
-			&socialMediaHours,
-			&lastMonthPurchases,
-			&createdAt,
-			&updatedAt,
-		); err != nil {
+		if err := rows.Scan(append(ar.scanDest(), &listed.FavoriteCount, &favoriteID)...); err != nil {
 			return nil, "", fmt.Errorf("could not scan asset: %w", err)
 		}
-
-		var asset assets.Asseter
-		switch assets.AssetType(assetType) {
-		case assets.TypeAssetChart:
-			chart := factory.CreateChart(title.String, xAxis.String, yAxis.String, data)
-			chart.CreatedAt = createdAt
-			chart.UpdatedAt = updatedAt
-			chart.ID = id
-			asset = chart
-
-		case assets.TypeAssetInsight:
-			insight := factory.CreateInsight(insightData.String)
-			insight.CreatedAt = createdAt
-			insight.UpdatedAt = updatedAt
-			insight.ID = id
-			asset = insight
-
-		case assets.TypeAssetAudience:
-			audience := factory.CreateAudience(
-				gender.String,
-				birthCountry.String,
-				int(ageMin.Int32),
-				int(ageMax.Int32),
-				int(socialMediaHours.Int32),
-				int(lastMonthPurchases.Int32),
-			)
-			audience.CreatedAt = createdAt
-			audience.UpdatedAt = updatedAt
-			audience.ID = id
-			asset = audience
+		if listed.Asset, err = ar.toAsset(); err != nil {
+			return nil, "", err
 		}
-		result = append(result, asset)
-		lastIDSeen = id
+		listed.FavoriteID = favoriteID.String
+		result = append(result, listed)
+		lastIDSeen = ar.id
 	}
 
 	if err := rows.Err(); err != nil {
@@ -180,56 +125,195 @@ func (r *Repository) ListAssets(ctx context.Context, params *assets.ListAssetsPa
 	return result, lastIDSeen, nil
 }
 
-// Internal
+// FetchAsset looks up an asset by ID across all asset tables.
+func (r *Repository) FetchAsset(ctx context.Context, id string) (assets.Asseter, error) {
+	asset, _, err := scanAsset(r.db.QueryRow(ctx, selectAssetsQuery()+`
+    WHERE combined.id = $1`, id,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, assets.ErrAssetNotFound
+		}
+		return nil, err
+	}
+	return asset, nil
+}
 
-func (r *Repository) storeChartAsset(ctx context.Context, asset assets.ChartAsset) error {
-	if _, err := r.db.Exec(ctx, `
-        INSERT INTO chart_assets (id, title, x_axis, y_axis, data, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7)`,
-		asset.ID,
-		asset.Data.Title,
-		asset.Data.XAxis,
-		asset.Data.YAxis,
-		asset.Data.Data,
-		asset.CreatedAt,
-		asset.UpdatedAt,
-	); err != nil {
-		return fmt.Errorf("could not insert chart asset: %w", err)
+// UpdateAsset replaces the data of an existing asset.
+// The returned asset carries the creation timestamp kept by the database.
+func (r *Repository) UpdateAsset(ctx context.Context, asset assets.Asseter) (assets.Asseter, error) {
+	kind, data, err := encodeAsset(asset)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+
+	record, err := storageRecord(kind, data)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := storageColumns(kind)
+	metadata := asset.Metadata()
+
+	if err := r.db.QueryRow(ctx, fmt.Sprintf(`
+        UPDATE %[1]s
+        SET (%[2]s, updated_at) = (
+            SELECT %[3]s, $2::timestamptz
+            FROM jsonb_populate_record(NULL::%[1]s, $1::jsonb) r
+        )
+        WHERE id = $3
+        RETURNING created_at`,
+		kind.Table(), strings.Join(columns, ", "), prefixColumns("r.", columns)),
+		record,
+		metadata.UpdatedAt,
+		metadata.ID,
+	).Scan(&metadata.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, assets.ErrAssetNotFound
+		}
+		return nil, fmt.Errorf("could not update %s asset: %w", strings.ToLower(string(kind.Type())), err)
+	}
+
+	updated, err := kind.Load(metadata, data)
+	if err != nil {
+		return nil, fmt.Errorf("could not load updated asset: %w", err)
+	}
+	return updated, nil
 }
 
-func (r *Repository) storeInsightAsset(ctx context.Context, asset assets.InsightAsset) error {
-	if _, err := r.db.Exec(ctx, `
-        INSERT INTO insight_assets (id, data, created_at, updated_at)
-        VALUES ($1, $2, $3, $4)`,
-		asset.ID,
-		asset.Data.Insight,
-		asset.CreatedAt,
-		asset.UpdatedAt,
-	); err != nil {
-		return fmt.Errorf("could not insret insight asset: %w", err)
+// DeleteAsset removes an asset and the favorites pointing to it.
+// Everything runs in a single statement, so we never leave dangling favorites behind.
+func (r *Repository) DeleteAsset(ctx context.Context, id string) error {
+	var (
+		deletes []string
+		counts  []string
+	)
+	for i, kind := range assets.Kinds() {
+		deletes = append(deletes, fmt.Sprintf("deleted_%d AS (DELETE FROM %s WHERE id = $1 RETURNING id)", i, kind.Table()))
+		counts = append(counts, fmt.Sprintf("(SELECT COUNT(*) FROM deleted_%d)", i))
+	}
+
+	var deleted int
+	if err := r.db.QueryRow(ctx, fmt.Sprintf(`
+        WITH
+            %s,
+            favs AS (DELETE FROM user_favorites WHERE asset_id = $1)
+        SELECT %s`,
+		strings.Join(deletes, ",\n            "), strings.Join(counts, " + ")),
+		id,
+	).Scan(&deleted); err != nil {
+		return fmt.Errorf("could not delete asset: %w", err)
+	}
+
+	if deleted == 0 {
+		return assets.ErrAssetNotFound
 	}
 	return nil
 }
 
-func (r *Repository) storeAudienceAsset(ctx context.Context, asset assets.AudienceAsset) error {
-	if _, err := r.db.Exec(ctx, `
-        INSERT INTO audience_assets (
-            id, gender, birth_country, age_min, age_max,
-            social_media_hours, last_month_purchases, created_at, updated_at
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
-		asset.ID,
-		asset.Data.Gender,
-		asset.Data.BirthCountry,
-		asset.Data.AgeMin,
-		asset.Data.AgeMax,
-		asset.Data.SocialMediaHours,
-		asset.Data.LastMonthPurchases,
-		asset.CreatedAt,
-		asset.UpdatedAt,
-	); err != nil {
-		return fmt.Errorf("could not insert audience asset: %w", err)
+// Internal
+
+// assetRow holds the columns produced by selectAssetsQuery.
+// Queries joining other tables with the assets can append its
+// scan destinations to their own before scanning a row.
+type assetRow struct {
+	id        string
+	assetType string
+	data      []byte
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+func (r *assetRow) scanDest() []any {
+	return []any{
+		&r.id,
+		&r.assetType,
+		&r.data,
+		&r.createdAt,
+		&r.updatedAt,
 	}
-	return nil
+}
+
+func (r *assetRow) toAsset() (assets.Asseter, error) {
+	kind, ok := assets.Lookup(assets.AssetType(r.assetType))
+	if !ok {
+		return nil, fmt.Errorf("unsupported asset type '%s'", r.assetType)
+	}
+
+	asset, err := kind.Load(assets.Metadata{ID: r.id, CreatedAt: r.createdAt, UpdatedAt: r.updatedAt}, r.data)
+	if err != nil {
+		return nil, fmt.Errorf("could not load asset '%s': %w", r.id, err)
+	}
+	return asset, nil
+}
+
+// scanAsset scans a row produced by selectAssetsQuery into its typed asset.
+func scanAsset(row pgx.Row) (assets.Asseter, string, error) {
+	var ar assetRow
+	if err := row.Scan(ar.scanDest()...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, "", err
+		}
+		return nil, "", fmt.Errorf("could not scan asset: %w", err)
+	}
+
+	asset, err := ar.toAsset()
+	if err != nil {
+		return nil, "", err
+	}
+	return asset, ar.id, nil
+}
+
+// encodeAsset returns the registered type of the asset, along with its data as JSON, keyed like in the API.
+func encodeAsset(asset assets.Asseter) (assets.Kind, []byte, error) {
+	kind, ok := assets.Lookup(asset.Type())
+	if !ok {
+		return nil, nil, errors.New("unsupported asset type")
+	}
+
+	data, err := json.Marshal(kind.Data(asset))
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not encode asset data: %w", err)
+	}
+	return kind, data, nil
+}
+
+// storageRecord rekeys the data of an asset from its fields' names to their columns,
+// which is the record jsonb_populate_record expects.
+func storageRecord(kind assets.Kind, data []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("could not decode asset data: %w", err)
+	}
+
+	record := make(map[string]json.RawMessage, len(fields))
+	for _, f := range kind.Fields() {
+		if v, ok := fields[f.Name]; ok {
+			record[f.Column] = v
+		}
+	}
+
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode asset record: %w", err)
+	}
+	return encoded, nil
+}
+
+// storageColumns returns the columns the fields of an asset type are stored in.
+func storageColumns(kind assets.Kind) []string {
+	columns := make([]string, 0, len(kind.Fields()))
+	for _, f := range kind.Fields() {
+		columns = append(columns, f.Column)
+	}
+	return columns
+}
+
+// prefixColumns qualifies the columns with prefix, as in "r.title, r.x_axis".
+func prefixColumns(prefix string, columns []string) string {
+	qualified := make([]string, 0, len(columns))
+	for _, c := range columns {
+		qualified = append(qualified, prefix+c)
+	}
+	return strings.Join(qualified, ", ")
 }
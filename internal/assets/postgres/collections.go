@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+)
+
+// selectCollectionsQuery selects collections with the number of favorites in them.
+// Trashed favorites keep their place in collections for when they are restored, but aren't counted.
+// Callers add their own WHERE clause on the alias c.
+const selectCollectionsQuery = `
+    SELECT
+        c.id, c.user_id, c.name,
+        (
+            SELECT COUNT(*) FROM favorite_collection_items i
+            JOIN user_favorites f ON f.id = i.favorite_id
+            WHERE i.collection_id = c.id AND f.deleted_at IS NULL
+        ),
+        c.created_at, c.updated_at
+    FROM favorite_collections c`
+
+// CreateCollection stores a new collection for the user.
+// favorites.ErrCollectionNameTaken is returned if the user already has a collection with that name.
+func (r *Repository) CreateCollection(ctx context.Context, userID string, params *favorites.CollectionParams) (*favorites.Collection, error) {
+	now := time.Now()
+	collection := favorites.Collection{
+		ID:        ulid.Make().String(),
+		UserID:    userID,
+		Name:      params.Name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if _, err := r.db.Exec(ctx, `
+        INSERT INTO favorite_collections (id, user_id, name, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5)`,
+		collection.ID, collection.UserID, collection.Name, collection.CreatedAt, collection.UpdatedAt,
+	); err != nil {
+		if isUniqueViolation(err, "unique_user_collection_name") {
+			return nil, favorites.ErrCollectionNameTaken
+		}
+		return nil, fmt.Errorf("could not insert collection: %w", err)
+	}
+	return &collection, nil
+}
+
+// ListCollections returns all of the user's collections, ordered by name.
+func (r *Repository) ListCollections(ctx context.Context, userID string) ([]favorites.Collection, error) {
+	rows, err := r.db.Query(ctx, selectCollectionsQuery+`
+        WHERE c.user_id = $1
+        ORDER BY c.name, c.id`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query collections: %w", err)
+	}
+
+	collections, err := pgx.CollectRows(rows, scanCollection)
+	if err != nil {
+		return nil, fmt.Errorf("could not scan collections: %w", err)
+	}
+	return collections, nil
+}
+
+// FetchCollection returns one of the user's collections.
+// favorites.ErrCollectionNotFound is returned if it doesn't exist or belongs to another user.
+func (r *Repository) FetchCollection(ctx context.Context, collectionID, userID string) (*favorites.Collection, error) {
+	rows, err := r.db.Query(ctx, selectCollectionsQuery+`
+        WHERE c.id = $1 AND c.user_id = $2`,
+		collectionID, userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query collection: %w", err)
+	}
+
+	collection, err := pgx.CollectExactlyOneRow(rows, scanCollection)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, favorites.ErrCollectionNotFound
+		}
+		return nil, fmt.Errorf("could not scan collection: %w", err)
+	}
+	return &collection, nil
+}
+
+// UpdateCollection renames one of the user's collections.
+func (r *Repository) UpdateCollection(ctx context.Context, collectionID, userID string, params *favorites.CollectionParams) (*favorites.Collection, error) {
+	result, err := r.db.Exec(ctx, `
+        UPDATE favorite_collections
+        SET name = $1, updated_at = $2
+        WHERE id = $3 AND user_id = $4`,
+		params.Name, time.Now(), collectionID, userID,
+	)
+	if err != nil {
+		if isUniqueViolation(err, "unique_user_collection_name") {
+			return nil, favorites.ErrCollectionNameTaken
+		}
+		return nil, fmt.Errorf("could not update collection: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, favorites.ErrCollectionNotFound
+	}
+	return r.FetchCollection(ctx, collectionID, userID)
+}
+
+// DeleteCollection deletes one of the user's collections.
+// Its favorites are kept, only their membership is dropped by the foreign key cascade.
+func (r *Repository) DeleteCollection(ctx context.Context, collectionID, userID string) error {
+	result, err := r.db.Exec(ctx, `
+        DELETE FROM favorite_collections
+        WHERE id = $1 AND user_id = $2`,
+		collectionID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("deleting collection: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return favorites.ErrCollectionNotFound
+	}
+	return nil
+}
+
+// AddToCollection adds one of the user's favorites to one of the user's collections.
+// Both must belong to the user. Adding a favorite twice is a no-op.
+func (r *Repository) AddToCollection(ctx context.Context, collectionID, favoriteID, userID string) error {
+	return r.withTx(ctx, func(tx pgx.Tx) error {
+		if err := checkCollection(ctx, tx, collectionID, userID); err != nil {
+			return err
+		}
+		if _, err := fetchPosition(ctx, tx, favoriteID, userID); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `
+            INSERT INTO favorite_collection_items (collection_id, favorite_id, created_at)
+            VALUES ($1, $2, $3)
+            ON CONFLICT (collection_id, favorite_id) DO NOTHING`,
+			collectionID, favoriteID, time.Now(),
+		); err != nil {
+			return fmt.Errorf("could not insert collection item: %w", err)
+		}
+		return nil
+	})
+}
+
+// RemoveFromCollection removes a favorite from one of the user's collections.
+// favorites.ErrFavoriteAssetNotFound is returned if the favorite isn't in the collection.
+func (r *Repository) RemoveFromCollection(ctx context.Context, collectionID, favoriteID, userID string) error {
+	return r.withTx(ctx, func(tx pgx.Tx) error {
+		if err := checkCollection(ctx, tx, collectionID, userID); err != nil {
+			return err
+		}
+
+		result, err := tx.Exec(ctx, `
+            DELETE FROM favorite_collection_items
+            WHERE collection_id = $1 AND favorite_id = $2`,
+			collectionID, favoriteID,
+		)
+		if err != nil {
+			return fmt.Errorf("deleting collection item: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return favorites.ErrFavoriteAssetNotFound
+		}
+		return nil
+	})
+}
+
+// checkCollection makes sure the collection exists and belongs to the user.
+// It locks the collection so it can't be deleted before the transaction ends.
+func checkCollection(ctx context.Context, tx pgx.Tx, collectionID, userID string) error {
+	var id string
+	if err := tx.QueryRow(ctx, `
+        SELECT id FROM favorite_collections
+        WHERE id = $1 AND user_id = $2
+        FOR SHARE`,
+		collectionID, userID,
+	).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return favorites.ErrCollectionNotFound
+		}
+		return fmt.Errorf("could not fetch collection: %w", err)
+	}
+	return nil
+}
+
+func scanCollection(row pgx.CollectableRow) (favorites.Collection, error) {
+	var c favorites.Collection
+	err := row.Scan(&c.ID, &c.UserID, &c.Name, &c.FavoriteCount, &c.CreatedAt, &c.UpdatedAt)
+	return c, err
+}
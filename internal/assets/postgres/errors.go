@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Postgres error codes worth retrying.
+// See https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+	pgLockNotAvailable     = "55P03"
+	pgTooManyConnections   = "53300"
+	pgAdminShutdown        = "57P01"
+	pgCannotConnectNow     = "57P03"
+
+	// Class 08 covers all connection exceptions.
+	pgConnectionExceptionClass = "08"
+)
+
+// pgUniqueViolation is raised when a row breaks a unique constraint.
+const pgUniqueViolation = "23505"
+
+// markTransient wraps err with favorites.ErrTransient
+// when retrying the operation that caused it might succeed.
+func markTransient(err error) error {
+	if err == nil || !isTransient(err) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", favorites.ErrTransient, err)
+}
+
+func isTransient(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgSerializationFailure,
+			pgDeadlockDetected,
+			pgLockNotAvailable,
+			pgTooManyConnections,
+			pgAdminShutdown,
+			pgCannotConnectNow:
+			return true
+		}
+		return strings.HasPrefix(pgErr.Code, pgConnectionExceptionClass)
+	}
+
+	// Connection resets, timeouts and the like.
+	// SafeToRetry covers failures that happened before anything was sent to the server.
+	var (
+		connectErr *pgconn.ConnectError
+		netErr     net.Error
+	)
+	return pgconn.SafeToRetry(err) ||
+		pgconn.Timeout(err) ||
+		errors.As(err, &connectErr) ||
+		errors.As(err, &netErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET)
+}
+
+// isUniqueViolation reports whether err was caused by breaking the given unique constraint.
+func isUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == constraint
+}
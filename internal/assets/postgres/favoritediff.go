@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
+	"github.com/jackc/pgx/v5"
+)
+
+// assetSnapshot builds the snapshot favorites keep of an asset from a row of selectAssetsQuery aliased as a,
+// which is the asset's data keyed like in the API.
+// Snapshots are compared as a whole, so it must keep building the same objects
+// as the ones stored before, including the ones migration 11 built for the favorites it found.
+const assetSnapshot = `a.data`
+
+// FetchFavoriteSnapshots returns the snapshot the user's favorite was favorited with, along with one of its asset as it is now.
+// Favorites stored without a snapshot get the current one, as there's no telling what changed.
+func (r *Repository) FetchFavoriteSnapshots(ctx context.Context, favoriteID, userID string) (*favorites.FavoriteSnapshots, error) {
+	var snapshots favorites.FavoriteSnapshots
+	if err := r.db.QueryRow(ctx, fmt.Sprintf(`
+        SELECT f.id, f.asset_id, f.asset_type, f.created_at, a.updated_at, COALESCE(f.asset_snapshot, %[1]s), %[1]s
+        FROM user_favorites f
+        JOIN (%[2]s) a ON a.id = f.asset_id
+        WHERE f.id = $1 AND f.user_id = $2 AND f.deleted_at IS NULL`,
+		assetSnapshot, selectAssetsQuery()),
+		favoriteID, userID,
+	).Scan(
+		&snapshots.FavoriteID,
+		&snapshots.AssetID,
+		&snapshots.AssetType,
+		&snapshots.FavoritedAt,
+		&snapshots.AssetUpdatedAt,
+		&snapshots.Favorited,
+		&snapshots.Current,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, favorites.ErrFavoriteAssetNotFound
+		}
+		return nil, fmt.Errorf("could not fetch favorite snapshots: %w", err)
+	}
+	return &snapshots, nil
+}
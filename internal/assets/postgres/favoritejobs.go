@@ -0,0 +1,405 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+)
+
+// EnqueueFavoriteJob writes a pending favorite job to the queue table.
+// Once this returns, the request to favorite the asset survives restarts
+// and is picked up by any consumer claiming jobs from the table.
+// If maxPending jobs are already pending, nothing is written and favorites.ErrQueueFull is returned.
+// The check and the insert run in one statement, but concurrent calls can still go slightly over the limit.
+func (r *Repository) EnqueueFavoriteJob(ctx context.Context, params *favorites.FavoriteAssetParams, maxPending int) (*favorites.FavoriteJob, error) {
+	now := time.Now()
+	job := favorites.FavoriteJob{
+		ID:          ulid.Make().String(),
+		UserID:      params.UserID,
+		AssetID:     params.AssetID,
+		Description: params.Description,
+		Status:      favorites.JobStatusPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	// Counting at most maxPending rows keeps the check cheap however long the queue gets.
+	result, err := r.db.Exec(ctx, `
+        INSERT INTO favorite_jobs (
+            id, user_id, asset_id, description, status, run_at, created_at, updated_at
+        )
+        SELECT $1, $2, $3, $4, $5, $6::timestamptz, $6::timestamptz, $6::timestamptz
+        WHERE (
+            SELECT count(*) FROM (
+                SELECT 1 FROM favorite_jobs WHERE status = 'PENDING' LIMIT $7::int
+            ) pending
+        ) < $7::int`,
+		job.ID,
+		job.UserID,
+		job.AssetID,
+		job.Description,
+		job.Status,
+		now,
+		maxPending,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not insert favorite job: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, favorites.ErrQueueFull
+	}
+	return &job, nil
+}
+
+// CountPendingFavoriteJobs returns how many jobs are waiting to be processed,
+// including the ones claimed by consumers and the ones waiting to be retried.
+func (r *Repository) CountPendingFavoriteJobs(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.QueryRow(ctx, `
+        SELECT count(*) FROM favorite_jobs WHERE status = 'PENDING'`,
+	).Scan(&count); err != nil {
+		return 0, fmt.Errorf("could not count pending favorite jobs: %w", err)
+	}
+	return count, nil
+}
+
+// ClaimFavoriteJobs leases up to limit pending jobs that are due, oldest first.
+// Rows locked by another consumer are skipped instead of waited on, so several
+// replicas can claim from the table at the same time without handing out the same job.
+// A claimed job that isn't completed before the lease expires is claimed again,
+// which is how jobs held by a crashed consumer get recovered.
+// A user's jobs are claimed together, as the run of due jobs starting with their oldest pending one,
+// so they can be stored at once. A job is only claimed along with all of the user's older pending jobs,
+// which keeps each user's jobs processed in the order they were queued, retries included.
+// The returned jobs are grouped by user, each user's in the order they were queued.
+func (r *Repository) ClaimFavoriteJobs(ctx context.Context, limit int, lease time.Duration) ([]favorites.FavoriteJob, error) {
+	rows, err := r.db.Query(ctx, `
+        WITH due AS (
+            SELECT id, user_id, created_at, MIN(run_at) OVER (PARTITION BY user_id) AS user_run_at
+            FROM favorite_jobs j
+            WHERE status = 'PENDING' AND run_at <= now()
+                AND (locked_until IS NULL OR locked_until < now())
+                AND NOT EXISTS (
+                    SELECT 1 FROM favorite_jobs older
+                    WHERE older.user_id = j.user_id AND older.status = 'PENDING'
+                        AND (older.created_at, older.id) < (j.created_at, j.id)
+                        AND (older.run_at > now() OR older.locked_until >= now())
+                )
+            ORDER BY user_run_at, user_id, created_at, id
+            LIMIT $1
+        ),
+        locked AS (
+            SELECT id, user_id, created_at FROM favorite_jobs
+            WHERE id IN (SELECT id FROM due) AND status = 'PENDING' AND run_at <= now()
+                AND (locked_until IS NULL OR locked_until < now())
+            FOR UPDATE SKIP LOCKED
+        ),
+        claimed AS (
+            UPDATE favorite_jobs
+            SET locked_until = now() + make_interval(secs => $2), attempts = attempts + 1
+            WHERE id IN (
+                SELECT id FROM locked l
+                WHERE NOT EXISTS (
+                    SELECT 1 FROM favorite_jobs older
+                    WHERE older.user_id = l.user_id AND older.status = 'PENDING'
+                        AND (older.created_at, older.id) < (l.created_at, l.id)
+                        AND older.id NOT IN (SELECT id FROM locked)
+                )
+            )
+            RETURNING id, user_id, asset_id, description, status, attempts, created_at, updated_at
+        )
+        SELECT id, user_id, asset_id, description, status, attempts, created_at, updated_at
+        FROM claimed
+        ORDER BY user_id, created_at, id`,
+		limit, lease.Seconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not claim favorite jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []favorites.FavoriteJob
+	for rows.Next() {
+		var (
+			job         favorites.FavoriteJob
+			description sql.NullString
+		)
+		if err := rows.Scan(
+			&job.ID,
+			&job.UserID,
+			&job.AssetID,
+			&description,
+			&job.Status,
+			&job.Attempts,
+			&job.CreatedAt,
+			&job.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("could not scan favorite job: %w", err)
+		}
+		job.Description = description.String
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not iterate over rows: %w", err)
+	}
+	return jobs, nil
+}
+
+// CompleteFavoriteJob marks a claimed job as succeeded and releases its lease.
+// Every claim counts an attempt, so attempt, the job's attempts as claimed, identifies the caller's lease.
+// If the lease expired and the job was claimed again or settled since, it's left alone and
+// favorites.ErrLeaseLost is returned.
+func (r *Repository) CompleteFavoriteJob(ctx context.Context, jobID string, attempt int) error {
+	result, err := r.db.Exec(ctx, `
+        UPDATE favorite_jobs
+        SET status = $1, last_error = NULL, locked_until = NULL, updated_at = $2
+        WHERE id = $3 AND status = 'PENDING' AND attempts = $4`,
+		favorites.JobStatusSucceeded, time.Now(), jobID, attempt,
+	)
+	if err != nil {
+		return fmt.Errorf("could not update favorite job: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return favorites.ErrLeaseLost
+	}
+	return nil
+}
+
+// RetryFavoriteJob releases a claimed job so it's claimed again once runAt is due.
+// The job stays pending, with jobErr recorded as the reason it's being retried.
+// As with CompleteFavoriteJob, favorites.ErrLeaseLost is returned if the lease for attempt is gone.
+func (r *Repository) RetryFavoriteJob(ctx context.Context, jobID string, attempt int, runAt time.Time, jobErr error) error {
+	result, err := r.db.Exec(ctx, `
+        UPDATE favorite_jobs
+        SET run_at = $1, last_error = $2, locked_until = NULL, updated_at = $3
+        WHERE id = $4 AND status = 'PENDING' AND attempts = $5`,
+		runAt, jobErr.Error(), time.Now(), jobID, attempt,
+	)
+	if err != nil {
+		return fmt.Errorf("could not update favorite job: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return favorites.ErrLeaseLost
+	}
+	return nil
+}
+
+// DeadLetterFavoriteJob marks a claimed job as failed and copies it to the dead letters,
+// along with jobErr, so it can be looked into and replayed later.
+// Both happen in the same transaction, so a failed job always has its dead letter, and only one:
+// if the lease for attempt is gone, neither happens and favorites.ErrLeaseLost is returned.
+func (r *Repository) DeadLetterFavoriteJob(ctx context.Context, jobID string, attempt int, jobErr error) error {
+	return r.withTx(ctx, func(tx pgx.Tx) error {
+		now := time.Now()
+
+		result, err := tx.Exec(ctx, `
+            UPDATE favorite_jobs
+            SET status = $1, last_error = $2, locked_until = NULL, updated_at = $3
+            WHERE id = $4 AND status = 'PENDING' AND attempts = $5`,
+			favorites.JobStatusFailed, jobErr.Error(), now, jobID, attempt,
+		)
+		if err != nil {
+			return fmt.Errorf("could not update favorite job: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return favorites.ErrLeaseLost
+		}
+
+		if _, err := tx.Exec(ctx, `
+            INSERT INTO favorite_dead_letters (
+                id, job_id, user_id, asset_id, description, attempts, last_error, created_at
+            )
+            SELECT $1, id, user_id, asset_id, description, attempts, last_error, $2
+            FROM favorite_jobs
+            WHERE id = $3`,
+			ulid.Make().String(), now, jobID,
+		); err != nil {
+			return fmt.Errorf("could not insert dead letter: %w", err)
+		}
+		return nil
+	})
+}
+
+// ReleaseFavoriteJobs gives back the lease on claimed jobs that won't be processed,
+// so other consumers can claim them right away instead of waiting for the lease to expire.
+// The jobs were never attempted, so the attempt counted when they were claimed is taken back.
+func (r *Repository) ReleaseFavoriteJobs(ctx context.Context, jobIDs []string) error {
+	if _, err := r.db.Exec(ctx, `
+        UPDATE favorite_jobs
+        SET locked_until = NULL, attempts = GREATEST(attempts - 1, 0)
+        WHERE id = ANY($1) AND status = 'PENDING' AND locked_until IS NOT NULL`,
+		jobIDs,
+	); err != nil {
+		return fmt.Errorf("could not release favorite jobs: %w", err)
+	}
+	return nil
+}
+
+// FetchFavoriteJob returns a favorite job by ID.
+func (r *Repository) FetchFavoriteJob(ctx context.Context, jobID string) (*favorites.FavoriteJob, error) {
+	var (
+		job         favorites.FavoriteJob
+		description sql.NullString
+		lastError   sql.NullString
+	)
+	err := r.db.QueryRow(ctx, `
+        SELECT id, user_id, asset_id, description, status, attempts, last_error, created_at, updated_at
+        FROM favorite_jobs
+        WHERE id = $1`,
+		jobID,
+	).Scan(
+		&job.ID,
+		&job.UserID,
+		&job.AssetID,
+		&description,
+		&job.Status,
+		&job.Attempts,
+		&lastError,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, favorites.ErrFavoriteJobNotFound
+		}
+		return nil, fmt.Errorf("could not fetch favorite job: %w", err)
+	}
+
+	job.Description = description.String
+	// Pending jobs may carry the error of a previous attempt, but they haven't failed.
+	if lastError.Valid && job.Status == favorites.JobStatusFailed {
+		job.Err = errors.New(lastError.String)
+	}
+	return &job, nil
+}
+
+// LatestPendingFavoriteJobID returns the ID of the user's most recently queued pending job,
+// or an empty string if the user has no pending jobs.
+func (r *Repository) LatestPendingFavoriteJobID(ctx context.Context, userID string) (string, error) {
+	var jobID string
+	err := r.db.QueryRow(ctx, `
+        SELECT id FROM favorite_jobs
+        WHERE user_id = $1 AND status = 'PENDING'
+        ORDER BY created_at DESC, id DESC
+        LIMIT 1`,
+		userID,
+	).Scan(&jobID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("could not fetch latest pending favorite job: %w", err)
+	}
+	return jobID, nil
+}
+
+// PurgeFavoriteJobs deletes finished jobs last updated before the given time.
+// Pending jobs are never purged, no matter how old they are.
+func (r *Repository) PurgeFavoriteJobs(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.Exec(ctx, `
+        DELETE FROM favorite_jobs
+        WHERE status <> 'PENDING' AND updated_at < $1`,
+		before,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("could not purge favorite jobs: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// ListDeadLetters returns a page of dead letters, most recent first.
+func (r *Repository) ListDeadLetters(ctx context.Context, params *favorites.ListDeadLettersParams) ([]favorites.DeadLetter, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT id, job_id, user_id, asset_id, description, attempts, last_error, created_at
+        FROM favorite_dead_letters
+        WHERE ($1 = '' OR id < $1)
+        ORDER BY id DESC
+        LIMIT $2`,
+		params.PageToken, params.PageSize,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not run query: %w", err)
+	}
+	defer rows.Close()
+
+	var result []favorites.DeadLetter
+	for rows.Next() {
+		var (
+			dl          favorites.DeadLetter
+			description sql.NullString
+		)
+		if err := rows.Scan(
+			&dl.ID,
+			&dl.JobID,
+			&dl.UserID,
+			&dl.AssetID,
+			&description,
+			&dl.Attempts,
+			&dl.LastError,
+			&dl.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("could not scan dead letter: %w", err)
+		}
+		dl.Description = description.String
+		result = append(result, dl)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not iterate over rows: %w", err)
+	}
+	return result, nil
+}
+
+// ReplayDeadLetter removes a dead letter and enqueues a new favorite job with its params.
+// Both happen in the same transaction, so a dead letter can't be replayed twice.
+func (r *Repository) ReplayDeadLetter(ctx context.Context, id string) (*favorites.FavoriteJob, error) {
+	now := time.Now()
+	job := favorites.FavoriteJob{
+		ID:        ulid.Make().String(),
+		Status:    favorites.JobStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		var description sql.NullString
+		if err := tx.QueryRow(ctx, `
+            DELETE FROM favorite_dead_letters
+            WHERE id = $1
+            RETURNING user_id, asset_id, description`,
+			id,
+		).Scan(&job.UserID, &job.AssetID, &description); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return favorites.ErrDeadLetterNotFound
+			}
+			return fmt.Errorf("could not delete dead letter: %w", err)
+		}
+		job.Description = description.String
+
+		if _, err := tx.Exec(ctx, `
+            INSERT INTO favorite_jobs (
+                id, user_id, asset_id, description, status, run_at, created_at, updated_at
+            ) VALUES ($1, $2, $3, $4, $5, $6, $6, $6)`,
+			job.ID,
+			job.UserID,
+			job.AssetID,
+			description,
+			job.Status,
+			now,
+		); err != nil {
+			return fmt.Errorf("could not insert favorite job: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
@@ -0,0 +1,185 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
+	"github.com/alesr/platform-go-challenge/internal/pkg/rankutil"
+	"github.com/jackc/pgx/v5"
+)
+
+// ReorderFavorites puts the given favorites first in the user's list, in the given order,
+// followed by the user's other favorites in the order they had.
+// The whole list gets fresh, evenly spread positions, which leaves room for moves to come.
+// favorites.ErrFavoriteAssetNotFound is returned if any of the favorites isn't the user's.
+func (r *Repository) ReorderFavorites(ctx context.Context, userID string, favoriteIDs []string) error {
+	return r.withTx(ctx, func(tx pgx.Tx) error {
+		if err := lockUserFavorites(ctx, tx, []string{userID}); err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx, `
+            SELECT id FROM user_favorites
+            WHERE user_id = $1 AND deleted_at IS NULL
+            ORDER BY position, id`,
+			userID,
+		)
+		if err != nil {
+			return fmt.Errorf("could not query favorites: %w", err)
+		}
+
+		current, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return fmt.Errorf("could not scan favorites: %w", err)
+		}
+
+		ordered := make([]string, 0, len(current))
+		ordered = append(ordered, favoriteIDs...)
+		for _, id := range favoriteIDs {
+			if !slices.Contains(current, id) {
+				return favorites.ErrFavoriteAssetNotFound
+			}
+		}
+		for _, id := range current {
+			if !slices.Contains(favoriteIDs, id) {
+				ordered = append(ordered, id)
+			}
+		}
+
+		if _, err := tx.Exec(ctx, `
+            UPDATE user_favorites f
+            SET position = o.position
+            FROM unnest($1::text[], $2::text[]) AS o(id, position)
+            WHERE f.id = o.id AND f.user_id = $3`,
+			ordered, rankutil.Spread(len(ordered)), userID,
+		); err != nil {
+			return fmt.Errorf("could not update positions: %w", err)
+		}
+		return nil
+	})
+}
+
+// MoveFavorite moves a user's favorite right before another of the user's favorites,
+// or to the end of the list when no favorite to move before is given.
+// Only the moved favorite gets a new position, one between its new neighbours.
+func (r *Repository) MoveFavorite(ctx context.Context, favoriteID, userID string, params *favorites.MoveFavoriteParams) error {
+	return r.withTx(ctx, func(tx pgx.Tx) error {
+		if err := lockUserFavorites(ctx, tx, []string{userID}); err != nil {
+			return err
+		}
+
+		if _, err := fetchPosition(ctx, tx, favoriteID, userID); err != nil {
+			return err
+		}
+
+		if params.BeforeID == favoriteID {
+			return nil
+		}
+
+		// the neighbours the favorite goes between, empty for the ends of the list
+		var before, after string
+		if params.BeforeID != "" {
+			var err error
+			if after, err = fetchPosition(ctx, tx, params.BeforeID, userID); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.QueryRow(ctx, `
+            SELECT COALESCE(MAX(position), '') FROM user_favorites
+            WHERE user_id = $1 AND id <> $2 AND deleted_at IS NULL AND ($3 = '' OR position < $3)`,
+			userID, favoriteID, after,
+		).Scan(&before); err != nil {
+			return fmt.Errorf("could not fetch previous position: %w", err)
+		}
+
+		position, err := rankutil.Between(before, after)
+		if err != nil {
+			return fmt.Errorf("could not rank favorite: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, `
+            UPDATE user_favorites SET position = $1
+            WHERE id = $2 AND user_id = $3`,
+			position, favoriteID, userID,
+		); err != nil {
+			return fmt.Errorf("could not update position: %w", err)
+		}
+		return nil
+	})
+}
+
+// lockUserFavorites serializes changes to the order of the given users' favorites until the transaction ends.
+// Positions are computed from the ones already stored, so two transactions computing them
+// at once could hand out the same one. Users are locked in order so batches can't deadlock.
+func lockUserFavorites(ctx context.Context, tx pgx.Tx, userIDs []string) error {
+	if _, err := tx.Exec(ctx, `
+        SELECT pg_advisory_xact_lock(hashtext('user_favorites:' || u.user_id))
+        FROM (SELECT DISTINCT user_id FROM unnest($1::text[]) AS user_id ORDER BY user_id) u`,
+		userIDs,
+	); err != nil {
+		return fmt.Errorf("could not lock user favorites: %w", err)
+	}
+	return nil
+}
+
+// fetchFirstPositions returns the position of each of the given users' first favorite,
+// keyed by user ID. Users without favorites are left out.
+// Trashed favorites count too, so the ones restored later don't share their positions with new ones.
+func fetchFirstPositions(ctx context.Context, tx pgx.Tx, userIDs []string) (map[string]string, error) {
+	rows, err := tx.Query(ctx, `
+        SELECT user_id, MIN(position) FROM user_favorites
+        WHERE user_id = ANY($1)
+        GROUP BY user_id`,
+		userIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query first positions: %w", err)
+	}
+	defer rows.Close()
+
+	positions := make(map[string]string, len(userIDs))
+	for rows.Next() {
+		var userID, position string
+		if err := rows.Scan(&userID, &position); err != nil {
+			return nil, fmt.Errorf("could not scan first position: %w", err)
+		}
+		positions[userID] = position
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not iterate over rows: %w", err)
+	}
+	return positions, nil
+}
+
+// topPositions hands out positions at the top of each user's list, where new favorites go.
+// Each position goes above the previous one, so the latest favorite ends up first.
+type topPositions map[string]string
+
+func (t topPositions) next(userID string) (string, error) {
+	position, err := rankutil.Before(t[userID])
+	if err != nil {
+		return "", fmt.Errorf("could not rank favorite: %w", err)
+	}
+	t[userID] = position
+	return position, nil
+}
+
+func fetchPosition(ctx context.Context, tx pgx.Tx, favoriteID, userID string) (string, error) {
+	var position string
+	if err := tx.QueryRow(ctx, `
+        SELECT position FROM user_favorites
+        WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`,
+		favoriteID, userID,
+	).Scan(&position); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", favorites.ErrFavoriteAssetNotFound
+		}
+		return "", fmt.Errorf("could not fetch position: %w", err)
+	}
+	return position, nil
+}
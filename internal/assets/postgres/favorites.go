@@ -2,9 +2,9 @@ package postgres
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/alesr/platform-go-challenge/internal/assets"
@@ -13,72 +13,431 @@ import (
 	"github.com/oklog/ulid/v2"
 )
 
-// StoreFavoriteAsset stores a favorite asset in the database.
-// It does in a transaction to guarantee the asset is not removed while the user is favoriting it.
-func (r *Repository) StoreFavoriteAsset(ctx context.Context, params *favorites.FavoriteAssetParams) error {
-	tx, err := r.db.Begin(ctx)
-	if err != nil {
-		return fmt.Errorf("could not begin transaction: %w", err)
+// StoreFavoriteAssets stores a batch of favorite assets in a single transaction,
+// resolving the type of every asset in one query and upserting them all in one statement.
+// The returned slice holds the outcome of each favorite, in the same order as params:
+// assets.ErrAssetNotFound for favorites whose asset doesn't exist, nil for the stored ones.
+// If the batch as a whole fails, nothing is stored and the error is returned instead.
+// Errors worth retrying are wrapped with favorites.ErrTransient.
+func (r *Repository) StoreFavoriteAssets(ctx context.Context, params []*favorites.FavoriteAssetParams) ([]error, error) {
+	results := make([]error, len(params))
+	if len(params) == 0 {
+		return results, nil
+	}
+
+	assetIDs := make([]string, 0, len(params))
+	batchUserIDs := make([]string, 0, len(params))
+	for _, p := range params {
+		assetIDs = append(assetIDs, p.AssetID)
+		batchUserIDs = append(batchUserIDs, p.UserID)
 	}
-	defer func() {
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		assetTypes, err := fetchAssetTypes(ctx, tx, assetIDs)
+		if err != nil {
+			return err
+		}
+
+		if err := lockUserFavorites(ctx, tx, batchUserIDs); err != nil {
+			return err
+		}
+
+		firstPositions, err := fetchFirstPositions(ctx, tx, batchUserIDs)
 		if err != nil {
-			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
-				err = fmt.Errorf("could not rollback transaction: %v (original error: %w)", rollbackErr, err)
+			return err
+		}
+		top := topPositions(firstPositions)
+
+		// A favorite can only be upserted once per statement, so if the batch
+		// holds the same one twice, the last one wins, as if they were stored in order.
+		// Favorites that already exist keep their position, the one handed out to them goes unused.
+		var (
+			ids, userIDs, favAssetIDs, types, descriptions, positions []string
+			rowByKey                                                  = make(map[[2]string]int, len(params))
+		)
+		for i, p := range params {
+			assetType, ok := assetTypes[p.AssetID]
+			if !ok {
+				results[i] = assets.ErrAssetNotFound
+				continue
+			}
+
+			key := [2]string{p.UserID, p.AssetID}
+			if row, ok := rowByKey[key]; ok {
+				descriptions[row] = p.Description
+				continue
+			}
+
+			position, err := top.next(p.UserID)
+			if err != nil {
+				return err
 			}
-			return
+
+			rowByKey[key] = len(ids)
+			ids = append(ids, ulid.Make().String())
+			userIDs = append(userIDs, p.UserID)
+			favAssetIDs = append(favAssetIDs, p.AssetID)
+			types = append(types, assetType)
+			descriptions = append(descriptions, p.Description)
+			positions = append(positions, position)
 		}
-		if commitErr := tx.Commit(ctx); commitErr != nil {
-			err = fmt.Errorf("committing transaction: %w", commitErr)
+
+		if len(ids) == 0 {
+			return nil
 		}
-	}()
 
-	var assetType sql.NullString
-	err = tx.QueryRow(ctx, `
-        SELECT
-            CASE
-                WHEN EXISTS (SELECT 1 FROM chart_assets WHERE id = $1) THEN 'CHART'
-                WHEN EXISTS (SELECT 1 FROM insight_assets WHERE id = $1) THEN 'INSIGHT'
-                WHEN EXISTS (SELECT 1 FROM audience_assets WHERE id = $1) THEN 'AUDIENCE'
-            END
-        `, params.AssetID).Scan(&assetType)
+		// Favorites that already exist keep the snapshot they were first favorited with.
+		if _, err := tx.Exec(ctx, fmt.Sprintf(`
+            INSERT INTO user_favorites (
+                id, user_id, asset_id, asset_type, description, position, asset_snapshot, created_at, updated_at
+            )
+            SELECT f.id, f.user_id, f.asset_id, f.asset_type, f.description, f.position, %s, $7, $7
+            FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::text[], $6::text[])
+                AS f(id, user_id, asset_id, asset_type, description, position)
+            JOIN (%s) a ON a.id = f.asset_id
+            ON CONFLICT (user_id, asset_id) WHERE deleted_at IS NULL DO UPDATE SET
+                description = EXCLUDED.description,
+                updated_at = EXCLUDED.updated_at`,
+			assetSnapshot, selectAssetsQuery()),
+			ids,
+			userIDs,
+			favAssetIDs,
+			types,
+			descriptions,
+			positions,
+			time.Now(),
+		); err != nil {
+			return fmt.Errorf("could not insert favorites: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, markTransient(err)
+	}
+	return results, nil
+}
+
+// errOpFailed rolls back an atomic batch of favorite ops once one of them fails.
+var errOpFailed = errors.New("favorite op failed")
+
+// ApplyFavoriteOps applies a batch of changes to a user's favorites in a single transaction, in order.
+// The returned slice holds the outcome of each op, in the same order as ops: nil if it was applied,
+// assets.ErrAssetNotFound when adding a missing asset, favorites.ErrFavoriteAssetNotFound
+// when removing or updating a favorite the user doesn't have.
+// With atomic set, the first op to fail rolls back the whole batch and every other op gets favorites.ErrBatchAborted,
+// and any other error fails the whole batch and is returned instead.
+// Otherwise each op runs in a savepoint of its own, so an op failing for any reason is rolled back and skipped,
+// with its error as its outcome, and only an error leaving the transaction unusable fails the whole batch.
+// Errors worth retrying are wrapped with favorites.ErrTransient.
+func (r *Repository) ApplyFavoriteOps(ctx context.Context, userID string, ops []favorites.FavoriteOp, atomic bool) ([]error, error) {
+	results := make([]error, len(ops))
+
+	var addedIDs []string
+	for _, op := range ops {
+		if op.Type == favorites.FavoriteOpAdd {
+			addedIDs = append(addedIDs, op.AssetID)
+		}
+	}
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		assetTypes := map[string]string{}
+		top := topPositions{}
+		if len(addedIDs) > 0 {
+			var err error
+			if assetTypes, err = fetchAssetTypes(ctx, tx, addedIDs); err != nil {
+				return err
+			}
+
+			if err := lockUserFavorites(ctx, tx, []string{userID}); err != nil {
+				return err
+			}
+			if top, err = fetchFirstPositions(ctx, tx, []string{userID}); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		for i, op := range ops {
+			if !atomic {
+				opErr, err := applyFavoriteOpInSavepoint(ctx, tx, userID, op, assetTypes, top, now)
+				if err != nil {
+					return err
+				}
+				results[i] = markTransient(opErr)
+				continue
+			}
+
+			err := applyFavoriteOp(ctx, tx, userID, op, assetTypes, top, now)
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, assets.ErrAssetNotFound) && !errors.Is(err, favorites.ErrFavoriteAssetNotFound) {
+				return err
+			}
 
+			results[i] = err
+			return errOpFailed
+		}
+		return nil
+	})
+
+	if errors.Is(err, errOpFailed) {
+		for i := range results {
+			if results[i] == nil {
+				results[i] = favorites.ErrBatchAborted
+			}
+		}
+		return results, nil
+	}
 	if err != nil {
-		return fmt.Errorf("could not get asset type: %w", err)
-	}
-
-	if !assetType.Valid || assetType.String == "" {
-		return assets.ErrAssetNotFound
-	}
-
-	_, err = tx.Exec(ctx, `
-        INSERT INTO user_favorites (
-            id, user_id, asset_id, asset_type, description, created_at, updated_at
-        ) VALUES ($1, $2, $3, $4, $5, $6, $6)
-        ON CONFLICT (user_id, asset_id) DO UPDATE SET
-            description = $5,
-            updated_at = $6`,
-		ulid.Make().String(),
-		params.UserID,
-		params.AssetID,
-		assetType.String,
-		params.Description,
-		time.Now(),
-	)
+		return nil, markTransient(err)
+	}
+	return results, nil
+}
 
+// applyFavoriteOpInSavepoint applies op in a savepoint of tx, rolled back if the op fails,
+// so the ops applied before it are kept and the ones after it can still be applied.
+// It returns the error of the op first, and an error leaving tx unusable second.
+func applyFavoriteOpInSavepoint(
+	ctx context.Context,
+	tx pgx.Tx,
+	userID string,
+	op favorites.FavoriteOp,
+	assetTypes map[string]string,
+	top topPositions,
+	now time.Time,
+) (error, error) {
+	savepoint, err := tx.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("could not insert favorite: %w", err)
+		return nil, fmt.Errorf("could not create savepoint: %w", err)
 	}
-	return nil
+
+	if opErr := applyFavoriteOp(ctx, savepoint, userID, op, assetTypes, top, now); opErr != nil {
+		if err := savepoint.Rollback(ctx); err != nil {
+			return nil, fmt.Errorf("could not roll back to savepoint: %v (op error: %w)", err, opErr)
+		}
+		return opErr, nil
+	}
+
+	if err := savepoint.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("could not release savepoint: %w", err)
+	}
+	return nil, nil
 }
 
-func (r *Repository) GetUserFavorites(ctx context.Context, userID string) ([]favorites.FavoriteAsset, error) {
-	rows, err := r.db.Query(ctx, `
-        SELECT id, user_id, asset_id, asset_type, description, created_at, updated_at
-        FROM user_favorites
-        WHERE user_id = $1
-        ORDER BY created_at DESC`,
-		userID,
+func applyFavoriteOp(
+	ctx context.Context,
+	tx pgx.Tx,
+	userID string,
+	op favorites.FavoriteOp,
+	assetTypes map[string]string,
+	top topPositions,
+	now time.Time,
+) error {
+	switch op.Type {
+	case favorites.FavoriteOpAdd:
+		assetType, ok := assetTypes[op.AssetID]
+		if !ok {
+			return assets.ErrAssetNotFound
+		}
+
+		position, err := top.next(userID)
+		if err != nil {
+			return err
+		}
+
+		// The asset may have been deleted since its type was fetched, in which case nothing is inserted.
+		result, err := tx.Exec(ctx, fmt.Sprintf(`
+            INSERT INTO user_favorites (
+                id, user_id, asset_id, asset_type, description, position, asset_snapshot, created_at, updated_at
+            )
+            SELECT $1, $2, a.id, $4, $5, $6, %s, $7, $7
+            FROM (%s) a
+            WHERE a.id = $3
+            ON CONFLICT (user_id, asset_id) WHERE deleted_at IS NULL DO UPDATE SET
+                description = EXCLUDED.description,
+                updated_at = EXCLUDED.updated_at`,
+			assetSnapshot, selectAssetsQuery()),
+			ulid.Make().String(), userID, op.AssetID, assetType, op.Description, position, now,
+		)
+		if err != nil {
+			return fmt.Errorf("could not insert favorite: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return assets.ErrAssetNotFound
+		}
+		return nil
+
+	case favorites.FavoriteOpRemove:
+		result, err := tx.Exec(ctx, `
+            UPDATE user_favorites
+            SET deleted_at = $1
+            WHERE user_id = $2 AND asset_id = $3 AND deleted_at IS NULL`,
+			now, userID, op.AssetID,
+		)
+		if err != nil {
+			return fmt.Errorf("could not trash favorite: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return favorites.ErrFavoriteAssetNotFound
+		}
+		return nil
+
+	case favorites.FavoriteOpUpdate:
+		result, err := tx.Exec(ctx, `
+            UPDATE user_favorites
+            SET description = $1, updated_at = $2
+            WHERE user_id = $3 AND asset_id = $4 AND deleted_at IS NULL`,
+			op.Description, now, userID, op.AssetID,
+		)
+		if err != nil {
+			return fmt.Errorf("could not update favorite: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return favorites.ErrFavoriteAssetNotFound
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported favorite op '%s'", op.Type)
+	}
+}
+
+// fetchAssetTypes returns the type of each of the given assets that exists, keyed by asset ID.
+// It runs in the caller's transaction so the assets are looked up in the same snapshot they are favorited in.
+func fetchAssetTypes(ctx context.Context, tx pgx.Tx, assetIDs []string) (map[string]string, error) {
+	rows, err := tx.Query(ctx, fmt.Sprintf(`
+        SELECT a.id, a.asset_type
+        FROM (%s) a
+        WHERE a.id = ANY($1)`,
+		selectAssetsQuery()),
+		assetIDs,
 	)
+	if err != nil {
+		return nil, fmt.Errorf("could not get asset types: %w", err)
+	}
+	defer rows.Close()
+
+	assetTypes := make(map[string]string, len(assetIDs))
+	for rows.Next() {
+		var id, assetType string
+		if err := rows.Scan(&id, &assetType); err != nil {
+			return nil, fmt.Errorf("could not scan asset type: %w", err)
+		}
+		assetTypes[id] = assetType
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not iterate over rows: %w", err)
+	}
+	return assetTypes, nil
+}
+
+// GetUserFavorites returns a page of the user's favorites along with the assets they point to.
+// The assets are joined in the same query, so listing favorites costs a single round-trip.
+// Favorites are ordered by position, and pages are keyset-based on (position, id),
+// which lets idx_user_favorites_user_position seek straight to the cursor instead of skipping over previous pages.
+// Sorted by frecency, they are ordered by frecency first, most used first, as idx_user_favorites_user_frecency is.
+// Searches are ordered by ts_rank before anything else, best matches first.
+func (r *Repository) GetUserFavorites(ctx context.Context, userID string, params *favorites.ListFavoritesParams) ([]favorites.FavoriteAsset, error) {
+	conditions := []string{"f.user_id = $1", "f.deleted_at IS NULL"}
+	args := []any{userID}
+
+	if params.CollectionID != "" {
+		args = append(args, params.CollectionID)
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM favorite_collection_items i WHERE i.favorite_id = f.id AND i.collection_id = $%d)", len(args),
+		))
+	}
+
+	if len(params.AssetTypes) > 0 {
+		assetTypes := make([]string, 0, len(params.AssetTypes))
+		for _, assetType := range params.AssetTypes {
+			assetTypes = append(assetTypes, string(assetType))
+		}
+		args = append(args, assetTypes)
+		conditions = append(conditions, fmt.Sprintf("f.asset_type = ANY($%d)", len(args)))
+	}
+
+	for _, bound := range []struct {
+		condition string
+		value     time.Time
+	}{
+		{"f.created_at >= $%d", params.CreatedAfter},
+		{"f.created_at < $%d", params.CreatedBefore},
+		{"f.updated_at >= $%d", params.UpdatedAfter},
+		{"f.updated_at < $%d", params.UpdatedBefore},
+	} {
+		if !bound.value.IsZero() {
+			args = append(args, bound.value)
+			conditions = append(conditions, fmt.Sprintf(bound.condition, len(args)))
+		}
+	}
+
+	if params.Rule != nil {
+		compiler := ruleCompiler{args: args}
+		condition, err := compiler.compile(params.Rule)
+		if err != nil {
+			return nil, fmt.Errorf("could not compile rule: %w", err)
+		}
+		args = compiler.args
+		conditions = append(conditions, condition)
+	}
+
+	// The keys favorites are ordered by, all ascending, so cursors can be compared with them as a row.
+	// Ranks and frecencies are negated, as they are ordered descending.
+	keys := []string{"f.position", "f.id"}
+	if params.Sort == favorites.SortFrecency {
+		keys = append([]string{"-f.frecency"}, keys...)
+	}
+
+	rank := "0::real"
+	if params.Query != "" {
+		args = append(args, params.Query)
+		tsQuery := fmt.Sprintf("websearch_to_tsquery('english', $%d)", len(args))
+		rank = fmt.Sprintf("ts_rank(f.search_vector, %s)", tsQuery)
+		conditions = append(conditions, "f.search_vector @@ "+tsQuery)
+		keys = append([]string{"-" + rank}, keys...)
+	}
+
+	if params.Cursor != nil {
+		var values []string
+		if params.Query != "" {
+			if params.Cursor.SearchRank == nil {
+				return nil, errors.New("cursor has no search rank")
+			}
+			args = append(args, *params.Cursor.SearchRank)
+			values = append(values, fmt.Sprintf("-$%d::real", len(args)))
+		}
+		if params.Sort == favorites.SortFrecency {
+			if params.Cursor.Frecency == nil {
+				return nil, errors.New("cursor has no frecency")
+			}
+			args = append(args, *params.Cursor.Frecency)
+			values = append(values, fmt.Sprintf("-$%d::float8", len(args)))
+		}
+		args = append(args, params.Cursor.Position, params.Cursor.ID)
+		values = append(values, fmt.Sprintf("$%d", len(args)-1), fmt.Sprintf("$%d", len(args)))
+
+		conditions = append(conditions, fmt.Sprintf("(%s) > (%s)", strings.Join(keys, ", "), strings.Join(values, ", ")))
+	}
+
+	args = append(args, params.PageSize)
+	query := fmt.Sprintf(`
+        SELECT
+            f.id, f.user_id, f.asset_id, f.asset_type, f.description, f.tags, f.position, f.created_at, f.updated_at,
+            %s, f.frecency,
+            COALESCE(f.asset_snapshot <> %s, false),
+            a.*
+        FROM user_favorites f
+        JOIN (%s) a ON a.id = f.asset_id
+        WHERE %s
+        ORDER BY %s
+        LIMIT $%d`,
+		rank, assetSnapshot, selectAssetsQuery(), strings.Join(conditions, " AND "), strings.Join(keys, ", "), len(args),
+	)
+
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("could not run query: %w", err)
 	}
@@ -86,18 +445,31 @@ func (r *Repository) GetUserFavorites(ctx context.Context, userID string) ([]fav
 
 	var result []favorites.FavoriteAsset
 	for rows.Next() {
-		var f favorites.FavoriteAsset
-		if err := rows.Scan(
+		var (
+			f  favorites.FavoriteAsset
+			ar assetRow
+		)
+		if err := rows.Scan(append([]any{
 			&f.ID,
 			&f.UserID,
 			&f.AssetID,
 			&f.AssetType,
 			&f.Description,
+			&f.Tags,
+			&f.Position,
 			&f.CreatedAt,
 			&f.UpdatedAt,
-		); err != nil {
+			&f.SearchRank,
+			&f.Frecency,
+			&f.ChangedSinceFavorited,
+		}, ar.scanDest()...)...); err != nil {
 			return nil, fmt.Errorf("could not scan favorite: %w", err)
 		}
+		asset, err := ar.toAsset()
+		if err != nil {
+			return nil, err
+		}
+		f.Asset = asset
 		result = append(result, f)
 	}
 
@@ -107,20 +479,23 @@ func (r *Repository) GetUserFavorites(ctx context.Context, userID string) ([]fav
 	return result, nil
 }
 
+// UpdateFavorite updates the description of the user's favorite, and its tags unless params.Tags is nil.
 func (r *Repository) UpdateFavorite(ctx context.Context, favID, userID string, params *favorites.UpdateFavoriteParams) (*favorites.FavoriteAsset, error) {
 	var favorite favorites.FavoriteAsset
 	err := r.db.QueryRow(ctx, `
         UPDATE user_favorites
-        SET description = $1, updated_at = $2
-        WHERE id = $3 AND user_id = $4
-        RETURNING id, user_id, asset_id, asset_type, description, created_at, updated_at`,
-		params.Description, time.Now(), favID, userID,
+        SET description = $1, tags = COALESCE($2, tags), updated_at = $3
+        WHERE id = $4 AND user_id = $5 AND deleted_at IS NULL
+        RETURNING id, user_id, asset_id, asset_type, description, tags, position, created_at, updated_at`,
+		params.Description, params.Tags, time.Now(), favID, userID,
 	).Scan(
 		&favorite.ID,
 		&favorite.UserID,
 		&favorite.AssetID,
 		&favorite.AssetType,
 		&favorite.Description,
+		&favorite.Tags,
+		&favorite.Position,
 		&favorite.CreatedAt,
 		&favorite.UpdatedAt,
 	)
@@ -134,11 +509,51 @@ func (r *Repository) UpdateFavorite(ctx context.Context, favID, userID string, p
 	return &favorite, nil
 }
 
+// RecordFavoriteOpen adds the weight of an open to the frecency of the user's favorite.
+// Frecencies are stored in log space, so the weight is added as in ln(e^frecency + e^weight),
+// without computing either exponential, which would overflow. The exponent left is clamped,
+// as Postgres errors out on underflows rather than rounding them to zero.
+func (r *Repository) RecordFavoriteOpen(ctx context.Context, favoriteID, userID string, weight float64) error {
+	result, err := r.db.Exec(ctx, `
+        UPDATE user_favorites
+        SET frecency = GREATEST(frecency, $1) + ln(1 + exp(-LEAST(abs(frecency - $1), 700)))
+        WHERE id = $2 AND user_id = $3 AND deleted_at IS NULL`,
+		weight, favoriteID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("could not record favorite open: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return favorites.ErrFavoriteAssetNotFound
+	}
+	return nil
+}
+
+// DeleteFavorite moves the user's favorite to the trash, see RestoreFavorite and PurgeTrashedFavorites.
 func (r *Repository) DeleteFavorite(ctx context.Context, favoriteID, userID string) error {
 	result, err := r.db.Exec(ctx, `
-        DELETE FROM user_favorites
-        WHERE id = $1 AND user_id = $2`,
-		favoriteID, userID,
+        UPDATE user_favorites
+        SET deleted_at = $1
+        WHERE id = $2 AND user_id = $3 AND deleted_at IS NULL`,
+		time.Now(), favoriteID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("deleting favorite: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return favorites.ErrFavoriteAssetNotFound
+	}
+	return nil
+}
+
+// DeleteFavoriteByAsset moves the user's favorite of the given asset to the trash, relying on
+// the unique_user_asset index for there being at most one that isn't trashed already.
+func (r *Repository) DeleteFavoriteByAsset(ctx context.Context, assetID, userID string) error {
+	result, err := r.db.Exec(ctx, `
+        UPDATE user_favorites
+        SET deleted_at = $1
+        WHERE user_id = $2 AND asset_id = $3 AND deleted_at IS NULL`,
+		time.Now(), userID, assetID,
 	)
 	if err != nil {
 		return fmt.Errorf("deleting favorite: %w", err)
@@ -148,3 +563,17 @@ func (r *Repository) DeleteFavorite(ctx context.Context, favoriteID, userID stri
 	}
 	return nil
 }
+
+// DeleteUserFavorites moves all of the user's favorites to the trash and returns how many were moved.
+func (r *Repository) DeleteUserFavorites(ctx context.Context, userID string) (int64, error) {
+	result, err := r.db.Exec(ctx, `
+        UPDATE user_favorites
+        SET deleted_at = $1
+        WHERE user_id = $2 AND deleted_at IS NULL`,
+		time.Now(), userID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("deleting favorites: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
+	"github.com/jackc/pgx/v5"
+)
+
+// GetTrashedFavorites returns a page of the user's trashed favorites deleted after deletedAfter,
+// along with the assets they point to, most recently deleted first.
+// Pages are keyset-based on (deleted_at, id), which idx_user_favorites_trash covers.
+func (r *Repository) GetTrashedFavorites(
+	ctx context.Context,
+	userID string,
+	params *favorites.ListTrashParams,
+	deletedAfter time.Time,
+) ([]favorites.TrashedFavorite, error) {
+	conditions := []string{"f.user_id = $1", "f.deleted_at > $2"}
+	args := []any{userID, deletedAfter}
+
+	if params.Cursor != nil {
+		args = append(args, params.Cursor.DeletedAt, params.Cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(f.deleted_at, f.id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	args = append(args, params.PageSize)
+	query := fmt.Sprintf(`
+        SELECT
+            f.id, f.user_id, f.asset_id, f.asset_type, f.description, f.tags, f.position, f.created_at, f.updated_at,
+            f.deleted_at,
+            COALESCE(f.asset_snapshot <> %s, false),
+            a.*
+        FROM user_favorites f
+        JOIN (%s) a ON a.id = f.asset_id
+        WHERE %s
+        ORDER BY f.deleted_at DESC, f.id DESC
+        LIMIT $%d`,
+		assetSnapshot, selectAssetsQuery(), strings.Join(conditions, " AND "), len(args),
+	)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not run query: %w", err)
+	}
+	defer rows.Close()
+
+	var result []favorites.TrashedFavorite
+	for rows.Next() {
+		var (
+			f  favorites.TrashedFavorite
+			ar assetRow
+		)
+		if err := rows.Scan(append([]any{
+			&f.ID,
+			&f.UserID,
+			&f.AssetID,
+			&f.AssetType,
+			&f.Description,
+			&f.Tags,
+			&f.Position,
+			&f.CreatedAt,
+			&f.UpdatedAt,
+			&f.DeletedAt,
+			&f.ChangedSinceFavorited,
+		}, ar.scanDest()...)...); err != nil {
+			return nil, fmt.Errorf("could not scan trashed favorite: %w", err)
+		}
+		asset, err := ar.toAsset()
+		if err != nil {
+			return nil, err
+		}
+		f.Asset = asset
+		result = append(result, f)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not iterate over rows: %w", err)
+	}
+	return result, nil
+}
+
+// RestoreFavorite takes the user's favorite out of the trash, as long as it was deleted after deletedAfter.
+// It keeps the position it had, and the collections it was in.
+// favorites.ErrFavoriteAlreadyExists is returned if the user has another favorite of the same asset.
+func (r *Repository) RestoreFavorite(ctx context.Context, favoriteID, userID string, deletedAfter time.Time) (*favorites.FavoriteAsset, error) {
+	var favorite favorites.FavoriteAsset
+	err := r.db.QueryRow(ctx, `
+        UPDATE user_favorites
+        SET deleted_at = NULL
+        WHERE id = $1 AND user_id = $2 AND deleted_at > $3
+        RETURNING id, user_id, asset_id, asset_type, description, tags, position, created_at, updated_at`,
+		favoriteID, userID, deletedAfter,
+	).Scan(
+		&favorite.ID,
+		&favorite.UserID,
+		&favorite.AssetID,
+		&favorite.AssetType,
+		&favorite.Description,
+		&favorite.Tags,
+		&favorite.Position,
+		&favorite.CreatedAt,
+		&favorite.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, favorites.ErrFavoriteAssetNotFound
+		}
+		if isUniqueViolation(err, "unique_user_asset") {
+			return nil, favorites.ErrFavoriteAlreadyExists
+		}
+		return nil, fmt.Errorf("could not restore favorite: %w", err)
+	}
+	return &favorite, nil
+}
+
+// PurgeTrashedFavorites removes the favorites trashed before the given time for good
+// and returns how many were removed. Their collection memberships go with them.
+func (r *Repository) PurgeTrashedFavorites(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.Exec(ctx, `
+        DELETE FROM user_favorites
+        WHERE deleted_at < $1`,
+		before,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("could not purge trashed favorites: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
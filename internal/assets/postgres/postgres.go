@@ -2,11 +2,15 @@ package postgres
 
 import (
 	"github.com/alesr/platform-go-challenge/internal/assets"
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-var _ assets.Repository = (*Repository)(nil)
+var (
+	_ assets.Repository    = (*Repository)(nil)
+	_ favorites.Repository = (*Repository)(nil)
+)
 
 type Repository struct {
 	db *pgxpool.Pool
@@ -0,0 +1,263 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alesr/platform-go-challenge/internal/assets"
+	audienceexpr "github.com/alesr/platform-go-challenge/internal/assets/audience"
+	"github.com/jackc/pgx/v5"
+)
+
+// respondentsBatchSize bounds how many respondents are sent to Postgres in a single statement.
+const respondentsBatchSize = 5_000
+
+// StoreRespondents inserts respondents into the panel, replacing the ones with the same ID.
+// They're stored in batches within a single transaction, so a panel is loaded whole or not at all.
+func (r *Repository) StoreRespondents(ctx context.Context, respondents []assets.Respondent) error {
+	now := time.Now()
+
+	return r.withTx(ctx, func(tx pgx.Tx) error {
+		for batch := range slices.Chunk(respondents, respondentsBatchSize) {
+			var (
+				ids            = make([]string, 0, len(batch))
+				genders        = make([]string, 0, len(batch))
+				birthCountries = make([]string, 0, len(batch))
+				ages           = make([]int, 0, len(batch))
+				hours          = make([]float64, 0, len(batch))
+				purchases      = make([]int, 0, len(batch))
+			)
+			for _, respondent := range batch {
+				ids = append(ids, respondent.ID)
+				genders = append(genders, respondent.Gender)
+				birthCountries = append(birthCountries, respondent.BirthCountry)
+				ages = append(ages, respondent.Age)
+				hours = append(hours, respondent.SocialMediaHours)
+				purchases = append(purchases, respondent.LastMonthPurchases)
+			}
+
+			if _, err := tx.Exec(ctx, `
+                INSERT INTO respondents (
+                    id, gender, birth_country, age, social_media_hours, last_month_purchases, created_at, updated_at
+                )
+                SELECT r.id, r.gender, r.birth_country, r.age, r.social_media_hours, r.last_month_purchases, $7, $7
+                FROM unnest($1::text[], $2::text[], $3::text[], $4::int[], $5::float8[], $6::int[])
+                    AS r(id, gender, birth_country, age, social_media_hours, last_month_purchases)
+                ON CONFLICT (id) DO UPDATE SET
+                    gender = EXCLUDED.gender,
+                    birth_country = EXCLUDED.birth_country,
+                    age = EXCLUDED.age,
+                    social_media_hours = EXCLUDED.social_media_hours,
+                    last_month_purchases = EXCLUDED.last_month_purchases,
+                    updated_at = EXCLUDED.updated_at`,
+				ids,
+				genders,
+				birthCountries,
+				ages,
+				hours,
+				purchases,
+				now,
+			); err != nil {
+				return fmt.Errorf("could not insert respondents: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// CountRespondents counts the respondents of the panel matching an audience:
+// of any of its genders, birth countries and age groups, within its ranges and matching its expression, if any.
+func (r *Repository) CountRespondents(ctx context.Context, audience assets.AudienceAsset) (int, error) {
+	var c respondentsCompiler
+
+	conditions := []string{
+		fmt.Sprintf("gender = ANY(%s::text[])", c.param(audience.Data.Genders)),
+		fmt.Sprintf("birth_country = ANY(%s::text[])", c.param(audience.Data.BirthCountries)),
+		c.compileAgeGroups(audience.Data.AgeGroups),
+		c.compileRange("social_media_hours", audience.Data.SocialMediaHours),
+		c.compileRange("last_month_purchases", audience.Data.LastMonthPurchases),
+	}
+
+	if audience.Data.Expression != "" {
+		expr, err := audienceexpr.Parse(audience.Data.Expression)
+		if err != nil {
+			return 0, fmt.Errorf("could not parse audience expression: %w", err)
+		}
+
+		condition, err := c.compile(expr)
+		if err != nil {
+			return 0, err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	var count int
+	if err := r.db.QueryRow(ctx, `
+        SELECT COUNT(*)
+        FROM respondents
+        WHERE `+strings.Join(conditions, "\n            AND "),
+		c.args...,
+	).Scan(&count); err != nil {
+		return 0, fmt.Errorf("could not count respondents: %w", err)
+	}
+	return count, nil
+}
+
+// respondentColumns maps the attributes audience expressions look at to the columns of the respondents table.
+var respondentColumns = map[string]string{
+	"gender":               "gender",
+	"birth_country":        "birth_country",
+	"age":                  "age",
+	"social_media_hours":   "social_media_hours",
+	"last_month_purchases": "last_month_purchases",
+}
+
+var expressionComparisons = map[audienceexpr.Op]string{
+	audienceexpr.OpEq:  "=",
+	audienceexpr.OpNe:  "<>",
+	audienceexpr.OpLt:  "<",
+	audienceexpr.OpLte: "<=",
+	audienceexpr.OpGt:  ">",
+	audienceexpr.OpGte: ">=",
+}
+
+// respondentsCompiler compiles the criteria of an audience into SQL conditions on the respondents table.
+// Values are never written into the SQL but appended to args as query parameters.
+type respondentsCompiler struct {
+	args []any
+}
+
+// compileAgeGroups matches the ages of any of the age groups.
+func (c *respondentsCompiler) compileAgeGroups(groups []assets.AgeGroup) string {
+	conditions := make([]string, 0, len(groups))
+	for _, g := range groups {
+		if ages, ok := g.Ages(); ok {
+			conditions = append(conditions, c.compileRange("age", ages))
+		}
+	}
+
+	if len(conditions) == 0 {
+		return "false"
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}
+
+// compileRange matches the values of the column within the range, bounds included.
+func (c *respondentsCompiler) compileRange(column string, r assets.Range) string {
+	conditions := make([]string, 0, 2)
+	if r.Min != nil {
+		conditions = append(conditions, fmt.Sprintf("%s >= %s::float8", column, c.param(float64(*r.Min))))
+	}
+	if r.Max != nil {
+		conditions = append(conditions, fmt.Sprintf("%s <= %s::float8", column, c.param(float64(*r.Max))))
+	}
+
+	if len(conditions) == 0 {
+		return "true"
+	}
+	return "(" + strings.Join(conditions, " AND ") + ")"
+}
+
+// compile compiles a parsed audience expression.
+func (c *respondentsCompiler) compile(expr audienceexpr.Expr) (string, error) {
+	switch e := expr.(type) {
+	case *audienceexpr.And:
+		return c.compileAll(e.Exprs, " AND ")
+
+	case *audienceexpr.Or:
+		return c.compileAll(e.Exprs, " OR ")
+
+	case *audienceexpr.Not:
+		condition, err := c.compile(e.Expr)
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + condition, nil
+
+	case *audienceexpr.Compare:
+		column, err := respondentColumn(e.Attr)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s %s %s", column, expressionComparisons[e.Op], c.valueParam(e.Value)), nil
+
+	case *audienceexpr.In:
+		column, err := respondentColumn(e.Attr)
+		if err != nil {
+			return "", err
+		}
+		condition := fmt.Sprintf("%s = ANY(%s)", column, c.valuesParam(e.Values))
+		if e.Negated {
+			return "NOT " + condition, nil
+		}
+		return condition, nil
+
+	case *audienceexpr.Between:
+		column, err := respondentColumn(e.Attr)
+		if err != nil {
+			return "", err
+		}
+		not := ""
+		if e.Negated {
+			not = "NOT "
+		}
+		return fmt.Sprintf("(%s %sBETWEEN %s::float8 AND %s::float8)", column, not, c.param(e.Low), c.param(e.High)), nil
+	}
+	return "", fmt.Errorf("unsupported audience expression %T", expr)
+}
+
+func (c *respondentsCompiler) compileAll(exprs []audienceexpr.Expr, operator string) (string, error) {
+	conditions := make([]string, 0, len(exprs))
+	for _, e := range exprs {
+		condition, err := c.compile(e)
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, condition)
+	}
+	return "(" + strings.Join(conditions, operator) + ")", nil
+}
+
+func (c *respondentsCompiler) param(value any) string {
+	c.args = append(c.args, value)
+	return fmt.Sprintf("$%d", len(c.args))
+}
+
+// valueParam adds a literal of an expression, cast to the type it's compared as.
+func (c *respondentsCompiler) valueParam(v audienceexpr.Value) string {
+	if _, ok := v.(string); ok {
+		return c.param(v) + "::text"
+	}
+	return c.param(v) + "::float8"
+}
+
+// valuesParam adds the literals of an IN expression, all of the same type as they are type-checked, as an array.
+func (c *respondentsCompiler) valuesParam(values []audienceexpr.Value) string {
+	if len(values) > 0 {
+		if _, ok := values[0].(string); ok {
+			return c.param(convertValues[string](values)) + "::text[]"
+		}
+	}
+	return c.param(convertValues[float64](values)) + "::float8[]"
+}
+
+func convertValues[T any](values []audienceexpr.Value) []T {
+	result := make([]T, 0, len(values))
+	for _, v := range values {
+		if typed, ok := v.(T); ok {
+			result = append(result, typed)
+		}
+	}
+	return result
+}
+
+func respondentColumn(attr string) (string, error) {
+	column, ok := respondentColumns[attr]
+	if !ok {
+		return "", fmt.Errorf("unknown audience attribute '%s'", attr)
+	}
+	return column, nil
+}
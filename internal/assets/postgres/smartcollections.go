@@ -0,0 +1,294 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alesr/platform-go-challenge/internal/assets"
+	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+)
+
+// CreateSmartCollection stores a new smart collection for the user, its rule as JSON.
+// favorites.ErrCollectionNameTaken is returned if the user already has a smart collection with that name.
+func (r *Repository) CreateSmartCollection(ctx context.Context, userID string, params *favorites.SmartCollectionParams) (*favorites.SmartCollection, error) {
+	now := time.Now()
+	collection := favorites.SmartCollection{
+		ID:        ulid.Make().String(),
+		UserID:    userID,
+		Name:      params.Name,
+		Rule:      params.Rule,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if _, err := r.db.Exec(ctx, `
+        INSERT INTO smart_collections (id, user_id, name, rule, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6)`,
+		collection.ID, collection.UserID, collection.Name, collection.Rule, collection.CreatedAt, collection.UpdatedAt,
+	); err != nil {
+		if isUniqueViolation(err, "unique_user_smart_collection_name") {
+			return nil, favorites.ErrCollectionNameTaken
+		}
+		return nil, fmt.Errorf("could not insert smart collection: %w", err)
+	}
+	return &collection, nil
+}
+
+// ListSmartCollections returns all of the user's smart collections, ordered by name.
+func (r *Repository) ListSmartCollections(ctx context.Context, userID string) ([]favorites.SmartCollection, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT id, user_id, name, rule, created_at, updated_at
+        FROM smart_collections
+        WHERE user_id = $1
+        ORDER BY name, id`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query smart collections: %w", err)
+	}
+
+	collections, err := pgx.CollectRows(rows, pgx.RowToStructByPos[favorites.SmartCollection])
+	if err != nil {
+		return nil, fmt.Errorf("could not scan smart collections: %w", err)
+	}
+	return collections, nil
+}
+
+// FetchSmartCollection returns one of the user's smart collections.
+// favorites.ErrSmartCollectionNotFound is returned if it doesn't exist or belongs to another user.
+func (r *Repository) FetchSmartCollection(ctx context.Context, collectionID, userID string) (*favorites.SmartCollection, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT id, user_id, name, rule, created_at, updated_at
+        FROM smart_collections
+        WHERE id = $1 AND user_id = $2`,
+		collectionID, userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query smart collection: %w", err)
+	}
+
+	collection, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByPos[favorites.SmartCollection])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, favorites.ErrSmartCollectionNotFound
+		}
+		return nil, fmt.Errorf("could not scan smart collection: %w", err)
+	}
+	return &collection, nil
+}
+
+// DeleteSmartCollection deletes one of the user's smart collections.
+func (r *Repository) DeleteSmartCollection(ctx context.Context, collectionID, userID string) error {
+	result, err := r.db.Exec(ctx, `
+        DELETE FROM smart_collections
+        WHERE id = $1 AND user_id = $2`,
+		collectionID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("deleting smart collection: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return favorites.ErrSmartCollectionNotFound
+	}
+	return nil
+}
+
+// ruleColumns maps the fields of the favorites rules look at to the columns of the GetUserFavorites query,
+// where f is the favorite. The asset's fields are looked up with ruleColumn.
+var ruleColumns = map[string]string{
+	"asset_type":  "f.asset_type",
+	"description": "f.description",
+	"created_at":  "f.created_at",
+	"updated_at":  "f.updated_at",
+}
+
+// ruleColumn returns the column of the GetUserFavorites query holding a rule field, where the favorited
+// asset's fields under "asset." are read from its data, in a, as the type the rule compares them as.
+// Sets of strings are compared one string at a time, in the column v of the elements returned as well,
+// which the condition is to be checked against.
+func ruleColumn(field string) (column, elements string, ok bool) {
+	if column, ok := ruleColumns[field]; ok {
+		return column, "", true
+	}
+
+	assetField, ok := favorites.LookupAssetRuleField(field)
+	if !ok {
+		return "", "", false
+	}
+
+	switch assetField.Type {
+	case assets.FieldString:
+		return fmt.Sprintf("(a.data->>'%s')", assetField.Name), "", true
+	case assets.FieldStrings:
+		return "v", fmt.Sprintf("jsonb_array_elements_text(a.data->'%s') AS v", assetField.Name), true
+	case assets.FieldNumber:
+		return fmt.Sprintf("(a.data->>'%s')::float8", assetField.Name), "", true
+	case assets.FieldRange:
+		return fmt.Sprintf("(a.data->'%s'->>'%s')::float8", assetField.Name, assetField.Bound), "", true
+	}
+	return "", "", false
+}
+
+// ruleCasts are the types rule values are sent as, so the comparisons don't
+// depend on how Postgres would infer the type of a parameter.
+var ruleCasts = map[favorites.RuleFieldType]string{
+	favorites.RuleFieldString: "text",
+	favorites.RuleFieldNumber: "float8",
+	favorites.RuleFieldTime:   "timestamptz",
+}
+
+var ruleComparisons = map[favorites.RuleOp]string{
+	favorites.RuleOpEq:  "=",
+	favorites.RuleOpNe:  "<>",
+	favorites.RuleOpLt:  "<",
+	favorites.RuleOpLte: "<=",
+	favorites.RuleOpGt:  ">",
+	favorites.RuleOpGte: ">=",
+}
+
+// ruleCompiler compiles a validated rule into a SQL condition.
+// Values are never written into the SQL but appended to args as query parameters.
+type ruleCompiler struct {
+	args []any
+}
+
+func (c *ruleCompiler) compile(rule *favorites.Rule) (string, error) {
+	switch {
+	case rule.All != nil:
+		return c.compileAll(rule.All, " AND ")
+
+	case rule.Any != nil:
+		return c.compileAll(rule.Any, " OR ")
+
+	case rule.Not != nil:
+		condition, err := c.compile(rule.Not)
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + condition, nil
+	}
+	return c.compileCondition(rule)
+}
+
+func (c *ruleCompiler) compileAll(rules []favorites.Rule, operator string) (string, error) {
+	conditions := make([]string, 0, len(rules))
+	for i := range rules {
+		condition, err := c.compile(&rules[i])
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, condition)
+	}
+	return "(" + strings.Join(conditions, operator) + ")", nil
+}
+
+// compileCondition compiles a single comparison. Columns without a value, like the
+// asset fields of other asset types, make it false rather than NULL, so negating it matches.
+func (c *ruleCompiler) compileCondition(rule *favorites.Rule) (string, error) {
+	column, elements, ok := ruleColumn(rule.Field)
+	fieldType, known := favorites.RuleFieldTypeOf(rule.Field)
+	if !ok || !known {
+		return "", fmt.Errorf("%w: unknown field '%s'", favorites.ErrInvalidRule, rule.Field)
+	}
+	cast := ruleCasts[fieldType]
+
+	var condition string
+	switch rule.Op {
+	case favorites.RuleOpIn:
+		values, ok := rule.Value.([]any)
+		if !ok {
+			return "", fmt.Errorf("%w: field '%s' expects a list of values", favorites.ErrInvalidRule, rule.Field)
+		}
+		param, err := ruleValues(fieldType, values)
+		if err != nil {
+			return "", err
+		}
+		condition = fmt.Sprintf("%s = ANY(%s::%s[])", column, c.param(param), cast)
+
+	case favorites.RuleOpContains:
+		value, ok := rule.Value.(string)
+		if !ok {
+			return "", fmt.Errorf("%w: field '%s' expects a string value", favorites.ErrInvalidRule, rule.Field)
+		}
+		condition = fmt.Sprintf("%s ILIKE %s", column, c.param("%"+escapeLike(value)+"%"))
+
+	case favorites.RuleOpWithinDays:
+		days, ok := rule.Value.(float64)
+		if !ok {
+			return "", fmt.Errorf("%w: field '%s' expects a number of days", favorites.ErrInvalidRule, rule.Field)
+		}
+		condition = fmt.Sprintf("%s >= now() - make_interval(days => %s::int)", column, c.param(int(days)))
+
+	default:
+		comparison, ok := ruleComparisons[rule.Op]
+		if !ok {
+			return "", fmt.Errorf("%w: unknown operator '%s'", favorites.ErrInvalidRule, rule.Op)
+		}
+		value, err := ruleValue(fieldType, rule.Value)
+		if err != nil {
+			return "", err
+		}
+		condition = fmt.Sprintf("%s %s %s::%s", column, comparison, c.param(value), cast)
+	}
+
+	if elements != "" {
+		// there are no elements for the assets of other types, so the condition is false for them
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE %s)", elements, condition), nil
+	}
+	return "COALESCE(" + condition + ", false)", nil
+}
+
+func (c *ruleCompiler) param(value any) string {
+	c.args = append(c.args, value)
+	return fmt.Sprintf("$%d", len(c.args))
+}
+
+// ruleValue converts a rule value decoded from JSON to the type it's sent to Postgres as.
+func ruleValue(fieldType favorites.RuleFieldType, value any) (any, error) {
+	if fieldType == favorites.RuleFieldTime {
+		s, _ := value.(string)
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, fmt.Errorf("%w: '%v' is not an RFC 3339 timestamp", favorites.ErrInvalidRule, value)
+		}
+		return t, nil
+	}
+	return value, nil
+}
+
+// ruleValues converts the values of an "in" condition to a typed slice, which is sent to Postgres as an array.
+func ruleValues(fieldType favorites.RuleFieldType, values []any) (any, error) {
+	switch fieldType {
+	case favorites.RuleFieldString:
+		return convertRuleValues[string](fieldType, values)
+	case favorites.RuleFieldNumber:
+		return convertRuleValues[float64](fieldType, values)
+	}
+	return convertRuleValues[time.Time](fieldType, values)
+}
+
+func convertRuleValues[T any](fieldType favorites.RuleFieldType, values []any) ([]T, error) {
+	result := make([]T, 0, len(values))
+	for _, v := range values {
+		value, err := ruleValue(fieldType, v)
+		if err != nil {
+			return nil, err
+		}
+		typed, ok := value.(T)
+		if !ok {
+			return nil, fmt.Errorf("%w: '%v' is not a %s value", favorites.ErrInvalidRule, v, fieldType)
+		}
+		result = append(result, typed)
+	}
+	return result, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s, so it's matched literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
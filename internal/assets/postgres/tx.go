@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// withTx runs fn in a transaction, committing it if fn succeeds and rolling it back otherwise.
+func (r *Repository) withTx(ctx context.Context, fn func(tx pgx.Tx) error) (err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				err = fmt.Errorf("could not rollback transaction: %v (original error: %w)", rollbackErr, err)
+			}
+			return
+		}
+		if commitErr := tx.Commit(ctx); commitErr != nil {
+			err = fmt.Errorf("committing transaction: %w", commitErr)
+		}
+	}()
+
+	return fn(tx)
+}
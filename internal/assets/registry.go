@@ -0,0 +1,240 @@
+package assets
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// FieldType is the type of the values a field of an asset's data holds.
+type FieldType string
+
+const (
+	// Enumerate field types
+
+	FieldString  FieldType = "string"
+	FieldStrings FieldType = "strings"
+	FieldNumber  FieldType = "number"
+	FieldNumbers FieldType = "numbers"
+	// FieldRange holds a Range, encoded as a JSON object.
+	FieldRange FieldType = "range"
+)
+
+// Field is one of the fields of an asset type's data.
+// Name is the field's key in the data encoded as JSON, which is how the API and
+// the favorites' snapshots know it, and Column the column it's stored in.
+type Field struct {
+	Name   string
+	Column string
+	Type   FieldType
+}
+
+// TypeSpec describes an asset type whose data is T, see Register.
+// T is encoded as JSON with encoding/json, and that's the shape of the asset's data in the API.
+type TypeSpec[T any] struct {
+	Type AssetType
+	// Table stores the assets of this type, with an id, created_at and updated_at column,
+	// and one column for each of Fields.
+	Table  string
+	Fields []Field
+	// Validate checks the data of the assets created or updated through the registry, it's optional.
+	Validate func(T) error
+	// Normalize rewrites valid data in its canonical form before it's stored, it's optional.
+	Normalize func(T) T
+}
+
+// Kind is a registered asset type, which the layers dealing with assets dispatch on.
+type Kind interface {
+	Type() AssetType
+	Table() string
+	Fields() []Field
+	// New creates an asset from its data as JSON. The asset gets a new ID unless id is given,
+	// which replaces the data of the existing asset with that ID.
+	New(id string, data []byte) (Asseter, error)
+	// Load builds a stored asset from its data as JSON, the data is not validated.
+	Load(metadata Metadata, data []byte) (Asseter, error)
+	// Patch replaces the fields present in data, leaving the others as they are in the asset.
+	Patch(asset Asseter, data []byte) (Asseter, error)
+	// Data returns the data of the asset, ready to be encoded as JSON.
+	Data(asset Asseter) any
+	// Validate checks the data of the asset, however it was created.
+	Validate(asset Asseter) error
+}
+
+var registry = struct {
+	sync.RWMutex
+	kinds  map[AssetType]Kind
+	fields map[string]Field
+}{
+	kinds:  make(map[AssetType]Kind),
+	fields: make(map[string]Field),
+}
+
+// Register makes an asset type available to every layer, so adding an asset type comes down to
+// registering it from its own package's init, and migrating its table.
+// Fields of different asset types can share a name if they share its type as well.
+// It panics if the asset type is registered twice or the spec is invalid, as it's a programming error.
+func Register[T any](spec TypeSpec[T]) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if spec.Type == "" || spec.Table == "" || len(spec.Fields) == 0 {
+		panic(fmt.Sprintf("assets: incomplete spec for asset type '%s'", spec.Type))
+	}
+
+	if _, ok := registry.kinds[spec.Type]; ok {
+		panic(fmt.Sprintf("assets: asset type '%s' registered twice", spec.Type))
+	}
+
+	for _, f := range spec.Fields {
+		if existing, ok := registry.fields[f.Name]; ok && existing.Type != f.Type {
+			panic(fmt.Sprintf("assets: field '%s' of asset type '%s' is already registered as %s", f.Name, spec.Type, existing.Type))
+		}
+	}
+
+	for _, f := range spec.Fields {
+		registry.fields[f.Name] = f
+	}
+	registry.kinds[spec.Type] = &kind[T]{spec: spec}
+}
+
+// Lookup returns the registered asset type t, and false if there is none.
+func Lookup(t AssetType) (Kind, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	k, ok := registry.kinds[t]
+	return k, ok
+}
+
+// Kinds returns all the registered asset types, ordered by type.
+func Kinds() []Kind {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	kinds := make([]Kind, 0, len(registry.kinds))
+	for _, k := range registry.kinds {
+		kinds = append(kinds, k)
+	}
+
+	slices.SortFunc(kinds, func(a, b Kind) int {
+		return cmp.Compare(a.Type(), b.Type())
+	})
+	return kinds
+}
+
+// LookupField returns the field of any registered asset type with the given name, and false if there is none.
+func LookupField(name string) (Field, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	f, ok := registry.fields[name]
+	return f, ok
+}
+
+// kind implements Kind for the asset type whose data is T.
+type kind[T any] struct {
+	spec TypeSpec[T]
+}
+
+func (k *kind[T]) Type() AssetType { return k.spec.Type }
+
+func (k *kind[T]) Table() string { return k.spec.Table }
+
+func (k *kind[T]) Fields() []Field { return slices.Clone(k.spec.Fields) }
+
+func (k *kind[T]) New(id string, data []byte) (Asseter, error) {
+	var d T
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("could not decode %s data: %w", k.spec.Type, err)
+	}
+	return k.asseter(k.newAsset(id, d))
+}
+
+func (k *kind[T]) Load(metadata Metadata, data []byte) (Asseter, error) {
+	var d T
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("could not decode %s data: %w", k.spec.Type, err)
+	}
+
+	return Asset[T]{
+		ID:        metadata.ID,
+		CreatedAt: metadata.CreatedAt,
+		UpdatedAt: metadata.UpdatedAt,
+		Data:      d,
+		assetType: k.spec.Type,
+	}, nil
+}
+
+func (k *kind[T]) Patch(asset Asseter, data []byte) (Asseter, error) {
+	existing, ok := asset.(Asset[T])
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s' is not '%s'", ErrAssetTypeMismatch, asset.Type(), k.spec.Type)
+	}
+
+	// Decoding a copy of the data, rather than on top of it, leaves the slices of the asset untouched.
+	current, err := json.Marshal(existing.Data)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode %s data: %w", k.spec.Type, err)
+	}
+
+	var d T
+	if err := json.Unmarshal(current, &d); err != nil {
+		return nil, fmt.Errorf("could not decode %s data: %w", k.spec.Type, err)
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &d); err != nil {
+			return nil, fmt.Errorf("could not decode %s data: %w", k.spec.Type, err)
+		}
+	}
+	return k.asseter(k.newAsset(existing.ID, d))
+}
+
+// asseter returns the asset built by newAsset as an Asseter, which is nil when there is an error.
+func (k *kind[T]) asseter(asset Asset[T], err error) (Asseter, error) {
+	if err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+func (k *kind[T]) Data(asset Asseter) any {
+	if a, ok := asset.(Asset[T]); ok {
+		return a.Data
+	}
+	return nil
+}
+
+func (k *kind[T]) Validate(asset Asseter) error {
+	a, ok := asset.(Asset[T])
+	if !ok {
+		return fmt.Errorf("%w: '%s' is not '%s'", ErrAssetTypeMismatch, asset.Type(), k.spec.Type)
+	}
+	return k.validate(a.Data)
+}
+
+func (k *kind[T]) validate(data T) error {
+	if k.spec.Validate == nil {
+		return nil
+	}
+	return k.spec.Validate(data)
+}
+
+func (k *kind[T]) newAsset(id string, data T) (Asset[T], error) {
+	if err := k.validate(data); err != nil {
+		return Asset[T]{}, err
+	}
+
+	if k.spec.Normalize != nil {
+		data = k.spec.Normalize(data)
+	}
+
+	asset := NewAsset(k.spec.Type, data)
+	if id != "" {
+		asset.ID = id
+	}
+	return asset, nil
+}
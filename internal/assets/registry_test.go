@@ -0,0 +1,280 @@
+package assets
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// report is an asset type registered by the tests only, the way a new asset type would register itself.
+type report struct {
+	Title string `json:"title"`
+	Pages int    `json:"pages"`
+}
+
+const typeAssetReport AssetType = "TEST_REPORT"
+
+var errNoPages = errors.New("report has no pages")
+
+func init() {
+	Register(TypeSpec[report]{
+		Type:  typeAssetReport,
+		Table: "report_assets",
+		Fields: []Field{
+			{Name: "title", Column: "title", Type: FieldString},
+			{Name: "pages", Column: "pages", Type: FieldNumber},
+		},
+		Validate: func(r report) error {
+			if r.Pages <= 0 {
+				return errNoPages
+			}
+			return nil
+		},
+	})
+}
+
+func TestLookup(t *testing.T) {
+	t.Parallel()
+
+	for _, assetType := range []AssetType{TypeAssetChart, TypeAssetInsight, TypeAssetAudience, typeAssetReport} {
+		kind, ok := Lookup(assetType)
+		require.True(t, ok, assetType)
+		assert.Equal(t, assetType, kind.Type())
+	}
+
+	_, ok := Lookup("VIDEO")
+	assert.False(t, ok)
+
+	kind, _ := Lookup(TypeAssetInsight)
+	assert.Equal(t, "insight_assets", kind.Table())
+	assert.Equal(t, []Field{{Name: "insight", Column: "data", Type: FieldString}}, kind.Fields())
+}
+
+func TestKinds(t *testing.T) {
+	t.Parallel()
+
+	var types []AssetType
+	for _, kind := range Kinds() {
+		types = append(types, kind.Type())
+	}
+
+	assert.True(t, slices.IsSorted(types))
+	assert.Subset(t, types, []AssetType{TypeAssetAudience, TypeAssetChart, TypeAssetInsight, typeAssetReport})
+}
+
+func TestLookupField(t *testing.T) {
+	t.Parallel()
+
+	f, ok := LookupField("birth_countries")
+	require.True(t, ok)
+	assert.Equal(t, FieldStrings, f.Type)
+
+	// shared by charts and reports
+	f, ok = LookupField("title")
+	require.True(t, ok)
+	assert.Equal(t, FieldString, f.Type)
+
+	_, ok = LookupField("foo")
+	assert.False(t, ok)
+}
+
+func TestRegister_Panics(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		givenSpec TypeSpec[report]
+	}{
+		{
+			name:      "incomplete spec",
+			givenSpec: TypeSpec[report]{Type: "TEST_INCOMPLETE"},
+		},
+		{
+			name: "registered twice",
+			givenSpec: TypeSpec[report]{
+				Type:   typeAssetReport,
+				Table:  "report_assets",
+				Fields: []Field{{Name: "pages", Column: "pages", Type: FieldNumber}},
+			},
+		},
+		{
+			name: "field registered with another type",
+			givenSpec: TypeSpec[report]{
+				Type:   "TEST_CONFLICT",
+				Table:  "conflict_assets",
+				Fields: []Field{{Name: "genders", Column: "genders", Type: FieldString}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Panics(t, func() { Register(tc.givenSpec) })
+
+			if tc.givenSpec.Type != typeAssetReport {
+				_, ok := Lookup(tc.givenSpec.Type)
+				assert.False(t, ok)
+			}
+		})
+	}
+}
+
+func TestKind_New(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		givenType   AssetType
+		givenID     string
+		givenData   string
+		expectData  any
+		expectErrIs error
+		expectErr   bool
+	}{
+		{
+			name:       "chart",
+			givenType:  TypeAssetChart,
+			givenData:  `{"title":"Foo","x_axis":"X","y_axis":"Y","data":[1,2]}`,
+			expectData: chart{Title: "Foo", XAxis: "X", YAxis: "Y", Data: []float64{1, 2}},
+		},
+		{
+			name:       "replacing an asset",
+			givenType:  TypeAssetInsight,
+			givenID:    "foo-id",
+			givenData:  `{"insight":"Bar"}`,
+			expectData: insight{Insight: "Bar"},
+		},
+		{
+			name:       "registered type",
+			givenType:  typeAssetReport,
+			givenData:  `{"title":"Quarterly","pages":3}`,
+			expectData: report{Title: "Quarterly", Pages: 3},
+		},
+		{
+			name:      "normalized data",
+			givenType: TypeAssetAudience,
+			givenData: `{"genders":["Male"],"birth_countries":["IT"],"age_groups":["65+"],"expression":"age>70"}`,
+			expectData: audience{
+				Genders:        []string{"Male"},
+				BirthCountries: []string{"IT"},
+				AgeGroups:      []AgeGroup{AgeGroup65Plus},
+				Expression:     "age > 70",
+			},
+		},
+		{
+			name:      "data of another shape",
+			givenType: TypeAssetInsight,
+			givenData: `{"insight":42}`,
+			expectErr: true,
+		},
+		{
+			name:        "invalid data",
+			givenType:   typeAssetReport,
+			givenData:   `{"title":"Quarterly"}`,
+			expectErrIs: errNoPages,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			kind, ok := Lookup(tc.givenType)
+			require.True(t, ok)
+
+			got, err := kind.New(tc.givenID, []byte(tc.givenData))
+
+			if tc.expectErrIs != nil {
+				assert.ErrorIs(t, err, tc.expectErrIs)
+				return
+			}
+
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.givenType, got.Type())
+			assert.Equal(t, tc.expectData, kind.Data(got))
+			assert.WithinDuration(t, time.Now(), got.Metadata().CreatedAt, time.Second)
+
+			if tc.givenID != "" {
+				assert.Equal(t, tc.givenID, got.Metadata().ID)
+			} else {
+				assert.NotEmpty(t, got.Metadata().ID)
+			}
+		})
+	}
+}
+
+func TestKind_Load(t *testing.T) {
+	t.Parallel()
+
+	kind, ok := Lookup(typeAssetReport)
+	require.True(t, ok)
+
+	metadata := Metadata{
+		ID:        "foo-id",
+		CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	// stored data isn't validated
+	got, err := kind.Load(metadata, []byte(`{"title":"Draft","pages":0}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, typeAssetReport, got.Type())
+	assert.Equal(t, metadata, got.Metadata())
+	assert.Equal(t, report{Title: "Draft"}, kind.Data(got))
+
+	_, err = kind.Load(metadata, []byte(`{"title":`))
+	assert.Error(t, err)
+}
+
+func TestKind_Patch(t *testing.T) {
+	t.Parallel()
+
+	kind, ok := Lookup(TypeAssetChart)
+	require.True(t, ok)
+
+	givenChart, err := NewAssetFactory().CreateChart("Foo", "X", "Y", []float64{1, 2})
+	require.NoError(t, err)
+
+	t.Run("patch some fields", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := kind.Patch(givenChart, []byte(`{"title":"Bar","data":[3]}`))
+		require.NoError(t, err)
+
+		assert.Equal(t, givenChart.ID, got.Metadata().ID)
+		assert.Equal(t, chart{Title: "Bar", XAxis: "X", YAxis: "Y", Data: []float64{3}}, kind.Data(got))
+
+		// the patched asset doesn't share its data with the original one
+		assert.Equal(t, []float64{1, 2}, givenChart.Data.Data)
+	})
+
+	t.Run("patch nothing", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := kind.Patch(givenChart, nil)
+		require.NoError(t, err)
+		assert.Equal(t, givenChart.Data, kind.Data(got))
+	})
+
+	t.Run("asset of another type", func(t *testing.T) {
+		t.Parallel()
+
+		givenInsight, err := NewAssetFactory().CreateInsight("Foo")
+		require.NoError(t, err)
+
+		_, err = kind.Patch(givenInsight, []byte(`{"title":"Bar"}`))
+		assert.ErrorIs(t, err, ErrAssetTypeMismatch)
+	})
+}
@@ -0,0 +1,204 @@
+package assets
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// Bounds on the attributes of respondents, on top of the ones shared with audiences.
+	maxRespondentIDLength = 127
+	maxRespondentAge      = 120
+)
+
+var (
+	// Enumerate respondent errors
+
+	ErrInvalidRespondent    = errors.New("invalid respondent")
+	ErrInvalidRespondentCSV = errors.New("invalid respondents CSV")
+	ErrNotAudience          = errors.New("asset is not an audience")
+)
+
+// Respondent is a person of the panel audiences are sized against,
+// with the attributes audiences pick people by.
+type Respondent struct {
+	ID                 string
+	Gender             string
+	BirthCountry       string
+	Age                int
+	SocialMediaHours   float64
+	LastMonthPurchases int
+}
+
+// AudienceSize is how many respondents of the panel match an audience.
+// Sizes below Threshold are suppressed, so small groups of people can't be singled out:
+// Size is nil and Suppressed is set, telling only that fewer than Threshold respondents match.
+type AudienceSize struct {
+	Size       *int
+	Suppressed bool
+	Threshold  int
+}
+
+// respondentColumns are the columns of a respondents CSV, in the order WriteRespondentsCSV writes them.
+var respondentColumns = []string{"id", "gender", "birth_country", "age", "social_media_hours", "last_month_purchases"}
+
+// ReadRespondentsCSV reads respondents from CSV with a header naming the columns, in any order.
+// Every respondent is validated, and errors tell the line of the record they are about.
+func ReadRespondentsCSV(r io.Reader) ([]Respondent, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: missing header", ErrInvalidRespondentCSV)
+		}
+		return nil, fmt.Errorf("could not read header: %w, %w", err, ErrInvalidRespondentCSV)
+	}
+
+	index := make(map[string]int, len(respondentColumns))
+	for i, column := range header {
+		index[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range respondentColumns {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("%w: missing column '%s'", ErrInvalidRespondentCSV, column)
+		}
+	}
+
+	var (
+		respondents []Respondent
+		seen        = make(map[string]int)
+	)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read record: %w, %w", err, ErrInvalidRespondentCSV)
+		}
+
+		line, _ := reader.FieldPos(0)
+		respondent, err := parseRespondent(func(column string) string { return strings.TrimSpace(record[index[column]]) })
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidRespondentCSV, line, err)
+		}
+
+		if first, ok := seen[respondent.ID]; ok {
+			return nil, fmt.Errorf("%w: line %d: %w: id '%s' already on line %d",
+				ErrInvalidRespondentCSV, line, ErrInvalidRespondent, respondent.ID, first)
+		}
+		seen[respondent.ID] = line
+		respondents = append(respondents, respondent)
+	}
+	return respondents, nil
+}
+
+// WriteRespondentsCSV writes respondents as CSV, which ReadRespondentsCSV reads back.
+func WriteRespondentsCSV(w io.Writer, respondents []Respondent) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(respondentColumns); err != nil {
+		return fmt.Errorf("could not write header: %w", err)
+	}
+
+	for _, r := range respondents {
+		if err := writer.Write([]string{
+			r.ID,
+			r.Gender,
+			r.BirthCountry,
+			strconv.Itoa(r.Age),
+			strconv.FormatFloat(r.SocialMediaHours, 'f', -1, 64),
+			strconv.Itoa(r.LastMonthPurchases),
+		}); err != nil {
+			return fmt.Errorf("could not write respondent: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("could not flush respondents: %w", err)
+	}
+	return nil
+}
+
+// parseRespondent builds a valid respondent from the values of its columns.
+func parseRespondent(value func(column string) string) (Respondent, error) {
+	age, err := strconv.Atoi(value("age"))
+	if err != nil {
+		return Respondent{}, fmt.Errorf("%w: age '%s' is not a whole number", ErrInvalidRespondent, value("age"))
+	}
+
+	hours, err := strconv.ParseFloat(value("social_media_hours"), 64)
+	if err != nil {
+		return Respondent{}, fmt.Errorf("%w: social media hours '%s' is not a number", ErrInvalidRespondent, value("social_media_hours"))
+	}
+
+	purchases, err := strconv.Atoi(value("last_month_purchases"))
+	if err != nil {
+		return Respondent{}, fmt.Errorf("%w: last month purchases '%s' is not a whole number", ErrInvalidRespondent, value("last_month_purchases"))
+	}
+
+	respondent := Respondent{
+		ID:                 value("id"),
+		Gender:             value("gender"),
+		BirthCountry:       value("birth_country"),
+		Age:                age,
+		SocialMediaHours:   hours,
+		LastMonthPurchases: purchases,
+	}
+
+	if err := validateRespondent(respondent); err != nil {
+		return Respondent{}, err
+	}
+	return respondent, nil
+}
+
+// validateRespondents checks the respondents are valid, and that no two of them have the same ID.
+func validateRespondents(respondents []Respondent) error {
+	seen := make(map[string]struct{}, len(respondents))
+	for _, r := range respondents {
+		if err := validateRespondent(r); err != nil {
+			return err
+		}
+		if _, ok := seen[r.ID]; ok {
+			return fmt.Errorf("%w: duplicate id '%s'", ErrInvalidRespondent, r.ID)
+		}
+		seen[r.ID] = struct{}{}
+	}
+	return nil
+}
+
+// validateRespondent checks the attributes of a respondent are within the bounds audiences use.
+func validateRespondent(r Respondent) error {
+	if r.ID == "" || utf8.RuneCountInString(r.ID) > maxRespondentIDLength {
+		return fmt.Errorf("%w: expected an id of 1 to %d characters", ErrInvalidRespondent, maxRespondentIDLength)
+	}
+
+	for _, v := range []string{r.Gender, r.BirthCountry} {
+		if strings.TrimSpace(v) == "" || utf8.RuneCountInString(v) > maxAssetStringFieldLength {
+			return fmt.Errorf("%w: '%s': expected a gender and a birth country of 1 to %d characters",
+				ErrInvalidRespondent, r.ID, maxAssetStringFieldLength)
+		}
+	}
+
+	if r.Age < 0 || r.Age > maxRespondentAge {
+		return fmt.Errorf("%w: '%s': expected an age between 0 and %d", ErrInvalidRespondent, r.ID, maxRespondentAge)
+	}
+
+	if math.IsNaN(r.SocialMediaHours) || r.SocialMediaHours < 0 || r.SocialMediaHours > maxDailySocialMediaHours {
+		return fmt.Errorf("%w: '%s': expected social media hours between 0 and %d", ErrInvalidRespondent, r.ID, maxDailySocialMediaHours)
+	}
+
+	if r.LastMonthPurchases < 0 || r.LastMonthPurchases > maxLastMonthPurchases {
+		return fmt.Errorf("%w: '%s': expected last month purchases between 0 and %d", ErrInvalidRespondent, r.ID, maxLastMonthPurchases)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package assets
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRespondentsCSV(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name              string
+		givenCSV          string
+		expectRespondents []Respondent
+		expectErr         error
+		expectErrMsg      string
+	}{
+		{
+			name: "respondents",
+			givenCSV: "id,gender,birth_country,age,social_media_hours,last_month_purchases\n" +
+				"r1,Female,Italy,30,2.5,3\n" +
+				"r2,Male,Brazil,65,0,0\n",
+			expectRespondents: []Respondent{
+				{ID: "r1", Gender: "Female", BirthCountry: "Italy", Age: 30, SocialMediaHours: 2.5, LastMonthPurchases: 3},
+				{ID: "r2", Gender: "Male", BirthCountry: "Brazil", Age: 65, SocialMediaHours: 0, LastMonthPurchases: 0},
+			},
+		},
+		{
+			name: "columns in any order and case, with spaces",
+			givenCSV: "Age, ID, last_month_purchases, gender, social_media_hours, Birth_Country\n" +
+				"30, r1, 3, Female, 2.5, Côte d'Ivoire\n",
+			expectRespondents: []Respondent{
+				{ID: "r1", Gender: "Female", BirthCountry: "Côte d'Ivoire", Age: 30, SocialMediaHours: 2.5, LastMonthPurchases: 3},
+			},
+		},
+		{
+			name:     "header only",
+			givenCSV: "id,gender,birth_country,age,social_media_hours,last_month_purchases\n",
+		},
+		{
+			name:         "empty",
+			givenCSV:     "",
+			expectErr:    ErrInvalidRespondentCSV,
+			expectErrMsg: "missing header",
+		},
+		{
+			name:         "missing column",
+			givenCSV:     "id,gender,birth_country,age,social_media_hours\n",
+			expectErr:    ErrInvalidRespondentCSV,
+			expectErrMsg: "missing column 'last_month_purchases'",
+		},
+		{
+			name: "wrong number of fields",
+			givenCSV: "id,gender,birth_country,age,social_media_hours,last_month_purchases\n" +
+				"r1,Female,Italy,30,2.5\n",
+			expectErr: ErrInvalidRespondentCSV,
+		},
+		{
+			name: "invalid number",
+			givenCSV: "id,gender,birth_country,age,social_media_hours,last_month_purchases\n" +
+				"r1,Female,Italy,30,2.5,3\n" +
+				"r2,Male,Brazil,thirty,2.5,3\n",
+			expectErr:    ErrInvalidRespondent,
+			expectErrMsg: "line 3: invalid respondent: age 'thirty' is not a whole number",
+		},
+		{
+			name: "out of bounds",
+			givenCSV: "id,gender,birth_country,age,social_media_hours,last_month_purchases\n" +
+				"r1,Female,Italy,30,25,3\n",
+			expectErr:    ErrInvalidRespondent,
+			expectErrMsg: "line 2: invalid respondent: 'r1': expected social media hours between 0 and 24",
+		},
+		{
+			name: "missing gender",
+			givenCSV: "id,gender,birth_country,age,social_media_hours,last_month_purchases\n" +
+				"r1, ,Italy,30,2,3\n",
+			expectErr: ErrInvalidRespondent,
+		},
+		{
+			name: "duplicate id",
+			givenCSV: "id,gender,birth_country,age,social_media_hours,last_month_purchases\n" +
+				"r1,Female,Italy,30,2.5,3\n" +
+				"r1,Male,Brazil,65,0,0\n",
+			expectErr:    ErrInvalidRespondent,
+			expectErrMsg: "id 'r1' already on line 2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ReadRespondentsCSV(strings.NewReader(tc.givenCSV))
+
+			if tc.expectErr != nil {
+				require.ErrorIs(t, err, tc.expectErr)
+				assert.ErrorIs(t, err, ErrInvalidRespondentCSV)
+				assert.Contains(t, err.Error(), tc.expectErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectRespondents, got)
+		})
+	}
+}
+
+func TestWriteRespondentsCSV(t *testing.T) {
+	t.Parallel()
+
+	given := []Respondent{
+		{ID: "r1", Gender: "Female", BirthCountry: "Italy", Age: 30, SocialMediaHours: 2.5, LastMonthPurchases: 3},
+		{ID: "r2", Gender: "Male", BirthCountry: "Bosnia, Herzegovina", Age: 65, SocialMediaHours: 0, LastMonthPurchases: 0},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteRespondentsCSV(&buf, given))
+
+	assert.Equal(t,
+		"id,gender,birth_country,age,social_media_hours,last_month_purchases\n"+
+			"r1,Female,Italy,30,2.5,3\n"+
+			"r2,Male,\"Bosnia, Herzegovina\",65,0,0\n",
+		buf.String(),
+	)
+
+	// what's written reads back the same
+	got, err := ReadRespondentsCSV(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, given, got)
+}
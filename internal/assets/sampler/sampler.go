@@ -1,12 +1,16 @@
 // Package generator provides a utility function to create sample assets for demonstration purposes.
-// It generates random instances of different asset types (Chart, Insight, and Audience).
+// It generates random instances of different asset types (Chart, Insight, and Audience),
+// and a synthetic panel of respondents to size audiences against.
 // To allow users to favorite assets during the Challenge's demonstration, we need existing assets,
 // hence this generator. It is implemented as a subpackage under assets to 1) clearly separate
 // non-business related concerns, and 2) allow easy removal when it becomes no longer necessary.
 package sampler
 
 import (
+	"fmt"
+	"math"
 	"math/rand"
+	"slices"
 	"strconv"
 
 	"github.com/alesr/platform-go-challenge/internal/assets"
@@ -16,22 +20,35 @@ import (
 var (
 	genders   = []string{"Male", "Female", "Other"}
 	countries = []string{"Germany", "Italy", "Brazil", "Portugal", "Slovenia", "Sweden", "Romania", "Greece"}
+
+	// expressions narrow down sampled audiences, which mostly have none
+	expressions = []string{
+		"", "", "", "",
+		`age BETWEEN 18 AND 24 OR social_media_hours > 5`,
+		`gender IN ("Female") AND age >= 30`,
+		`NOT (birth_country = "Italy" AND last_month_purchases < 2)`,
+	}
 )
 
-func SampleAssets(n int) []assets.Asseter {
+// SampleAssets creates n random assets of any type.
+// They go through the asset factory, so an error means the sampler produced invalid data.
+func SampleAssets(n int) ([]assets.Asseter, error) {
 	samples := make([]assets.Asseter, n)
 	factory := assets.NewAssetFactory()
 
 	for i := 0; i < n; i++ {
-		assetType := rand.Intn(3)
+		var (
+			sample assets.Asseter
+			err    error
+		)
 
-		switch assetType {
+		switch rand.Intn(3) {
 		case 0:
 			data := make([]float64, rand.Intn(5)+1)
 			for j := range data {
 				data[j] = rand.Float64() * 100
 			}
-			samples[i] = factory.CreateChart(
+			sample, err = factory.CreateChart(
 				"Chart "+strconv.Itoa(i),
 				"X Axis",
 				"Y Axis",
@@ -39,18 +56,64 @@ func SampleAssets(n int) []assets.Asseter {
 			)
 
 		case 1:
-			samples[i] = factory.CreateInsight(faker.Sentence())
+			sample, err = factory.CreateInsight(faker.Sentence())
 
 		case 2:
-			samples[i] = factory.CreateAudience(
-				genders[rand.Intn(len(genders))],
-				countries[rand.Intn(len(countries))],
-				rand.Intn(60),    // ageMin
-				rand.Intn(20)+60, // ageMax
-				rand.Intn(9000),  // socialMediaHours
-				rand.Intn(100),   // lastMonthPurchases
+			sample, err = factory.CreateAudience(
+				sampleOf(genders),
+				sampleOf(countries),
+				sampleOf(assets.AgeGroups()),
+				sampleRange(24),  // socialMediaHours, a day
+				sampleRange(100), // lastMonthPurchases
+				expressions[rand.Intn(len(expressions))],
 			)
 		}
+
+		if err != nil {
+			return nil, fmt.Errorf("could not sample asset: %w", err)
+		}
+		samples[i] = sample
+	}
+	return samples, nil
+}
+
+// SampleRespondents creates a synthetic panel of n respondents, with the genders and countries
+// sampled audiences pick people by, so their sizes can be tested without a real panel.
+func SampleRespondents(n int) []assets.Respondent {
+	respondents := make([]assets.Respondent, n)
+	for i := range respondents {
+		respondents[i] = assets.Respondent{
+			ID:                 "respondent-" + strconv.Itoa(i+1),
+			Gender:             genders[rand.Intn(len(genders))],
+			BirthCountry:       countries[rand.Intn(len(countries))],
+			Age:                13 + rand.Intn(68),                  // 13 to 80
+			SocialMediaHours:   math.Round(rand.Float64()*120) / 10, // 0 to 12, to the tenth of an hour
+			LastMonthPurchases: rand.Intn(21),
+		}
+	}
+	return respondents
+}
+
+// sampleOf returns a random non-empty subset of values, in their order.
+func sampleOf[T any](values []T) []T {
+	picked := rand.Perm(len(values))[:rand.Intn(len(values))+1]
+	slices.Sort(picked)
+
+	subset := make([]T, 0, len(picked))
+	for _, i := range picked {
+		subset = append(subset, values[i])
+	}
+	return subset
+}
+
+// sampleRange returns a random range within 0 and max, open on either side or closed.
+func sampleRange(max int) assets.Range {
+	min := rand.Intn(max)
+	switch rand.Intn(3) {
+	case 0:
+		return assets.AtLeast(min)
+	case 1:
+		return assets.AtMost(min + 1)
 	}
-	return samples
+	return assets.Between(min, min+rand.Intn(max-min)+1)
 }
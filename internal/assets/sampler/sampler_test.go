@@ -1,10 +1,12 @@
 package sampler
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/alesr/platform-go-challenge/internal/assets"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSampleAssets(t *testing.T) {
@@ -32,7 +34,8 @@ func TestSampleAssets(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			got := SampleAssets(tc.amount)
+			got, err := SampleAssets(tc.amount)
+			require.NoError(t, err)
 
 			assert.Equal(t, tc.amount, len(got))
 
@@ -59,11 +62,11 @@ func TestSampleAssets(t *testing.T) {
 					audienceAsset, ok := asset.(assets.AudienceAsset)
 					assert.True(t, ok)
 					assert.NotEmpty(t, audienceAsset.ID)
-					assert.NotEmpty(t, audienceAsset.Data.Gender)
-					assert.NotEmpty(t, audienceAsset.Data.BirthCountry)
-					assert.GreaterOrEqual(t, audienceAsset.Data.AgeMax, audienceAsset.Data.AgeMin)
-					assert.GreaterOrEqual(t, audienceAsset.Data.SocialMediaHours, 0)
-					assert.GreaterOrEqual(t, audienceAsset.Data.LastMonthPurchases, 0)
+					assert.NotEmpty(t, audienceAsset.Data.Genders)
+					assert.NotEmpty(t, audienceAsset.Data.BirthCountries)
+					assert.NotEmpty(t, audienceAsset.Data.AgeGroups)
+					assert.False(t, audienceAsset.Data.SocialMediaHours.Min == nil && audienceAsset.Data.SocialMediaHours.Max == nil)
+					assert.False(t, audienceAsset.Data.LastMonthPurchases.Min == nil && audienceAsset.Data.LastMonthPurchases.Max == nil)
 
 				default:
 					t.Errorf("unexpected asset type: %v", asset.Type())
@@ -73,3 +76,23 @@ func TestSampleAssets(t *testing.T) {
 		})
 	}
 }
+
+func TestSampleRespondents(t *testing.T) {
+	t.Parallel()
+
+	got := SampleRespondents(1000)
+	require.Len(t, got, 1000)
+
+	// the panel is valid, as the CSV it's written as reads back
+	var buf bytes.Buffer
+	require.NoError(t, assets.WriteRespondentsCSV(&buf, got))
+
+	read, err := assets.ReadRespondentsCSV(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, got, read)
+
+	for _, r := range got {
+		assert.Contains(t, genders, r.Gender)
+		assert.Contains(t, countries, r.BirthCountry)
+	}
+}
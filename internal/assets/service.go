@@ -12,28 +12,56 @@ import (
 var (
 	// Enumerate service errors
 
-	ErrAssetNotFound = errors.New("asset not found")
+	ErrAssetNotFound     = errors.New("asset not found")
+	ErrAssetTypeMismatch = errors.New("asset type cannot be changed")
 )
 
 // Repository defines the interface for asset storage operations.
 // Exported so we can guarantee that the postgres implementation implements this interface.
 type Repository interface {
 	StoreAsset(ctx context.Context, asset Asseter) error
-	ListAssets(ctx context.Context, params *ListAssetsParams) ([]Asseter, string, error)
+	ListAssets(ctx context.Context, params *ListAssetsParams) ([]ListedAsset, string, error)
+	FetchAsset(ctx context.Context, id string) (Asseter, error)
+	UpdateAsset(ctx context.Context, asset Asseter) (Asseter, error)
+	DeleteAsset(ctx context.Context, id string) error
+	StoreRespondents(ctx context.Context, respondents []Respondent) error
+	CountRespondents(ctx context.Context, audience AudienceAsset) (int, error)
 }
 
+// defaultSizeThreshold is the smallest audience size reported by default.
+const defaultSizeThreshold = 10
+
 // Service provides asset management operations including listing, storing, and managing user favorites.
 type Service struct {
-	logger     *slog.Logger
-	repository Repository
+	logger        *slog.Logger
+	repository    Repository
+	sizeThreshold int
+}
+
+// Option configures the service.
+type Option func(*Service)
+
+// WithSizeThreshold sets the smallest audience size reported, the k of k-anonymity.
+// Audiences matching fewer respondents have their size suppressed.
+func WithSizeThreshold(k int) Option {
+	return func(s *Service) {
+		if k > 0 {
+			s.sizeThreshold = k
+		}
+	}
 }
 
 // NewService instantiates a new assets service.
-func NewService(logger *slog.Logger, repo Repository) *Service {
-	return &Service{
-		logger:     logger.WithGroup("assets-service"),
-		repository: repo,
+func NewService(logger *slog.Logger, repo Repository, opts ...Option) *Service {
+	s := &Service{
+		logger:        logger.WithGroup("assets-service"),
+		repository:    repo,
+		sizeThreshold: defaultSizeThreshold,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // StoreAssets receives a list of assets and stores them in the repository.
@@ -43,6 +71,12 @@ func (s *Service) StoreAssets(_ context.Context, assets []Asseter) error {
 	ctx, cancel := context.WithTimeout(context.Background(), BackgroundCtxTimeout)
 	defer cancel()
 
+	for _, asset := range assets {
+		if err := validateAsset(asset); err != nil {
+			return fmt.Errorf("could not validate asset: %w", err)
+		}
+	}
+
 	eg, ctx := errgroup.WithContext(ctx)
 	for _, asset := range assets {
 		eg.Go(func() error {
@@ -59,11 +93,118 @@ func (s *Service) StoreAssets(_ context.Context, assets []Asseter) error {
 	return nil
 }
 
-// ListAssets returns a paginated list of assets.
-func (s *Service) ListAssets(ctx context.Context, params *ListAssetsParams) ([]Asseter, string, error) {
+// ListAssets returns a paginated list of assets, along with how each of them has been favorited.
+func (s *Service) ListAssets(ctx context.Context, params *ListAssetsParams) ([]ListedAsset, string, error) {
 	assets, nextPageToken, err := s.repository.ListAssets(ctx, params)
 	if err != nil {
 		return nil, "", fmt.Errorf("could not list assets: %w", err)
 	}
 	return assets, nextPageToken, nil
 }
+
+// CreateAsset stores a single asset.
+func (s *Service) CreateAsset(_ context.Context, asset Asseter) error {
+	if err := validateAsset(asset); err != nil {
+		return fmt.Errorf("could not validate asset: %w", err)
+	}
+
+	// detach so we prevent writing interruption if context is canceled
+	ctx, cancel := context.WithTimeout(context.Background(), BackgroundCtxTimeout)
+	defer cancel()
+
+	if err := s.repository.StoreAsset(ctx, asset); err != nil {
+		return fmt.Errorf("could not store asset: %w", err)
+	}
+	return nil
+}
+
+// FetchAsset returns the asset identified by the given ID.
+func (s *Service) FetchAsset(ctx context.Context, id string) (Asseter, error) {
+	asset, err := s.repository.FetchAsset(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch asset: %w", err)
+	}
+	return asset, nil
+}
+
+// UpdateAsset replaces the data of the asset identified by the given ID.
+// An asset lives in a table per type, so changing its type is not allowed.
+func (s *Service) UpdateAsset(_ context.Context, id string, asset Asseter) (Asseter, error) {
+	// detach so we prevent writing interruption if context is canceled
+	ctx, cancel := context.WithTimeout(context.Background(), BackgroundCtxTimeout)
+	defer cancel()
+
+	existing, err := s.repository.FetchAsset(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch asset: %w", err)
+	}
+
+	if existing.Type() != asset.Type() {
+		return nil, ErrAssetTypeMismatch
+	}
+
+	if err := validateAsset(asset); err != nil {
+		return nil, fmt.Errorf("could not validate asset: %w", err)
+	}
+
+	updated, err := s.repository.UpdateAsset(ctx, asset)
+	if err != nil {
+		return nil, fmt.Errorf("could not update asset: %w", err)
+	}
+	return updated, nil
+}
+
+// DeleteAsset deletes an asset along with any favorites pointing to it.
+func (s *Service) DeleteAsset(ctx context.Context, id string) error {
+	if err := s.repository.DeleteAsset(ctx, id); err != nil {
+		return fmt.Errorf("could not delete asset: %w", err)
+	}
+	return nil
+}
+
+// StoreRespondents adds respondents to the panel audiences are sized against,
+// replacing the ones with the same ID.
+func (s *Service) StoreRespondents(ctx context.Context, respondents []Respondent) error {
+	if err := validateRespondents(respondents); err != nil {
+		return fmt.Errorf("could not validate respondents: %w", err)
+	}
+
+	if err := s.repository.StoreRespondents(ctx, respondents); err != nil {
+		return fmt.Errorf("could not store respondents: %w", err)
+	}
+	return nil
+}
+
+// AudienceSize counts the respondents of the panel matching the audience identified by the given ID.
+// Sizes below the service's threshold are suppressed, see AudienceSize.
+func (s *Service) AudienceSize(ctx context.Context, id string) (*AudienceSize, error) {
+	asset, err := s.repository.FetchAsset(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch asset: %w", err)
+	}
+
+	audience, ok := asset.(AudienceAsset)
+	if !ok {
+		return nil, ErrNotAudience
+	}
+
+	count, err := s.repository.CountRespondents(ctx, audience)
+	if err != nil {
+		return nil, fmt.Errorf("could not count respondents: %w", err)
+	}
+
+	if count < s.sizeThreshold {
+		return &AudienceSize{Suppressed: true, Threshold: s.sizeThreshold}, nil
+	}
+	return &AudienceSize{Size: &count, Threshold: s.sizeThreshold}, nil
+}
+
+// validateAsset checks the asset's data with the rules its type registered,
+// so invalid data never reaches the repository, whichever way the asset was built.
+func validateAsset(asset Asseter) error {
+	kind, ok := Lookup(asset.Type())
+	if !ok {
+		return fmt.Errorf("unsupported asset type '%s'", asset.Type())
+	}
+	return kind.Validate(asset)
+}
@@ -21,6 +21,10 @@ func TestNewService(t *testing.T) {
 
 	assert.Equal(t, logger.WithGroup("assets-service"), got.logger)
 	assert.Equal(t, &repo, got.repository)
+	assert.Equal(t, defaultSizeThreshold, got.sizeThreshold)
+
+	got = NewService(logger, &repo, WithSizeThreshold(50))
+	assert.Equal(t, 50, got.sizeThreshold)
 }
 
 func TestService_ListAssets(t *testing.T) {
@@ -37,22 +41,28 @@ func TestService_ListAssets(t *testing.T) {
 
 	testCases := []struct {
 		name            string
-		givenMockResult func() ([]Asseter, string, error)
-		expectedAssets  []Asseter
+		givenMockResult func() ([]ListedAsset, string, error)
+		expectedAssets  []ListedAsset
 		expectedToken   string
 		expectedError   error
 	}{
 		{
 			name: "success",
-			givenMockResult: func() ([]Asseter, string, error) {
-				return []Asseter{assets.charts[0], assets.insights[0]}, "bar-page-tkn", nil
+			givenMockResult: func() ([]ListedAsset, string, error) {
+				return []ListedAsset{
+					{Asset: assets.charts[0], FavoriteCount: 2, FavoriteID: "foo-fav-id"},
+					{Asset: assets.insights[0]},
+				}, "bar-page-tkn", nil
+			},
+			expectedAssets: []ListedAsset{
+				{Asset: assets.charts[0], FavoriteCount: 2, FavoriteID: "foo-fav-id"},
+				{Asset: assets.insights[0]},
 			},
-			expectedAssets: []Asseter{assets.charts[0], assets.insights[0]},
-			expectedToken:  "bar-page-tkn",
+			expectedToken: "bar-page-tkn",
 		},
 		{
 			name: "repository returns error",
-			givenMockResult: func() ([]Asseter, string, error) {
+			givenMockResult: func() ([]ListedAsset, string, error) {
 				return nil, "", assert.AnError
 			},
 			expectedError: assert.AnError,
@@ -65,7 +75,7 @@ func TestService_ListAssets(t *testing.T) {
 
 			var repoCalled bool
 			repo := repoMock{
-				listAssetsFunc: func(ctx context.Context, params *ListAssetsParams) ([]Asseter, string, error) {
+				listAssetsFunc: func(ctx context.Context, params *ListAssetsParams) ([]ListedAsset, string, error) {
 					repoCalled = true
 
 					assert.Equal(t, givenParams.PageSize, params.PageSize)
@@ -101,6 +111,7 @@ func TestStoreAssets(t *testing.T) {
 		name        string
 		givenAssets []Asseter
 		expectError bool
+		expectErrIs error
 		repoError   error
 	}{
 		{
@@ -120,8 +131,18 @@ func TestStoreAssets(t *testing.T) {
 				assets.insights[0],
 			},
 			expectError: true,
+			expectErrIs: assert.AnError,
 			repoError:   assert.AnError,
 		},
+		{
+			name: "invalid asset",
+			givenAssets: []Asseter{
+				assets.charts[0],
+				NewAsset(TypeAssetInsight, insight{}),
+			},
+			expectError: true,
+			expectErrIs: ErrEmptyInsight,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -146,7 +167,7 @@ func TestStoreAssets(t *testing.T) {
 
 			if tc.expectError {
 				require.Error(t, err)
-				assert.ErrorIs(t, err, tc.repoError)
+				assert.ErrorIs(t, err, tc.expectErrIs)
 				// verify that we stopped processing after the error
 				assert.Equal(t, int32(0), storedAssets.Load())
 				return
@@ -170,17 +191,433 @@ func createTestAssetsHelper(t *testing.T) testAssets {
 	factory := NewAssetFactory()
 	return testAssets{
 		charts: []ChartAsset{
-			factory.CreateChart("Should I get hired?", "contributions", "bugs", []float64{100, 3000, 2}),
+			mustCreate(factory.CreateChart("Should I get hired?", "contributions", "bugs", []float64{100, 3000, 2})),
 			// Btw, now I realized that I should have chosen two dimensions for the data points.
-			factory.CreateChart("Colleagues coming for a gyros in Crete", "weeks", "number of visits", []float64{1500, 2500, 3500}),
+			mustCreate(factory.CreateChart("Colleagues coming for a gyros in Crete", "weeks", "number of visits", []float64{1500, 2500, 3500})),
 		},
 		insights: []InsightAsset{
-			factory.CreateInsight("I'm a very chill and friendly dev"),
-			factory.CreateInsight("I think I'm gonna know if you read the tests thoroughly =]"),
+			mustCreate(factory.CreateInsight("I'm a very chill and friendly dev")),
+			mustCreate(factory.CreateInsight("I think I'm gonna know if you read the tests thoroughly =]")),
 		},
 		audiences: []AudienceAsset{
-			factory.CreateAudience("Female", "BR", 25, 34, 3, 5),
-			factory.CreateAudience("Male", "IT", 18, 24, 5, 8),
+			mustCreate(factory.CreateAudience(
+				[]string{"Female"},
+				[]string{"BR"},
+				[]AgeGroup{AgeGroup25To34},
+				Between(3, 3),
+				AtMost(5),
+				"",
+			)),
+			mustCreate(factory.CreateAudience(
+				[]string{"Male"},
+				[]string{"IT"},
+				[]AgeGroup{AgeGroup18To24},
+				Between(5, 5),
+				AtMost(8),
+				"",
+			)),
+		},
+	}
+}
+
+// mustCreate returns the asset created by the factory, for test data known to be valid.
+func mustCreate[T Asseter](asset T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return asset
+}
+
+func TestService_CreateAsset(t *testing.T) {
+	t.Parallel()
+
+	assets := createTestAssetsHelper(t)
+
+	testCases := []struct {
+		name          string
+		givenRepoErr  error
+		expectedError error
+	}{
+		{
+			name: "success",
+		},
+		{
+			name:          "repository returns error",
+			givenRepoErr:  assert.AnError,
+			expectedError: assert.AnError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var repoCalled bool
+			repo := repoMock{
+				storeAssetFunc: func(ctx context.Context, asset Asseter) error {
+					repoCalled = true
+					assert.Equal(t, assets.charts[0], asset)
+					return tc.givenRepoErr
+				},
+			}
+
+			svc := Service{repository: &repo}
+
+			err := svc.CreateAsset(context.TODO(), assets.charts[0])
+
+			require.True(t, repoCalled)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestService_FetchAsset(t *testing.T) {
+	t.Parallel()
+
+	assets := createTestAssetsHelper(t)
+
+	testCases := []struct {
+		name            string
+		givenMockResult func() (Asseter, error)
+		expectedAsset   Asseter
+		expectedError   error
+	}{
+		{
+			name: "success",
+			givenMockResult: func() (Asseter, error) {
+				return assets.insights[0], nil
+			},
+			expectedAsset: assets.insights[0],
+		},
+		{
+			name: "asset not found",
+			givenMockResult: func() (Asseter, error) {
+				return nil, ErrAssetNotFound
+			},
+			expectedError: ErrAssetNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := repoMock{
+				fetchAssetFunc: func(ctx context.Context, id string) (Asseter, error) {
+					assert.Equal(t, assets.insights[0].ID, id)
+					return tc.givenMockResult()
+				},
+			}
+
+			svc := Service{repository: &repo}
+
+			got, err := svc.FetchAsset(context.TODO(), assets.insights[0].ID)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedAsset, got)
+		})
+	}
+}
+
+func TestService_UpdateAsset(t *testing.T) {
+	t.Parallel()
+
+	assets := createTestAssetsHelper(t)
+
+	givenChart := assets.charts[1]
+	givenChart.ID = assets.charts[0].ID
+
+	invalidChart := givenChart
+	invalidChart.Data.Title = ""
+
+	testCases := []struct {
+		name             string
+		givenAsset       Asseter
+		givenFetchResult func() (Asseter, error)
+		givenUpdateErr   error
+		expectUpdate     bool
+		expectedError    error
+	}{
+		{
+			name:       "success",
+			givenAsset: givenChart,
+			givenFetchResult: func() (Asseter, error) {
+				return assets.charts[0], nil
+			},
+			expectUpdate: true,
+		},
+		{
+			name:       "asset not found",
+			givenAsset: givenChart,
+			givenFetchResult: func() (Asseter, error) {
+				return nil, ErrAssetNotFound
+			},
+			expectedError: ErrAssetNotFound,
+		},
+		{
+			name:       "asset type mismatch",
+			givenAsset: assets.insights[0],
+			givenFetchResult: func() (Asseter, error) {
+				return assets.charts[0], nil
+			},
+			expectedError: ErrAssetTypeMismatch,
+		},
+		{
+			name:       "invalid asset",
+			givenAsset: invalidChart,
+			givenFetchResult: func() (Asseter, error) {
+				return assets.charts[0], nil
+			},
+			expectedError: ErrEmptyChartTitle,
+		},
+		{
+			name:       "repository update error",
+			givenAsset: givenChart,
+			givenFetchResult: func() (Asseter, error) {
+				return assets.charts[0], nil
+			},
+			givenUpdateErr: assert.AnError,
+			expectUpdate:   true,
+			expectedError:  assert.AnError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var updateCalled bool
+			repo := repoMock{
+				fetchAssetFunc: func(ctx context.Context, id string) (Asseter, error) {
+					assert.Equal(t, assets.charts[0].ID, id)
+					return tc.givenFetchResult()
+				},
+				updateAssetFunc: func(ctx context.Context, asset Asseter) (Asseter, error) {
+					updateCalled = true
+					assert.Equal(t, tc.givenAsset, asset)
+					if tc.givenUpdateErr != nil {
+						return nil, tc.givenUpdateErr
+					}
+					return asset, nil
+				},
+			}
+
+			svc := Service{repository: &repo}
+
+			got, err := svc.UpdateAsset(context.TODO(), assets.charts[0].ID, tc.givenAsset)
+
+			assert.Equal(t, tc.expectUpdate, updateCalled)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, got)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.givenAsset, got)
+		})
+	}
+}
+
+func TestService_DeleteAsset(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		givenRepoErr  error
+		expectedError error
+	}{
+		{
+			name: "success",
 		},
+		{
+			name:          "asset not found",
+			givenRepoErr:  ErrAssetNotFound,
+			expectedError: ErrAssetNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := repoMock{
+				deleteAssetFunc: func(ctx context.Context, id string) error {
+					assert.Equal(t, "foo-asset-id", id)
+					return tc.givenRepoErr
+				},
+			}
+
+			svc := Service{repository: &repo}
+
+			err := svc.DeleteAsset(context.TODO(), "foo-asset-id")
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestService_StoreRespondents(t *testing.T) {
+	t.Parallel()
+
+	validRespondent := Respondent{
+		ID:                 "r1",
+		Gender:             "Female",
+		BirthCountry:       "Italy",
+		Age:                30,
+		SocialMediaHours:   2.5,
+		LastMonthPurchases: 3,
+	}
+
+	invalidAge := validRespondent
+	invalidAge.ID = "r2"
+	invalidAge.Age = 200
+
+	testCases := []struct {
+		name             string
+		givenRespondents []Respondent
+		expectStored     bool
+		expectErr        error
+	}{
+		{
+			name:             "valid respondents",
+			givenRespondents: []Respondent{validRespondent},
+			expectStored:     true,
+		},
+		{
+			name:             "invalid respondent",
+			givenRespondents: []Respondent{validRespondent, invalidAge},
+			expectErr:        ErrInvalidRespondent,
+		},
+		{
+			name:             "duplicate id",
+			givenRespondents: []Respondent{validRespondent, validRespondent},
+			expectErr:        ErrInvalidRespondent,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stored []Respondent
+			repo := repoMock{
+				storeRespondentsFunc: func(ctx context.Context, respondents []Respondent) error {
+					stored = respondents
+					return nil
+				},
+			}
+
+			svc := Service{repository: &repo}
+
+			err := svc.StoreRespondents(context.TODO(), tc.givenRespondents)
+
+			if tc.expectErr != nil {
+				assert.ErrorIs(t, err, tc.expectErr)
+				assert.Nil(t, stored)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.givenRespondents, stored)
+		})
+	}
+}
+
+func TestService_AudienceSize(t *testing.T) {
+	t.Parallel()
+
+	factory := NewAssetFactory()
+
+	givenAudience := mustCreate(factory.CreateAudience(
+		[]string{"Female"},
+		[]string{"Italy"},
+		[]AgeGroup{AgeGroup18To24},
+		AtLeast(1),
+		AtMost(5),
+		"",
+	))
+	givenChart := mustCreate(factory.CreateChart("Foo", "X", "Y", []float64{1}))
+
+	expectSize := 10
+
+	testCases := []struct {
+		name        string
+		givenAsset  Asseter
+		givenCount  int
+		givenErr    error
+		expectSize  *AudienceSize
+		expectedErr error
+	}{
+		{
+			name:       "size at the threshold",
+			givenAsset: givenAudience,
+			givenCount: 10,
+			expectSize: &AudienceSize{Size: &expectSize, Threshold: 10},
+		},
+		{
+			name:       "size below the threshold is suppressed",
+			givenAsset: givenAudience,
+			givenCount: 9,
+			expectSize: &AudienceSize{Suppressed: true, Threshold: 10},
+		},
+		{
+			name:       "no respondents",
+			givenAsset: givenAudience,
+			givenCount: 0,
+			expectSize: &AudienceSize{Suppressed: true, Threshold: 10},
+		},
+		{
+			name:        "asset is not an audience",
+			givenAsset:  givenChart,
+			expectedErr: ErrNotAudience,
+		},
+		{
+			name:        "asset not found",
+			givenErr:    ErrAssetNotFound,
+			expectedErr: ErrAssetNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := repoMock{
+				fetchAssetFunc: func(ctx context.Context, id string) (Asseter, error) {
+					assert.Equal(t, "foo-asset-id", id)
+					return tc.givenAsset, tc.givenErr
+				},
+				countRespondentsFunc: func(ctx context.Context, audience AudienceAsset) (int, error) {
+					assert.Equal(t, givenAudience, audience)
+					return tc.givenCount, nil
+				},
+			}
+
+			svc := NewService(logutil.NewNoop(), &repo, WithSizeThreshold(10))
+
+			got, err := svc.AudienceSize(context.TODO(), "foo-asset-id")
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectSize, got)
+		})
 	}
 }
@@ -0,0 +1,68 @@
+package assets
+
+// The built-in asset types register themselves like any other asset type would,
+// see Register. Their data is encoded as JSON with the same keys the API uses.
+
+func init() {
+	Register(TypeSpec[chart]{
+		Type:  TypeAssetChart,
+		Table: "chart_assets",
+		Fields: []Field{
+			{Name: "title", Column: "title", Type: FieldString},
+			{Name: "x_axis", Column: "x_axis", Type: FieldString},
+			{Name: "y_axis", Column: "y_axis", Type: FieldString},
+			{Name: "data", Column: "data", Type: FieldNumbers},
+		},
+		Validate: validateChart,
+	})
+
+	Register(TypeSpec[insight]{
+		Type:  TypeAssetInsight,
+		Table: "insight_assets",
+		Fields: []Field{
+			{Name: "insight", Column: "data", Type: FieldString},
+		},
+		Validate: validateInsight,
+	})
+
+	Register(TypeSpec[audience]{
+		Type:  TypeAssetAudience,
+		Table: "audience_assets",
+		Fields: []Field{
+			{Name: "genders", Column: "genders", Type: FieldStrings},
+			{Name: "birth_countries", Column: "birth_countries", Type: FieldStrings},
+			{Name: "age_groups", Column: "age_groups", Type: FieldStrings},
+			{Name: "social_media_hours", Column: "social_media_hours", Type: FieldRange},
+			{Name: "last_month_purchases", Column: "last_month_purchases", Type: FieldRange},
+			{Name: "expression", Column: "expression", Type: FieldString},
+		},
+		Validate:  validateAudience,
+		Normalize: normalizeAudience,
+	})
+}
+
+// Chart defines the data structure of a chart asset.
+type chart struct {
+	Title string    `json:"title"`
+	XAxis string    `json:"x_axis"`
+	YAxis string    `json:"y_axis"`
+	Data  []float64 `json:"data"`
+}
+
+// insight defines the data structure of an insight asset.
+type insight struct {
+	Insight string `json:"insight"`
+}
+
+// audience defines the data structure of an audience: the people of any of its genders,
+// birth countries and age groups, whose daily hours on social media and purchases
+// in the last month are within its ranges, and who match its expression, if any.
+// The expression is written in the language of the audience package, and stored in canonical form.
+type audience struct {
+	Genders            []string   `json:"genders"`
+	BirthCountries     []string   `json:"birth_countries"`
+	AgeGroups          []AgeGroup `json:"age_groups"`
+	SocialMediaHours   Range      `json:"social_media_hours"`
+	LastMonthPurchases Range      `json:"last_month_purchases"`
+	Expression         string     `json:"expression,omitempty"`
+}
@@ -0,0 +1,192 @@
+package assets
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	audienceexpr "github.com/alesr/platform-go-challenge/internal/assets/audience"
+)
+
+const (
+	// Bounds on the data of assets. String fields are stored as VARCHAR(255), insights as TEXT.
+	maxAssetStringFieldLength = 255
+	maxInsightLength          = 10_000
+	maxChartDataPoints        = 10_000
+	maxAudienceValues         = 250
+	maxDailySocialMediaHours  = 24
+	maxLastMonthPurchases     = 10_000
+)
+
+var (
+	// Enumerate asset validation errors
+
+	ErrEmptyChartTitle         = errors.New("chart title is empty")
+	ErrEmptyAxisLabel          = errors.New("chart axis label is empty")
+	ErrInvalidChartData        = errors.New("invalid chart data")
+	ErrEmptyInsight            = errors.New("insight is empty")
+	ErrInsightTooLong          = errors.New("insight is too long")
+	ErrEmptyGender             = errors.New("audience gender is empty")
+	ErrEmptyBirthCountry       = errors.New("audience birth country is empty")
+	ErrInvalidAgeGroup         = errors.New("invalid audience age group")
+	ErrInvalidSocialMediaHours = errors.New("invalid audience social media hours")
+	ErrInvalidPurchases        = errors.New("invalid audience last month purchases")
+	ErrFieldTooLong            = errors.New("asset field is too long")
+	ErrTooManyValues           = errors.New("asset field has too many values")
+	ErrDuplicateValue          = errors.New("asset field has duplicate values")
+	ErrInvalidRange            = errors.New("invalid range, min is above max")
+)
+
+// FieldError is a validation error on one of the fields of an asset's data,
+// named as in the API. Err is one of the asset validation errors.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("invalid '%s': %v", e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+func fieldError(field string, err error) error {
+	return &FieldError{Field: field, Err: err}
+}
+
+func fieldTooLong(max int) error {
+	return fmt.Errorf("%w: expected up to %d characters", ErrFieldTooLong, max)
+}
+
+// Asset data is validated field by field, stopping at the first invalid field,
+// so the error names a single field.
+
+func validateChart(c chart) error {
+	if strings.TrimSpace(c.Title) == "" {
+		return fieldError("title", ErrEmptyChartTitle)
+	}
+
+	for _, axis := range []struct{ field, label string }{{"x_axis", c.XAxis}, {"y_axis", c.YAxis}} {
+		if strings.TrimSpace(axis.label) == "" {
+			return fieldError(axis.field, ErrEmptyAxisLabel)
+		}
+	}
+
+	for _, f := range []struct{ field, value string }{{"title", c.Title}, {"x_axis", c.XAxis}, {"y_axis", c.YAxis}} {
+		if utf8.RuneCountInString(f.value) > maxAssetStringFieldLength {
+			return fieldError(f.field, fieldTooLong(maxAssetStringFieldLength))
+		}
+	}
+
+	if len(c.Data) == 0 || len(c.Data) > maxChartDataPoints {
+		return fieldError("data", fmt.Errorf("%w: expected between 1 and %d points", ErrInvalidChartData, maxChartDataPoints))
+	}
+
+	for _, v := range c.Data {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fieldError("data", fmt.Errorf("%w: points must be finite numbers", ErrInvalidChartData))
+		}
+	}
+	return nil
+}
+
+func validateInsight(i insight) error {
+	if strings.TrimSpace(i.Insight) == "" {
+		return fieldError("insight", ErrEmptyInsight)
+	}
+
+	if utf8.RuneCountInString(i.Insight) > maxInsightLength {
+		return fieldError("insight", fmt.Errorf("%w: expected up to %d characters", ErrInsightTooLong, maxInsightLength))
+	}
+	return nil
+}
+
+func validateAudience(a audience) error {
+	if err := validateValues("genders", a.Genders, ErrEmptyGender); err != nil {
+		return err
+	}
+
+	if err := validateValues("birth_countries", a.BirthCountries, ErrEmptyBirthCountry); err != nil {
+		return err
+	}
+
+	if len(a.AgeGroups) == 0 {
+		return fieldError("age_groups", fmt.Errorf("%w: expected at least one of %v", ErrInvalidAgeGroup, ageGroups))
+	}
+
+	for i, g := range a.AgeGroups {
+		if !g.Valid() {
+			return fieldError("age_groups", fmt.Errorf("%w: '%s', expected one of %v", ErrInvalidAgeGroup, g, ageGroups))
+		}
+		if slices.Contains(a.AgeGroups[:i], g) {
+			return fieldError("age_groups", ErrDuplicateValue)
+		}
+	}
+
+	if err := validateRange("social_media_hours", a.SocialMediaHours, maxDailySocialMediaHours, ErrInvalidSocialMediaHours); err != nil {
+		return err
+	}
+	if err := validateRange("last_month_purchases", a.LastMonthPurchases, maxLastMonthPurchases, ErrInvalidPurchases); err != nil {
+		return err
+	}
+
+	if a.Expression != "" {
+		if _, err := audienceexpr.Parse(a.Expression); err != nil {
+			return fieldError("expression", err)
+		}
+	}
+	return nil
+}
+
+// normalizeAudience rewrites the expression of a valid audience in canonical form.
+func normalizeAudience(a audience) audience {
+	if canonical, err := audienceexpr.Canonical(a.Expression); err == nil {
+		a.Expression = canonical
+	}
+	return a
+}
+
+// validateValues checks a set of strings has between 1 and maxAudienceValues distinct values,
+// none of them empty or too long. errEmpty is returned for an empty set or value.
+func validateValues(field string, values []string, errEmpty error) error {
+	if len(values) == 0 {
+		return fieldError(field, errEmpty)
+	}
+
+	if len(values) > maxAudienceValues {
+		return fieldError(field, fmt.Errorf("%w: expected up to %d", ErrTooManyValues, maxAudienceValues))
+	}
+
+	for i, v := range values {
+		if strings.TrimSpace(v) == "" {
+			return fieldError(field, errEmpty)
+		}
+		if utf8.RuneCountInString(v) > maxAssetStringFieldLength {
+			return fieldError(field, fieldTooLong(maxAssetStringFieldLength))
+		}
+		if slices.Contains(values[:i], v) {
+			return fieldError(field, ErrDuplicateValue)
+		}
+	}
+	return nil
+}
+
+// validateRange checks the bounds of r are between 0 and max, with its min not above its max.
+// errBounds is returned for bounds out of 0 and max.
+func validateRange(field string, r Range, max int, errBounds error) error {
+	for _, bound := range []*int{r.Min, r.Max} {
+		if bound != nil && (*bound < 0 || *bound > max) {
+			return fieldError(field, fmt.Errorf("%w: expected bounds between 0 and %d", errBounds, max))
+		}
+	}
+
+	if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+		return fieldError(field, ErrInvalidRange)
+	}
+	return nil
+}
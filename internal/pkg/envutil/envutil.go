@@ -1,6 +1,11 @@
 package envutil
 
-import "os"
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
 
 func GetEnv(key, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
@@ -8,3 +13,29 @@ func GetEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func GetEnvInt(key string, fallback int) (int, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse %s as an integer: %w", key, err)
+	}
+	return n, nil
+}
+
+func GetEnvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse %s as a duration: %w", key, err)
+	}
+	return d, nil
+}
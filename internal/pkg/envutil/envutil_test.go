@@ -2,6 +2,7 @@ package envutil
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -44,3 +45,109 @@ func TestGetEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestGetEnvInt(t *testing.T) {
+	testCases := []struct {
+		name          string
+		givenKey      string
+		givenValue    string
+		givenFallback int
+		expect        int
+		isEnvSet      bool
+		expectErr     bool
+	}{
+		{
+			name:          "return envar when it exists",
+			givenKey:      "FOO_INT_KEY",
+			givenValue:    "42",
+			givenFallback: 7,
+			expect:        42,
+			isEnvSet:      true,
+		},
+		{
+			name:          "return fallback when envar doesn't exist",
+			givenKey:      "BAR_INT_KEY",
+			givenFallback: 7,
+			expect:        7,
+			isEnvSet:      false,
+		},
+		{
+			name:          "return error when envar is not an integer",
+			givenKey:      "BAZ_INT_KEY",
+			givenValue:    "foo",
+			givenFallback: 7,
+			isEnvSet:      true,
+			expectErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isEnvSet {
+				t.Setenv(tc.givenKey, tc.givenValue)
+			}
+
+			got, err := GetEnvInt(tc.givenKey, tc.givenFallback)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, got)
+		})
+	}
+}
+
+func TestGetEnvDuration(t *testing.T) {
+	testCases := []struct {
+		name          string
+		givenKey      string
+		givenValue    string
+		givenFallback time.Duration
+		expect        time.Duration
+		isEnvSet      bool
+		expectErr     bool
+	}{
+		{
+			name:          "return envar when it exists",
+			givenKey:      "FOO_DURATION_KEY",
+			givenValue:    "50ms",
+			givenFallback: time.Second,
+			expect:        50 * time.Millisecond,
+			isEnvSet:      true,
+		},
+		{
+			name:          "return fallback when envar doesn't exist",
+			givenKey:      "BAR_DURATION_KEY",
+			givenFallback: time.Second,
+			expect:        time.Second,
+			isEnvSet:      false,
+		},
+		{
+			name:          "return error when envar is not a duration",
+			givenKey:      "BAZ_DURATION_KEY",
+			givenValue:    "foo",
+			givenFallback: time.Second,
+			isEnvSet:      true,
+			expectErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isEnvSet {
+				t.Setenv(tc.givenKey, tc.givenValue)
+			}
+
+			got, err := GetEnvDuration(tc.givenKey, tc.givenFallback)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, got)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+// Package rankutil generates lexicographic rank keys, strings whose byte order
+// gives the order of the items they rank. A key can always be generated between
+// two others, so an item can be moved without touching the keys of the rest.
+//
+// Keys are made of base 36 digits, 0-9 then a-z, and never end with a '0':
+// there's no key between "1" and "10", so we never let the latter exist.
+package rankutil
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+const base = len(digits)
+
+var (
+	ErrInvalidKey     = errors.New("invalid rank key")
+	ErrKeysOutOfOrder = errors.New("rank keys out of order")
+)
+
+// Between returns a key sorting after a and before b.
+// An empty a stands for the start of the order and an empty b for its end,
+// so Between("", "") returns the first key of an empty order.
+func Between(a, b string) (string, error) {
+	if err := validate(a); err != nil {
+		return "", err
+	}
+	if err := validate(b); err != nil {
+		return "", err
+	}
+	if b != "" && a >= b {
+		return "", fmt.Errorf("%w: '%s' is not before '%s'", ErrKeysOutOfOrder, a, b)
+	}
+	return midpoint(a, b), nil
+}
+
+// Before returns a key sorting before b, for items added one after the other at the start of the order.
+// Unlike Between("", b), which halves the room left each time and so grows keys by a digit every few calls,
+// it takes the key right below b at b's own width, and only once b is the smallest key of that width
+// moves on to keys twice as wide: keys grow with the logarithm of the number of calls, not linearly.
+// An empty b stands for the end of the order, so Before("") returns the first key of an empty order.
+func Before(b string) (string, error) {
+	if err := validate(b); err != nil {
+		return "", err
+	}
+	if b == "" {
+		return midpoint("", ""), nil
+	}
+
+	// the smallest key of its width, "0...01", leaves no room below but at a larger width
+	if strings.TrimLeft(b, "0") == "1" {
+		return strings.Repeat("0", len(b)) + strings.Repeat("z", len(b)), nil
+	}
+
+	key := []byte(b)
+	if last := len(key) - 1; key[last] != digits[1] {
+		key[last] = digits[strings.IndexByte(digits, key[last])-1]
+		return string(key), nil
+	}
+
+	// going down by one would end the key with a zero, and trimming it would give up the room
+	// left at this width, so go down by two instead, borrowing from the digits before
+	i := len(key) - 1
+	key[i] = digits[base-1]
+	for i--; key[i] == digits[0]; i-- {
+		key[i] = digits[base-1]
+	}
+	key[i] = digits[strings.IndexByte(digits, key[i])-1]
+	return string(key), nil
+}
+
+// Spread returns n keys in order, spaced evenly so there's
+// plenty of room to move items between them later on.
+func Spread(n int) []string {
+	if n <= 0 {
+		return nil
+	}
+
+	// the shortest keys with room for n of them, leaving some room at both ends
+	width, space := 1, base
+	for space <= n+1 {
+		width++
+		space *= base
+	}
+
+	keys := make([]string, n)
+	for i := range n {
+		keys[i] = encode((i+1)*space/(n+1), width)
+	}
+	return keys
+}
+
+// midpoint returns the key halfway between a and b, both valid and a < b.
+// An empty b stands for the end of the order.
+func midpoint(a, b string) string {
+	if b != "" {
+		// skip the prefix both keys share, reading past the end of a as zeros
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			return b[:n] + midpoint(tail(a, n), b[n:])
+		}
+	}
+
+	lo := strings.IndexByte(digits, digitAt(a, 0))
+	hi := base
+	if b != "" {
+		hi = strings.IndexByte(digits, b[0])
+	}
+
+	if hi-lo > 1 {
+		return string(digits[(lo+hi+1)/2])
+	}
+
+	// consecutive digits, so the key must be longer than this one digit
+	if len(b) > 1 {
+		return b[:1]
+	}
+	return string(digits[lo]) + midpoint(tail(a, 1), "")
+}
+
+// encode returns n in base 36, left padded with zeros to width, without trailing zeros.
+func encode(n, width int) string {
+	key := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		key[i] = digits[n%base]
+		n /= base
+	}
+	return strings.TrimRight(string(key), "0")
+}
+
+func validate(key string) error {
+	for i := range len(key) {
+		if strings.IndexByte(digits, key[i]) < 0 {
+			return fmt.Errorf("%w: '%s' has an unexpected character", ErrInvalidKey, key)
+		}
+	}
+	if strings.HasSuffix(key, "0") {
+		return fmt.Errorf("%w: '%s' ends with a zero", ErrInvalidKey, key)
+	}
+	return nil
+}
+
+func digitAt(key string, i int) byte {
+	if i < len(key) {
+		return key[i]
+	}
+	return digits[0]
+}
+
+func tail(key string, i int) string {
+	if i < len(key) {
+		return key[i:]
+	}
+	return ""
+}
@@ -0,0 +1,228 @@
+package rankutil
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBetween(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		givenA    string
+		givenB    string
+		expect    string
+		expectErr error
+	}{
+		{
+			name:   "empty order",
+			expect: "i",
+		},
+		{
+			name:   "after the last key",
+			givenA: "i",
+			expect: "r",
+		},
+		{
+			name:   "before the first key",
+			givenB: "i",
+			expect: "9",
+		},
+		{
+			name:   "between keys far apart",
+			givenA: "a",
+			givenB: "k",
+			expect: "f",
+		},
+		{
+			name:   "between consecutive digits",
+			givenA: "a",
+			givenB: "b",
+			expect: "ai",
+		},
+		{
+			name:   "between a key and a longer one",
+			givenA: "a",
+			givenB: "b5",
+			expect: "b",
+		},
+		{
+			name:   "between keys sharing a prefix",
+			givenA: "a1",
+			givenB: "a1k",
+			expect: "a1a",
+		},
+		{
+			name:   "before a key made of the first digits",
+			givenB: "01",
+			expect: "00i",
+		},
+		{
+			name:   "after the last digit",
+			givenA: "z",
+			expect: "zi",
+		},
+		{
+			name:      "keys out of order",
+			givenA:    "k",
+			givenB:    "a",
+			expectErr: ErrKeysOutOfOrder,
+		},
+		{
+			name:      "same keys",
+			givenA:    "k",
+			givenB:    "k",
+			expectErr: ErrKeysOutOfOrder,
+		},
+		{
+			name:      "unexpected character",
+			givenA:    "A",
+			expectErr: ErrInvalidKey,
+		},
+		{
+			name:      "trailing zero",
+			givenB:    "a0",
+			expectErr: ErrInvalidKey,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Between(tc.givenA, tc.givenB)
+			if tc.expectErr != nil {
+				assert.ErrorIs(t, err, tc.expectErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, got)
+		})
+	}
+}
+
+func TestBetween_RepeatedMoves(t *testing.T) {
+	t.Parallel()
+
+	keys := Spread(10)
+
+	// keep moving items to random places, the keys must always stay valid and in order
+	rnd := rand.New(rand.NewPCG(1, 2))
+	for range 1000 {
+		i := rnd.IntN(len(keys) + 1)
+
+		var a, b string
+		if i > 0 {
+			a = keys[i-1]
+		}
+		if i < len(keys) {
+			b = keys[i]
+		}
+
+		key, err := Between(a, b)
+		require.NoError(t, err)
+		require.NoError(t, validate(key))
+
+		keys = slices.Insert(keys, i, key)
+		require.True(t, slices.IsSorted(keys), "keys out of order after inserting %q at %d", key, i)
+	}
+}
+
+func TestSpread(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, Spread(0))
+	assert.Equal(t, []string{"i"}, Spread(1))
+	assert.Equal(t, []string{"c", "o"}, Spread(2))
+
+	for _, n := range []int{35, 36, 1000} {
+		keys := Spread(n)
+		require.Len(t, keys, n)
+		assert.True(t, slices.IsSorted(keys))
+		assert.Len(t, slices.Compact(slices.Clone(keys)), n, "keys must be unique")
+
+		for _, key := range keys {
+			assert.NoError(t, validate(key))
+		}
+	}
+}
+
+func TestBefore(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		givenB    string
+		expect    string
+		expectErr error
+	}{
+		{
+			name:   "empty order",
+			expect: "i",
+		},
+		{
+			name:   "one digit",
+			givenB: "i",
+			expect: "h",
+		},
+		{
+			name:   "borrowing rather than ending with a zero",
+			givenB: "a01",
+			expect: "9zz",
+		},
+		{
+			name:   "smallest key of one digit",
+			givenB: "1",
+			expect: "0z",
+		},
+		{
+			name:   "smallest key of two digits",
+			givenB: "01",
+			expect: "00zz",
+		},
+		{
+			name:      "invalid key",
+			givenB:    "a0",
+			expectErr: ErrInvalidKey,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Before(tc.givenB)
+			if tc.expectErr != nil {
+				assert.ErrorIs(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, got)
+		})
+	}
+}
+
+func TestBefore_RepeatedInserts(t *testing.T) {
+	t.Parallel()
+
+	var key string
+	for i := range 20_000 {
+		next, err := Before(key)
+		require.NoError(t, err)
+		require.NoError(t, validate(next))
+
+		if key != "" {
+			require.Less(t, next, key, "insert %d", i)
+		}
+		key = next
+	}
+
+	// keys grow with the logarithm of the number of inserts
+	assert.LessOrEqual(t, len(key), 8)
+}
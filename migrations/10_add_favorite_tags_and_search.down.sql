@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_user_favorites_search;
+DROP TRIGGER IF EXISTS trg_user_favorites_search_vector ON user_favorites;
+DROP FUNCTION IF EXISTS update_favorite_search_vector();
+
+ALTER TABLE user_favorites DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE user_favorites DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,25 @@
+-- Tags users label their favorites with, stored lowercased and without duplicates
+ALTER TABLE user_favorites ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- Full-text search over descriptions and tags, tags weighing more since users pick them on purpose.
+-- array_to_string isn't immutable, so the document is kept up to date by a trigger rather than a generated column.
+ALTER TABLE user_favorites ADD COLUMN search_vector TSVECTOR;
+
+CREATE FUNCTION update_favorite_search_vector() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', array_to_string(NEW.tags, ' ')), 'A') ||
+        setweight(to_tsvector('english', coalesce(NEW.description, '')), 'B');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_user_favorites_search_vector
+BEFORE INSERT OR UPDATE OF description, tags ON user_favorites
+FOR EACH ROW EXECUTE FUNCTION update_favorite_search_vector();
+
+-- Fires the trigger for the existing favorites
+UPDATE user_favorites SET description = description;
+
+-- Supports searching favorites with q=
+CREATE INDEX idx_user_favorites_search ON user_favorites USING GIN (search_vector);
@@ -0,0 +1 @@
+ALTER TABLE user_favorites DROP COLUMN IF EXISTS asset_snapshot;
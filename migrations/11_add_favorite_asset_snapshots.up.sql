@@ -0,0 +1,27 @@
+-- The asset's data as it was when it was favorited, keyed like the asset's data in the API,
+-- so users can tell whether it changed since. See assetSnapshot in the postgres package,
+-- which builds the same objects from the current asset rows.
+ALTER TABLE user_favorites ADD COLUMN asset_snapshot JSONB;
+
+-- What the existing favorites were favorited with is lost, so they start from the assets as they are now
+UPDATE user_favorites f
+SET asset_snapshot = jsonb_build_object('title', a.title, 'x_axis', a.x_axis, 'y_axis', a.y_axis, 'data', a.data)
+FROM chart_assets a
+WHERE a.id = f.asset_id;
+
+UPDATE user_favorites f
+SET asset_snapshot = jsonb_build_object('insight', a.data)
+FROM insight_assets a
+WHERE a.id = f.asset_id;
+
+UPDATE user_favorites f
+SET asset_snapshot = jsonb_build_object(
+    'gender', a.gender,
+    'birth_country', a.birth_country,
+    'age_min', a.age_min,
+    'age_max', a.age_max,
+    'social_media_hours', a.social_media_hours,
+    'last_month_purchases', a.last_month_purchases
+)
+FROM audience_assets a
+WHERE a.id = f.asset_id;
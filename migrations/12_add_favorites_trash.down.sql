@@ -0,0 +1,32 @@
+-- Trashed favorites would be back otherwise, so they go for good.
+-- They aren't counted, and the trigger still knows it at this point.
+DELETE FROM user_favorites WHERE deleted_at IS NOT NULL;
+
+DROP TRIGGER IF EXISTS trg_user_favorites_count ON user_favorites;
+
+CREATE OR REPLACE FUNCTION count_asset_favorites() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO asset_favorite_counts (asset_id, favorite_count)
+        VALUES (NEW.asset_id, 1)
+        ON CONFLICT (asset_id) DO UPDATE SET
+            favorite_count = asset_favorite_counts.favorite_count + 1;
+    ELSE
+        UPDATE asset_favorite_counts
+        SET favorite_count = favorite_count - 1
+        WHERE asset_id = OLD.asset_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_user_favorites_count
+AFTER INSERT OR DELETE ON user_favorites
+FOR EACH ROW EXECUTE FUNCTION count_asset_favorites();
+
+DROP INDEX IF EXISTS idx_user_favorites_deleted_at;
+DROP INDEX IF EXISTS idx_user_favorites_trash;
+DROP INDEX IF EXISTS unique_user_asset;
+ALTER TABLE user_favorites ADD CONSTRAINT unique_user_asset UNIQUE (user_id, asset_id);
+
+ALTER TABLE user_favorites DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,52 @@
+-- Deleted favorites go to the trash first, so users can restore them until their retention period is over.
+-- Trashed favorites have deleted_at set, and the favorites service purges them once they expire.
+ALTER TABLE user_favorites ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Users can favorite an asset again while their previous favorite of it is in the trash
+ALTER TABLE user_favorites DROP CONSTRAINT unique_user_asset;
+CREATE UNIQUE INDEX unique_user_asset ON user_favorites(user_id, asset_id) WHERE deleted_at IS NULL;
+
+-- Supports listing a user's trash, most recently deleted first
+CREATE INDEX idx_user_favorites_trash ON user_favorites(user_id, deleted_at DESC, id DESC) WHERE deleted_at IS NOT NULL;
+
+-- Supports purging expired favorites
+CREATE INDEX idx_user_favorites_deleted_at ON user_favorites(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Only live favorites are counted: trashing one counts as deleting it, restoring it as inserting it,
+-- and purging it changes nothing as it was no longer counted
+CREATE OR REPLACE FUNCTION count_asset_favorites() RETURNS TRIGGER AS $$
+DECLARE
+    delta INT := 0;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        IF NEW.deleted_at IS NULL THEN
+            delta := 1;
+        END IF;
+    ELSIF TG_OP = 'DELETE' THEN
+        IF OLD.deleted_at IS NULL THEN
+            delta := -1;
+        END IF;
+    ELSIF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+        delta := -1;
+    ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+        delta := 1;
+    END IF;
+
+    IF delta > 0 THEN
+        INSERT INTO asset_favorite_counts (asset_id, favorite_count)
+        VALUES (NEW.asset_id, 1)
+        ON CONFLICT (asset_id) DO UPDATE SET
+            favorite_count = asset_favorite_counts.favorite_count + 1;
+    ELSIF delta < 0 THEN
+        UPDATE asset_favorite_counts
+        SET favorite_count = favorite_count - 1
+        WHERE asset_id = OLD.asset_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER trg_user_favorites_count ON user_favorites;
+CREATE TRIGGER trg_user_favorites_count
+AFTER INSERT OR DELETE OR UPDATE OF deleted_at ON user_favorites
+FOR EACH ROW EXECUTE FUNCTION count_asset_favorites();
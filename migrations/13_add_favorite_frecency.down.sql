@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_user_favorites_user_frecency;
+
+ALTER TABLE user_favorites DROP COLUMN IF EXISTS frecency;
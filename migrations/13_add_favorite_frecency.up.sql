@@ -0,0 +1,8 @@
+-- How often and how recently the user opened each favorite, as the log of a score that halves over a fixed half-life.
+-- Each open adds its weight, growing with time, in log space, see RecordFavoriteOpen in the postgres package.
+-- All scores decay at the same rate, so the stored values keep their order and never need updating as time goes by.
+-- Favorites never opened have a score of zero, whose log is -Infinity.
+ALTER TABLE user_favorites ADD COLUMN frecency DOUBLE PRECISION NOT NULL DEFAULT '-Infinity';
+
+-- Supports listing favorites by frecency, most used first, with keyset pages on (-frecency, position, id)
+CREATE INDEX idx_user_favorites_user_frecency ON user_favorites(user_id, (-frecency), position, id) WHERE deleted_at IS NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE user_favorites
+ADD CONSTRAINT asset_type_check CHECK (asset_type IN ('CHART', 'INSIGHT', 'AUDIENCE'));
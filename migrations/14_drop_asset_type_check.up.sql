@@ -0,0 +1,4 @@
+-- Asset types are registered in code, each with its own table, so the types
+-- a favorite can point to are no longer a fixed list. Favorites take their type
+-- from the asset they point to, which is looked up when they are stored.
+ALTER TABLE user_favorites DROP CONSTRAINT IF EXISTS asset_type_check;
@@ -0,0 +1,49 @@
+-- Going back to single values keeps the first gender and birth country, the ages the age groups span
+-- and a bound of each range, open ranges without bounds becoming 0.
+
+CREATE FUNCTION pg_temp.audience_age_min(age_groups TEXT[]) RETURNS INTEGER AS $$
+    SELECT COALESCE(MIN(split_part(rtrim(g, '+'), '-', 1)::INTEGER), 0) FROM unnest(age_groups) AS g
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE FUNCTION pg_temp.audience_age_max(age_groups TEXT[]) RETURNS INTEGER AS $$
+    SELECT COALESCE(MAX(CASE WHEN g LIKE '%+' THEN 120 ELSE split_part(g, '-', 2)::INTEGER END), 0)
+    FROM unnest(age_groups) AS g
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE FUNCTION pg_temp.range_value(r JSONB) RETURNS INTEGER AS $$
+    SELECT COALESCE((r->>'min')::INTEGER, (r->>'max')::INTEGER, 0)
+$$ LANGUAGE SQL IMMUTABLE;
+
+UPDATE user_favorites
+SET asset_snapshot = jsonb_build_object(
+    'gender', asset_snapshot->'genders'->0,
+    'birth_country', asset_snapshot->'birth_countries'->0,
+    'age_min', pg_temp.audience_age_min(ARRAY(SELECT jsonb_array_elements_text(asset_snapshot->'age_groups'))),
+    'age_max', pg_temp.audience_age_max(ARRAY(SELECT jsonb_array_elements_text(asset_snapshot->'age_groups'))),
+    'social_media_hours', pg_temp.range_value(asset_snapshot->'social_media_hours'),
+    'last_month_purchases', pg_temp.range_value(asset_snapshot->'last_month_purchases')
+)
+WHERE asset_type = 'AUDIENCE' AND asset_snapshot ? 'genders';
+
+ALTER TABLE audience_assets
+    ADD COLUMN gender VARCHAR(255),
+    ADD COLUMN birth_country VARCHAR(255),
+    ADD COLUMN age_min INTEGER,
+    ADD COLUMN age_max INTEGER;
+
+UPDATE audience_assets
+SET gender = genders[1],
+    birth_country = birth_countries[1],
+    age_min = pg_temp.audience_age_min(age_groups),
+    age_max = pg_temp.audience_age_max(age_groups);
+
+ALTER TABLE audience_assets
+    ALTER COLUMN social_media_hours TYPE INTEGER USING pg_temp.range_value(social_media_hours),
+    ALTER COLUMN last_month_purchases TYPE INTEGER USING pg_temp.range_value(last_month_purchases),
+    ALTER COLUMN gender SET NOT NULL,
+    ALTER COLUMN birth_country SET NOT NULL,
+    ALTER COLUMN age_min SET NOT NULL,
+    ALTER COLUMN age_max SET NOT NULL,
+    DROP COLUMN genders,
+    DROP COLUMN birth_countries,
+    DROP COLUMN age_groups;
@@ -0,0 +1,66 @@
+-- Audiences are made of the people of any of their genders, birth countries and age groups,
+-- whose daily hours on social media and purchases in the last month are within ranges.
+-- Ranges are stored as JSON objects with an optional min and max, like the API encodes them.
+
+-- The standard age groups an age range overlaps, ages under 13 falling in the youngest one
+CREATE FUNCTION pg_temp.audience_age_groups(age_min INTEGER, age_max INTEGER) RETURNS TEXT[] AS $$
+    SELECT ARRAY(
+        SELECT g.age_group
+        FROM (VALUES
+            ('13-17', 13, 17),
+            ('18-24', 18, 24),
+            ('25-34', 25, 34),
+            ('35-44', 35, 44),
+            ('45-54', 45, 54),
+            ('55-64', 55, 64),
+            ('65+', 65, NULL)
+        ) AS g(age_group, age_from, age_to)
+        WHERE g.age_from <= GREATEST(age_max, 13) AND (g.age_to IS NULL OR g.age_to >= age_min)
+        ORDER BY g.age_from
+    )
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- The snapshots of favorited audiences are converted like the audiences,
+-- so they only differ from them if the audience changed since it was favorited
+UPDATE user_favorites
+SET asset_snapshot = jsonb_build_object(
+    'genders', jsonb_build_array(asset_snapshot->'gender'),
+    'birth_countries', jsonb_build_array(asset_snapshot->'birth_country'),
+    'age_groups', to_jsonb(pg_temp.audience_age_groups(
+        (asset_snapshot->>'age_min')::INTEGER,
+        (asset_snapshot->>'age_max')::INTEGER
+    )),
+    'social_media_hours', jsonb_build_object(
+        'min', asset_snapshot->'social_media_hours',
+        'max', asset_snapshot->'social_media_hours'
+    ),
+    'last_month_purchases', jsonb_build_object(
+        'min', asset_snapshot->'last_month_purchases',
+        'max', asset_snapshot->'last_month_purchases'
+    )
+)
+WHERE asset_type = 'AUDIENCE' AND asset_snapshot ? 'gender';
+
+ALTER TABLE audience_assets
+    ADD COLUMN genders TEXT[],
+    ADD COLUMN birth_countries TEXT[],
+    ADD COLUMN age_groups TEXT[];
+
+UPDATE audience_assets
+SET genders = ARRAY[gender],
+    birth_countries = ARRAY[birth_country],
+    age_groups = pg_temp.audience_age_groups(age_min, age_max);
+
+-- Exact numbers become the closed ranges holding only them
+ALTER TABLE audience_assets
+    ALTER COLUMN social_media_hours TYPE JSONB
+        USING jsonb_build_object('min', social_media_hours, 'max', social_media_hours),
+    ALTER COLUMN last_month_purchases TYPE JSONB
+        USING jsonb_build_object('min', last_month_purchases, 'max', last_month_purchases),
+    ALTER COLUMN genders SET NOT NULL,
+    ALTER COLUMN birth_countries SET NOT NULL,
+    ALTER COLUMN age_groups SET NOT NULL,
+    DROP COLUMN gender,
+    DROP COLUMN birth_country,
+    DROP COLUMN age_min,
+    DROP COLUMN age_max;
@@ -0,0 +1,5 @@
+UPDATE user_favorites
+SET asset_snapshot = asset_snapshot - 'expression'
+WHERE asset_type = 'AUDIENCE' AND asset_snapshot IS NOT NULL;
+
+ALTER TABLE audience_assets DROP COLUMN expression;
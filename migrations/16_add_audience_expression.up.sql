@@ -0,0 +1,8 @@
+-- Audiences can be narrowed with an expression on the attributes of the people they are made of,
+-- stored in canonical form, and empty for audiences defined by their fields alone.
+
+ALTER TABLE audience_assets ADD COLUMN expression TEXT NOT NULL DEFAULT '';
+
+UPDATE user_favorites
+SET asset_snapshot = asset_snapshot || jsonb_build_object('expression', '')
+WHERE asset_type = 'AUDIENCE' AND asset_snapshot IS NOT NULL;
@@ -0,0 +1,15 @@
+-- Respondents make up the panel audiences are sized against, loaded from CSV with "pgc respondents load".
+-- Their columns are named after the attributes audience expressions look at.
+CREATE TABLE respondents (
+    id VARCHAR(127) PRIMARY KEY,
+    gender VARCHAR(255) NOT NULL,
+    birth_country VARCHAR(255) NOT NULL,
+    age INTEGER NOT NULL CHECK (age BETWEEN 0 AND 120),
+    social_media_hours DOUBLE PRECISION NOT NULL CHECK (social_media_hours BETWEEN 0 AND 24),
+    last_month_purchases INTEGER NOT NULL CHECK (last_month_purchases BETWEEN 0 AND 10000),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Audiences always narrow respondents down by birth country and gender first
+CREATE INDEX idx_respondents_birth_country_gender ON respondents(birth_country, gender);
@@ -0,0 +1,20 @@
+CREATE TABLE favorite_jobs (
+    id VARCHAR(127) PRIMARY KEY,
+    user_id VARCHAR(127) NOT NULL,
+    asset_id VARCHAR(127) NOT NULL,
+    description TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT job_status_check CHECK (status IN ('PENDING', 'SUCCEEDED', 'FAILED'))
+);
+
+-- Supports ClaimFavoriteJobs which picks the oldest pending jobs first.
+-- Finished jobs are left out so the index stays small as the table grows.
+CREATE INDEX idx_favorite_jobs_pending ON favorite_jobs(created_at) WHERE status = 'PENDING';
+
+-- Supports PurgeFavoriteJobs which removes finished jobs past the retention period
+CREATE INDEX idx_favorite_jobs_finished ON favorite_jobs(updated_at) WHERE status <> 'PENDING';
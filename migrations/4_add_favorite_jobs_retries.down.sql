@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS favorite_dead_letters;
+
+DROP INDEX IF EXISTS idx_favorite_jobs_pending;
+CREATE INDEX idx_favorite_jobs_pending ON favorite_jobs(created_at) WHERE status = 'PENDING';
+
+ALTER TABLE favorite_jobs DROP COLUMN IF EXISTS run_at;
@@ -0,0 +1,20 @@
+-- Jobs that failed with a transient error are retried once run_at is due
+ALTER TABLE favorite_jobs ADD COLUMN run_at TIMESTAMP WITH TIME ZONE;
+UPDATE favorite_jobs SET run_at = created_at;
+ALTER TABLE favorite_jobs ALTER COLUMN run_at SET NOT NULL;
+
+-- ClaimFavoriteJobs now picks the pending jobs that are due first
+DROP INDEX IF EXISTS idx_favorite_jobs_pending;
+CREATE INDEX idx_favorite_jobs_pending ON favorite_jobs(run_at) WHERE status = 'PENDING';
+
+-- Favorite jobs that failed for good, kept with everything needed to replay them
+CREATE TABLE favorite_dead_letters (
+    id VARCHAR(127) PRIMARY KEY,
+    job_id VARCHAR(127) NOT NULL,
+    user_id VARCHAR(127) NOT NULL,
+    asset_id VARCHAR(127) NOT NULL,
+    description TEXT,
+    attempts INT NOT NULL,
+    last_error TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_favorite_jobs_user_pending;
@@ -0,0 +1,3 @@
+-- ClaimFavoriteJobs only claims a user's oldest pending job,
+-- and synchronous operations look up a user's latest one
+CREATE INDEX idx_favorite_jobs_user_pending ON favorite_jobs(user_id, created_at, id) WHERE status = 'PENDING';
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS trg_user_favorites_count ON user_favorites;
+DROP FUNCTION IF EXISTS count_asset_favorites();
+DROP TABLE IF EXISTS asset_favorite_counts;
@@ -0,0 +1,30 @@
+-- How many users favorited each asset, so listing assets doesn't have to count favorites.
+-- Kept up to date by a trigger on user_favorites, whatever the path favorites are written through.
+CREATE TABLE asset_favorite_counts (
+    asset_id VARCHAR(127) PRIMARY KEY,
+    favorite_count BIGINT NOT NULL DEFAULT 0
+);
+
+INSERT INTO asset_favorite_counts (asset_id, favorite_count)
+SELECT asset_id, COUNT(*) FROM user_favorites GROUP BY asset_id;
+
+-- Upserting an existing favorite fires an UPDATE rather than an INSERT, so it isn't counted twice
+CREATE FUNCTION count_asset_favorites() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO asset_favorite_counts (asset_id, favorite_count)
+        VALUES (NEW.asset_id, 1)
+        ON CONFLICT (asset_id) DO UPDATE SET
+            favorite_count = asset_favorite_counts.favorite_count + 1;
+    ELSE
+        UPDATE asset_favorite_counts
+        SET favorite_count = favorite_count - 1
+        WHERE asset_id = OLD.asset_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_user_favorites_count
+AFTER INSERT OR DELETE ON user_favorites
+FOR EACH ROW EXECUTE FUNCTION count_asset_favorites();
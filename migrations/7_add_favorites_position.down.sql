@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_user_favorites_user_position;
+CREATE INDEX idx_user_favorites_user_created ON user_favorites(user_id, created_at DESC);
+
+ALTER TABLE user_favorites DROP COLUMN IF EXISTS position;
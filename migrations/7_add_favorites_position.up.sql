@@ -0,0 +1,19 @@
+-- Favorites are listed by position, a rank key users rearrange their dashboard with.
+-- Rank keys compare byte by byte, whatever the database collation.
+ALTER TABLE user_favorites ADD COLUMN position TEXT COLLATE "C";
+
+-- Keep the current order, newest first. Zero padded hex numbers sort like the app's
+-- base 36 keys, and dropping the trailing zeros, which rank keys can't end with, keeps them in order.
+UPDATE user_favorites f
+SET position = rtrim(lpad(to_hex(r.rn), 8, '0'), '0')
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_at DESC, id DESC) AS rn
+    FROM user_favorites
+) r
+WHERE f.id = r.id;
+
+ALTER TABLE user_favorites ALTER COLUMN position SET NOT NULL;
+
+-- Supports GetUserFavorites, which now orders by position and pages by (position, id)
+DROP INDEX IF EXISTS idx_user_favorites_user_created;
+CREATE INDEX idx_user_favorites_user_position ON user_favorites(user_id, position, id);
@@ -0,0 +1,20 @@
+-- Collections are user named folders of favorites.
+CREATE TABLE favorite_collections (
+    id VARCHAR(127) PRIMARY KEY,
+    user_id VARCHAR(127) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT unique_user_collection_name UNIQUE (user_id, name)
+);
+
+-- A favorite can be in several collections. Deleting either side drops the membership only.
+CREATE TABLE favorite_collection_items (
+    collection_id VARCHAR(127) NOT NULL REFERENCES favorite_collections(id) ON DELETE CASCADE,
+    favorite_id VARCHAR(127) NOT NULL REFERENCES user_favorites(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (collection_id, favorite_id)
+);
+
+-- Supports the cascade when a favorite is deleted
+CREATE INDEX idx_favorite_collection_items_favorite ON favorite_collection_items(favorite_id);
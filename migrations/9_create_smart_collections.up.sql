@@ -0,0 +1,11 @@
+-- Smart collections are saved rules matched against a user's favorites when listed.
+-- The rule is the JSON predicate the app compiles to SQL, see favorites.Rule.
+CREATE TABLE smart_collections (
+    id VARCHAR(127) PRIMARY KEY,
+    user_id VARCHAR(127) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    rule JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT unique_user_smart_collection_name UNIQUE (user_id, name)
+);
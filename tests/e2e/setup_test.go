@@ -103,6 +103,7 @@ func setupTestEnvironment(ctx context.Context) (*testEnvironment, error) {
 	assetsRepo := postgres.NewRepository(dbPool)
 	assetsSvc := assets.NewService(logger, assetsRepo)
 	favSvc := favorites.NewService(logger, assetsRepo, usersSvc)
+	favSvc.StartConsumers()
 
 	if err := populateTestDatabase(ctx, assetsSvc); err != nil {
 		return nil, fmt.Errorf("populate test database: %w", err)
@@ -136,6 +137,11 @@ func teardownTestEnvironment(ctx context.Context, env *testEnvironment) error {
             TRUNCATE TABLE insight_assets CASCADE;
             TRUNCATE TABLE audience_assets CASCADE;
             TRUNCATE TABLE user_favorites CASCADE;
+            TRUNCATE TABLE asset_favorite_counts CASCADE;
+            TRUNCATE TABLE favorite_collections CASCADE;
+            TRUNCATE TABLE smart_collections CASCADE;
+            TRUNCATE TABLE favorite_jobs CASCADE;
+            TRUNCATE TABLE favorite_dead_letters CASCADE;
         `); err != nil {
 			return fmt.Errorf("clean database tables: %w", err)
 		}
@@ -180,6 +186,9 @@ func setupTestDatabase(ctx context.Context) (*pgxpool.Pool, error) {
         TRUNCATE TABLE insight_assets CASCADE;
         TRUNCATE TABLE audience_assets CASCADE;
         TRUNCATE TABLE user_favorites CASCADE;
+        TRUNCATE TABLE asset_favorite_counts CASCADE;
+        TRUNCATE TABLE favorite_collections CASCADE;
+        TRUNCATE TABLE smart_collections CASCADE;
     `); err != nil {
 		return nil, fmt.Errorf("clean database tables: %w", err)
 	}
@@ -192,7 +201,11 @@ func setupTestUsersService(logger *slog.Logger, usersSample []users.User) *users
 }
 
 func populateTestDatabase(ctx context.Context, assetsSvc *assets.Service) error {
-	return assetsSvc.StoreAssets(ctx, sampler.SampleAssets(preloadedTestAssets))
+	sample, err := sampler.SampleAssets(preloadedTestAssets)
+	if err != nil {
+		return fmt.Errorf("could not sample assets: %w", err)
+	}
+	return assetsSvc.StoreAssets(ctx, sample)
 }
 
 func setupTestHTTPServer(
@@ -216,7 +229,7 @@ func setupTestHTTPServer(
 		return nil, fmt.Errorf("create error handler: %w", err)
 	}
 
-	restHandlers := handlers.New(logger, errHandler, usersSvc, assetsSvc, favSvc)
+	restHandlers := handlers.New(logger, resterrors.NewHandler(errHandler), resterrors.Lookup, usersSvc, assetsSvc, favSvc)
 	restApp := rest.NewApp(logger, &httpSrv, restHandlers)
 
 	if err := restApp.Start(); err != nil {
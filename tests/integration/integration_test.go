@@ -17,12 +17,15 @@ package integration
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/alesr/platform-go-challenge/internal/assets"
 	"github.com/alesr/platform-go-challenge/internal/assets/favorites"
@@ -93,7 +96,7 @@ func setupTestDB() *pgxpool.Pool {
 
 	// to start with a clean slate
 	if _, err := pool.Exec(ctx, `
-		TRUNCATE chart_assets, insight_assets, audience_assets, user_favorites CASCADE
+		TRUNCATE chart_assets, insight_assets, audience_assets, user_favorites, asset_favorite_counts, favorite_collections, favorite_collection_items, smart_collections, favorite_jobs, favorite_dead_letters, respondents CASCADE
 	`); err != nil {
 		log.Fatalln(err)
 	}
@@ -103,12 +106,21 @@ func setupTestDB() *pgxpool.Pool {
 func cleanUp() {
 	defer pool.Close()
 	if _, err := pool.Exec(context.Background(), `
-		TRUNCATE chart_assets, insight_assets, audience_assets, user_favorites CASCADE
+		TRUNCATE chart_assets, insight_assets, audience_assets, user_favorites, asset_favorite_counts, favorite_collections, favorite_collection_items, smart_collections, favorite_jobs, favorite_dead_letters, respondents CASCADE
 	`); err != nil {
 		log.Fatalln(err)
 	}
 }
 
+// storeFavorite stores a single favorite asset the way the queue consumer does, in a batch of its own.
+func storeFavorite(ctx context.Context, repo *postgres.Repository, params *favorites.FavoriteAssetParams) error {
+	results, err := repo.StoreFavoriteAssets(ctx, []*favorites.FavoriteAssetParams{params})
+	if err != nil {
+		return err
+	}
+	return results[0]
+}
+
 func TestRepository_StoreAndListAssets(t *testing.T) {
 	t.Parallel()
 
@@ -121,13 +133,23 @@ func TestRepository_StoreAndListAssets(t *testing.T) {
 
 	factory := assets.NewAssetFactory()
 
-	chartAsset := factory.CreateChart("Test Chart", "X", "Y", []float64{1.0, 2.0})
+	chartAsset, err := factory.CreateChart("Test Chart", "X", "Y", []float64{1.0, 2.0})
+	require.NoError(t, err)
 	chartAsset.ID = "test-chart-123"
 
-	insightAsset := factory.CreateInsight("Test Insight")
+	insightAsset, err := factory.CreateInsight("Test Insight")
+	require.NoError(t, err)
 	insightAsset.ID = "test-insight-123"
 
-	audienceAsset := factory.CreateAudience("M", "IT", 20, 30, 2, 5)
+	audienceAsset, err := factory.CreateAudience(
+		[]string{"M"},
+		[]string{"IT"},
+		[]assets.AgeGroup{assets.AgeGroup18To24, assets.AgeGroup25To34},
+		assets.Between(2, 2),
+		assets.AtMost(5),
+		`age >= 18 AND birth_country != "FR"`,
+	)
+	require.NoError(t, err)
 	audienceAsset.ID = "test-audience-123"
 
 	require.NoError(t, repo.StoreAsset(ctx, chartAsset))
@@ -144,7 +166,7 @@ func TestRepository_StoreAndListAssets(t *testing.T) {
 	foundAssets := make(map[string]struct{})
 
 	for _, a := range returnedAssets {
-		switch v := a.(type) {
+		switch v := a.Asset.(type) {
 		case assets.ChartAsset:
 			if v.ID == chartAsset.ID {
 				foundAssets[v.ID] = struct{}{}
@@ -160,7 +182,8 @@ func TestRepository_StoreAndListAssets(t *testing.T) {
 		case assets.AudienceAsset:
 			if v.ID == audienceAsset.ID {
 				foundAssets[v.ID] = struct{}{}
-				assert.Equal(t, "M", v.Data.Gender)
+				// sets, ranges and the expression round trip through their columns
+				assert.Equal(t, audienceAsset.Data, v.Data)
 				assert.Equal(t, assets.TypeAssetAudience, v.Type())
 			}
 		}
@@ -171,6 +194,66 @@ func TestRepository_StoreAndListAssets(t *testing.T) {
 	assert.Contains(t, foundAssets, audienceAsset.ID)
 }
 
+func TestRepository_ListAssetsFavoriteStatus(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	repo := postgres.NewRepository(pool)
+	ctx := context.Background()
+
+	factory := assets.NewAssetFactory()
+
+	// IDs sorting after every other test's assets, so the page starts with them
+	chartAsset, err := factory.CreateChart("Count Chart", "X", "Y", []float64{1.0})
+	require.NoError(t, err)
+	chartAsset.ID = "zz-count-chart-1"
+	require.NoError(t, repo.StoreAsset(ctx, chartAsset))
+
+	insightAsset, err := factory.CreateInsight("Count insight")
+	require.NoError(t, err)
+	insightAsset.ID = "zz-count-insight-1"
+	require.NoError(t, repo.StoreAsset(ctx, insightAsset))
+
+	results, err := repo.StoreFavoriteAssets(ctx, []*favorites.FavoriteAssetParams{
+		{UserID: "count-user", AssetID: chartAsset.ID},
+		{UserID: "count-other-user", AssetID: chartAsset.ID},
+		{UserID: "count-other-user", AssetID: insightAsset.ID},
+		// favoriting again must not be counted twice
+		{UserID: "count-user", AssetID: chartAsset.ID, Description: "again"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []error{nil, nil, nil, nil}, results)
+
+	listed, _, err := repo.ListAssets(ctx, &assets.ListAssetsParams{
+		PageSize:  2,
+		PageToken: "zz-count-",
+		UserID:    "count-user",
+	})
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+
+	favs, err := repo.GetUserFavorites(ctx, "count-user", &favorites.ListFavoritesParams{PageSize: 10})
+	require.NoError(t, err)
+	require.Len(t, favs, 1)
+
+	assert.Equal(t, int64(2), listed[0].FavoriteCount)
+	assert.Equal(t, favs[0].ID, listed[0].FavoriteID)
+	assert.Equal(t, int64(1), listed[1].FavoriteCount)
+	assert.Empty(t, listed[1].FavoriteID)
+
+	// unfavoriting is reflected in the counts
+	require.NoError(t, repo.DeleteFavoriteByAsset(ctx, chartAsset.ID, "count-other-user"))
+
+	listed, _, err = repo.ListAssets(ctx, &assets.ListAssetsParams{PageSize: 2, PageToken: "zz-count-"})
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	assert.Equal(t, int64(1), listed[0].FavoriteCount)
+	assert.Empty(t, listed[0].FavoriteID)
+}
+
 func TestRepository_FavoriteAssets(t *testing.T) {
 	t.Parallel()
 
@@ -183,7 +266,8 @@ func TestRepository_FavoriteAssets(t *testing.T) {
 
 	// create and store a test asset
 	factory := assets.NewAssetFactory()
-	chartAsset := factory.CreateChart("Test Chart", "X", "Y", []float64{1.0, 2.0})
+	chartAsset, err := factory.CreateChart("Test Chart", "X", "Y", []float64{1.0, 2.0})
+	require.NoError(t, err)
 	chartAsset.ID = "chart-1"
 
 	require.NoError(t, repo.StoreAsset(ctx, chartAsset))
@@ -197,7 +281,7 @@ func TestRepository_FavoriteAssets(t *testing.T) {
 			Description: "This should fail",
 		}
 
-		err := repo.StoreFavoriteAsset(ctx, &favParams)
+		err := storeFavorite(ctx, repo, &favParams)
 		require.ErrorIs(t, err, assets.ErrAssetNotFound)
 	})
 
@@ -211,15 +295,21 @@ func TestRepository_FavoriteAssets(t *testing.T) {
 			Description: "Foo chart",
 		}
 
-		require.NoError(t, repo.StoreFavoriteAsset(ctx, &favParams))
+		require.NoError(t, storeFavorite(ctx, repo, &favParams))
 
 		// test getting favorites
-		favs, err := repo.GetUserFavorites(ctx, "test-user")
+		favs, err := repo.GetUserFavorites(ctx, "test-user", &favorites.ListFavoritesParams{PageSize: 10})
 		require.NoError(t, err)
 		require.Len(t, favs, 1)
 		require.Equal(t, chartAsset.ID, favs[0].AssetID)
 		require.Equal(t, "Foo chart", favs[0].Description)
 
+		// the favorited asset comes along with the favorite
+		require.NotNil(t, favs[0].Asset)
+		require.Equal(t, assets.TypeAssetChart, favs[0].Asset.Type())
+		assert.Equal(t, "Test Chart", favs[0].Asset.(assets.ChartAsset).Data.Title)
+		assert.Equal(t, []float64{1.0, 2.0}, favs[0].Asset.(assets.ChartAsset).Data.Data)
+
 		// test updating a favorite
 		updateParams := favorites.UpdateFavoriteParams{
 			Description: "Updated description",
@@ -230,9 +320,1567 @@ func TestRepository_FavoriteAssets(t *testing.T) {
 		require.Equal(t, "Updated description", updatedFav.Description)
 
 		// verify the update
-		favs, err = repo.GetUserFavorites(ctx, "test-user")
+		favs, err = repo.GetUserFavorites(ctx, "test-user", &favorites.ListFavoritesParams{PageSize: 10})
 		require.NoError(t, err)
 		require.Len(t, favs, 1)
 		require.Equal(t, "Updated description", favs[0].Description)
 	})
 }
+
+func TestRepository_StoreFavoriteAssetsBatch(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	repo := postgres.NewRepository(pool)
+	ctx := context.Background()
+
+	factory := assets.NewAssetFactory()
+
+	chartAsset, err := factory.CreateChart("Batch Chart", "X", "Y", []float64{1.0})
+	require.NoError(t, err)
+	chartAsset.ID = "batch-chart-1"
+	require.NoError(t, repo.StoreAsset(ctx, chartAsset))
+
+	insightAsset, err := factory.CreateInsight("Batch insight")
+	require.NoError(t, err)
+	insightAsset.ID = "batch-insight-1"
+	require.NoError(t, repo.StoreAsset(ctx, insightAsset))
+
+	results, err := repo.StoreFavoriteAssets(ctx, []*favorites.FavoriteAssetParams{
+		{UserID: "batch-user", AssetID: chartAsset.ID, Description: "first"},
+		{UserID: "batch-user", AssetID: "batch-missing-asset"},
+		{UserID: "batch-user", AssetID: insightAsset.ID},
+		// the same favorite twice in a batch, the last one wins
+		{UserID: "batch-user", AssetID: chartAsset.ID, Description: "second"},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.NoError(t, results[0])
+	assert.ErrorIs(t, results[1], assets.ErrAssetNotFound)
+	assert.NoError(t, results[2])
+	assert.NoError(t, results[3])
+
+	favs, err := repo.GetUserFavorites(ctx, "batch-user", &favorites.ListFavoritesParams{PageSize: 10})
+	require.NoError(t, err)
+	require.Len(t, favs, 2)
+
+	byAsset := make(map[string]favorites.FavoriteAsset, len(favs))
+	for _, fav := range favs {
+		byAsset[fav.AssetID] = fav
+	}
+	assert.Equal(t, "second", byAsset[chartAsset.ID].Description)
+	assert.Equal(t, assets.TypeAssetChart, byAsset[chartAsset.ID].Asset.Type())
+	assert.Equal(t, assets.TypeAssetInsight, byAsset[insightAsset.ID].Asset.Type())
+
+	// storing again updates the existing favorites
+	results, err = repo.StoreFavoriteAssets(ctx, []*favorites.FavoriteAssetParams{
+		{UserID: "batch-user", AssetID: insightAsset.ID, Description: "updated"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []error{nil}, results)
+
+	favs, err = repo.GetUserFavorites(ctx, "batch-user", &favorites.ListFavoritesParams{PageSize: 10})
+	require.NoError(t, err)
+	require.Len(t, favs, 2)
+}
+
+func TestRepository_ApplyFavoriteOps(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	repo := postgres.NewRepository(pool)
+	ctx := context.Background()
+
+	factory := assets.NewAssetFactory()
+
+	chartAsset, err := factory.CreateChart("Ops Chart", "X", "Y", []float64{1.0})
+	require.NoError(t, err)
+	chartAsset.ID = "ops-chart-1"
+	require.NoError(t, repo.StoreAsset(ctx, chartAsset))
+
+	insightAsset, err := factory.CreateInsight("Ops insight")
+	require.NoError(t, err)
+	insightAsset.ID = "ops-insight-1"
+	require.NoError(t, repo.StoreAsset(ctx, insightAsset))
+
+	t.Run("each operation on its own", func(t *testing.T) {
+		results, err := repo.ApplyFavoriteOps(ctx, "ops-user", []favorites.FavoriteOp{
+			{Type: favorites.FavoriteOpAdd, AssetID: chartAsset.ID, Description: "chart"},
+			{Type: favorites.FavoriteOpAdd, AssetID: "ops-missing-asset"},
+			{Type: favorites.FavoriteOpRemove, AssetID: insightAsset.ID},
+			// Postgres rejects NUL bytes in text, failing the statement rather than finding nothing to update
+			{Type: favorites.FavoriteOpUpdate, AssetID: chartAsset.ID, Description: "bad \x00 chart"},
+			{Type: favorites.FavoriteOpUpdate, AssetID: chartAsset.ID, Description: "updated chart"},
+		}, false)
+		require.NoError(t, err)
+		require.Len(t, results, 5)
+		assert.NoError(t, results[0])
+		assert.ErrorIs(t, results[1], assets.ErrAssetNotFound)
+		assert.ErrorIs(t, results[2], favorites.ErrFavoriteAssetNotFound)
+		assert.Error(t, results[3])
+		assert.NoError(t, results[4])
+
+		favs, err := repo.GetUserFavorites(ctx, "ops-user", &favorites.ListFavoritesParams{PageSize: 10})
+		require.NoError(t, err)
+		require.Len(t, favs, 1)
+		assert.Equal(t, chartAsset.ID, favs[0].AssetID)
+		assert.Equal(t, "updated chart", favs[0].Description)
+	})
+
+	t.Run("atomic batch is rolled back on failure", func(t *testing.T) {
+		results, err := repo.ApplyFavoriteOps(ctx, "ops-atomic-user", []favorites.FavoriteOp{
+			{Type: favorites.FavoriteOpAdd, AssetID: chartAsset.ID},
+			{Type: favorites.FavoriteOpUpdate, AssetID: insightAsset.ID, Description: "not a favorite"},
+			{Type: favorites.FavoriteOpAdd, AssetID: insightAsset.ID},
+		}, true)
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.ErrorIs(t, results[0], favorites.ErrBatchAborted)
+		assert.ErrorIs(t, results[1], favorites.ErrFavoriteAssetNotFound)
+		assert.ErrorIs(t, results[2], favorites.ErrBatchAborted)
+
+		favs, err := repo.GetUserFavorites(ctx, "ops-atomic-user", &favorites.ListFavoritesParams{PageSize: 10})
+		require.NoError(t, err)
+		assert.Empty(t, favs)
+	})
+}
+
+func TestRepository_DeleteFavoritesByAssetAndUser(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	repo := postgres.NewRepository(pool)
+	ctx := context.Background()
+
+	factory := assets.NewAssetFactory()
+
+	chartAsset, err := factory.CreateChart("Clear Chart", "X", "Y", []float64{1.0})
+	require.NoError(t, err)
+	chartAsset.ID = "clear-chart-1"
+	require.NoError(t, repo.StoreAsset(ctx, chartAsset))
+
+	insightAsset, err := factory.CreateInsight("Clear insight")
+	require.NoError(t, err)
+	insightAsset.ID = "clear-insight-1"
+	require.NoError(t, repo.StoreAsset(ctx, insightAsset))
+
+	for _, userID := range []string{"clear-user", "clear-other-user"} {
+		results, err := repo.StoreFavoriteAssets(ctx, []*favorites.FavoriteAssetParams{
+			{UserID: userID, AssetID: chartAsset.ID},
+			{UserID: userID, AssetID: insightAsset.ID},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []error{nil, nil}, results)
+	}
+
+	require.NoError(t, repo.DeleteFavoriteByAsset(ctx, chartAsset.ID, "clear-user"))
+
+	// already deleted
+	err = repo.DeleteFavoriteByAsset(ctx, chartAsset.ID, "clear-user")
+	assert.ErrorIs(t, err, favorites.ErrFavoriteAssetNotFound)
+
+	deleted, err := repo.DeleteUserFavorites(ctx, "clear-user")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	favs, err := repo.GetUserFavorites(ctx, "clear-user", &favorites.ListFavoritesParams{PageSize: 10})
+	require.NoError(t, err)
+	assert.Empty(t, favs)
+
+	// other users' favorites are left alone
+	favs, err = repo.GetUserFavorites(ctx, "clear-other-user", &favorites.ListFavoritesParams{PageSize: 10})
+	require.NoError(t, err)
+	assert.Len(t, favs, 2)
+}
+
+func TestRepository_FetchUpdateDeleteAsset(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	repo := postgres.NewRepository(pool)
+	ctx := context.Background()
+
+	factory := assets.NewAssetFactory()
+
+	insightAsset, err := factory.CreateInsight("Original insight")
+	require.NoError(t, err)
+	insightAsset.ID = "crud-insight-123"
+
+	require.NoError(t, repo.StoreAsset(ctx, insightAsset))
+
+	// a favorite pointing to the asset must go away with it
+	require.NoError(t, storeFavorite(ctx, repo, &favorites.FavoriteAssetParams{
+		UserID:  "crud-user",
+		AssetID: insightAsset.ID,
+	}))
+
+	fetched, err := repo.FetchAsset(ctx, insightAsset.ID)
+	require.NoError(t, err)
+	require.Equal(t, assets.TypeAssetInsight, fetched.Type())
+	assert.Equal(t, "Original insight", fetched.(assets.InsightAsset).Data.Insight)
+
+	replacement, err := factory.CreateInsight("Updated insight")
+	require.NoError(t, err)
+	replacement.ID = insightAsset.ID
+
+	updated, err := repo.UpdateAsset(ctx, replacement)
+	require.NoError(t, err)
+	assert.Equal(t, "Updated insight", updated.(assets.InsightAsset).Data.Insight)
+	assert.WithinDuration(t, insightAsset.CreatedAt, updated.(assets.InsightAsset).CreatedAt, time.Millisecond)
+
+	require.NoError(t, repo.DeleteAsset(ctx, insightAsset.ID))
+
+	_, err = repo.FetchAsset(ctx, insightAsset.ID)
+	require.ErrorIs(t, err, assets.ErrAssetNotFound)
+
+	favs, err := repo.GetUserFavorites(ctx, "crud-user", &favorites.ListFavoritesParams{PageSize: 10})
+	require.NoError(t, err)
+	assert.Empty(t, favs)
+
+	require.ErrorIs(t, repo.DeleteAsset(ctx, insightAsset.ID), assets.ErrAssetNotFound)
+
+	_, err = repo.UpdateAsset(ctx, replacement)
+	require.ErrorIs(t, err, assets.ErrAssetNotFound)
+}
+
+func TestRepository_GetUserFavoritesPagination(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	repo := postgres.NewRepository(pool)
+	ctx := context.Background()
+
+	factory := assets.NewAssetFactory()
+
+	const (
+		userID       = "paginated-user"
+		numFavorites = 5
+	)
+
+	for i := 0; i < numFavorites; i++ {
+		insight, err := factory.CreateInsight(fmt.Sprintf("Paginated insight %d", i))
+		require.NoError(t, err)
+		require.NoError(t, repo.StoreAsset(ctx, insight))
+		require.NoError(t, storeFavorite(ctx, repo, &favorites.FavoriteAssetParams{
+			UserID:  userID,
+			AssetID: insight.ID,
+		}))
+	}
+
+	var (
+		seen   = make(map[string]struct{})
+		params = favorites.ListFavoritesParams{PageSize: 2}
+	)
+
+	for {
+		page, err := repo.GetUserFavorites(ctx, userID, &params)
+		require.NoError(t, err)
+
+		for _, fav := range page {
+			_, dup := seen[fav.ID]
+			require.False(t, dup, "favorite %s returned twice", fav.ID)
+			seen[fav.ID] = struct{}{}
+		}
+
+		if len(page) < params.PageSize {
+			break
+		}
+
+		last := page[len(page)-1]
+		params.Cursor = &favorites.Cursor{Position: last.Position, ID: last.ID}
+	}
+	assert.Len(t, seen, numFavorites)
+}
+
+func TestRepository_OrderFavorites(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	repo := postgres.NewRepository(pool)
+	ctx := context.Background()
+
+	const userID = "order-user"
+
+	factory := assets.NewAssetFactory()
+
+	// favorited one after the other, so the latest one comes first
+	for i := range 4 {
+		insight, err := factory.CreateInsight(fmt.Sprintf("Ordered insight %d", i))
+		require.NoError(t, err)
+		require.NoError(t, repo.StoreAsset(ctx, insight))
+		require.NoError(t, storeFavorite(ctx, repo, &favorites.FavoriteAssetParams{
+			UserID:      userID,
+			AssetID:     insight.ID,
+			Description: fmt.Sprint(i),
+		}))
+	}
+
+	listOrder := func() []string {
+		favs, err := repo.GetUserFavorites(ctx, userID, &favorites.ListFavoritesParams{PageSize: 10})
+		require.NoError(t, err)
+
+		order := make([]string, 0, len(favs))
+		for _, fav := range favs {
+			order = append(order, fav.Description)
+		}
+		return order
+	}
+
+	favs, err := repo.GetUserFavorites(ctx, userID, &favorites.ListFavoritesParams{PageSize: 10})
+	require.NoError(t, err)
+	require.Equal(t, []string{"3", "2", "1", "0"}, listOrder())
+
+	idByDescription := make(map[string]string, len(favs))
+	for _, fav := range favs {
+		idByDescription[fav.Description] = fav.ID
+	}
+
+	t.Run("reorder puts the given favorites first", func(t *testing.T) {
+		require.NoError(t, repo.ReorderFavorites(ctx, userID, []string{idByDescription["0"], idByDescription["2"]}))
+		assert.Equal(t, []string{"0", "2", "3", "1"}, listOrder())
+	})
+
+	t.Run("reorder with another user's favorite", func(t *testing.T) {
+		err := repo.ReorderFavorites(ctx, "order-other-user", []string{idByDescription["0"]})
+		assert.ErrorIs(t, err, favorites.ErrFavoriteAssetNotFound)
+	})
+
+	t.Run("move before another favorite", func(t *testing.T) {
+		require.NoError(t, repo.MoveFavorite(ctx, idByDescription["1"], userID, &favorites.MoveFavoriteParams{
+			BeforeID: idByDescription["2"],
+		}))
+		assert.Equal(t, []string{"0", "1", "2", "3"}, listOrder())
+
+		require.NoError(t, repo.MoveFavorite(ctx, idByDescription["3"], userID, &favorites.MoveFavoriteParams{
+			BeforeID: idByDescription["0"],
+		}))
+		assert.Equal(t, []string{"3", "0", "1", "2"}, listOrder())
+	})
+
+	t.Run("move to the end", func(t *testing.T) {
+		require.NoError(t, repo.MoveFavorite(ctx, idByDescription["3"], userID, &favorites.MoveFavoriteParams{}))
+		assert.Equal(t, []string{"0", "1", "2", "3"}, listOrder())
+	})
+
+	t.Run("move another user's favorite", func(t *testing.T) {
+		err := repo.MoveFavorite(ctx, idByDescription["3"], "order-other-user", &favorites.MoveFavoriteParams{})
+		assert.ErrorIs(t, err, favorites.ErrFavoriteAssetNotFound)
+	})
+
+	t.Run("new favorites go first", func(t *testing.T) {
+		insight, err := factory.CreateInsight("Ordered insight 4")
+		require.NoError(t, err)
+		require.NoError(t, repo.StoreAsset(ctx, insight))
+		require.NoError(t, storeFavorite(ctx, repo, &favorites.FavoriteAssetParams{
+			UserID:      userID,
+			AssetID:     insight.ID,
+			Description: "4",
+		}))
+		assert.Equal(t, []string{"4", "0", "1", "2", "3"}, listOrder())
+	})
+}
+
+func TestRepository_FavoriteCollections(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	repo := postgres.NewRepository(pool)
+	ctx := context.Background()
+
+	const (
+		userID      = "collections-user"
+		otherUserID = "collections-other-user"
+	)
+
+	factory := assets.NewAssetFactory()
+
+	favoriteIDs := make([]string, 0, 2)
+	for i := range 2 {
+		insight, err := factory.CreateInsight(fmt.Sprintf("Collected insight %d", i))
+		require.NoError(t, err)
+		require.NoError(t, repo.StoreAsset(ctx, insight))
+		require.NoError(t, storeFavorite(ctx, repo, &favorites.FavoriteAssetParams{
+			UserID:  userID,
+			AssetID: insight.ID,
+		}))
+	}
+
+	favs, err := repo.GetUserFavorites(ctx, userID, &favorites.ListFavoritesParams{PageSize: 10})
+	require.NoError(t, err)
+	require.Len(t, favs, 2)
+	for _, fav := range favs {
+		favoriteIDs = append(favoriteIDs, fav.ID)
+	}
+
+	marketing, err := repo.CreateCollection(ctx, userID, &favorites.CollectionParams{Name: "Marketing"})
+	require.NoError(t, err)
+
+	sales, err := repo.CreateCollection(ctx, userID, &favorites.CollectionParams{Name: "Sales"})
+	require.NoError(t, err)
+
+	t.Run("names are unique per user", func(t *testing.T) {
+		_, err := repo.CreateCollection(ctx, userID, &favorites.CollectionParams{Name: "Marketing"})
+		assert.ErrorIs(t, err, favorites.ErrCollectionNameTaken)
+
+		_, err = repo.UpdateCollection(ctx, sales.ID, userID, &favorites.CollectionParams{Name: "Marketing"})
+		assert.ErrorIs(t, err, favorites.ErrCollectionNameTaken)
+
+		other, err := repo.CreateCollection(ctx, otherUserID, &favorites.CollectionParams{Name: "Marketing"})
+		require.NoError(t, err)
+		require.NoError(t, repo.DeleteCollection(ctx, other.ID, otherUserID))
+	})
+
+	t.Run("a favorite can be in several collections", func(t *testing.T) {
+		require.NoError(t, repo.AddToCollection(ctx, marketing.ID, favoriteIDs[0], userID))
+		require.NoError(t, repo.AddToCollection(ctx, sales.ID, favoriteIDs[0], userID))
+		require.NoError(t, repo.AddToCollection(ctx, sales.ID, favoriteIDs[1], userID))
+
+		// adding twice is a no-op
+		require.NoError(t, repo.AddToCollection(ctx, sales.ID, favoriteIDs[1], userID))
+
+		collections, err := repo.ListCollections(ctx, userID)
+		require.NoError(t, err)
+		require.Len(t, collections, 2)
+		assert.Equal(t, "Marketing", collections[0].Name)
+		assert.Equal(t, 1, collections[0].FavoriteCount)
+		assert.Equal(t, "Sales", collections[1].Name)
+		assert.Equal(t, 2, collections[1].FavoriteCount)
+	})
+
+	t.Run("list favorites in a collection", func(t *testing.T) {
+		favs, err := repo.GetUserFavorites(ctx, userID, &favorites.ListFavoritesParams{
+			PageSize:     10,
+			CollectionID: marketing.ID,
+		})
+		require.NoError(t, err)
+		require.Len(t, favs, 1)
+		assert.Equal(t, favoriteIDs[0], favs[0].ID)
+	})
+
+	t.Run("other users can't see or change the collection", func(t *testing.T) {
+		_, err := repo.FetchCollection(ctx, marketing.ID, otherUserID)
+		assert.ErrorIs(t, err, favorites.ErrCollectionNotFound)
+
+		_, err = repo.UpdateCollection(ctx, marketing.ID, otherUserID, &favorites.CollectionParams{Name: "Mine"})
+		assert.ErrorIs(t, err, favorites.ErrCollectionNotFound)
+
+		err = repo.AddToCollection(ctx, marketing.ID, favoriteIDs[1], otherUserID)
+		assert.ErrorIs(t, err, favorites.ErrCollectionNotFound)
+
+		err = repo.DeleteCollection(ctx, marketing.ID, otherUserID)
+		assert.ErrorIs(t, err, favorites.ErrCollectionNotFound)
+	})
+
+	t.Run("only the user's favorites can be added", func(t *testing.T) {
+		other, err := repo.CreateCollection(ctx, otherUserID, &favorites.CollectionParams{Name: "Borrowed"})
+		require.NoError(t, err)
+
+		err = repo.AddToCollection(ctx, other.ID, favoriteIDs[0], otherUserID)
+		assert.ErrorIs(t, err, favorites.ErrFavoriteAssetNotFound)
+	})
+
+	t.Run("remove from a collection", func(t *testing.T) {
+		require.NoError(t, repo.RemoveFromCollection(ctx, sales.ID, favoriteIDs[0], userID))
+
+		err := repo.RemoveFromCollection(ctx, sales.ID, favoriteIDs[0], userID)
+		assert.ErrorIs(t, err, favorites.ErrFavoriteAssetNotFound)
+
+		collection, err := repo.FetchCollection(ctx, sales.ID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, collection.FavoriteCount)
+	})
+
+	t.Run("deleting a favorite drops it from its collections", func(t *testing.T) {
+		require.NoError(t, repo.DeleteFavorite(ctx, favoriteIDs[1], userID))
+
+		collection, err := repo.FetchCollection(ctx, sales.ID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, collection.FavoriteCount)
+	})
+
+	t.Run("deleting a collection keeps its favorites", func(t *testing.T) {
+		require.NoError(t, repo.DeleteCollection(ctx, marketing.ID, userID))
+
+		_, err := repo.FetchCollection(ctx, marketing.ID, userID)
+		assert.ErrorIs(t, err, favorites.ErrCollectionNotFound)
+
+		favs, err := repo.GetUserFavorites(ctx, userID, &favorites.ListFavoritesParams{PageSize: 10})
+		require.NoError(t, err)
+		require.Len(t, favs, 1)
+		assert.Equal(t, favoriteIDs[0], favs[0].ID)
+	})
+}
+
+func TestRepository_SmartCollections(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	repo := postgres.NewRepository(pool)
+	ctx := context.Background()
+
+	const userID = "smart-user"
+
+	factory := assets.NewAssetFactory()
+
+	german, err := factory.CreateAudience(
+		[]string{"F"},
+		[]string{"Austria", "Germany"},
+		[]assets.AgeGroup{assets.AgeGroup25To34},
+		assets.AtLeast(3),
+		assets.AtMost(2),
+		"",
+	)
+	require.NoError(t, err)
+	french, err := factory.CreateAudience(
+		[]string{"F", "M"},
+		[]string{"France"},
+		[]assets.AgeGroup{assets.AgeGroup18To24},
+		assets.Between(1, 2),
+		assets.AtMost(1),
+		"",
+	)
+	require.NoError(t, err)
+	chart, err := factory.CreateChart("Sales up 50%", "Month", "Revenue", []float64{1, 2})
+	require.NoError(t, err)
+	insight, err := factory.CreateInsight("Sales are up")
+	require.NoError(t, err)
+
+	// favorited one after the other, so the latest one comes first
+	for _, fav := range []struct {
+		asset       assets.Asseter
+		id          string
+		description string
+	}{
+		{german, german.ID, "german"},
+		{french, french.ID, "french"},
+		{chart, chart.ID, "chart"},
+		{insight, insight.ID, "insight"},
+	} {
+		require.NoError(t, repo.StoreAsset(ctx, fav.asset))
+		require.NoError(t, storeFavorite(ctx, repo, &favorites.FavoriteAssetParams{
+			UserID:      userID,
+			AssetID:     fav.id,
+			Description: fav.description,
+		}))
+	}
+
+	// a favorite of another user, which no rule must match
+	require.NoError(t, storeFavorite(ctx, repo, &favorites.FavoriteAssetParams{
+		UserID:  "smart-other-user",
+		AssetID: german.ID,
+	}))
+
+	match := func(t *testing.T, rule string) []string {
+		var r favorites.Rule
+		require.NoError(t, json.Unmarshal([]byte(rule), &r))
+		require.NoError(t, r.Validate())
+
+		favs, err := repo.GetUserFavorites(ctx, userID, &favorites.ListFavoritesParams{PageSize: 10, Rule: &r})
+		require.NoError(t, err)
+
+		matched := make([]string, 0, len(favs))
+		for _, fav := range favs {
+			matched = append(matched, fav.Description)
+		}
+		return matched
+	}
+
+	testCases := []struct {
+		name      string
+		givenRule string
+		expected  []string
+	}{
+		{
+			name:      "audiences born in Germany",
+			givenRule: `{"all":[{"field":"asset_type","op":"eq","value":"AUDIENCE"},{"field":"asset.birth_countries","op":"eq","value":"Germany"}]}`,
+			expected:  []string{"german"},
+		},
+		{
+			name:      "charts favorited in the last 30 days",
+			givenRule: `{"all":[{"field":"asset_type","op":"eq","value":"CHART"},{"field":"created_at","op":"within_days","value":30}]}`,
+			expected:  []string{"chart"},
+		},
+		{
+			name:      "negation matches assets without the field",
+			givenRule: `{"not":{"field":"asset.birth_countries","op":"eq","value":"Germany"}}`,
+			expected:  []string{"insight", "chart", "french"},
+		},
+		{
+			name:      "numbers and in",
+			givenRule: `{"any":[{"field":"asset.social_media_hours.min","op":"gte","value":3},{"field":"asset_type","op":"in","value":["INSIGHT"]}]}`,
+			expected:  []string{"insight", "german"},
+		},
+		{
+			name:      "open bounds hold no value",
+			givenRule: `{"field":"asset.last_month_purchases.min","op":"gte","value":0}`,
+			expected:  []string{},
+		},
+		{
+			name:      "any of a set",
+			givenRule: `{"field":"asset.genders","op":"in","value":["M","X"]}`,
+			expected:  []string{"french"},
+		},
+		{
+			name:      "contains on a set",
+			givenRule: `{"field":"asset.birth_countries","op":"contains","value":"aus"}`,
+			expected:  []string{"german"},
+		},
+		{
+			name:      "contains matches wildcards literally",
+			givenRule: `{"field":"asset.title","op":"contains","value":"50%"}`,
+			expected:  []string{"chart"},
+		},
+		{
+			name:      "contains without match",
+			givenRule: `{"field":"asset.insight","op":"contains","value":"up_"}`,
+			expected:  []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, match(t, tc.givenRule))
+		})
+	}
+
+	t.Run("store and fetch", func(t *testing.T) {
+		var rule favorites.Rule
+		require.NoError(t, json.Unmarshal([]byte(testCases[0].givenRule), &rule))
+
+		created, err := repo.CreateSmartCollection(ctx, userID, &favorites.SmartCollectionParams{Name: "German audiences", Rule: rule})
+		require.NoError(t, err)
+
+		_, err = repo.CreateSmartCollection(ctx, userID, &favorites.SmartCollectionParams{Name: "German audiences", Rule: rule})
+		assert.ErrorIs(t, err, favorites.ErrCollectionNameTaken)
+
+		fetched, err := repo.FetchSmartCollection(ctx, created.ID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, rule, fetched.Rule)
+
+		_, err = repo.FetchSmartCollection(ctx, created.ID, "smart-other-user")
+		assert.ErrorIs(t, err, favorites.ErrSmartCollectionNotFound)
+
+		listed, err := repo.ListSmartCollections(ctx, userID)
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+		assert.Equal(t, created.ID, listed[0].ID)
+
+		require.NoError(t, repo.DeleteSmartCollection(ctx, created.ID, userID))
+		assert.ErrorIs(t, repo.DeleteSmartCollection(ctx, created.ID, userID), favorites.ErrSmartCollectionNotFound)
+	})
+}
+
+func TestRepository_SearchFavorites(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	repo := postgres.NewRepository(pool)
+	ctx := context.Background()
+
+	const userID = "search-user"
+
+	factory := assets.NewAssetFactory()
+
+	// favorited one after the other, so the latest one comes first
+	for _, description := range []string{"quarterly revenue review", "notes on revenue", "marketing campaign"} {
+		insight, err := factory.CreateInsight(description)
+		require.NoError(t, err)
+		require.NoError(t, repo.StoreAsset(ctx, insight))
+		require.NoError(t, storeFavorite(ctx, repo, &favorites.FavoriteAssetParams{
+			UserID:      userID,
+			AssetID:     insight.ID,
+			Description: description,
+		}))
+	}
+
+	favs, err := repo.GetUserFavorites(ctx, userID, &favorites.ListFavoritesParams{PageSize: 10})
+	require.NoError(t, err)
+	require.Len(t, favs, 3)
+
+	favIDs := make(map[string]string, len(favs))
+	for _, fav := range favs {
+		assert.Empty(t, fav.Tags)
+		favIDs[fav.Description] = fav.ID
+	}
+
+	updated, err := repo.UpdateFavorite(ctx, favIDs["marketing campaign"], userID, &favorites.UpdateFavoriteParams{
+		Description: "marketing campaign",
+		Tags:        []string{"revenue", "q1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"revenue", "q1"}, updated.Tags)
+
+	// nil tags are left as they are
+	updated, err = repo.UpdateFavorite(ctx, favIDs["quarterly revenue review"], userID, &favorites.UpdateFavoriteParams{
+		Description: "quarterly revenue review",
+		Tags:        []string{"finance"},
+	})
+	require.NoError(t, err)
+	updated, err = repo.UpdateFavorite(ctx, favIDs["quarterly revenue review"], userID, &favorites.UpdateFavoriteParams{
+		Description: "quarterly revenue review",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"finance"}, updated.Tags)
+
+	search := func(t *testing.T, query string, pageSize int) []string {
+		var (
+			matched []string
+			cursor  *favorites.Cursor
+		)
+		for {
+			favs, err := repo.GetUserFavorites(ctx, userID, &favorites.ListFavoritesParams{
+				PageSize: pageSize,
+				Cursor:   cursor,
+				Query:    query,
+			})
+			require.NoError(t, err)
+
+			for _, fav := range favs {
+				matched = append(matched, fav.Description)
+			}
+			if len(favs) < pageSize {
+				return matched
+			}
+
+			last := favs[len(favs)-1]
+			cursor = &favorites.Cursor{Position: last.Position, ID: last.ID, SearchRank: &last.SearchRank}
+		}
+	}
+
+	testCases := []struct {
+		name       string
+		givenQuery string
+		expected   []string
+	}{
+		{
+			name:       "tags rank above descriptions, ties keep the user's order",
+			givenQuery: "revenue",
+			expected:   []string{"marketing campaign", "notes on revenue", "quarterly revenue review"},
+		},
+		{
+			name:       "words are stemmed",
+			givenQuery: "reviews",
+			expected:   []string{"quarterly revenue review"},
+		},
+		{
+			name:       "tags only",
+			givenQuery: "finance",
+			expected:   []string{"quarterly revenue review"},
+		},
+		{
+			name:       "web search syntax",
+			givenQuery: "revenue -marketing",
+			expected:   []string{"notes on revenue", "quarterly revenue review"},
+		},
+		{
+			name:       "no match",
+			givenQuery: "audience",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, search(t, tc.givenQuery, 10))
+			assert.Equal(t, tc.expected, search(t, tc.givenQuery, 1), "paged one by one")
+		})
+	}
+}
+
+func TestRepository_FilterFavorites(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	repo := postgres.NewRepository(pool)
+	ctx := context.Background()
+
+	const userID = "filter-user"
+
+	factory := assets.NewAssetFactory()
+
+	chart, err := factory.CreateChart("Sales", "Month", "Revenue", []float64{1, 2})
+	require.NoError(t, err)
+	insight, err := factory.CreateInsight("Sales are up")
+	require.NoError(t, err)
+	audience, err := factory.CreateAudience(
+		[]string{"F"},
+		[]string{"Germany"},
+		[]assets.AgeGroup{assets.AgeGroup25To34},
+		assets.Between(3, 3),
+		assets.AtMost(2),
+		"",
+	)
+	require.NoError(t, err)
+
+	favorite := func(a assets.Asseter, id, description string) {
+		require.NoError(t, repo.StoreAsset(ctx, a))
+		require.NoError(t, storeFavorite(ctx, repo, &favorites.FavoriteAssetParams{
+			UserID:      userID,
+			AssetID:     id,
+			Description: description,
+		}))
+	}
+
+	favorite(chart, chart.ID, "chart")
+	favorite(insight, insight.ID, "insight")
+	afterFirstTwo := time.Now()
+	favorite(audience, audience.ID, "audience")
+
+	favs, err := repo.GetUserFavorites(ctx, userID, &favorites.ListFavoritesParams{PageSize: 10})
+	require.NoError(t, err)
+	require.Len(t, favs, 3)
+
+	beforeUpdate := time.Now()
+	for _, fav := range favs {
+		if fav.Description == "chart" {
+			_, err := repo.UpdateFavorite(ctx, fav.ID, userID, &favorites.UpdateFavoriteParams{Description: "chart"})
+			require.NoError(t, err)
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		givenParams favorites.ListFavoritesParams
+		expected    []string
+	}{
+		{
+			name:        "asset type",
+			givenParams: favorites.ListFavoritesParams{AssetTypes: []assets.AssetType{assets.TypeAssetChart}},
+			expected:    []string{"chart"},
+		},
+		{
+			name: "asset types",
+			givenParams: favorites.ListFavoritesParams{
+				AssetTypes: []assets.AssetType{assets.TypeAssetAudience, assets.TypeAssetInsight},
+			},
+			expected: []string{"audience", "insight"},
+		},
+		{
+			name:        "created after",
+			givenParams: favorites.ListFavoritesParams{CreatedAfter: afterFirstTwo},
+			expected:    []string{"audience"},
+		},
+		{
+			name:        "created before",
+			givenParams: favorites.ListFavoritesParams{CreatedBefore: afterFirstTwo},
+			expected:    []string{"insight", "chart"},
+		},
+		{
+			name:        "updated after",
+			givenParams: favorites.ListFavoritesParams{UpdatedAfter: beforeUpdate},
+			expected:    []string{"chart"},
+		},
+		{
+			name: "combined",
+			givenParams: favorites.ListFavoritesParams{
+				AssetTypes:    []assets.AssetType{assets.TypeAssetChart, assets.TypeAssetInsight},
+				CreatedBefore: afterFirstTwo,
+				UpdatedBefore: beforeUpdate,
+			},
+			expected: []string{"insight"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// paged one by one, so the filters are checked to combine with pagination
+			var matched []string
+			params := tc.givenParams
+			params.PageSize = 1
+			for {
+				favs, err := repo.GetUserFavorites(ctx, userID, &params)
+				require.NoError(t, err)
+				if len(favs) == 0 {
+					break
+				}
+				matched = append(matched, favs[0].Description)
+				params.Cursor = &favorites.Cursor{Position: favs[0].Position, ID: favs[0].ID}
+			}
+			assert.Equal(t, tc.expected, matched)
+		})
+	}
+}
+
+func TestRepository_FavoriteSnapshots(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	repo := postgres.NewRepository(pool)
+	ctx := context.Background()
+
+	const userID = "snapshot-user"
+
+	factory := assets.NewAssetFactory()
+
+	chart, err := factory.CreateChart("Sales", "Month", "Revenue", []float64{1, 2.5})
+	require.NoError(t, err)
+	insight, err := factory.CreateInsight("Sales are up")
+	require.NoError(t, err)
+	audience, err := factory.CreateAudience(
+		[]string{"F"},
+		[]string{"Germany"},
+		[]assets.AgeGroup{assets.AgeGroup25To34},
+		assets.Between(3, 3),
+		assets.AtMost(2),
+		"",
+	)
+	require.NoError(t, err)
+
+	for _, fav := range []struct {
+		asset assets.Asseter
+		id    string
+	}{
+		{chart, chart.ID},
+		{insight, insight.ID},
+		{audience, audience.ID},
+	} {
+		require.NoError(t, repo.StoreAsset(ctx, fav.asset))
+		require.NoError(t, storeFavorite(ctx, repo, &favorites.FavoriteAssetParams{
+			UserID:      userID,
+			AssetID:     fav.id,
+			Description: string(fav.asset.Type()),
+		}))
+	}
+
+	changed := func(t *testing.T) map[string]bool {
+		favs, err := repo.GetUserFavorites(ctx, userID, &favorites.ListFavoritesParams{PageSize: 10})
+		require.NoError(t, err)
+
+		result := make(map[string]bool, len(favs))
+		for _, fav := range favs {
+			result[fav.AssetID] = fav.ChangedSinceFavorited
+		}
+		return result
+	}
+
+	assert.Equal(t, map[string]bool{chart.ID: false, insight.ID: false, audience.ID: false}, changed(t))
+
+	updatedInsight, err := factory.CreateInsight("Sales are down")
+	require.NoError(t, err)
+	updatedInsight.ID = insight.ID
+	_, err = repo.UpdateAsset(ctx, updatedInsight)
+	require.NoError(t, err)
+
+	// the same data again isn't a change
+	_, err = repo.UpdateAsset(ctx, chart)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]bool{chart.ID: false, insight.ID: true, audience.ID: false}, changed(t))
+
+	// favoriting the asset again keeps the snapshot it was first favorited with
+	require.NoError(t, storeFavorite(ctx, repo, &favorites.FavoriteAssetParams{
+		UserID:      userID,
+		AssetID:     insight.ID,
+		Description: "again",
+	}))
+	assert.True(t, changed(t)[insight.ID])
+
+	favs, err := repo.GetUserFavorites(ctx, userID, &favorites.ListFavoritesParams{PageSize: 10})
+	require.NoError(t, err)
+
+	for _, fav := range favs {
+		snapshots, err := repo.FetchFavoriteSnapshots(ctx, fav.ID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, fav.AssetID, snapshots.AssetID)
+
+		switch fav.AssetID {
+		case chart.ID:
+			expected := favorites.AssetSnapshot{"title": "Sales", "x_axis": "Month", "y_axis": "Revenue", "data": []any{1.0, 2.5}}
+			assert.Equal(t, expected, snapshots.Favorited)
+			assert.Equal(t, expected, snapshots.Current)
+		case insight.ID:
+			assert.Equal(t, favorites.AssetSnapshot{"insight": "Sales are up"}, snapshots.Favorited)
+			assert.Equal(t, favorites.AssetSnapshot{"insight": "Sales are down"}, snapshots.Current)
+		case audience.ID:
+			assert.Equal(t, favorites.AssetSnapshot{
+				"gender":               "F",
+				"birth_country":        "Germany",
+				"age_min":              25.0,
+				"age_max":              34.0,
+				"social_media_hours":   3.0,
+				"last_month_purchases": 2.0,
+			}, snapshots.Favorited)
+		}
+
+		_, err = repo.FetchFavoriteSnapshots(ctx, fav.ID, "snapshot-other-user")
+		assert.ErrorIs(t, err, favorites.ErrFavoriteAssetNotFound)
+	}
+}
+
+func TestRepository_FavoritesTrash(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	repo := postgres.NewRepository(pool)
+	ctx := context.Background()
+
+	const userID = "trash-user"
+
+	factory := assets.NewAssetFactory()
+
+	// IDs sorting after every other test's assets, so the page starts with them
+	chart, err := factory.CreateChart("Trashed Chart", "X", "Y", []float64{1.0})
+	require.NoError(t, err)
+	chart.ID = "zz-trash-chart-1"
+	require.NoError(t, repo.StoreAsset(ctx, chart))
+
+	insight, err := factory.CreateInsight("Kept insight")
+	require.NoError(t, err)
+	require.NoError(t, repo.StoreAsset(ctx, insight))
+
+	results, err := repo.StoreFavoriteAssets(ctx, []*favorites.FavoriteAssetParams{
+		{UserID: userID, AssetID: insight.ID},
+		{UserID: userID, AssetID: chart.ID, Description: "Trashed favorite"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []error{nil, nil}, results)
+
+	favs, err := repo.GetUserFavorites(ctx, userID, &favorites.ListFavoritesParams{PageSize: 10})
+	require.NoError(t, err)
+	require.Len(t, favs, 2)
+	trashedID := favs[0].ID
+	require.Equal(t, chart.ID, favs[0].AssetID)
+
+	collection, err := repo.CreateCollection(ctx, userID, &favorites.CollectionParams{Name: "Trash"})
+	require.NoError(t, err)
+	require.NoError(t, repo.AddToCollection(ctx, collection.ID, trashedID, userID))
+
+	assertCounts := func(t *testing.T, expectedFavorites, expectedInCollection int) {
+		t.Helper()
+
+		listed, _, err := repo.ListAssets(ctx, &assets.ListAssetsParams{PageSize: 1, PageToken: "zz-trash-", UserID: userID})
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+		assert.Equal(t, int64(expectedFavorites), listed[0].FavoriteCount)
+		assert.Equal(t, expectedFavorites == 0, listed[0].FavoriteID == "")
+
+		collection, err := repo.FetchCollection(ctx, collection.ID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, expectedInCollection, collection.FavoriteCount)
+	}
+
+	deletedAfter := time.Now().Add(-time.Hour)
+
+	t.Run("deleting moves the favorite to the trash", func(t *testing.T) {
+		require.NoError(t, repo.DeleteFavorite(ctx, trashedID, userID))
+
+		err := repo.DeleteFavorite(ctx, trashedID, userID)
+		assert.ErrorIs(t, err, favorites.ErrFavoriteAssetNotFound)
+
+		_, err = repo.UpdateFavorite(ctx, trashedID, userID, &favorites.UpdateFavoriteParams{Description: "foo"})
+		assert.ErrorIs(t, err, favorites.ErrFavoriteAssetNotFound)
+
+		favs, err := repo.GetUserFavorites(ctx, userID, &favorites.ListFavoritesParams{PageSize: 10})
+		require.NoError(t, err)
+		require.Len(t, favs, 1)
+		assert.Equal(t, insight.ID, favs[0].AssetID)
+
+		trashed, err := repo.GetTrashedFavorites(ctx, userID, &favorites.ListTrashParams{PageSize: 10}, deletedAfter)
+		require.NoError(t, err)
+		require.Len(t, trashed, 1)
+		assert.Equal(t, trashedID, trashed[0].ID)
+		assert.Equal(t, "Trashed favorite", trashed[0].Description)
+		assert.WithinDuration(t, time.Now(), trashed[0].DeletedAt, time.Minute)
+		require.NotNil(t, trashed[0].Asset)
+		assert.Equal(t, assets.TypeAssetChart, trashed[0].Asset.Type())
+
+		// expired favorites aren't listed
+		trashed, err = repo.GetTrashedFavorites(ctx, userID, &favorites.ListTrashParams{PageSize: 10}, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		assert.Empty(t, trashed)
+
+		assertCounts(t, 0, 0)
+	})
+
+	t.Run("the asset can be favorited again while in the trash", func(t *testing.T) {
+		require.NoError(t, storeFavorite(ctx, repo, &favorites.FavoriteAssetParams{UserID: userID, AssetID: chart.ID}))
+		assertCounts(t, 1, 0)
+
+		_, err := repo.RestoreFavorite(ctx, trashedID, userID, deletedAfter)
+		assert.ErrorIs(t, err, favorites.ErrFavoriteAlreadyExists)
+
+		require.NoError(t, repo.DeleteFavoriteByAsset(ctx, chart.ID, userID))
+
+		// pages go from the most recently deleted
+		first, err := repo.GetTrashedFavorites(ctx, userID, &favorites.ListTrashParams{PageSize: 1}, deletedAfter)
+		require.NoError(t, err)
+		require.Len(t, first, 1)
+		assert.NotEqual(t, trashedID, first[0].ID)
+
+		second, err := repo.GetTrashedFavorites(ctx, userID, &favorites.ListTrashParams{
+			PageSize: 1,
+			Cursor:   &favorites.TrashCursor{DeletedAt: first[0].DeletedAt, ID: first[0].ID},
+		}, deletedAfter)
+		require.NoError(t, err)
+		require.Len(t, second, 1)
+		assert.Equal(t, trashedID, second[0].ID)
+	})
+
+	t.Run("restoring brings the favorite back", func(t *testing.T) {
+		_, err := repo.RestoreFavorite(ctx, trashedID, "trash-other-user", deletedAfter)
+		assert.ErrorIs(t, err, favorites.ErrFavoriteAssetNotFound)
+
+		// expired favorites can't be restored
+		_, err = repo.RestoreFavorite(ctx, trashedID, userID, time.Now().Add(time.Minute))
+		assert.ErrorIs(t, err, favorites.ErrFavoriteAssetNotFound)
+
+		restored, err := repo.RestoreFavorite(ctx, trashedID, userID, deletedAfter)
+		require.NoError(t, err)
+		assert.Equal(t, "Trashed favorite", restored.Description)
+
+		_, err = repo.RestoreFavorite(ctx, trashedID, userID, deletedAfter)
+		assert.ErrorIs(t, err, favorites.ErrFavoriteAssetNotFound)
+
+		favs, err := repo.GetUserFavorites(ctx, userID, &favorites.ListFavoritesParams{PageSize: 10})
+		require.NoError(t, err)
+		require.Len(t, favs, 2)
+		assert.Equal(t, trashedID, favs[0].ID)
+
+		assertCounts(t, 1, 1)
+	})
+
+	t.Run("purging removes expired favorites for good", func(t *testing.T) {
+		purged, err := repo.PurgeTrashedFavorites(ctx, time.Now())
+		require.NoError(t, err)
+		assert.Positive(t, purged)
+
+		trashed, err := repo.GetTrashedFavorites(ctx, userID, &favorites.ListTrashParams{PageSize: 10}, time.Time{})
+		require.NoError(t, err)
+		assert.Empty(t, trashed)
+
+		assertCounts(t, 1, 1)
+	})
+}
+
+func TestRepository_FrecencyFavorites(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	repo := postgres.NewRepository(pool)
+	ctx := context.Background()
+
+	const (
+		userID      = "frecency-user"
+		otherUserID = "frecency-other-user"
+	)
+
+	factory := assets.NewAssetFactory()
+
+	favoriteIDs := make(map[string]string)
+	for _, name := range []string{"a", "b", "c", "d"} {
+		insight, err := factory.CreateInsight("Frecency " + name)
+		require.NoError(t, err)
+		require.NoError(t, repo.StoreAsset(ctx, insight))
+		require.NoError(t, storeFavorite(ctx, repo, &favorites.FavoriteAssetParams{UserID: userID, AssetID: insight.ID, Description: name}))
+	}
+
+	favs, err := repo.GetUserFavorites(ctx, userID, &favorites.ListFavoritesParams{PageSize: 10})
+	require.NoError(t, err)
+	require.Len(t, favs, 4)
+
+	byPosition := make([]string, 0, len(favs))
+	for _, f := range favs {
+		favoriteIDs[f.Description] = f.ID
+		byPosition = append(byPosition, f.Description)
+		assert.True(t, math.IsInf(f.Frecency, -1), "never opened favorites have no frecency")
+	}
+
+	// "c" is opened once a long time ago, "a" twice more recently, so it ranks first
+	require.NoError(t, repo.RecordFavoriteOpen(ctx, favoriteIDs["c"], userID, 1.0))
+	require.NoError(t, repo.RecordFavoriteOpen(ctx, favoriteIDs["a"], userID, 5.0))
+	require.NoError(t, repo.RecordFavoriteOpen(ctx, favoriteIDs["a"], userID, 6.0))
+
+	// the rest keep their relative order by position
+	var expected []string
+	expected = append(expected, "a", "c")
+	for _, name := range byPosition {
+		if name != "a" && name != "c" {
+			expected = append(expected, name)
+		}
+	}
+
+	descriptions := func(favs []favorites.FavoriteAsset) []string {
+		names := make([]string, 0, len(favs))
+		for _, f := range favs {
+			names = append(names, f.Description)
+		}
+		return names
+	}
+
+	t.Run("sorted by frecency", func(t *testing.T) {
+		favs, err := repo.GetUserFavorites(ctx, userID, &favorites.ListFavoritesParams{PageSize: 10, Sort: favorites.SortFrecency})
+		require.NoError(t, err)
+		assert.Equal(t, expected, descriptions(favs))
+
+		// two opens add up: ln(e^5 + e^6)
+		assert.InDelta(t, 6.0+math.Log1p(math.Exp(-1)), favs[0].Frecency, 1e-9)
+		assert.InDelta(t, 1.0, favs[1].Frecency, 1e-9)
+	})
+
+	t.Run("paginated by frecency", func(t *testing.T) {
+		var got []string
+		params := &favorites.ListFavoritesParams{PageSize: 1, Sort: favorites.SortFrecency}
+		for range len(expected) + 1 {
+			page, err := repo.GetUserFavorites(ctx, userID, params)
+			require.NoError(t, err)
+			if len(page) == 0 {
+				break
+			}
+			last := page[len(page)-1]
+			got = append(got, descriptions(page)...)
+			params.Cursor = &favorites.Cursor{Position: last.Position, ID: last.ID, Frecency: &last.Frecency}
+		}
+		assert.Equal(t, expected, got)
+	})
+
+	t.Run("cursor without frecency", func(t *testing.T) {
+		_, err := repo.GetUserFavorites(ctx, userID, &favorites.ListFavoritesParams{
+			PageSize: 10,
+			Sort:     favorites.SortFrecency,
+			Cursor:   &favorites.Cursor{Position: favs[0].Position, ID: favs[0].ID},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("opening someone else's favorite", func(t *testing.T) {
+		err := repo.RecordFavoriteOpen(ctx, favoriteIDs["b"], otherUserID, 10.0)
+		assert.ErrorIs(t, err, favorites.ErrFavoriteAssetNotFound)
+	})
+
+	t.Run("opening a trashed favorite", func(t *testing.T) {
+		require.NoError(t, repo.DeleteFavorite(ctx, favoriteIDs["d"], userID))
+
+		err := repo.RecordFavoriteOpen(ctx, favoriteIDs["d"], userID, 10.0)
+		assert.ErrorIs(t, err, favorites.ErrFavoriteAssetNotFound)
+	})
+}
+
+func TestRepository_FavoriteJobsQueue(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	repo := postgres.NewRepository(pool)
+	ctx := context.Background()
+
+	first, err := repo.EnqueueFavoriteJob(ctx, &favorites.FavoriteAssetParams{
+		UserID:      "queue-user-1",
+		AssetID:     "queue-asset-1",
+		Description: "first",
+	}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, favorites.JobStatusPending, first.Status)
+
+	second, err := repo.EnqueueFavoriteJob(ctx, &favorites.FavoriteAssetParams{
+		UserID:  "queue-user-2",
+		AssetID: "queue-asset-2",
+	}, 2)
+	require.NoError(t, err)
+
+	// two jobs pending already
+	_, err = repo.EnqueueFavoriteJob(ctx, &favorites.FavoriteAssetParams{
+		UserID:  "queue-user-3",
+		AssetID: "queue-asset-3",
+	}, 2)
+	assert.ErrorIs(t, err, favorites.ErrQueueFull)
+
+	pending, err := repo.CountPendingFavoriteJobs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), pending)
+
+	// oldest job first
+	claimed, err := repo.ClaimFavoriteJobs(ctx, 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, first.ID, claimed[0].ID)
+	assert.Equal(t, "first", claimed[0].Description)
+
+	// leased jobs are not handed out again
+	claimed, err = repo.ClaimFavoriteJobs(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, second.ID, claimed[0].ID)
+
+	// released jobs can be claimed right away, and releasing them doesn't count as an attempt
+	require.NoError(t, repo.ReleaseFavoriteJobs(ctx, []string{second.ID}))
+
+	claimed, err = repo.ClaimFavoriteJobs(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, second.ID, claimed[0].ID)
+	assert.Equal(t, 1, claimed[0].Attempts)
+
+	// a job is only settled under the lease of its latest claim
+	err = repo.RetryFavoriteJob(ctx, second.ID, 0, time.Now(), assert.AnError)
+	assert.ErrorIs(t, err, favorites.ErrLeaseLost)
+
+	// a retried job is not claimed before it's due
+	require.NoError(t, repo.RetryFavoriteJob(ctx, second.ID, 1, time.Now().Add(time.Hour), assert.AnError))
+
+	claimed, err = repo.ClaimFavoriteJobs(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	job, err := repo.FetchFavoriteJob(ctx, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, favorites.JobStatusPending, job.Status)
+	assert.Equal(t, 1, job.Attempts)
+	assert.NoError(t, job.Err)
+
+	require.NoError(t, repo.CompleteFavoriteJob(ctx, first.ID, 1))
+	require.NoError(t, repo.DeadLetterFavoriteJob(ctx, second.ID, 1, assets.ErrAssetNotFound))
+
+	// settled jobs can't be settled again
+	err = repo.CompleteFavoriteJob(ctx, second.ID, 1)
+	assert.ErrorIs(t, err, favorites.ErrLeaseLost)
+
+	err = repo.DeadLetterFavoriteJob(ctx, first.ID, 1, assets.ErrAssetNotFound)
+	assert.ErrorIs(t, err, favorites.ErrLeaseLost)
+
+	job, err = repo.FetchFavoriteJob(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, favorites.JobStatusSucceeded, job.Status)
+	assert.NoError(t, job.Err)
+
+	job, err = repo.FetchFavoriteJob(ctx, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, favorites.JobStatusFailed, job.Status)
+	assert.EqualError(t, job.Err, assets.ErrAssetNotFound.Error())
+
+	// finished jobs are not claimed anymore
+	claimed, err = repo.ClaimFavoriteJobs(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	// the failed job left a dead letter behind
+	deadLetters, err := repo.ListDeadLetters(ctx, &favorites.ListDeadLettersParams{PageSize: 10})
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, second.ID, deadLetters[0].JobID)
+	assert.Equal(t, "queue-user-2", deadLetters[0].UserID)
+	assert.Equal(t, "queue-asset-2", deadLetters[0].AssetID)
+	assert.Equal(t, 1, deadLetters[0].Attempts)
+	assert.Equal(t, assets.ErrAssetNotFound.Error(), deadLetters[0].LastError)
+
+	purged, err := repo.PurgeFavoriteJobs(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, purged)
+
+	_, err = repo.FetchFavoriteJob(ctx, first.ID)
+	assert.ErrorIs(t, err, favorites.ErrFavoriteJobNotFound)
+
+	// replaying the dead letter queues a new job with the same params
+	replayed, err := repo.ReplayDeadLetter(ctx, deadLetters[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "queue-user-2", replayed.UserID)
+	assert.Equal(t, "queue-asset-2", replayed.AssetID)
+
+	claimed, err = repo.ClaimFavoriteJobs(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, replayed.ID, claimed[0].ID)
+
+	_, err = repo.ReplayDeadLetter(ctx, deadLetters[0].ID)
+	assert.ErrorIs(t, err, favorites.ErrDeadLetterNotFound)
+
+	require.NoError(t, repo.CompleteFavoriteJob(ctx, replayed.ID, 1))
+
+	// a user's due jobs are claimed together, in the order they were queued
+	older, err := repo.EnqueueFavoriteJob(ctx, &favorites.FavoriteAssetParams{
+		UserID:  "queue-user-4",
+		AssetID: "queue-asset-4",
+	}, 10)
+	require.NoError(t, err)
+
+	newer, err := repo.EnqueueFavoriteJob(ctx, &favorites.FavoriteAssetParams{
+		UserID:  "queue-user-4",
+		AssetID: "queue-asset-5",
+	}, 10)
+	require.NoError(t, err)
+
+	latest, err := repo.LatestPendingFavoriteJobID(ctx, "queue-user-4")
+	require.NoError(t, err)
+	assert.Equal(t, newer.ID, latest)
+
+	claimed, err = repo.ClaimFavoriteJobs(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	assert.Equal(t, older.ID, claimed[0].ID)
+	assert.Equal(t, newer.ID, claimed[1].ID)
+
+	// a retried job keeps its place ahead of the user's newer jobs, which wait for it
+	require.NoError(t, repo.RetryFavoriteJob(ctx, older.ID, 1, time.Now().Add(time.Hour), assert.AnError))
+	require.NoError(t, repo.RetryFavoriteJob(ctx, newer.ID, 1, time.Now(), assert.AnError))
+
+	claimed, err = repo.ClaimFavoriteJobs(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	require.NoError(t, repo.RetryFavoriteJob(ctx, older.ID, 1, time.Now(), assert.AnError))
+
+	// a limit cutting through a user's jobs leaves the newer ones for later
+	claimed, err = repo.ClaimFavoriteJobs(ctx, 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, older.ID, claimed[0].ID)
+
+	claimed, err = repo.ClaimFavoriteJobs(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	require.NoError(t, repo.CompleteFavoriteJob(ctx, older.ID, 2))
+
+	claimed, err = repo.ClaimFavoriteJobs(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, newer.ID, claimed[0].ID)
+
+	require.NoError(t, repo.CompleteFavoriteJob(ctx, newer.ID, 2))
+
+	latest, err = repo.LatestPendingFavoriteJobID(ctx, "queue-user-4")
+	require.NoError(t, err)
+	assert.Empty(t, latest)
+}
+
+func TestRepository_CountRespondents(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	repo := postgres.NewRepository(pool)
+	ctx := context.Background()
+
+	// a country of their own, so other tests' respondents are never counted
+	const country = "Respondentland"
+
+	respondents := []assets.Respondent{
+		{ID: "resp-1", Gender: "Female", BirthCountry: country, Age: 17, SocialMediaHours: 6, LastMonthPurchases: 1},
+		{ID: "resp-2", Gender: "Female", BirthCountry: country, Age: 18, SocialMediaHours: 2.5, LastMonthPurchases: 0},
+		{ID: "resp-3", Gender: "Female", BirthCountry: country, Age: 30, SocialMediaHours: 5.5, LastMonthPurchases: 4},
+		{ID: "resp-4", Gender: "Male", BirthCountry: country, Age: 24, SocialMediaHours: 1, LastMonthPurchases: 2},
+		{ID: "resp-5", Gender: "Other", BirthCountry: country, Age: 70, SocialMediaHours: 0, LastMonthPurchases: 12},
+		{ID: "resp-6", Gender: "Female", BirthCountry: "Elsewhere", Age: 20, SocialMediaHours: 3, LastMonthPurchases: 1},
+	}
+	require.NoError(t, repo.StoreRespondents(ctx, respondents))
+
+	// loading a respondent again replaces it
+	respondents[0].Age = 16
+	require.NoError(t, repo.StoreRespondents(ctx, respondents[:1]))
+
+	allAgeGroups := assets.AgeGroups()
+
+	testCases := []struct {
+		name            string
+		givenGenders    []string
+		givenAgeGroups  []assets.AgeGroup
+		givenHours      assets.Range
+		givenPurchases  assets.Range
+		givenExpression string
+		expectCount     int
+	}{
+		{
+			name:           "everyone of the country",
+			givenGenders:   []string{"Female", "Male", "Other"},
+			givenAgeGroups: allAgeGroups,
+			expectCount:    5,
+		},
+		{
+			name:           "genders and age groups",
+			givenGenders:   []string{"Female", "Male"},
+			givenAgeGroups: []assets.AgeGroup{assets.AgeGroup18To24},
+			expectCount:    2,
+		},
+		{
+			name:           "oldest age group is open above",
+			givenGenders:   []string{"Other"},
+			givenAgeGroups: []assets.AgeGroup{assets.AgeGroup65Plus},
+			expectCount:    1,
+		},
+		{
+			name:           "ranges bounds included",
+			givenGenders:   []string{"Female", "Male", "Other"},
+			givenAgeGroups: allAgeGroups,
+			givenHours:     assets.Between(1, 5),
+			givenPurchases: assets.AtLeast(2),
+			expectCount:    1,
+		},
+		{
+			name:            "expression",
+			givenGenders:    []string{"Female", "Male", "Other"},
+			givenAgeGroups:  allAgeGroups,
+			givenExpression: `gender IN ("Female") AND (age BETWEEN 18 AND 24 OR social_media_hours > 5)`,
+			expectCount:     3,
+		},
+		{
+			name:            "negated expression",
+			givenGenders:    []string{"Female", "Male", "Other"},
+			givenAgeGroups:  allAgeGroups,
+			givenExpression: `NOT gender = "Female" AND age NOT IN (24) AND last_month_purchases NOT BETWEEN 0 AND 2`,
+			expectCount:     1,
+		},
+	}
+
+	factory := assets.NewAssetFactory()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			audience, err := factory.CreateAudience(
+				tc.givenGenders,
+				[]string{country},
+				tc.givenAgeGroups,
+				tc.givenHours,
+				tc.givenPurchases,
+				tc.givenExpression,
+			)
+			require.NoError(t, err)
+
+			got, err := repo.CountRespondents(ctx, audience)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectCount, got)
+		})
+	}
+}